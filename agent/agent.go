@@ -30,6 +30,7 @@ import (
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/logging/measure"
+	"github.com/ligato/cn-infra/utils/handover"
 	"github.com/ligato/cn-infra/utils/once"
 )
 
@@ -78,6 +79,7 @@ func NewAgent(opts ...Option) Agent {
 
 	if !flag.Parsed() {
 		config.DefineDirFlag()
+		config.DefineProfileFlags()
 		for _, p := range options.Plugins {
 			name := p.String()
 			infraLogger.Debugf("registering flags for: %q", name)
@@ -87,8 +89,9 @@ func NewAgent(opts ...Option) Agent {
 	}
 
 	return &agent{
-		opts:   options,
-		tracer: measure.NewTracer("agent-plugins"),
+		opts:        options,
+		tracer:      measure.NewTracer("agent-plugins"),
+		pluginTimes: make(map[infra.Plugin]*pluginTimes),
 	}
 }
 
@@ -104,6 +107,9 @@ type agent struct {
 
 	mu        sync.Mutex
 	curPlugin infra.Plugin
+
+	pluginTimes map[infra.Plugin]*pluginTimes
+	bootReport  *BootReport
 }
 
 // Options returns the Options the agent was created with
@@ -176,11 +182,17 @@ func (a *agent) starter() error {
 	}
 	close(started)
 
+	took := time.Since(t)
 	agentLogger.Infof("Agent started with %d plugins (took %v)",
-		len(a.opts.Plugins), time.Since(t).Round(time.Millisecond))
+		len(a.opts.Plugins), took.Round(time.Millisecond))
 
 	a.stopCh = make(chan struct{}) // If we are started, we have a stopCh to signal stopping
 
+	stopUpgrade := func() {}
+	if a.opts.UpgradeSignal != nil {
+		stopUpgrade = handover.HandleSignal(a.opts.UpgradeSignal, agentLogger)
+	}
+
 	go func() {
 		var quit <-chan struct{}
 		if a.opts.Context != nil {
@@ -199,10 +211,18 @@ func (a *agent) starter() error {
 		}
 		// Doesn't hurt to call Stop twice, its idempotent because of the
 		// stopOnce
+		stopUpgrade()
 		a.Stop()
 		signal.Stop(sig)
 	}()
 
+	report := a.buildBootReport(t, took)
+	a.mu.Lock()
+	a.bootReport = report
+	a.mu.Unlock()
+	logBanner(report)
+	a.emitBootReport(report)
+
 	return nil
 }
 
@@ -223,6 +243,7 @@ func (a *agent) start() error {
 		}
 
 		a.tracer.LogTime(fmt.Sprintf("%v.Init", plugin), t)
+		a.pluginTimes[plugin] = &pluginTimes{init: time.Since(t)}
 	}
 
 	// AfterInit plugins
@@ -238,6 +259,10 @@ func (a *agent) start() error {
 			if err := postPlugin.AfterInit(); err != nil {
 				return err
 			}
+			if times, ok := a.pluginTimes[plugin]; ok {
+				times.afterInit = time.Since(t)
+				times.hasAfterInit = true
+			}
 		} else {
 			agentLogger.Debugf("-- AfterInit(): %v (not used)", plugin)
 		}
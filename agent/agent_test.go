@@ -15,7 +15,9 @@
 package agent_test // Different name from package agent to insure we test with the 'outside the package' experience
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sync"
 	"syscall"
@@ -269,6 +271,58 @@ func TestAgentWithPluginNoAfterInit(t *testing.T) {
 	Expect(err).To(BeNil())
 }
 
+func TestBootReport(t *testing.T) {
+	RegisterTestingT(t)
+
+	file, err := ioutil.TempFile("", "boot-report")
+	Expect(err).To(BeNil())
+	file.Close()
+	defer os.Remove(file.Name())
+
+	p := NewTestPlugin(false, false, false)
+	p.SetName("plugin")
+	reporter := &TestReporterPlugin{}
+	a := agent.NewAgent(agent.Plugins(p, reporter), agent.BootReportFile(file.Name()))
+	Expect(agent.GetBootReport(a)).To(BeNil())
+	Expect(a.Start()).To(Succeed())
+	defer a.Stop()
+
+	data, err := ioutil.ReadFile(file.Name())
+	Expect(err).To(BeNil())
+	var report agent.BootReport
+	Expect(json.Unmarshal(data, &report)).To(Succeed())
+	Expect(report.Version).To(Equal(agent.BuildVersion))
+	Expect(report.PID).To(Equal(os.Getpid()))
+	Expect(report.Plugins).To(HaveLen(2))
+	Expect(report.Plugins[0].Name).To(Equal("plugin"))
+	Expect(report.Plugins[0].Health).To(Equal("ok"))
+	Expect(report.Plugins[0].AfterInitDuration).ToNot(BeEmpty())
+	Expect(report.Plugins[1].ListenAddresses).To(Equal([]string{"http://0.0.0.0:9191"}))
+	Expect(report.Plugins[1].AfterInitDuration).To(BeEmpty())
+	Expect(agent.GetBootReport(a).Plugins).To(HaveLen(2))
+}
+
+// Define the TestReporterPlugin contributing to the boot report
+
+type TestReporterPlugin struct {
+	TestPluginNoAfterInit
+}
+
+func (*TestReporterPlugin) String() string {
+	return "reporter"
+}
+
+func (*TestReporterPlugin) ListenAddresses() []string {
+	return []string{"http://0.0.0.0:9191"}
+}
+
+func (*TestReporterPlugin) PluginHealth(pluginName infra.PluginName) string {
+	if pluginName == "plugin" {
+		return "ok"
+	}
+	return ""
+}
+
 // Define the TestPluginNoAfterInit we will use for testing
 
 type TestPluginNoAfterInit struct{}
@@ -0,0 +1,214 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ligato/cn-infra/config"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+)
+
+// BootReportFileEnv is the name of the environment variable setting the file
+// the boot report is written into (see BootReportFile).
+const BootReportFileEnv = "BOOT_REPORT_FILE"
+
+// BootReport describes the started agent. It is built once all plugins
+// have been initialized and can be used to verify deployments automatically
+// or included into support bundles.
+type BootReport struct {
+	Version       string          `json:"version"`
+	BuildDate     string          `json:"build-date,omitempty"`
+	CommitHash    string          `json:"commit-hash,omitempty"`
+	GoVersion     string          `json:"go-version"`
+	Hostname      string          `json:"hostname,omitempty"`
+	PID           int             `json:"pid"`
+	StartedAt     time.Time       `json:"started-at"`
+	StartDuration string          `json:"start-duration"`
+	ConfigDir     string          `json:"config-dir,omitempty"`
+	ConfigProfile string          `json:"config-profile,omitempty"`
+	Plugins       []*PluginReport `json:"plugins"`
+}
+
+// PluginReport describes a single plugin in the BootReport.
+type PluginReport struct {
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	ConfigFile        string   `json:"config-file,omitempty"`
+	ListenAddresses   []string `json:"listen-addresses,omitempty"`
+	Health            string   `json:"health,omitempty"`
+	InitDuration      string   `json:"init-duration"`
+	AfterInitDuration string   `json:"after-init-duration,omitempty"`
+}
+
+// ListenAddressReporter can be implemented by plugins accepting connections
+// to include their listen addresses in the boot report.
+type ListenAddressReporter interface {
+	// ListenAddresses returns addresses the plugin listens on.
+	ListenAddresses() []string
+}
+
+// HealthReporter can be implemented by a plugin tracking the state of other
+// plugins (i.e. statuscheck) to include their health in the boot report.
+type HealthReporter interface {
+	// PluginHealth returns the state of the plugin or empty string if unknown.
+	PluginHealth(pluginName infra.PluginName) string
+}
+
+// BootReportFile returns an Option that writes the boot report as JSON into
+// the file once the agent has started. By default, the file is taken from
+// the BOOT_REPORT_FILE environment variable.
+func BootReportFile(path string) Option {
+	return func(o *Options) {
+		o.BootReportFile = path
+	}
+}
+
+// LogBootReport returns an Option that logs the boot report once the agent has started.
+func LogBootReport() Option {
+	return func(o *Options) {
+		o.LogBootReport = true
+	}
+}
+
+// GetBootReport returns the report built once the agent a has started.
+// It returns nil before that or if the agent was not created by NewAgent.
+func GetBootReport(a Agent) *BootReport {
+	if ag, ok := a.(*agent); ok {
+		ag.mu.Lock()
+		defer ag.mu.Unlock()
+		return ag.bootReport
+	}
+	return nil
+}
+
+// pluginTimes holds durations of plugin start phases.
+type pluginTimes struct {
+	init, afterInit time.Duration
+	hasAfterInit    bool
+}
+
+// buildBootReport creates report of the started agent.
+func (a *agent) buildBootReport(startedAt time.Time, took time.Duration) *BootReport {
+	report := &BootReport{
+		Version:       BuildVersion,
+		BuildDate:     BuildDate,
+		CommitHash:    CommitHash,
+		GoVersion:     runtime.Version(),
+		PID:           os.Getpid(),
+		StartedAt:     startedAt,
+		StartDuration: took.Round(time.Millisecond).String(),
+		ConfigProfile: config.ActiveProfile(),
+	}
+	report.Hostname, _ = os.Hostname()
+	report.ConfigDir, _ = config.Dir()
+
+	var health []HealthReporter
+	for _, plugin := range a.opts.Plugins {
+		if hr, ok := plugin.(HealthReporter); ok {
+			health = append(health, hr)
+		}
+	}
+
+	for _, plugin := range a.opts.Plugins {
+		pr := &PluginReport{
+			Name:       plugin.String(),
+			Type:       reflect.TypeOf(plugin).String(),
+			ConfigFile: pluginConfigFile(plugin),
+		}
+		if times, ok := a.pluginTimes[plugin]; ok {
+			pr.InitDuration = times.init.Round(time.Microsecond).String()
+			if times.hasAfterInit {
+				pr.AfterInitDuration = times.afterInit.Round(time.Microsecond).String()
+			}
+		}
+		if lr, ok := plugin.(ListenAddressReporter); ok {
+			pr.ListenAddresses = lr.ListenAddresses()
+		}
+		for _, hr := range health {
+			if pr.Health = hr.PluginHealth(infra.PluginName(pr.Name)); pr.Health != "" {
+				break
+			}
+		}
+		report.Plugins = append(report.Plugins, pr)
+	}
+	return report
+}
+
+// logBanner logs a short summary of the started agent.
+func logBanner(report *BootReport) {
+	var listen []string
+	for _, pr := range report.Plugins {
+		listen = append(listen, pr.ListenAddresses...)
+	}
+	fields := logging.Fields{
+		"Version": report.Version,
+		"PID":     report.PID,
+	}
+	if len(listen) > 0 {
+		fields["Listen"] = strings.Join(listen, ",")
+	}
+	agentLogger.WithFields(fields).Infof("Agent is up with %d plugins", len(report.Plugins))
+}
+
+// emitBootReport writes and/or logs the boot report as configured by options.
+func (a *agent) emitBootReport(report *BootReport) {
+	if a.opts.BootReportFile == "" && !a.opts.LogBootReport {
+		return
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		agentLogger.Errorf("marshalling boot report failed: %v", err)
+		return
+	}
+	if a.opts.LogBootReport {
+		agentLogger.Infof("Boot report: %s", data)
+	}
+	if a.opts.BootReportFile != "" {
+		if err := ioutil.WriteFile(a.opts.BootReportFile, data, 0644); err != nil {
+			agentLogger.Errorf("writing boot report to %s failed: %v", a.opts.BootReportFile, err)
+		}
+	}
+}
+
+// pluginConfigFile returns path of the config file used by the plugin
+// (plugins embedding infra.PluginDeps have it in the Cfg field).
+func pluginConfigFile(plugin infra.Plugin) string {
+	v := reflect.ValueOf(plugin)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName("Cfg")
+	if !field.IsValid() || !field.CanInterface() {
+		return ""
+	}
+	if cfg, ok := field.Interface().(config.PluginConfig); ok && cfg != nil {
+		return cfg.GetConfigName()
+	}
+	return ""
+}
@@ -38,11 +38,23 @@ There are various options available to customize agent:
 	QuitSignals(signals)	- sets signals used to quit the running agent (default: SIGINT, SIGTERM)
 	StartTimeout(dur)   	- sets start timeout (default: 15s)
 	StopTimeout(dur)    	- sets stop timeout (default: 5s)
+	BootReportFile(path)	- writes JSON boot report into file (default: $BOOT_REPORT_FILE)
+	LogBootReport()     	- logs JSON boot report once the agent started
 
 There are two options for adding plugins to the agent:
 
 	Plugins(...)	- adds just single plugins without lookup
 	AllPlugins(...)	- adds plugin along with all of its plugin deps
 
+Boot report
+
+Once all plugins are initialized, the agent builds BootReport with version
+info, config directory and profile and, for every plugin, its config file
+and start durations. Plugins implementing ListenAddressReporter add their
+listen addresses (REST, GRPC) and a plugin implementing HealthReporter
+(statuscheck) adds the state of all plugins. The report is available via
+GetBootReport(agent) and can be written into a file or logged. A short
+banner summarizing the report is logged once the agent has started.
+
 */
 package agent
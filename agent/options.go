@@ -36,12 +36,16 @@ var (
 
 // Options specifies option list for the Agent
 type Options struct {
-	StartTimeout time.Duration
-	StopTimeout  time.Duration
-	QuitSignals  []os.Signal
-	QuitChan     chan struct{}
-	Context      context.Context
-	Plugins      []infra.Plugin
+	StartTimeout  time.Duration
+	StopTimeout   time.Duration
+	QuitSignals   []os.Signal
+	QuitChan      chan struct{}
+	UpgradeSignal os.Signal
+	Context       context.Context
+	Plugins       []infra.Plugin
+
+	BootReportFile string
+	LogBootReport  bool
 
 	pluginMap   map[infra.Plugin]struct{}
 	pluginNames map[string]struct{}
@@ -55,8 +59,9 @@ func newOptions(opts ...Option) Options {
 			os.Interrupt,
 			syscall.SIGTERM,
 		},
-		pluginMap:   make(map[infra.Plugin]struct{}),
-		pluginNames: make(map[string]struct{}),
+		BootReportFile: os.Getenv(BootReportFileEnv),
+		pluginMap:      make(map[infra.Plugin]struct{}),
+		pluginNames:    make(map[string]struct{}),
 	}
 
 	for _, o := range opts {
@@ -113,6 +118,15 @@ func QuitOnClose(ch chan struct{}) Option {
 	}
 }
 
+// UpgradeOnSignal returns an Option that will set signal which starts new instance
+// of the agent binary taking over listening sockets (see utils/handover). Running agent
+// stops once the new instance is ready.
+func UpgradeOnSignal(sig os.Signal) Option {
+	return func(o *Options) {
+		o.UpgradeSignal = sig
+	}
+}
+
 // Plugins creates an Option that adds a list of Plugins to the Agent's Plugin list
 func Plugins(plugins ...infra.Plugin) Option {
 	return func(o *Options) {
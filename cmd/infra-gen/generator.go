@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	pluginNameRe = regexp.MustCompile(`^[a-z][a-z0-9]*([-_][a-z0-9]+)*$`)
+	packageRe    = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// Params are the values used to render the plugin skeleton.
+type Params struct {
+	// Name of the plugin (PluginName), also used as name of the config file
+	Name string
+	// Package is the name of the generated Go package
+	Package string
+}
+
+// NewParams validates the plugin name and derives package name from it if not set.
+func NewParams(name, pkg string) (*Params, error) {
+	if !pluginNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid plugin name %q: use lower case letters, digits, '-' or '_'", name)
+	}
+	if pkg == "" {
+		pkg = strings.NewReplacer("-", "", "_", "").Replace(name)
+	}
+	if !packageRe.MatchString(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+	return &Params{Name: name, Package: pkg}, nil
+}
+
+// skeleton lists generated files with their templates
+var skeleton = []struct {
+	file string
+	tmpl *template.Template
+}{
+	{"doc.go", template.Must(template.New("doc").Parse(docTmpl))},
+	{"options.go", template.Must(template.New("options").Parse(optionsTmpl))},
+	{"plugin.go", template.Must(template.New("plugin").Parse(pluginTmpl))},
+	{"plugin_test.go", template.Must(template.New("test").Parse(testTmpl))},
+	{"{{.Name}}.conf", template.Must(template.New("conf").Parse(confTmpl))},
+}
+
+// Generate renders the plugin skeleton into the output directory and returns paths
+// of the created files. Existing files are not overwritten unless force is set.
+func Generate(params *Params, outDir string, force bool) ([]string, error) {
+	type rendered struct {
+		path string
+		data []byte
+	}
+	var files []rendered
+	for _, f := range skeleton {
+		path := filepath.Join(outDir, strings.Replace(f.file, "{{.Name}}", params.Name, 1))
+		if _, err := os.Stat(path); err == nil && !force {
+			return nil, fmt.Errorf("file %s already exists (use -force to overwrite)", path)
+		}
+
+		var buf bytes.Buffer
+		if err := f.tmpl.Execute(&buf, params); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %v", f.file, err)
+		}
+		data := buf.Bytes()
+		if strings.HasSuffix(path, ".go") {
+			var err error
+			if data, err = format.Source(data); err != nil {
+				return nil, fmt.Errorf("failed to format %s: %v", f.file, err)
+			}
+		}
+		files = append(files, rendered{path: path, data: data})
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	var created []string
+	for _, f := range files {
+		if err := ioutil.WriteFile(f.path, f.data, 0644); err != nil {
+			return created, err
+		}
+		created = append(created, f.path)
+	}
+	return created, nil
+}
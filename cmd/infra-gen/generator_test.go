@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewParams(t *testing.T) {
+	RegisterTestingT(t)
+
+	params, err := NewParams("my-plugin", "")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(params.Package).To(Equal("myplugin"))
+
+	_, err = NewParams("My Plugin", "")
+	Expect(err).To(HaveOccurred())
+	_, err = NewParams("my-plugin", "my-pkg")
+	Expect(err).To(HaveOccurred())
+}
+
+func TestGenerate(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "infra-gen")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	params, err := NewParams("my-plugin", "")
+	Expect(err).ToNot(HaveOccurred())
+	out := filepath.Join(dir, params.Package)
+
+	files, err := Generate(params, out, false)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(files).To(ConsistOf(
+		filepath.Join(out, "doc.go"),
+		filepath.Join(out, "options.go"),
+		filepath.Join(out, "plugin.go"),
+		filepath.Join(out, "plugin_test.go"),
+		filepath.Join(out, "my-plugin.conf"),
+	))
+
+	options, err := ioutil.ReadFile(filepath.Join(out, "options.go"))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(string(options)).To(ContainSubstring(`p.PluginName = "my-plugin"`))
+
+	// existing files are kept unless forced
+	_, err = Generate(params, out, false)
+	Expect(err).To(HaveOccurred())
+	_, err = Generate(params, out, true)
+	Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command infra-gen generates skeleton of a new cn-infra plugin consistent
+// with conventions used across cn-infra plugins (Deps embedding PluginDeps,
+// options.go with NewPlugin/UseDeps/UseConf, plugin config file and unit test).
+//
+// Usage:
+//
+//	infra-gen -name my-plugin [-pkg myplugin] [-out ./myplugin] [-force]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var (
+		name  = flag.String("name", "", "name of the plugin (e.g. my-plugin), required")
+		pkg   = flag.String("pkg", "", "name of the generated package (derived from plugin name if not set)")
+		out   = flag.String("out", "", "output directory (./<pkg> if not set)")
+		force = flag.Bool("force", false, "overwrite existing files")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -name <plugin-name> [options]\n\nOptions:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	params, err := NewParams(*name, *pkg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = params.Package
+	}
+
+	files, err := Generate(params, *out, *force)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, file := range files {
+		fmt.Println("created", file)
+	}
+}
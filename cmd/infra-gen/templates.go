@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+const docTmpl = `// Package {{.Package}} implements the {{.Name}} plugin.
+package {{.Package}}
+`
+
+const optionsTmpl = `package {{.Package}}
+
+// DefaultPlugin is a default instance of Plugin.
+var DefaultPlugin = *NewPlugin()
+
+// NewPlugin creates a new Plugin with the provided Options.
+func NewPlugin(opts ...Option) *Plugin {
+	p := &Plugin{}
+
+	p.PluginName = "{{.Name}}"
+
+	for _, o := range opts {
+		o(p)
+	}
+
+	p.PluginDeps.Setup()
+
+	return p
+}
+
+// Option is a function that can be used in NewPlugin to customize Plugin.
+type Option func(*Plugin)
+
+// UseDeps returns Option that can inject custom dependencies.
+func UseDeps(cb func(*Deps)) Option {
+	return func(p *Plugin) {
+		cb(&p.Deps)
+	}
+}
+
+// UseConf returns Option which injects a particular configuration.
+func UseConf(conf Config) Option {
+	return func(p *Plugin) {
+		p.conf = &conf
+	}
+}
+`
+
+const pluginTmpl = `package {{.Package}}
+
+import (
+	"github.com/ligato/cn-infra/infra"
+)
+
+// Plugin is the {{.Name}} plugin.
+type Plugin struct {
+	Deps
+
+	conf *Config
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	// add other dependencies here
+}
+
+// Config is the configuration of the plugin loaded from {{.Name}}.conf.
+type Config struct {
+	Disabled bool ` + "`json:\"disabled\"`" + `
+}
+
+// Init loads the plugin configuration.
+func (p *Plugin) Init() error {
+	if p.conf == nil {
+		p.conf = &Config{}
+		found, err := p.Cfg.LoadValue(p.conf)
+		if err != nil {
+			return err
+		}
+		if !found {
+			p.Log.Debugf("%s config not found, using defaults", p.String())
+		}
+	}
+	if p.conf.Disabled {
+		p.Log.Info("plugin disabled by configuration")
+		return nil
+	}
+
+	// initialize the plugin here
+	return nil
+}
+
+// AfterInit is called once Init of all plugins have returned without error.
+func (p *Plugin) AfterInit() error {
+	return nil
+}
+
+// Close releases resources used by the plugin.
+func (p *Plugin) Close() error {
+	return nil
+}
+`
+
+const testTmpl = `package {{.Package}}
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPluginInit(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(Config{}))
+	Expect(p.String()).To(Equal("{{.Name}}"))
+	Expect(p.Init()).To(Succeed())
+	Expect(p.AfterInit()).To(Succeed())
+	Expect(p.Close()).To(Succeed())
+}
+`
+
+const confTmpl = `# Disables the plugin.
+disabled: false
+`
@@ -15,9 +15,11 @@
 package config_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/ligato/cn-infra/config"
+	"github.com/namsral/flag"
 	. "github.com/onsi/gomega"
 )
 
@@ -58,3 +60,24 @@ func TestForPluginWithSpecifiedConfigFile(t *testing.T) {
 	configName := pluginConfig.GetConfigName()
 	Expect(configName).Should(BeEquivalentTo(configFileName))
 }
+
+func TestPluginConfigFiles(t *testing.T) {
+	RegisterTestingT(t)
+	defer func(commandLine *flag.FlagSet) {
+		flag.CommandLine = commandLine
+	}(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	pluginName := "configfilesplugin"
+	configFileName := pluginWithConfigFileName + ".conf"
+	config.ForPlugin(pluginName, config.WithCustomizedFlag(config.FlagName(pluginName), "missing.conf"))
+	config.ForPlugin("confignofileplugin3")
+	config.DefineFlagsFor(pluginName)
+	config.DefineFlagsFor("confignofileplugin3")
+
+	// config file set by the flag is used
+	Expect(flag.CommandLine.Parse([]string{"-" + config.FlagName(pluginName), configFileName})).To(Succeed())
+	files := config.PluginConfigFiles()
+	Expect(files).Should(HaveKeyWithValue(pluginName, configFileName))
+	Expect(files).ShouldNot(HaveKey("confignofileplugin3"))
+}
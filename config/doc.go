@@ -14,4 +14,12 @@
 
 // Package config contains helper functions for parsing of configuration
 // files.
+//
+// Besides per-plugin config files, settings of multiple plugins can be
+// grouped into named profiles (e.g. "dev", "production", "minimal") defined
+// in one profile file (see Profiles). The profile is selected with the flag
+// -config-profile (or CONFIG_PROFILE env variable), the profile file
+// location with -config-profiles (or CONFIG_PROFILES env variable, default
+// "profiles.conf" looked up also in the config dir). Settings of the selected
+// profile override the values loaded from the plugin config files.
 package config
@@ -145,9 +145,37 @@ func ForPlugin(name string, opts ...Option) PluginConfig {
 
 	pluginFlags[name] = opt.flagSet
 
-	return &pluginConfig{
+	pc := &pluginConfig{
+		name:       name,
 		configFlag: opt.FlagName,
 	}
+	pluginConfigs.Lock()
+	pluginConfigs.byName[name] = pc
+	pluginConfigs.Unlock()
+	return pc
+}
+
+// pluginConfigs holds configs returned by ForPlugin indexed by the plugin name.
+var pluginConfigs = struct {
+	sync.Mutex
+	byName map[string]*pluginConfig
+}{byName: make(map[string]*pluginConfig)}
+
+// PluginConfigFiles returns paths of the config files used by plugins (see ForPlugin)
+// indexed by the plugin name. The paths are resolved the same way as when the plugins
+// load their configuration, i.e. from the config flags (or their defaults) and the
+// config dir. Plugins without config file are omitted.
+func PluginConfigFiles() map[string]string {
+	pluginConfigs.Lock()
+	defer pluginConfigs.Unlock()
+
+	files := make(map[string]string)
+	for name, pc := range pluginConfigs.byName {
+		if file := pc.GetConfigName(); file != "" {
+			files[name] = file
+		}
+	}
+	return files
 }
 
 // Dir returns config directory by evaluating the flag DirFlag. It interprets "." as current working directory.
@@ -167,25 +195,32 @@ func Dir() (dir string, err error) {
 }
 
 type pluginConfig struct {
+	name       string
 	configFlag string
 	access     sync.Mutex
 	configName string
 }
 
 // LoadValue binds the configuration to config method argument.
+// Settings of the active configuration profile (see Profiles)
+// override the values from the config file.
 func (p *pluginConfig) LoadValue(config interface{}) (found bool, err error) {
 	cfgName := p.GetConfigName()
-	if cfgName == "" {
-		return false, nil
+	if cfgName != "" {
+		// TODO: switch to Viper (possible to have one huge config file)
+		err = ParseConfigFromYamlFile(cfgName, config)
+		if err != nil {
+			return false, err
+		}
+		found = true
 	}
 
-	// TODO: switch to Viper (possible to have one huge config file)
-	err = ParseConfigFromYamlFile(cfgName, config)
+	applied, err := applyProfile(p.name, config)
 	if err != nil {
 		return false, err
 	}
 
-	return true, nil
+	return found || applied, nil
 }
 
 // GetConfigName looks up flag value and uses it to:
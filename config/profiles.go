@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/namsral/flag"
+)
+
+const (
+	// ProfileFlag is the name of the flag selecting the configuration profile.
+	ProfileFlag = "config-profile"
+
+	// ProfileEnv is the env variable selecting the configuration profile if the flag is not set.
+	ProfileEnv = "CONFIG_PROFILE"
+
+	// ProfileUsage used as a usage of ProfileFlag.
+	ProfileUsage = "Name of the configuration profile to apply; can also be set via 'CONFIG_PROFILE' env variable."
+
+	// ProfilesFileFlag is the name of the flag defining location of the profile file.
+	ProfilesFileFlag = "config-profiles"
+
+	// ProfilesFileEnv is the env variable defining location of the profile file if the flag is not set.
+	ProfilesFileEnv = "CONFIG_PROFILES"
+
+	// ProfilesFileDefault is the default name of the profile file (searched also in the config dir).
+	ProfilesFileDefault = "profiles" + FileExtension
+
+	// ProfilesFileUsage used as a usage of ProfilesFileFlag.
+	ProfilesFileUsage = "Location of the configuration profiles file; can also be set via 'CONFIG_PROFILES' env variable."
+)
+
+// Profiles are named groups of plugin settings defined in a single file.
+// The settings are mapped by profile name and plugin name:
+//
+//	production:
+//	  logs:
+//	    default-level: info
+//	  http:
+//	    enable-token-auth: true
+//	dev:
+//	  logs:
+//	    default-level: debug
+type Profiles map[string]map[string]map[string]interface{}
+
+// DefineProfileFlags defines flags selecting the configuration profile.
+func DefineProfileFlags() {
+	if flag.CommandLine.Lookup(ProfileFlag) == nil {
+		flag.CommandLine.String(ProfileFlag, "", ProfileUsage)
+	}
+	if flag.CommandLine.Lookup(ProfilesFileFlag) == nil {
+		flag.CommandLine.String(ProfilesFileFlag, ProfilesFileDefault, ProfilesFileUsage)
+	}
+}
+
+// LoadProfiles parses the profile file at the location <path>.
+func LoadProfiles(path string) (Profiles, error) {
+	profiles := Profiles{}
+	if err := ParseConfigFromYamlFile(path, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// ActiveProfile returns the name of the selected configuration profile,
+// empty if no profile is selected.
+func ActiveProfile() string {
+	return flagOrEnv(ProfileFlag, ProfileEnv, "")
+}
+
+// profileSettings returns settings of the plugin defined by the active profile,
+// nil if there is no active profile or it does not define settings of the plugin.
+func profileSettings(pluginName string) (map[string]interface{}, error) {
+	profile := ActiveProfile()
+	if profile == "" {
+		return nil, nil
+	}
+	path := findFile(flagOrEnv(ProfilesFileFlag, ProfilesFileEnv, ProfilesFileDefault))
+	if path == "" {
+		return nil, fmt.Errorf("profile %q selected, but profile file was not found", profile)
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not defined in %s", profile, path)
+	}
+	return settings[pluginName], nil
+}
+
+// flagOrEnv returns value of the flag if set, otherwise value of the env variable
+// or the default value.
+func flagOrEnv(flagName, envName, def string) string {
+	if flg := flag.CommandLine.Lookup(flagName); flg != nil {
+		if val := flg.Value.String(); val != "" && val != flg.DefValue {
+			return val
+		}
+	}
+	if val := os.Getenv(envName); val != "" {
+		return val
+	}
+	return def
+}
+
+// findFile returns the path if the file exists, alternatively the path
+// in the config dir (see Dir()). It returns empty string if neither exists.
+func findFile(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if cfgDir, err := Dir(); err == nil && cfgDir != "" {
+		if dirPath := filepath.Join(cfgDir, path); dirPath != path {
+			if _, err := os.Stat(dirPath); err == nil {
+				return dirPath
+			}
+		}
+	}
+	return ""
+}
+
+// applyProfile overrides the configuration with settings of the active profile.
+func applyProfile(pluginName string, cfg interface{}) (applied bool, err error) {
+	settings, err := profileSettings(pluginName)
+	if err != nil || settings == nil {
+		return false, err
+	}
+	// re-encode the settings to reuse decoding of the config files
+	b, err := yaml.Marshal(settings)
+	if err != nil {
+		return false, err
+	}
+	if err := parseConfigFromYamlBytes(b, cfg); err != nil {
+		return false, fmt.Errorf("profile %q for %s: %v", ActiveProfile(), pluginName, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/namsral/flag"
+	. "github.com/onsi/gomega"
+)
+
+const testProfiles = `
+production:
+  profiled:
+    level: info
+    timeout: 5s
+dev:
+  profiled:
+    level: debug
+`
+
+func TestProfileOverridesConfigFile(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "profiles")
+	Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	profilesFile := filepath.Join(dir, "profiles.conf")
+	Expect(ioutil.WriteFile(profilesFile, []byte(testProfiles), 0644)).To(Succeed())
+	cfgFile := filepath.Join(dir, "profiled.conf")
+	Expect(ioutil.WriteFile(cfgFile, []byte("level: warn\nname: agent\n"), 0644)).To(Succeed())
+
+	// the plugin flag is defined in a fresh command line, so that the test can be repeated
+	defer func(commandLine *flag.FlagSet) {
+		flag.CommandLine = commandLine
+	}(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	os.Setenv(ProfilesFileEnv, profilesFile)
+	defer os.Unsetenv(ProfilesFileEnv)
+	os.Setenv(ProfileEnv, "production")
+	defer os.Unsetenv(ProfileEnv)
+
+	type profiledConfig struct {
+		Name    string        `json:"name"`
+		Level   string        `json:"level"`
+		Timeout time.Duration `json:"timeout"`
+	}
+
+	pluginConfig := ForPlugin("profiled", WithCustomizedFlag(FlagName("profiled"), cfgFile))
+	DefineFlagsFor("profiled")
+
+	cfg := profiledConfig{}
+	found, err := pluginConfig.LoadValue(&cfg)
+	Expect(err).To(BeNil())
+	Expect(found).To(BeTrue())
+	Expect(cfg).To(Equal(profiledConfig{Name: "agent", Level: "info", Timeout: 5 * time.Second}))
+
+	// profile applies also without the config file
+	os.Setenv(ProfileEnv, "dev")
+	cfg = profiledConfig{}
+	found, err = ForPlugin("profiled", WithCustomizedFlag("")).LoadValue(&cfg)
+	Expect(err).To(BeNil())
+	Expect(found).To(BeTrue())
+	Expect(cfg).To(Equal(profiledConfig{Level: "debug"}))
+
+	// plugins without settings in the profile are not affected
+	found, err = ForPlugin("not-profiled", WithCustomizedFlag("")).LoadValue(&cfg)
+	Expect(err).To(BeNil())
+	Expect(found).To(BeFalse())
+
+	os.Setenv(ProfileEnv, "unknown")
+	_, err = pluginConfig.LoadValue(&cfg)
+	Expect(err).To(HaveOccurred())
+}
+
+func TestProfileFlag(t *testing.T) {
+	RegisterTestingT(t)
+
+	defer func(commandLine *flag.FlagSet) {
+		flag.CommandLine = commandLine
+	}(flag.CommandLine)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	DefineProfileFlags()
+	Expect(flag.CommandLine.Parse([]string{"-config-profile=dev", "-config-profiles=dev.conf"})).To(Succeed())
+
+	Expect(ActiveProfile()).To(Equal("dev"))
+	Expect(flagOrEnv(ProfilesFileFlag, ProfilesFileEnv, ProfilesFileDefault)).To(Equal("dev.conf"))
+}
@@ -119,6 +119,14 @@ type WithPrevValue interface {
 	GetPrevValue(prevValue proto.Message) (prevValueExist bool, err error)
 }
 
+// WithProtoMessage is implemented by lazy values which carry an unmarshaled
+// proto message. It allows to serialize the value without knowing its type
+// upfront (see keyval.ProtoTxn.SetValues).
+type WithProtoMessage interface {
+	// GetProtoMessage returns the carried message (nil if the value is empty).
+	GetProtoMessage() proto.Message
+}
+
 // LazyValue defines value that is unmarshaled into proto message on demand.
 // The reason for defining interface with only one method is primarily to unify
 // interfaces in this package.
@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanout
+
+import "strings"
+
+// Config of the fan-out publisher.
+type Config struct {
+	Sinks []SinkConfig `json:"sinks"`
+}
+
+// SinkConfig defines key filter of a single sink.
+type SinkConfig struct {
+	// Name of the sink (see UseSink).
+	Name string `json:"name"`
+	// Disabled sink receives no data.
+	Disabled bool `json:"disabled"`
+	// Include lists key prefixes propagated to the sink, all keys if empty.
+	Include []string `json:"include"`
+	// Exclude lists key prefixes not propagated to the sink.
+	Exclude []string `json:"exclude"`
+}
+
+// Match returns true if the key passes the sink filter.
+func (c *SinkConfig) Match(key string) bool {
+	if c.Disabled {
+		return false
+	}
+	for _, prefix := range c.Exclude {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, prefix := range c.Include {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fanout implements a datasync publisher that fans out the data
+// (typically status updates) to multiple sinks, e.g. etcd via kvdbsync,
+// Kafka via msgsync or a gRPC stream via grpcsync.StreamWriter.
+//
+// Every sink can be given key filters in the plugin configuration, so that
+// the routing of keys to sinks is configured in one place:
+//
+//	sinks:
+//	  - name: etcd
+//	    exclude: ["/vnf-agent/vpp1/check/status/v1/interface/"]
+//	  - name: kafka
+//	    include: ["/vnf-agent/vpp1/check/status/"]
+//
+// Sinks without configuration receive all keys. The sinks are injected by name:
+//
+//	statusPublisher := fanout.NewPlugin(
+//		fanout.UseSink("etcd", &etcdDataSync),
+//		fanout.UseSink("kafka", &kafkaDataSync),
+//	)
+//	statuscheck.DefaultPlugin.Transport = statusPublisher
+package fanout
@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/infra"
+)
+
+// Plugin implements datasync.KeyProtoValWriter propagating the data
+// to all sinks whose key filter matches the key.
+type Plugin struct {
+	Deps
+
+	config *Config
+	sinks  []*sink
+}
+
+// Deps groups dependencies injected into the plugin so that they are
+// logically separated from other plugin fields.
+type Deps struct {
+	infra.PluginDeps
+	// Sinks maps sink names used in the configuration to the writers.
+	Sinks map[string]datasync.KeyProtoValWriter
+}
+
+type sink struct {
+	SinkConfig
+	writer datasync.KeyProtoValWriter
+}
+
+// Init loads the configuration and builds the sinks.
+func (p *Plugin) Init() error {
+	if p.config == nil {
+		p.config = &Config{}
+		if _, err := p.Cfg.LoadValue(p.config); err != nil {
+			return err
+		}
+	}
+
+	configs := make(map[string]SinkConfig)
+	for _, c := range p.config.Sinks {
+		if _, ok := p.Sinks[c.Name]; !ok {
+			return fmt.Errorf("configured sink %q is not available", c.Name)
+		}
+		configs[c.Name] = c
+	}
+
+	names := make([]string, 0, len(p.Sinks))
+	for name := range p.Sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c, ok := configs[name]
+		if !ok {
+			c = SinkConfig{Name: name}
+		}
+		if c.Disabled {
+			p.Log.Infof("sink %s is disabled", name)
+			continue
+		}
+		p.sinks = append(p.sinks, &sink{SinkConfig: c, writer: p.Sinks[name]})
+		p.Log.Debugf("sink %s: include %v, exclude %v", name, c.Include, c.Exclude)
+	}
+
+	return nil
+}
+
+// Put propagates the data to all sinks whose filter matches the key.
+// Failure of one sink does not prevent propagation to the others,
+// the errors of all failed sinks are returned together.
+func (p *Plugin) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	var errs []string
+	for _, s := range p.sinks {
+		if !s.Match(key) {
+			continue
+		}
+		if err := s.writer.Put(key, data, opts...); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", s.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("put %s failed for sinks: %s", key, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
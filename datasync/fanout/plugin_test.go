@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fanout
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+type writerMock struct {
+	keys []string
+	err  error
+}
+
+func (w *writerMock) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	w.keys = append(w.keys, key)
+	return w.err
+}
+
+func TestFanOut(t *testing.T) {
+	RegisterTestingT(t)
+
+	etcd, kafka, grpc := &writerMock{}, &writerMock{}, &writerMock{}
+	p := NewPlugin(
+		UseSink("etcd", etcd), UseSink("kafka", kafka), UseSink("grpc", grpc),
+		UseConf(Config{Sinks: []SinkConfig{
+			{Name: "etcd", Exclude: []string{"status/if/"}},
+			{Name: "kafka", Include: []string{"status/"}},
+			{Name: "grpc", Disabled: true},
+		}}),
+	)
+	Expect(p.Init()).To(Succeed())
+
+	Expect(p.Put("status/if/loop0", &status.PluginStatus{})).To(Succeed())
+	Expect(p.Put("status/agent", &status.AgentStatus{})).To(Succeed())
+	Expect(p.Put("config/x", &status.AgentStatus{})).To(Succeed())
+
+	Expect(etcd.keys).To(Equal([]string{"status/agent", "config/x"}))
+	Expect(kafka.keys).To(Equal([]string{"status/if/loop0", "status/agent"}))
+	Expect(grpc.keys).To(BeEmpty())
+
+	kafka.err = errors.New("broker down")
+	err := p.Put("status/agent", &status.AgentStatus{})
+	Expect(err).To(MatchError(ContainSubstring("kafka: broker down")))
+	Expect(etcd.keys).To(HaveLen(3))
+}
+
+func TestUnknownSink(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseSink("etcd", &writerMock{}),
+		UseConf(Config{Sinks: []SinkConfig{{Name: "kafka"}}}))
+	Expect(p.Init()).NotTo(Succeed())
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcsync
+
+import (
+	"io"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase/msg"
+	"github.com/ligato/cn-infra/logging/logrus"
+	"golang.org/x/net/context"
+)
+
+// jsonContentType is the content type of data sent by StreamWriter.
+const jsonContentType = "application/json"
+
+// StreamWriter implements datasync.KeyProtoValWriter that publishes the data
+// into the DataChanges stream of a remote DataMsgService (see DataMsgServiceServer).
+// The stream is opened with the first Put and re-opened after a failure.
+type StreamWriter struct {
+	ctx    context.Context
+	client msg.DataMsgServiceClient
+
+	mu     sync.Mutex
+	stream msg.DataMsgService_DataChangesClient
+}
+
+// NewStreamWriter creates a new instance of StreamWriter using the given client.
+// The stream is closed when <ctx> is canceled.
+func NewStreamWriter(ctx context.Context, client msg.DataMsgServiceClient) *StreamWriter {
+	return &StreamWriter{ctx: ctx, client: client}
+}
+
+// Put sends the data into the stream as JSON.
+func (w *StreamWriter) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	req := &msg.DataChangeRequest{
+		Key:           key,
+		OperationType: msg.PutDel_PUT,
+		ContentType:   jsonContentType,
+	}
+	if data == nil {
+		req.OperationType = msg.PutDel_DEL
+	} else {
+		content, err := (&jsonpb.Marshaler{}).MarshalToString(data)
+		if err != nil {
+			return err
+		}
+		req.Content = []byte(content)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stream == nil {
+		stream, err := w.client.DataChanges(w.ctx)
+		if err != nil {
+			return err
+		}
+		w.stream = stream
+		go w.receiveReplies(stream)
+	}
+	if err := w.stream.Send(req); err != nil {
+		w.stream = nil
+		return err
+	}
+	return nil
+}
+
+// Close closes the stream.
+func (w *StreamWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stream == nil {
+		return nil
+	}
+	err := w.stream.CloseSend()
+	w.stream = nil
+	return err
+}
+
+// receiveReplies drains the replies of the remote service until the stream is closed.
+// The closed stream is dropped, so that the next Put opens a new one.
+func (w *StreamWriter) receiveReplies(stream msg.DataMsgService_DataChangesClient) {
+	defer func() {
+		w.mu.Lock()
+		if w.stream == stream {
+			w.stream = nil
+		}
+		w.mu.Unlock()
+	}()
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			if w.ctx.Err() == nil {
+				logrus.DefaultLogger().Debugf("data changes stream closed: %v", err)
+			}
+			return
+		}
+	}
+}
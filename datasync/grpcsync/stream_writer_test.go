@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcsync
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/datasync/syncbase/msg"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// closeKey makes the test server close the stream it was received from
+const closeKey = "close"
+
+// streamServer is an in-process DataMsgService recording the received changes
+type streamServer struct {
+	msg.DataMsgServiceServer
+	changes chan *msg.DataChangeRequest
+	// stream is closed by the client
+	closed chan struct{}
+}
+
+func (s *streamServer) DataChanges(stream msg.DataMsgService_DataChangesServer) error {
+	for {
+		chng, err := stream.Recv()
+		if err == io.EOF {
+			s.closed <- struct{}{}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if chng.Key == closeKey {
+			return nil
+		}
+		s.changes <- chng
+	}
+}
+
+func startStreamServer(t *testing.T) (*streamServer, msg.DataMsgServiceClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &streamServer{changes: make(chan *msg.DataChangeRequest, 10), closed: make(chan struct{}, 10)}
+	srv := grpc.NewServer()
+	msg.RegisterDataMsgServiceServer(srv, s)
+	go srv.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, msg.NewDataMsgServiceClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	RegisterTestingT(t)
+
+	s, client, stop := startStreamServer(t)
+	defer stop()
+
+	w := NewStreamWriter(context.Background(), client)
+
+	// put is sent as JSON
+	Expect(w.Put("config/a", &msg.PingRequest{Message: "a"})).To(Succeed())
+	var chng *msg.DataChangeRequest
+	Eventually(s.changes).Should(Receive(&chng))
+	Expect(chng.Key).To(Equal("config/a"))
+	Expect(chng.OperationType).To(Equal(msg.PutDel_PUT))
+	Expect(chng.ContentType).To(Equal(jsonContentType))
+	Expect(string(chng.Content)).To(MatchJSON(`{"message":"a"}`))
+
+	// nil value is sent as delete
+	Expect(w.Put("config/a", nil)).To(Succeed())
+	Eventually(s.changes).Should(Receive(&chng))
+	Expect(chng.Key).To(Equal("config/a"))
+	Expect(chng.OperationType).To(Equal(msg.PutDel_DEL))
+	Expect(chng.Content).To(BeEmpty())
+
+	// stream closed by the server is re-opened by the next put
+	Expect(w.Put(closeKey, nil)).To(Succeed())
+	Eventually(func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.stream == nil
+	}, time.Second).Should(BeTrue())
+	Expect(w.Put("config/b", &msg.PingRequest{Message: "b"})).To(Succeed())
+	Eventually(s.changes).Should(Receive(&chng))
+	Expect(chng.Key).To(Equal("config/b"))
+
+	// close ends the stream on the server side
+	Expect(w.Close()).To(Succeed())
+	Eventually(s.closed).Should(Receive())
+	Expect(w.Close()).To(Succeed())
+}
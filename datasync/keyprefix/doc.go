@@ -0,0 +1,38 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyprefix implements a central registry of key prefixes used
+// by datasync watchers.
+//
+// Every component declares the key prefixes (and optionally key templates)
+// it works with. The registry refuses declarations of the same kind made
+// by different owners whose prefixes overlap (one prefix is a prefix
+// of the other), because such keys could not be unambiguously attributed
+// to a single owner. The list of declarations can be used for discoverability,
+// e.g. it is exposed over REST by the restapi sub-package. Prefixes watched
+// via kvdbsync are declared only if the registry is injected into it
+// (see kvdbsync.UsePrefixRegistry).
+//
+// Example:
+//
+//	err := keyprefix.DefaultRegistry.Declare(keyprefix.Declaration{
+//	    Owner:    "ifplugin",
+//	    Kind:     keyprefix.Watcher,
+//	    Prefix:   "config/interfaces/",
+//	    Template: "config/interfaces/{name}",
+//	})
+//
+// Key templates (KeyTemplate) can be used to build and parse keys of the
+// declared structure instead of hand-written string slicing.
+package keyprefix
@@ -0,0 +1,29 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import "strings"
+
+// MatchLongest returns the longest of the prefixes matching the key,
+// or an empty string if none of them matches.
+func MatchLongest(key string, prefixes []string) string {
+	var match string
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) && len(prefix) >= len(match) {
+			match = prefix
+		}
+	}
+	return match
+}
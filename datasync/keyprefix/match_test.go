@@ -0,0 +1,30 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMatchLongest(t *testing.T) {
+	RegisterTestingT(t)
+
+	prefixes := []string{"/vnf/", "/vnf/if/"}
+	Expect(MatchLongest("/vnf/if/eth0", prefixes)).To(Equal("/vnf/if/"))
+	Expect(MatchLongest("/vnf/route/1", prefixes)).To(Equal("/vnf/"))
+	Expect(MatchLongest("/other/a", prefixes)).To(BeEmpty())
+}
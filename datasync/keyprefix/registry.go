@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Kind distinguishes the role in which the key prefix is used. Components
+// using prefixes in other roles may define their own kinds.
+type Kind string
+
+// Watcher is a component receiving changes of keys under the prefix
+// (e.g. datasync watcher registered in kvdbsync).
+const Watcher Kind = "watcher"
+
+// DefaultRegistry is the registry shared by all components of the agent.
+var DefaultRegistry = NewRegistry()
+
+// Declaration of a key prefix made by a single owner.
+type Declaration struct {
+	// Owner identifies the declaring component (e.g. plugin or resync name).
+	Owner string `json:"owner"`
+	// Kind is the role in which the prefix is used.
+	Kind Kind `json:"kind"`
+	// Prefix all keys of the owner start with.
+	Prefix string `json:"prefix"`
+	// Template optionally describes the structure of keys
+	// under the prefix, e.g. "config/interfaces/{name}" (see KeyTemplate).
+	Template string `json:"template,omitempty"`
+}
+
+// String returns human-readable description of the declaration.
+func (d Declaration) String() string {
+	return fmt.Sprintf("%s %q of %s", d.Kind, d.Prefix, d.Owner)
+}
+
+// overlaps returns true if keys of one declaration may also be keys of the other one.
+func (d Declaration) overlaps(other Declaration) bool {
+	return strings.HasPrefix(d.Prefix, other.Prefix) || strings.HasPrefix(other.Prefix, d.Prefix)
+}
+
+// CollisionError is returned by Declare if the new declaration
+// ambiguously overlaps with a declaration of another owner.
+type CollisionError struct {
+	Declared Declaration
+	Existing Declaration
+}
+
+// Error returns description of the collision.
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("key prefix collision: %v overlaps with %v", e.Declared, e.Existing)
+}
+
+// Registry keeps key prefix declarations and validates that they do not collide.
+type Registry struct {
+	mu    sync.RWMutex
+	decls []*declaration
+}
+
+// declaration counts repeated declarations of the same prefix by the same owner,
+// so that the prefix stays declared until all of them are withdrawn.
+type declaration struct {
+	Declaration
+	refs int
+}
+
+// NewRegistry returns a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Declare adds a new declaration to the registry. Declaring the same prefix
+// repeatedly by the same owner does not add a new declaration, but the prefix
+// stays declared until it is withdrawn as many times as it was declared.
+// CollisionError is returned if a declaration of the same kind made by another
+// owner overlaps with <d>.
+func (r *Registry) Declare(d Declaration) error {
+	if d.Owner == "" {
+		return errors.Errorf("key prefix %q declared without owner", d.Prefix)
+	}
+	if d.Template != "" {
+		if !strings.HasPrefix(d.Template, d.Prefix) {
+			return errors.Errorf("key template %q does not start with prefix %q", d.Template, d.Prefix)
+		}
+		if _, err := NewKeyTemplate(d.Template); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var same *declaration
+	for _, existing := range r.decls {
+		if existing.Kind != d.Kind || !existing.overlaps(d) {
+			continue
+		}
+		if existing.Owner != d.Owner {
+			return &CollisionError{Declared: d, Existing: existing.Declaration}
+		}
+		if existing.Prefix == d.Prefix {
+			same = existing
+		}
+	}
+	if same != nil {
+		same.refs++
+		if d.Template != "" {
+			same.Template = d.Template
+		}
+		return nil
+	}
+	r.decls = append(r.decls, &declaration{Declaration: d, refs: 1})
+
+	return nil
+}
+
+// Withdraw removes the declaration of <prefix> made by <owner> in the role <kind>
+// (once withdrawn as many times as it was declared).
+func (r *Registry) Withdraw(owner string, kind Kind, prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, d := range r.decls {
+		if d.Owner == owner && d.Kind == kind && d.Prefix == prefix {
+			if d.refs--; d.refs == 0 {
+				r.decls = append(r.decls[:i], r.decls[i+1:]...)
+			}
+			return
+		}
+	}
+}
+
+// WithdrawAll removes all declarations made by <owner> in the role <kind>,
+// regardless of how many times they were declared.
+func (r *Registry) WithdrawAll(owner string, kind Kind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decls := r.decls[:0]
+	for _, d := range r.decls {
+		if d.Owner != owner || d.Kind != kind {
+			decls = append(decls, d)
+		}
+	}
+	r.decls = decls
+}
+
+// Declarations returns all declarations sorted by the prefix.
+func (r *Registry) Declarations() []Declaration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decls := make([]Declaration, 0, len(r.decls))
+	for _, d := range r.decls {
+		decls = append(decls, d.Declaration)
+	}
+	sortDeclarations(decls)
+
+	return decls
+}
+
+// Lookup returns declarations with prefix matching the given key,
+// the most specific (longest) prefixes first.
+func (r *Registry) Lookup(key string) []Declaration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var decls []Declaration
+	for _, d := range r.decls {
+		if strings.HasPrefix(key, d.Prefix) {
+			decls = append(decls, d.Declaration)
+		}
+	}
+	sort.SliceStable(decls, func(i, j int) bool {
+		return len(decls[i].Prefix) > len(decls[j].Prefix)
+	})
+
+	return decls
+}
+
+func sortDeclarations(decls []Declaration) {
+	sort.Slice(decls, func(i, j int) bool {
+		if decls[i].Prefix != decls[j].Prefix {
+			return decls[i].Prefix < decls[j].Prefix
+		}
+		if decls[i].Kind != decls[j].Kind {
+			return decls[i].Kind < decls[j].Kind
+		}
+		return decls[i].Owner < decls[j].Owner
+	})
+}
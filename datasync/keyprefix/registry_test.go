@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// applier is a kind of component other than watcher, declared by the test
+const applier Kind = "applier"
+
+func TestDeclareCollision(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := NewRegistry()
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/if/"})).To(Succeed())
+	// repeated declaration and nested prefix of the same owner are fine
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/if/"})).To(Succeed())
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/if/loop/"})).To(Succeed())
+	// the same prefix in a different role is fine
+	Expect(r.Declare(Declaration{Owner: "b", Kind: applier, Prefix: "config/if/"})).To(Succeed())
+	// disjoint prefix is fine
+	Expect(r.Declare(Declaration{Owner: "b", Kind: Watcher, Prefix: "config/bd/"})).To(Succeed())
+
+	err := r.Declare(Declaration{Owner: "c", Kind: Watcher, Prefix: "config/if/tap/"})
+	Expect(err).To(BeAssignableToTypeOf(&CollisionError{}))
+	Expect(err.(*CollisionError).Existing.Owner).To(Equal("a"))
+
+	err = r.Declare(Declaration{Owner: "c", Kind: Watcher, Prefix: "config/"})
+	Expect(err).To(HaveOccurred())
+
+	Expect(r.Declarations()).To(HaveLen(4))
+}
+
+func TestDeclareTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := NewRegistry()
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/if/", Template: "config/bd/{name}"})).
+		NotTo(Succeed())
+	Expect(r.Declare(Declaration{Prefix: "config/if/"})).NotTo(Succeed())
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/if/", Template: "config/if/{name}"})).
+		To(Succeed())
+	Expect(r.Declarations()[0].Template).To(Equal("config/if/{name}"))
+}
+
+func TestWithdrawAndLookup(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := NewRegistry()
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/"})).To(Succeed())
+	Expect(r.Declare(Declaration{Owner: "b", Kind: applier, Prefix: "config/if/"})).To(Succeed())
+
+	found := r.Lookup("config/if/loop0")
+	Expect(found).To(HaveLen(2))
+	Expect(found[0].Owner).To(Equal("b"))
+	Expect(r.Lookup("status/if/loop0")).To(BeEmpty())
+
+	r.Withdraw("a", Watcher, "config/")
+	Expect(r.Declare(Declaration{Owner: "c", Kind: Watcher, Prefix: "config/if/"})).To(Succeed())
+
+	r.WithdrawAll("b", applier)
+	Expect(r.Declarations()).To(HaveLen(1))
+}
+
+func TestRepeatedDeclarationIsCounted(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := NewRegistry()
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/"})).To(Succeed())
+	Expect(r.Declare(Declaration{Owner: "a", Kind: Watcher, Prefix: "config/"})).To(Succeed())
+
+	r.Withdraw("a", Watcher, "config/")
+	Expect(r.Declarations()).To(HaveLen(1))
+	r.Withdraw("a", Watcher, "config/")
+	Expect(r.Declarations()).To(BeEmpty())
+}
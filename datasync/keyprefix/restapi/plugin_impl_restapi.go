@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/datasync/keyprefix"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// PrefixesPath is URL path of the key prefix registry.
+	PrefixesPath = "/datasync/prefixes"
+	// keyParam is URL query parameter used to filter declarations by key.
+	keyParam = "key"
+)
+
+// Plugin registers REST handler exposing the key prefix registry.
+type Plugin struct {
+	Deps
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	HTTP     rest.HTTPHandlers // inject
+	Registry *keyprefix.Registry
+}
+
+// Init registers the REST handler.
+func (p *Plugin) Init() error {
+	if p.HTTP == nil {
+		p.Log.Info("Unable to register key prefix registry handler, HTTP is nil")
+		return nil
+	}
+	p.HTTP.RegisterHTTPHandler(PrefixesPath, p.prefixesHandler, http.MethodGet)
+
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// prefixesHandler returns the key prefix declarations.
+func (p *Plugin) prefixesHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var decls []keyprefix.Declaration
+		if key := req.URL.Query().Get(keyParam); key != "" {
+			decls = p.Registry.Lookup(key)
+		} else {
+			decls = p.Registry.Declarations()
+		}
+		if decls == nil {
+			decls = []keyprefix.Declaration{}
+		}
+		formatter.JSON(w, http.StatusOK, decls)
+	}
+}
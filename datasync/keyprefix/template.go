@@ -0,0 +1,189 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeyTemplate describes the structure of keys, e.g. "/vnf/{name}/route/{dst}".
+// Parameters are enclosed in curly braces. The value of a parameter spans
+// up to the next occurrence of the following literal part of the template,
+// the value of the parameter at the end of the template spans up to the end
+// of the key (e.g. "10.0.0.0/24" is a valid value of {dst}).
+//
+// KeyTemplate replaces hand-written building and slicing of keys:
+//
+//	routeKey := keyprefix.MustKeyTemplate("/vnf/{name}/route/{dst}")
+//	key, err := routeKey.Build("vnf1", "10.0.0.0/24")
+//	params, ok := routeKey.Parse(key) // {"name": "vnf1", "dst": "10.0.0.0/24"}
+type KeyTemplate struct {
+	template string
+	parts    []templatePart
+	params   []string
+}
+
+// templatePart is either a literal or a parameter of the template.
+type templatePart struct {
+	literal string
+	param   string
+}
+
+// NewKeyTemplate parses the template. It returns error if braces are not
+// balanced, a parameter has an empty or duplicate name or two parameters
+// are not separated by a literal.
+func NewKeyTemplate(template string) (*KeyTemplate, error) {
+	t := &KeyTemplate{template: template}
+	rest := template
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if closing := strings.IndexByte(rest, '}'); closing >= 0 && (open < 0 || closing < open) {
+			return nil, errors.Errorf("key template %q: unexpected '}'", template)
+		}
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:open]})
+		} else if len(t.parts) > 0 {
+			return nil, errors.Errorf("key template %q: parameters must be separated by a literal", template)
+		}
+		closing := strings.IndexByte(rest, '}')
+		if closing < 0 {
+			return nil, errors.Errorf("key template %q: missing '}'", template)
+		}
+		param := rest[open+1 : closing]
+		if param == "" || strings.ContainsAny(param, "{/") {
+			return nil, errors.Errorf("key template %q: invalid parameter name %q", template, param)
+		}
+		for _, p := range t.params {
+			if p == param {
+				return nil, errors.Errorf("key template %q: duplicate parameter %q", template, param)
+			}
+		}
+		t.parts = append(t.parts, templatePart{param: param})
+		t.params = append(t.params, param)
+		rest = rest[closing+1:]
+	}
+	return t, nil
+}
+
+// MustKeyTemplate is like NewKeyTemplate but panics if the template is invalid.
+// It is intended for templates assigned to package level variables.
+func MustKeyTemplate(template string) *KeyTemplate {
+	t, err := NewKeyTemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template.
+func (t *KeyTemplate) String() string {
+	return t.template
+}
+
+// Params returns names of the parameters in the order of appearance.
+func (t *KeyTemplate) Params() []string {
+	return append([]string(nil), t.params...)
+}
+
+// Prefix returns the literal part of the template preceding the first parameter,
+// i.e. the prefix shared by all keys matching the template.
+func (t *KeyTemplate) Prefix() string {
+	if len(t.parts) > 0 && t.parts[0].param == "" {
+		return t.parts[0].literal
+	}
+	return ""
+}
+
+// Build returns the key with parameters replaced by the values (in the order
+// of Params). It returns error if the number of values does not match
+// or a value would make the key ambiguous.
+func (t *KeyTemplate) Build(values ...string) (string, error) {
+	if len(values) != len(t.params) {
+		return "", errors.Errorf("key template %q expects %d values, got %d", t.template, len(t.params), len(values))
+	}
+	var key strings.Builder
+	next := 0
+	for i, part := range t.parts {
+		if part.param == "" {
+			key.WriteString(part.literal)
+			continue
+		}
+		value := values[next]
+		next++
+		if value == "" {
+			return "", errors.Errorf("key template %q: empty value of %q", t.template, part.param)
+		}
+		if i+1 < len(t.parts) && strings.Contains(value, t.parts[i+1].literal) {
+			return "", errors.Errorf("key template %q: value %q of %q contains %q",
+				t.template, value, part.param, t.parts[i+1].literal)
+		}
+		key.WriteString(value)
+	}
+	return key.String(), nil
+}
+
+// BuildMap is like Build, but takes the values mapped by parameter names.
+func (t *KeyTemplate) BuildMap(params map[string]string) (string, error) {
+	values := make([]string, 0, len(t.params))
+	for _, param := range t.params {
+		value, ok := params[param]
+		if !ok {
+			return "", errors.Errorf("key template %q: missing value of %q", t.template, param)
+		}
+		values = append(values, value)
+	}
+	return t.Build(values...)
+}
+
+// Parse returns values of the parameters in the key. The second return value
+// is false if the key does not match the template.
+func (t *KeyTemplate) Parse(key string) (map[string]string, bool) {
+	params := make(map[string]string, len(t.params))
+	rest := key
+	for i, part := range t.parts {
+		if part.param == "" {
+			if !strings.HasPrefix(rest, part.literal) {
+				return nil, false
+			}
+			rest = rest[len(part.literal):]
+			continue
+		}
+		end := len(rest)
+		if i+1 < len(t.parts) {
+			end = strings.Index(rest, t.parts[i+1].literal)
+		}
+		if end <= 0 {
+			return nil, false
+		}
+		params[part.param] = rest[:end]
+		rest = rest[end:]
+	}
+	if rest != "" {
+		return nil, false
+	}
+	return params, true
+}
+
+// Match returns true if the key matches the template.
+func (t *KeyTemplate) Match(key string) bool {
+	_, ok := t.Parse(key)
+	return ok
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprefix
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestKeyTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	tmpl := MustKeyTemplate("/vnf/{name}/route/{dst}")
+	Expect(tmpl.Params()).To(Equal([]string{"name", "dst"}))
+	Expect(tmpl.Prefix()).To(Equal("/vnf/"))
+
+	key, err := tmpl.Build("vnf1", "10.0.0.0/24")
+	Expect(err).To(BeNil())
+	Expect(key).To(Equal("/vnf/vnf1/route/10.0.0.0/24"))
+
+	params, ok := tmpl.Parse(key)
+	Expect(ok).To(BeTrue())
+	Expect(params).To(Equal(map[string]string{"name": "vnf1", "dst": "10.0.0.0/24"}))
+
+	key, err = tmpl.BuildMap(params)
+	Expect(err).To(BeNil())
+	Expect(key).To(Equal("/vnf/vnf1/route/10.0.0.0/24"))
+
+	Expect(tmpl.Match("/vnf/vnf1/route/")).To(BeFalse())
+	Expect(tmpl.Match("/vnf//route/x")).To(BeFalse())
+	Expect(tmpl.Match("/vnf/vnf1/iface/x")).To(BeFalse())
+
+	_, err = tmpl.Build("vnf/1/route/", "x")
+	Expect(err).To(HaveOccurred())
+	_, err = tmpl.Build("vnf1")
+	Expect(err).To(HaveOccurred())
+}
+
+func TestInvalidKeyTemplate(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, tmpl := range []string{"/a/{name", "/a/name}", "/a/{}", "/a/{x}{y}", "/a/{x}/{x}", "/a/{x/y}"} {
+		_, err := NewKeyTemplate(tmpl)
+		Expect(err).To(HaveOccurred(), tmpl)
+	}
+	tmpl := MustKeyTemplate("config/{name}/suffix")
+	Expect(tmpl.Match("config/a/suffix")).To(BeTrue())
+	Expect(tmpl.Match("config/a/suffix/x")).To(BeFalse())
+}
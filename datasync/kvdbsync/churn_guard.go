@@ -0,0 +1,433 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging/logrus"
+)
+
+// ChurnGuardConfig defines what is considered abnormal churn of the configuration.
+// It can be loaded from the "churn-guard" section of the plugin configuration file.
+type ChurnGuardConfig struct {
+	// MaxDeletes is the maximum number of deleted keys under a single watched
+	// prefix within the Interval. Exceeding it pauses changes of the prefix.
+	MaxDeletes int `json:"max-deletes"`
+	// Interval of the sliding window in which the deletes are counted.
+	Interval time.Duration `json:"interval"`
+	// MaxHeldChanges is the maximum number of changes held for a paused prefix.
+	// Once exceeded, the held changes are dropped and the watchers of the prefix
+	// are resynchronized with the KV store when the prefix is resumed.
+	MaxHeldChanges int `json:"max-held-changes"`
+}
+
+// DefaultChurnGuardConfig returns ChurnGuardConfig with default values,
+// which are also used for unset (zero) values of the config.
+func DefaultChurnGuardConfig() ChurnGuardConfig {
+	return ChurnGuardConfig{
+		MaxDeletes:     100,
+		Interval:       10 * time.Second,
+		MaxHeldChanges: 10000,
+	}
+}
+
+// withDefaults replaces unset values with defaults.
+func (c ChurnGuardConfig) withDefaults() ChurnGuardConfig {
+	def := DefaultChurnGuardConfig()
+	if c.MaxDeletes <= 0 {
+		c.MaxDeletes = def.MaxDeletes
+	}
+	if c.Interval <= 0 {
+		c.Interval = def.Interval
+	}
+	if c.MaxHeldChanges <= 0 {
+		c.MaxHeldChanges = def.MaxHeldChanges
+	}
+	return c
+}
+
+// GuardedPrefix describes the state of a watched prefix protected by the ChurnGuard.
+type GuardedPrefix struct {
+	Prefix         string    `json:"prefix"`
+	Paused         bool      `json:"paused"`
+	PausedAt       time.Time `json:"paused-at,omitempty"`
+	Fenced         bool      `json:"fenced"`
+	MissingKeys    int       `json:"missing-keys"`
+	Delivering     bool      `json:"delivering"`
+	HeldChanges    int       `json:"held-changes"`
+	DroppedChanges int       `json:"dropped-changes"`
+	RecentDeletes  int       `json:"recent-deletes"`
+}
+
+// ChurnGuard protects the agent from abnormal churn of the watched configuration
+// (e.g. runaway controller deleting everything). If too many keys under a watched
+// prefix are deleted within the configured interval, changes of the prefix (and
+// resync of watchers of the prefix) are held until the operator confirms them
+// (Confirm) or rejects them (Reject). The number of held changes is limited,
+// changes exceeding the limit are dropped and replaced by resync of the watchers.
+//
+// Rejected changes are never delivered. The watchers of the prefix stay on the last
+// delivered state and the prefix stays fenced (its changes and resyncs are held)
+// until the NB is repaired (all keys deleted since the prefix was paused are put
+// back) or the operator resumes it (Resume).
+type ChurnGuard struct {
+	now func() time.Time
+	// loop runs the delivery of confirmed changes and resyncs, the kvdbsync plugin
+	// replaces it with its own loop so that they are stopped when the plugin is closed
+	loop *infra.EventLoop
+
+	mu       sync.Mutex
+	cfg      ChurnGuardConfig
+	prefixes map[string]*guardedPrefix
+}
+
+type guardedPrefix struct {
+	deletes  []time.Time
+	paused   bool
+	pausedAt time.Time
+	// fenced is set after the held changes were rejected, until the prefix is repaired or resumed
+	fenced bool
+	// missing are keys deleted since the prefix was paused and not put back yet
+	missing map[string]struct{}
+	held    []func()
+	// dropped counts changes dropped after the held changes exceeded the limit,
+	// the watchers are resynchronized instead of delivering them
+	dropped int
+	// delivering is set while confirmed changes are delivered in the background
+	delivering bool
+	// resyncs of watchers of the prefix, started when held changes are rejected
+	resyncs []func()
+}
+
+// NewChurnGuard creates a new ChurnGuard. Unset values of the config are replaced
+// with defaults (see DefaultChurnGuardConfig).
+func NewChurnGuard(cfg ChurnGuardConfig) *ChurnGuard {
+	return &ChurnGuard{
+		cfg:      cfg.withDefaults(),
+		now:      time.Now,
+		loop:     &infra.EventLoop{},
+		prefixes: make(map[string]*guardedPrefix),
+	}
+}
+
+// SetConfig changes thresholds of the guard, e.g. once loaded from the configuration file.
+func (g *ChurnGuard) SetConfig(cfg ChurnGuardConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg.withDefaults()
+}
+
+func (g *ChurnGuard) getPrefix(prefix string) *guardedPrefix {
+	gp, ok := g.prefixes[prefix]
+	if !ok {
+		gp = &guardedPrefix{}
+		g.prefixes[prefix] = gp
+	}
+	return gp
+}
+
+// trimDeletes drops deletes older than the interval.
+func (g *ChurnGuard) trimDeletes(gp *guardedPrefix, now time.Time) {
+	i := 0
+	for i < len(gp.deletes) && now.Sub(gp.deletes[i]) > g.cfg.Interval {
+		i++
+	}
+	gp.deletes = gp.deletes[i:]
+}
+
+// registerResync registers resync of watchers of the prefixes, which is started
+// when changes held for any of the prefixes are rejected.
+func (g *ChurnGuard) registerResync(prefixes []string, resync func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, prefix := range prefixes {
+		gp := g.getPrefix(prefix)
+		gp.resyncs = append(gp.resyncs, resync)
+	}
+}
+
+// trackMissing records keys deleted (and put back) while changes of the prefix are held.
+func (g *ChurnGuard) trackMissing(gp *guardedPrefix, key string, op datasync.Op) {
+	if op == datasync.Delete {
+		if gp.missing == nil {
+			gp.missing = make(map[string]struct{})
+		}
+		gp.missing[key] = struct{}{}
+	} else {
+		delete(gp.missing, key)
+	}
+}
+
+// hold holds the delivery for the paused prefix. If the number of held changes
+// exceeds the limit, they are all dropped together with the following changes.
+func (g *ChurnGuard) hold(prefix string, gp *guardedPrefix, deliver func()) {
+	if gp.dropped > 0 {
+		gp.dropped++
+		return
+	}
+	if len(gp.held) < g.cfg.MaxHeldChanges {
+		gp.held = append(gp.held, deliver)
+		return
+	}
+	gp.dropped = len(gp.held) + 1
+	gp.held = nil
+	logrus.DefaultLogger().Warnf("more than %d changes under %q held, dropping them, watchers of the prefix "+
+		"will be resynced once resumed", g.cfg.MaxHeldChanges, prefix)
+}
+
+// takeDropped clears the dropped changes and returns resyncs of the watchers,
+// which replace their delivery.
+func (g *ChurnGuard) takeDropped(gp *guardedPrefix) []func() {
+	gp.dropped = 0
+	return gp.resyncs
+}
+
+// admit delivers the change of the key under the prefix unless the prefix
+// is paused or fenced (or confirmed changes are still being delivered),
+// in which case the delivery is held.
+func (g *ChurnGuard) admit(prefix, key string, op datasync.Op, deliver func()) {
+	g.mu.Lock()
+	gp := g.getPrefix(prefix)
+	if gp.fenced {
+		g.trackMissing(gp, key, op)
+		if len(gp.missing) > 0 {
+			g.hold(prefix, gp, deliver)
+			g.mu.Unlock()
+			return
+		}
+		// the NB is repaired, this change is delivered by the resync
+		resyncs := g.unfence(gp)
+		g.mu.Unlock()
+		logrus.DefaultLogger().Infof("keys deleted under %q were put back, resyncing watchers of the prefix", prefix)
+		g.startResyncs(resyncs)
+		return
+	}
+	var pausedNow bool
+	if !gp.paused && op == datasync.Delete {
+		now := g.now()
+		g.trimDeletes(gp, now)
+		gp.deletes = append(gp.deletes, now)
+		if len(gp.deletes) > g.cfg.MaxDeletes {
+			gp.paused = true
+			gp.pausedAt = now
+			pausedNow = true
+		}
+	}
+	if gp.paused {
+		g.trackMissing(gp, key, op)
+	}
+	if gp.paused || gp.delivering {
+		g.hold(prefix, gp, deliver)
+		deletes, interval := len(gp.deletes), g.cfg.Interval
+		g.mu.Unlock()
+		if pausedNow {
+			logrus.DefaultLogger().Warnf("%d keys under %q deleted within %v, changes are paused until confirmed",
+				deletes, prefix, interval)
+		}
+		return
+	}
+	g.mu.Unlock()
+
+	deliver()
+}
+
+// holdIfPaused holds the delivery if any of the prefixes is paused or fenced
+// (or confirmed changes of the prefix are still being delivered).
+func (g *ChurnGuard) holdIfPaused(prefixes []string, deliver func()) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, prefix := range prefixes {
+		if gp, ok := g.prefixes[prefix]; ok && (gp.paused || gp.fenced || gp.delivering) {
+			g.hold(prefix, gp, deliver)
+			return true
+		}
+	}
+	return false
+}
+
+// Confirm resumes the paused prefix and delivers the held changes in the background,
+// in the order they were received. Changes received in the meantime are delivered
+// after them. If the held changes were dropped, the watchers of the prefix are
+// resynchronized with the KV store instead. It returns the number of confirmed changes.
+func (g *ChurnGuard) Confirm(prefix string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gp, ok := g.prefixes[prefix]
+	if !ok || !gp.paused {
+		return 0
+	}
+	confirmed := len(gp.held) + gp.dropped
+	gp.paused = false
+	gp.deletes = nil
+	gp.missing = nil
+	if !gp.delivering && gp.dropped > 0 {
+		// confirmed changes are already in the KV store
+		g.startResyncs(g.takeDropped(gp))
+	} else if !gp.delivering && confirmed > 0 {
+		gp.delivering = true
+		g.loop.Go(func(ctx context.Context) {
+			g.deliverHeld(ctx, gp)
+		})
+	}
+	if confirmed > 0 {
+		logrus.DefaultLogger().Infof("%d held changes under %q confirmed", confirmed, prefix)
+	}
+	return confirmed
+}
+
+// deliverHeld delivers held changes of the prefix until there are none left
+// or the prefix is paused again. Changes dropped in the meantime are replaced
+// by resync of the watchers. The delivery stops once the context is canceled.
+func (g *ChurnGuard) deliverHeld(ctx context.Context, gp *guardedPrefix) {
+	for {
+		g.mu.Lock()
+		if ctx.Err() != nil {
+			gp.delivering = false
+			g.mu.Unlock()
+			return
+		}
+		if gp.paused || len(gp.held) == 0 {
+			gp.delivering = false
+			var resyncs []func()
+			if !gp.paused && gp.dropped > 0 {
+				resyncs = g.takeDropped(gp)
+			}
+			g.mu.Unlock()
+			for _, resync := range resyncs {
+				resync()
+			}
+			return
+		}
+		held := gp.held
+		gp.held = nil
+		g.mu.Unlock()
+
+		for i, deliver := range held {
+			if ctx.Err() != nil {
+				// keep the rest held, the delivery is not resumed
+				g.mu.Lock()
+				gp.held = append(held[i:], gp.held...)
+				g.mu.Unlock()
+				break
+			}
+			deliver()
+		}
+	}
+}
+
+// Reject drops the changes held for the paused prefix. The watchers of the prefix
+// stay on the last delivered state, the prefix is fenced - its changes and resyncs
+// are held (and never delivered) until the keys deleted since the prefix was paused
+// are put back to the NB, or the operator resumes the prefix (Resume). Then
+// the watchers are resynchronized with the KV store. It returns the number
+// of rejected changes.
+func (g *ChurnGuard) Reject(prefix string) int {
+	g.mu.Lock()
+	gp, ok := g.prefixes[prefix]
+	if !ok || !gp.paused {
+		g.mu.Unlock()
+		return 0
+	}
+	rejected := len(gp.held) + gp.dropped
+	gp.paused = false
+	gp.fenced = true
+	gp.held = nil
+	gp.dropped = 0
+	gp.deletes = nil
+	missing := len(gp.missing)
+	g.mu.Unlock()
+
+	logrus.DefaultLogger().Warnf("%d held changes under %q rejected, the prefix is fenced until %d deleted keys "+
+		"are put back or it is resumed", rejected, prefix, missing)
+	return rejected
+}
+
+// Resume lifts the fence of the prefix after its changes were rejected. The changes
+// held since then are dropped and the watchers of the prefix are resynchronized
+// with the current content of the KV store in the background. It returns the number
+// of dropped changes.
+func (g *ChurnGuard) Resume(prefix string) int {
+	g.mu.Lock()
+	gp, ok := g.prefixes[prefix]
+	if !ok || !gp.fenced {
+		g.mu.Unlock()
+		return 0
+	}
+	dropped := len(gp.held) + gp.dropped
+	resyncs := g.unfence(gp)
+	g.mu.Unlock()
+
+	logrus.DefaultLogger().Infof("prefix %q resumed, resyncing watchers of the prefix", prefix)
+	g.startResyncs(resyncs)
+	return dropped
+}
+
+// startResyncs resynchronizes the watchers in the background. Resyncs which have not
+// started before the loop is stopped are skipped.
+func (g *ChurnGuard) startResyncs(resyncs []func()) {
+	for _, resync := range resyncs {
+		resync := resync
+		g.loop.Go(func(ctx context.Context) {
+			if ctx.Err() == nil {
+				resync()
+			}
+		})
+	}
+}
+
+// unfence lifts the fence of the prefix, drops the changes held since the reject
+// and returns resyncs of the watchers, which replace their delivery.
+func (g *ChurnGuard) unfence(gp *guardedPrefix) []func() {
+	gp.fenced = false
+	gp.missing = nil
+	gp.held = nil
+	return g.takeDropped(gp)
+}
+
+// Status returns the state of all guarded prefixes sorted by the prefix.
+func (g *ChurnGuard) Status() []GuardedPrefix {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	status := make([]GuardedPrefix, 0, len(g.prefixes))
+	for prefix, gp := range g.prefixes {
+		g.trimDeletes(gp, now)
+		status = append(status, GuardedPrefix{
+			Prefix:         prefix,
+			Paused:         gp.paused,
+			PausedAt:       gp.pausedAt,
+			Fenced:         gp.fenced,
+			MissingKeys:    len(gp.missing),
+			Delivering:     gp.delivering,
+			HeldChanges:    len(gp.held),
+			DroppedChanges: gp.dropped,
+			RecentDeletes:  len(gp.deletes),
+		})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Prefix < status[j].Prefix
+	})
+	return status
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/db/keyval/kvtest"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/infra"
+	. "github.com/onsi/gomega"
+)
+
+func TestChurnGuardPausesAndConfirms(t *testing.T) {
+	RegisterTestingT(t)
+
+	guard := NewChurnGuard(ChurnGuardConfig{MaxDeletes: 2, Interval: time.Minute})
+	var (
+		mu        sync.Mutex
+		delivered []string
+	)
+	deliver := func(key string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, key)
+		}
+	}
+	getDelivered := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), delivered...)
+	}
+
+	guard.admit("/vnf/", "/vnf/a", datasync.Delete, deliver("a"))
+	guard.admit("/vnf/", "/vnf/b", datasync.Delete, deliver("b"))
+	guard.admit("/vnf/", "/vnf/c", datasync.Delete, deliver("c"))
+	guard.admit("/vnf/", "/vnf/d", datasync.Put, deliver("d"))
+	guard.admit("/other/", "/other/e", datasync.Delete, deliver("e"))
+	Expect(getDelivered()).To(Equal([]string{"a", "b", "e"}))
+
+	status := guard.Status()
+	Expect(status).To(HaveLen(2))
+	Expect(status[1].Prefix).To(Equal("/vnf/"))
+	Expect(status[1].Paused).To(BeTrue())
+	Expect(status[1].HeldChanges).To(Equal(2))
+
+	Expect(guard.holdIfPaused([]string{"/vnf/"}, deliver("resync"))).To(BeTrue())
+	Expect(guard.holdIfPaused([]string{"/other/"}, deliver("resync"))).To(BeFalse())
+
+	Expect(guard.Confirm("/vnf/")).To(Equal(3))
+	// changes received while the confirmed ones are delivered keep the order
+	guard.admit("/vnf/", "/vnf/f", datasync.Put, deliver("f"))
+	Eventually(getDelivered).Should(Equal([]string{"a", "b", "e", "c", "d", "resync", "f"}))
+	Expect(guard.Confirm("/vnf/")).To(Equal(0))
+}
+
+func TestChurnGuardRejectsAndSlidesWindow(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now()
+	guard := NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Second})
+	guard.now = func() time.Time { return now }
+	var delivered int32
+	deliver := func() { atomic.AddInt32(&delivered, 1) }
+	resynced := make(chan struct{}, 1)
+	guard.registerResync([]string{"/vnf/"}, func() { resynced <- struct{}{} })
+
+	guard.admit("/vnf/", "/vnf/a", datasync.Delete, deliver)
+	now = now.Add(2 * time.Second)
+	guard.admit("/vnf/", "/vnf/b", datasync.Delete, deliver)
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(2)))
+
+	guard.admit("/vnf/", "/vnf/c", datasync.Delete, deliver)
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(2)))
+	Expect(guard.Reject("/vnf/")).To(Equal(1))
+	Expect(guard.Status()[0].Fenced).To(BeTrue())
+	Expect(guard.Status()[0].MissingKeys).To(Equal(1))
+
+	// the prefix stays fenced until the deleted key is put back
+	guard.admit("/vnf/", "/vnf/d", datasync.Put, deliver)
+	Expect(guard.holdIfPaused([]string{"/vnf/"}, deliver)).To(BeTrue())
+	Consistently(resynced).ShouldNot(Receive())
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(2)))
+
+	guard.admit("/vnf/", "/vnf/c", datasync.Put, deliver)
+	Eventually(resynced).Should(Receive())
+	Expect(guard.Status()[0].Fenced).To(BeFalse())
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(2)))
+
+	guard.admit("/vnf/", "/vnf/e", datasync.Put, deliver)
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(3)))
+}
+
+func TestChurnGuardResume(t *testing.T) {
+	RegisterTestingT(t)
+
+	guard := NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Minute})
+	var delivered int32
+	deliver := func() { atomic.AddInt32(&delivered, 1) }
+	resynced := make(chan struct{}, 1)
+	guard.registerResync([]string{"/vnf/"}, func() { resynced <- struct{}{} })
+
+	guard.admit("/vnf/", "/vnf/a", datasync.Delete, deliver)
+	guard.admit("/vnf/", "/vnf/b", datasync.Delete, deliver)
+	Expect(guard.Resume("/vnf/")).To(BeZero())
+	Expect(guard.Reject("/vnf/")).To(Equal(1))
+	guard.admit("/vnf/", "/vnf/c", datasync.Delete, deliver)
+
+	Expect(guard.Resume("/vnf/")).To(Equal(1))
+	Eventually(resynced).Should(Receive())
+	Expect(guard.Status()[0].Fenced).To(BeFalse())
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(1)))
+}
+
+func TestChurnGuardDeliveryStopsWithLoop(t *testing.T) {
+	RegisterTestingT(t)
+
+	guard := NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Minute})
+	var loop infra.EventLoop
+	guard.loop = &loop
+	started, release := make(chan struct{}), make(chan struct{})
+	var delivered []string
+
+	guard.admit("/vnf/", "/vnf/a", datasync.Delete, func() { delivered = append(delivered, "a") })
+	guard.admit("/vnf/", "/vnf/b", datasync.Delete, func() {
+		close(started)
+		<-release
+		delivered = append(delivered, "b")
+	})
+	guard.admit("/vnf/", "/vnf/c", datasync.Delete, func() { delivered = append(delivered, "c") })
+	Expect(guard.Confirm("/vnf/")).To(Equal(2))
+	Eventually(started).Should(BeClosed())
+
+	// the remaining change is not delivered once the loop is stopped
+	stopped := make(chan struct{})
+	go func() {
+		loop.Stop()
+		close(stopped)
+	}()
+	Eventually(loop.Context().Done()).Should(BeClosed())
+	close(release)
+	Eventually(stopped).Should(BeClosed())
+	Expect(delivered).To(Equal([]string{"a", "b"}))
+	prefixes := guard.Status()
+	Expect(prefixes[0].Delivering).To(BeFalse())
+	Expect(prefixes[0].HeldChanges).To(Equal(1))
+}
+
+func TestChurnGuardConfigDefaults(t *testing.T) {
+	RegisterTestingT(t)
+
+	guard := NewChurnGuard(ChurnGuardConfig{})
+	Expect(guard.cfg).To(Equal(DefaultChurnGuardConfig()))
+
+	var delivered int
+	guard.admit("/vnf/", "/vnf/k", datasync.Delete, func() { delivered++ })
+	Expect(delivered).To(Equal(1))
+
+	guard.SetConfig(ChurnGuardConfig{MaxDeletes: 5})
+	Expect(guard.cfg.MaxDeletes).To(Equal(5))
+	Expect(guard.cfg.Interval).To(Equal(DefaultChurnGuardConfig().Interval))
+	Expect(guard.cfg.MaxHeldChanges).To(Equal(DefaultChurnGuardConfig().MaxHeldChanges))
+}
+
+func TestChurnGuardDropsChangesOverLimit(t *testing.T) {
+	RegisterTestingT(t)
+
+	guard := NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Minute, MaxHeldChanges: 3})
+	var delivered int32
+	deliver := func() { atomic.AddInt32(&delivered, 1) }
+	resynced := make(chan struct{}, 1)
+	guard.registerResync([]string{"/vnf/"}, func() { resynced <- struct{}{} })
+
+	guard.admit("/vnf/", "/vnf/k", datasync.Delete, deliver)
+	for i := 0; i < 3; i++ {
+		guard.admit("/vnf/", "/vnf/k", datasync.Delete, deliver)
+	}
+	Expect(guard.Status()[0].HeldChanges).To(Equal(3))
+
+	guard.admit("/vnf/", "/vnf/k", datasync.Put, deliver)
+	guard.admit("/vnf/", "/vnf/k", datasync.Put, deliver)
+	status := guard.Status()[0]
+	Expect(status.HeldChanges).To(BeZero())
+	Expect(status.DroppedChanges).To(Equal(5))
+
+	// dropped changes are replaced by resync of the watchers
+	Expect(guard.Confirm("/vnf/")).To(Equal(5))
+	Eventually(resynced).Should(Receive())
+	Expect(guard.Status()[0].DroppedChanges).To(BeZero())
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(1)))
+
+	guard.admit("/vnf/", "/vnf/k", datasync.Put, deliver)
+	Expect(atomic.LoadInt32(&delivered)).To(Equal(int32(2)))
+}
+
+// watchResp is a change received from the KV store without previous value.
+type watchResp struct {
+	*syncbase.Change
+}
+
+func (r watchResp) GetPrevValue(prevValue proto.Message) (bool, error) {
+	return false, nil
+}
+
+func TestChurnGuardHeldChangesDoNotUpdateRevisions(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := &watchBrokerKeys{
+		changeChan: make(chan datasync.ChangeEvent, 10),
+		prefixes:   []string{"/vnf/"},
+		adapter: &watcher{
+			base:  syncbase.NewRegistry(),
+			guard: NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Minute}),
+		},
+	}
+	lastRev := keys.adapter.base.LastRev()
+	for _, key := range []string{"/vnf/a", "/vnf/b"} {
+		lastRev.PutWithRevision(key, syncbase.NewKeyVal(key, nil, 1))
+	}
+
+	keys.watchChanges(watchResp{syncbase.NewChange("/vnf/a", nil, 2, datasync.Delete)})
+	keys.watchChanges(watchResp{syncbase.NewChange("/vnf/b", nil, 3, datasync.Delete)})
+	Expect(keys.changeChan).To(HaveLen(1))
+	found, _ := lastRev.Get("/vnf/a")
+	Expect(found).To(BeFalse())
+	// held change is not recorded until it is delivered
+	found, _ = lastRev.Get("/vnf/b")
+	Expect(found).To(BeTrue())
+
+	keys.adapter.guard.Confirm("/vnf/")
+	Eventually(keys.changeChan).Should(HaveLen(2))
+	found, _ = lastRev.Get("/vnf/b")
+	Expect(found).To(BeFalse())
+}
+
+func TestChurnGuardRejectedDeleteIsNotDelivered(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	broker := kvtest.NewPlugin(store).NewBroker("")
+	for _, key := range []string{"/vnf/a", "/vnf/b"} {
+		Expect(broker.Put(key, &status.PluginStatus{Name: key})).To(Succeed())
+	}
+	keys := &watchBrokerKeys{
+		changeChan: make(chan datasync.ChangeEvent, 10),
+		resyncChan: make(chan datasync.ResyncEvent, 10),
+		prefixes:   []string{"/vnf/"},
+		adapter: &watcher{
+			db:    broker,
+			base:  syncbase.NewRegistry(),
+			guard: NewChurnGuard(ChurnGuardConfig{MaxDeletes: 1, Interval: time.Minute}),
+		},
+	}
+	keys.adapter.guard.registerResync(keys.prefixes, keys.rejectedResync)
+	Expect(keys.resyncRev()).To(Succeed())
+
+	// runaway controller deletes everything
+	for i, key := range []string{"/vnf/a", "/vnf/b"} {
+		_, err := broker.Delete(key)
+		Expect(err).ToNot(HaveOccurred())
+		keys.watchChanges(watchResp{syncbase.NewChange(key, nil, int64(10+i), datasync.Delete)})
+	}
+	Expect(keys.changeChan).To(HaveLen(1))
+	Expect(keys.adapter.guard.Reject("/vnf/")).To(Equal(1))
+
+	// neither the change nor resync deliver the rejected delete
+	Expect(keys.adapter.guard.holdIfPaused(keys.prefixes, keys.heldResync)).To(BeTrue())
+	Consistently(keys.changeChan).Should(HaveLen(1))
+	Consistently(keys.resyncChan).ShouldNot(Receive())
+	found, _ := keys.adapter.base.LastRev().Get("/vnf/b")
+	Expect(found).To(BeTrue())
+
+	// once the NB is repaired, the watchers are resynced with it
+	Expect(broker.Put("/vnf/b", &status.PluginStatus{Name: "/vnf/b"})).To(Succeed())
+	keys.watchChanges(watchResp{syncbase.NewChange("/vnf/b", &status.PluginStatus{Name: "/vnf/b"}, 12, datasync.Put)})
+	var resyncEvent datasync.ResyncEvent
+	Eventually(keys.resyncChan).Should(Receive(&resyncEvent))
+	kv, stop := resyncEvent.GetValues()["/vnf/"].GetNext()
+	Expect(stop).To(BeFalse())
+	Expect(kv.GetKey()).To(Equal("/vnf/b"))
+	resyncEvent.Done(nil)
+	Expect(keys.changeChan).To(HaveLen(1))
+}
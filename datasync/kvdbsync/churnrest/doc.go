@@ -0,0 +1,23 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package churnrest exposes the kvdbsync churn guard over REST, so that
+// the operator can inspect prefixes paused due to abnormal churn and confirm
+// or reject the held changes.
+//
+// GET  /datasync/churn-guard                  returns state of guarded prefixes,
+// POST /datasync/churn-guard/confirm?prefix=  applies changes held for the prefix,
+// POST /datasync/churn-guard/reject?prefix=   drops changes held for the prefix and fences it,
+// POST /datasync/churn-guard/resume?prefix=   lifts the fence of the prefix and resyncs it.
+package churnrest
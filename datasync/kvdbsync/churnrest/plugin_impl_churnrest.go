@@ -0,0 +1,150 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package churnrest
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/datasync/kvdbsync"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// GuardPath is URL path of the churn guard state.
+	GuardPath = "/datasync/churn-guard"
+	// ConfirmPath is URL path used to confirm changes held for a prefix.
+	ConfirmPath = GuardPath + "/confirm"
+	// RejectPath is URL path used to reject changes held for a prefix.
+	RejectPath = GuardPath + "/reject"
+	// ResumePath is URL path used to lift the fence of a prefix after its changes were rejected.
+	ResumePath = GuardPath + "/resume"
+	// prefixParam is URL query parameter selecting the paused prefix.
+	prefixParam = "prefix"
+)
+
+// ErrNotPaused is the class of errors returned when confirming or rejecting
+// changes of a prefix that is not paused.
+var ErrNotPaused = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "kvdbsync/prefix-not-paused",
+	Plugin:     "churn-guard-rest",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusConflict,
+})
+
+// ErrNotFenced is the class of errors returned when resuming a prefix
+// that is not fenced.
+var ErrNotFenced = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "kvdbsync/prefix-not-fenced",
+	Plugin:     "churn-guard-rest",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusConflict,
+})
+
+// Plugin registers REST handlers of the churn guard.
+type Plugin struct {
+	Deps
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	HTTP  rest.HTTPHandlers // inject
+	Guard *kvdbsync.ChurnGuard
+}
+
+// Resolution is returned after the held changes are confirmed or rejected,
+// or the prefix is resumed.
+type Resolution struct {
+	Prefix  string `json:"prefix"`
+	Changes int    `json:"changes"`
+}
+
+// Init registers the REST handlers.
+func (p *Plugin) Init() error {
+	if p.HTTP == nil || p.Guard == nil {
+		p.Log.Info("Unable to register churn guard handlers, HTTP or Guard is nil")
+		return nil
+	}
+	p.HTTP.RegisterHTTPHandler(GuardPath, p.statusHandler, http.MethodGet)
+	p.HTTP.RegisterHTTPHandler(ConfirmPath, p.resolveHandler(p.Guard.Confirm), http.MethodPost)
+	p.HTTP.RegisterHTTPHandler(RejectPath, p.resolveHandler(p.Guard.Reject), http.MethodPost)
+	p.HTTP.RegisterHTTPHandler(ResumePath, p.resumeHandler, http.MethodPost)
+
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// statusHandler returns the state of guarded prefixes.
+func (p *Plugin) statusHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, p.Guard.Status())
+	}
+}
+
+// resolveHandler confirms or rejects changes held for the prefix.
+func (p *Plugin) resolveHandler(resolve func(prefix string) int) rest.HandlerProvider {
+	return func(formatter *render.Render) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			prefix := req.URL.Query().Get(prefixParam)
+			if !p.isPaused(prefix) {
+				rest.WriteError(formatter, w, ErrNotPaused.Errorf("prefix %q is not paused", prefix))
+				return
+			}
+			formatter.JSON(w, http.StatusOK, Resolution{
+				Prefix:  prefix,
+				Changes: resolve(prefix),
+			})
+		}
+	}
+}
+
+// resumeHandler lifts the fence of the prefix.
+func (p *Plugin) resumeHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		prefix := req.URL.Query().Get(prefixParam)
+		if !p.isFenced(prefix) {
+			rest.WriteError(formatter, w, ErrNotFenced.Errorf("prefix %q is not fenced", prefix))
+			return
+		}
+		formatter.JSON(w, http.StatusOK, Resolution{
+			Prefix:  prefix,
+			Changes: p.Guard.Resume(prefix),
+		})
+	}
+}
+
+func (p *Plugin) isPaused(prefix string) bool {
+	for _, status := range p.Guard.Status() {
+		if status.Prefix == prefix {
+			return status.Paused
+		}
+	}
+	return false
+}
+
+func (p *Plugin) isFenced(prefix string) bool {
+	for _, status := range p.Guard.Status() {
+		if status.Prefix == prefix {
+			return status.Fenced
+		}
+	}
+	return false
+}
@@ -31,4 +31,46 @@
 // triggered, then the Data Broker is used to read all particular keys &
 // values from the key-value store. Reading all particular keys & values is
 // more reliable but less efficient data synchronization method.
+//
+// Optionally, the data can be mirrored into a local cache (e.g. Bolt file, see
+// UseCache). If the key-value store is unreachable at startup, the last known
+// configuration is read from the cache during RESYNC, so that the agent
+// converges to it before the connection is established.
+//
+// If a status check is injected (see UseStatusCheck) and the key-value store
+// becomes unreachable later, the plugin switches into the "frozen NB" mode:
+// the agent keeps the last known configuration, the plugin reports degraded
+// state to the status check and buffers writes (e.g. status updates), which
+// return ErrBuffered. Once the connectivity returns, the buffered writes are
+// flushed and RESYNC is started. Writes keep being buffered until the flush
+// is finished, so they are not overwritten by older buffered values.
+//
+// Optionally, watched prefixes can be protected against abnormal churn (see
+// UseChurnGuard). If more keys under a prefix are deleted within the configured
+// interval than allowed, the changes of the prefix are held until the operator
+// confirms or rejects them (e.g. over REST, see package churnrest). Rejected
+// changes are dropped, the watchers of the prefix stay on the last delivered
+// state and the prefix stays fenced (changes and resyncs are held) until
+// the deleted keys are put back to the KV store or the operator resumes
+// the prefix. Then the watchers are resynchronized with the KV store, the same
+// happens when more changes are held than allowed and they are confirmed.
+// The thresholds can be set in the plugin configuration file:
+//
+//	churn-guard:
+//	  max-deletes: 100
+//	  interval: 10s
+//	  max-held-changes: 10000
+//
+// Optionally, the number of keys and the size of values under watched prefixes
+// can be limited (see UseQuota). Values exceeding the quota are not delivered
+// to the watchers and the error is published back to NB under QuotaErrorKey.
+//
+// Optionally, the agent can be put into maintenance mode (see UseMaintenance).
+// Changes received from the key-value store in the meantime are queued and
+// replayed in order once the mode is left (e.g. over REST, see package
+// maintenance). If more changes are queued than allowed, they are dropped
+// and all watchers are resynchronized with the KV store instead:
+//
+//	maintenance:
+//	  max-queued-changes: 10000
 package kvdbsync
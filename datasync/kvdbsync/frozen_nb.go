@@ -0,0 +1,226 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/pkg/errors"
+)
+
+// healthProbePrefix is listed by the status check probe to verify that the KV store is reachable.
+const healthProbePrefix = "kvdbsync/probe/"
+
+// errNotConnected is reported while the agent runs from the local cache.
+var errNotConnected = errors.New("KV store is not connected, using the last known configuration")
+
+// frozenNB tracks the "frozen NB" mode entered when the KV store becomes unreachable.
+// The watchers keep the last known configuration (no changes are received) and the writes
+// into the KV store (e.g. status updates) are buffered until the connectivity returns.
+// The mode is left only after all buffered writes were flushed, writes done during
+// the flush are buffered as well, so that an older buffered value never overwrites them.
+type frozenNB struct {
+	sync.Mutex
+	frozen  bool
+	since   time.Time
+	keys    []string
+	pending map[string]*pendingWrite
+
+	// recovering is set while the buffered writes are flushed
+	recovering bool
+	// lost is set if the KV store became unreachable again during the flush
+	lost bool
+}
+
+// pendingWrite is the last buffered write for a key.
+type pendingWrite struct {
+	value   proto.Message // nil for delete
+	putOpts []datasync.PutOption
+	delOpts []datasync.DelOption
+}
+
+// freeze enters the frozen mode, returns false if it was already entered.
+func (nb *frozenNB) freeze() bool {
+	nb.Lock()
+	defer nb.Unlock()
+
+	if nb.frozen {
+		if nb.recovering && !nb.lost {
+			nb.lost = true
+			return true
+		}
+		return false
+	}
+	nb.frozen = true
+	nb.since = time.Now()
+	return true
+}
+
+// startRecovery marks the start of the flush of the buffered writes. It returns
+// false if the mode is not frozen or the flush is already running.
+func (nb *frozenNB) startRecovery() (started bool, since time.Time, buffered int) {
+	nb.Lock()
+	defer nb.Unlock()
+
+	if !nb.frozen || nb.recovering {
+		return false, nb.since, 0
+	}
+	nb.recovering = true
+	return true, nb.since, len(nb.keys)
+}
+
+// takePending returns writes buffered since the last call. Once there are no more
+// writes to flush, the frozen mode is left and recovered is returned true.
+// If the KV store became unreachable again, the flush is stopped (done is true),
+// the mode stays frozen and the remaining writes stay buffered.
+func (nb *frozenNB) takePending() (keys []string, writes map[string]*pendingWrite, done, recovered bool) {
+	nb.Lock()
+	defer nb.Unlock()
+
+	if nb.lost {
+		nb.recovering, nb.lost = false, false
+		return nil, nil, true, false
+	}
+	if len(nb.keys) == 0 {
+		nb.frozen, nb.recovering = false, false
+		return nil, nil, true, true
+	}
+	keys, writes = nb.keys, nb.pending
+	nb.keys, nb.pending = nil, nil
+	return keys, writes, false, false
+}
+
+// buffer stores the write if the mode is frozen, returns false otherwise.
+func (nb *frozenNB) buffer(key string, write *pendingWrite) bool {
+	nb.Lock()
+	defer nb.Unlock()
+
+	if !nb.frozen {
+		return false
+	}
+	if nb.pending == nil {
+		nb.pending = make(map[string]*pendingWrite)
+	}
+	if _, exists := nb.pending[key]; !exists {
+		nb.keys = append(nb.keys, key)
+	}
+	nb.pending[key] = write
+	return true
+}
+
+// restore puts back the writes which failed to be flushed, unless a newer write was
+// buffered for the same key in the meantime. The restored writes are flushed before
+// those buffered during the flush. The flush is stopped as if the KV store became
+// unreachable, so that the writes are retried by the next recovery round.
+func (nb *frozenNB) restore(keys []string, writes map[string]*pendingWrite) {
+	nb.Lock()
+	defer nb.Unlock()
+
+	if nb.pending == nil {
+		nb.pending = make(map[string]*pendingWrite)
+	}
+	var restored []string
+	for _, key := range keys {
+		if _, newer := nb.pending[key]; newer {
+			continue
+		}
+		nb.pending[key] = writes[key]
+		restored = append(restored, key)
+	}
+	nb.keys = append(restored, nb.keys...)
+	nb.lost = true
+}
+
+// probeKV is the status check probe of the plugin. Once the KV store becomes unreachable,
+// the plugin switches into the frozen NB mode and reports degraded state. When the connectivity
+// returns, the buffered writes are flushed and the resync is started.
+func (p *Plugin) probeKV() (statuscheck.PluginState, error) {
+	p.mu.Lock()
+	adapter, connected, cached := p.adapter, p.connected, p.cachedKeys != nil
+	p.mu.Unlock()
+
+	if !connected {
+		if cached {
+			return statuscheck.Degraded, errNotConnected
+		}
+		return statuscheck.Init, nil
+	}
+
+	if _, err := adapter.db.ListKeys(healthProbePrefix); err != nil {
+		if p.nb.freeze() {
+			p.Log.Warnf("%s unreachable, keeping the last known configuration until it recovers: %v",
+				p.KvPlugin, err)
+		}
+		return statuscheck.Degraded, err
+	}
+
+	if started, since, buffered := p.nb.startRecovery(); started {
+		p.Log.Infof("%s reachable again after %v, %d buffered writes", p.KvPlugin,
+			time.Since(since).Round(time.Second), buffered)
+		p.loop.Go(func(ctx context.Context) {
+			p.recoverNB(adapter)
+		})
+	}
+	return statuscheck.OK, nil
+}
+
+// recoverNB flushes writes buffered in the frozen NB mode and starts the resync
+// to apply changes of the configuration done while the KV store was unreachable.
+// Writes buffered during the flush are flushed in the next round, the frozen mode
+// is left once there is nothing more to flush.
+func (p *Plugin) recoverNB(adapter *watcher) {
+	for {
+		keys, writes, done, recovered := p.nb.takePending()
+		if done {
+			if !recovered {
+				p.Log.Warnf("%s unreachable again, keeping the remaining writes buffered for the next recovery", p.KvPlugin)
+				return
+			}
+			break
+		}
+		if failed := p.flushWrites(adapter, keys, writes); len(failed) > 0 {
+			p.nb.restore(failed, writes)
+		}
+	}
+
+	if resync, ok := p.ResyncOrch.(interface{ DoResync() }); ok {
+		resync.DoResync()
+	}
+}
+
+// flushWrites writes the buffered values in the order they were first buffered.
+// The flush stops at the first failed write, the keys which were not written
+// (including the failed one) are returned.
+func (p *Plugin) flushWrites(adapter *watcher, keys []string, writes map[string]*pendingWrite) (failed []string) {
+	for i, key := range keys {
+		var err error
+		if write := writes[key]; write.value != nil {
+			err = adapter.db.Put(key, write.value, write.putOpts...)
+		} else {
+			_, err = adapter.db.Delete(key, write.delOpts...)
+		}
+		if err != nil {
+			p.Log.Warnf("writing buffered value of %s failed, %d writes stay buffered: %v",
+				key, len(keys)-i, err)
+			return keys[i:]
+		}
+	}
+	return nil
+}
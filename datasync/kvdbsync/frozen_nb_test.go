@@ -0,0 +1,251 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/datasync/resync"
+	"github.com/ligato/cn-infra/db/keyval/kvtest"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/servicelabel"
+	. "github.com/onsi/gomega"
+)
+
+func TestFrozenNBBuffer(t *testing.T) {
+	RegisterTestingT(t)
+
+	var nb frozenNB
+	Expect(nb.buffer("a", &pendingWrite{value: &status.PluginStatus{}})).To(BeFalse())
+
+	Expect(nb.freeze()).To(BeTrue())
+	Expect(nb.freeze()).To(BeFalse())
+	Expect(nb.buffer("a", &pendingWrite{value: &status.PluginStatus{Name: "a1"}})).To(BeTrue())
+	Expect(nb.buffer("b", &pendingWrite{value: &status.PluginStatus{Name: "b"}})).To(BeTrue())
+	Expect(nb.buffer("a", &pendingWrite{})).To(BeTrue())
+
+	started, _, buffered := nb.startRecovery()
+	Expect(started).To(BeTrue())
+	Expect(buffered).To(Equal(2))
+	started, _, _ = nb.startRecovery()
+	Expect(started).To(BeFalse())
+
+	keys, writes, done, _ := nb.takePending()
+	Expect(done).To(BeFalse())
+	Expect(keys).To(Equal([]string{"a", "b"}))
+	Expect(writes["a"].value).To(BeNil())
+	Expect(writes["b"].value).To(Equal(&status.PluginStatus{Name: "b"}))
+
+	// still frozen until everything is flushed
+	Expect(nb.buffer("c", &pendingWrite{})).To(BeTrue())
+	keys, _, done, _ = nb.takePending()
+	Expect(done).To(BeFalse())
+	Expect(keys).To(Equal([]string{"c"}))
+
+	_, _, done, recovered := nb.takePending()
+	Expect(done).To(BeTrue())
+	Expect(recovered).To(BeTrue())
+	Expect(nb.buffer("d", &pendingWrite{})).To(BeFalse())
+}
+
+func TestFrozenNBLostDuringRecovery(t *testing.T) {
+	RegisterTestingT(t)
+
+	var nb frozenNB
+	Expect(nb.freeze()).To(BeTrue())
+	Expect(nb.buffer("a", &pendingWrite{})).To(BeTrue())
+	started, _, _ := nb.startRecovery()
+	Expect(started).To(BeTrue())
+
+	Expect(nb.freeze()).To(BeTrue())
+	_, _, done, recovered := nb.takePending()
+	Expect(done).To(BeTrue())
+	Expect(recovered).To(BeFalse())
+
+	// the write stays buffered for the next recovery
+	started, _, buffered := nb.startRecovery()
+	Expect(started).To(BeTrue())
+	Expect(buffered).To(Equal(1))
+}
+
+// resyncMock counts resyncs started by the plugin.
+type resyncMock struct {
+	mu      sync.Mutex
+	resyncs int
+}
+
+// Register is not used, the tests do not watch any keys.
+func (r *resyncMock) Register(resyncName string) resync.Registration {
+	return nil
+}
+
+func (r *resyncMock) DoResync() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resyncs++
+}
+
+func (r *resyncMock) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resyncs
+}
+
+func newFrozenNBTestPlugin(store *kvtest.Store, resyncOrch *resyncMock) *Plugin {
+	p := NewPlugin(UseKV(kvtest.NewPlugin(store)), UseDeps(func(deps *Deps) {
+		deps.ResyncOrch = resyncOrch
+		deps.ServiceLabel = &servicelabel.Plugin{MicroserviceLabel: "test"}
+	}))
+	Expect(p.Init()).To(Succeed())
+	Expect(p.initKvPlugin()).To(Succeed())
+	return p
+}
+
+func TestProbeKVFreezesAndRecovers(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	resyncOrch := &resyncMock{}
+	p := newFrozenNBTestPlugin(store, resyncOrch)
+	broker := kvtest.NewPlugin(store).NewBroker("/vnf-agent/test/")
+
+	state, err := p.probeKV()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(state).To(Equal(statuscheck.OK))
+	Expect(p.Put("status/a", &status.PluginStatus{Name: "a"})).To(Succeed())
+
+	// KV store unreachable: writes are buffered
+	store.InjectFault(kvtest.Fault{Op: kvtest.OpList, Err: errors.New("etcd down")})
+	state, err = p.probeKV()
+	Expect(err).To(HaveOccurred())
+	Expect(state).To(Equal(statuscheck.Degraded))
+
+	value := &status.PluginStatus{Name: "b"}
+	Expect(p.Put("status/b", value)).To(Equal(ErrBuffered))
+	value.Name = "modified after Put"
+	_, err = p.Delete("status/a")
+	Expect(err).To(Equal(ErrBuffered))
+	Expect(store.Calls(kvtest.OpPut)).To(Equal(1))
+
+	// KV store reachable again: buffered writes are flushed and resync is started
+	store.ClearFaults()
+	state, err = p.probeKV()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(state).To(Equal(statuscheck.OK))
+	Eventually(resyncOrch.count).Should(Equal(1))
+
+	var stored status.PluginStatus
+	found, _, err := broker.GetValue("status/b", &stored)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(stored.Name).To(Equal("b"))
+	found, _, _ = broker.GetValue("status/a", &stored)
+	Expect(found).To(BeFalse())
+
+	// writes are not buffered anymore
+	Expect(p.Put("status/c", &status.PluginStatus{Name: "c"})).To(Succeed())
+}
+
+func TestRecoverNBRetriesFailedWrite(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	resyncOrch := &resyncMock{}
+	p := newFrozenNBTestPlugin(store, resyncOrch)
+	broker := kvtest.NewPlugin(store).NewBroker("/vnf-agent/test/")
+
+	store.InjectFault(kvtest.Fault{Op: kvtest.OpPut, Key: "/vnf-agent/test/status/a", Err: errors.New("failed"), Times: 1})
+	p.nb.freeze()
+	Expect(p.Put("status/a", &status.PluginStatus{Name: "a"})).To(Equal(ErrBuffered))
+	Expect(p.Put("status/b", &status.PluginStatus{Name: "b"})).To(Equal(ErrBuffered))
+	p.nb.startRecovery()
+	p.recoverNB(p.adapter)
+
+	// the failed write and the remaining ones stay buffered
+	var stored status.PluginStatus
+	found, _, _ := broker.GetValue("status/a", &stored)
+	Expect(found).To(BeFalse())
+	found, _, _ = broker.GetValue("status/b", &stored)
+	Expect(found).To(BeFalse())
+	Expect(resyncOrch.count()).To(BeZero())
+	Expect(p.Put("status/c", &status.PluginStatus{Name: "c"})).To(Equal(ErrBuffered))
+
+	// retried by the next recovery round
+	started, _, buffered := p.nb.startRecovery()
+	Expect(started).To(BeTrue())
+	Expect(buffered).To(Equal(3))
+	p.recoverNB(p.adapter)
+	for _, key := range []string{"status/a", "status/b", "status/c"} {
+		found, _, _ = broker.GetValue(key, &stored)
+		Expect(found).To(BeTrue(), key)
+	}
+	Expect(resyncOrch.count()).To(Equal(1))
+}
+
+func TestFrozenNBRestoreKeepsNewerWrite(t *testing.T) {
+	RegisterTestingT(t)
+
+	var nb frozenNB
+	nb.freeze()
+	nb.buffer("a", &pendingWrite{value: &status.PluginStatus{Name: "old a"}})
+	nb.buffer("b", &pendingWrite{value: &status.PluginStatus{Name: "b"}})
+	nb.startRecovery()
+	keys, writes, _, _ := nb.takePending()
+
+	// newer write buffered during the flush which failed
+	nb.buffer("a", &pendingWrite{value: &status.PluginStatus{Name: "new a"}})
+	nb.restore(keys, writes)
+
+	_, _, done, recovered := nb.takePending()
+	Expect(done).To(BeTrue())
+	Expect(recovered).To(BeFalse())
+	nb.startRecovery()
+	keys, writes, _, _ = nb.takePending()
+	Expect(keys).To(Equal([]string{"b", "a"}))
+	Expect(writes["a"].value).To(Equal(&status.PluginStatus{Name: "new a"}))
+}
+
+func TestWriteDuringRecoveryIsNotOverwritten(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	resyncOrch := &resyncMock{}
+	p := newFrozenNBTestPlugin(store, resyncOrch)
+	defer p.Close()
+	broker := kvtest.NewPlugin(store).NewBroker("/vnf-agent/test/")
+
+	store.InjectFault(kvtest.Fault{Op: kvtest.OpList, Err: errors.New("etcd down")})
+	_, err := p.probeKV()
+	Expect(err).To(HaveOccurred())
+	Expect(p.Put("status/a", &status.PluginStatus{Name: "old"})).To(Equal(ErrBuffered))
+
+	// the flush of the buffered value is slow, a newer value is written meanwhile
+	store.ClearFaults()
+	store.InjectFault(kvtest.Fault{Op: kvtest.OpPut, Key: "/vnf-agent/test/status/a", Delay: 100 * time.Millisecond, Times: 1})
+	_, err = p.probeKV()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(p.Put("status/a", &status.PluginStatus{Name: "new"})).To(Equal(ErrBuffered))
+
+	Eventually(resyncOrch.count).Should(Equal(1))
+	var stored status.PluginStatus
+	found, _, err := broker.GetValue("status/a", &stored)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(stored.Name).To(Equal("new"))
+}
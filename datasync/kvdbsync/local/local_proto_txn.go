@@ -27,11 +27,15 @@ import (
 // protoTxnItem is used in ProtoTxn.
 type protoTxnItem struct {
 	data   proto.Message
+	lazy   datasync.LazyValue
 	delete bool
 }
 
 // GetValue returns the value of the pair.
 func (item *protoTxnItem) GetValue(out proto.Message) error {
+	if item.lazy != nil {
+		return item.lazy.GetValue(out)
+	}
 	if item.data != nil {
 		proto.Merge(out, item.data)
 	}
@@ -64,6 +68,27 @@ func (txn *ProtoTxn) Put(key string, data proto.Message) keyval.ProtoTxn {
 	return txn
 }
 
+// SetValues adds store operations of multiple values into transaction
+// under a single lock. It is intended for resync-style callers staging
+// thousands of values at once. Nil value is staged as delete operation.
+func (txn *ProtoTxn) SetValues(values map[string]datasync.LazyValue) keyval.ProtoTxn {
+	txn.access.Lock()
+	defer txn.access.Unlock()
+
+	if len(txn.items) == 0 {
+		txn.items = make(map[string]*protoTxnItem, len(values))
+	}
+	for key, value := range values {
+		if value == nil {
+			txn.items[key] = &protoTxnItem{delete: true}
+		} else {
+			txn.items[key] = &protoTxnItem{lazy: value}
+		}
+	}
+
+	return txn
+}
+
 // Delete adds delete operation into transaction.
 func (txn *ProtoTxn) Delete(key string) keyval.ProtoTxn {
 	txn.access.Lock()
@@ -87,7 +112,11 @@ func (txn *ProtoTxn) Commit(ctx context.Context) error {
 			changeType = datasync.Delete
 		}
 
-		kvs[key] = syncbase.NewChange(key, item.data, 0, changeType)
+		if item.lazy != nil {
+			kvs[key] = syncbase.NewLazyChange(key, item.lazy, 0, changeType)
+		} else {
+			kvs[key] = syncbase.NewChange(key, item.data, 0, changeType)
+		}
 	}
 
 	return txn.commit(ctx, kvs)
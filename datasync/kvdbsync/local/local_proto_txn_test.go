@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+func TestProtoTxnSetValues(t *testing.T) {
+	RegisterTestingT(t)
+
+	var committed map[string]datasync.ChangeValue
+	txn := NewProtoTxn(func(ctx context.Context, kvs map[string]datasync.ChangeValue) error {
+		committed = kvs
+		return nil
+	})
+
+	txn.Put("/a", &status.PluginStatus{Name: "a"})
+	txn.SetValues(map[string]datasync.LazyValue{
+		"/b": syncbase.NewChange("/b", &status.PluginStatus{Name: "b"}, 0, datasync.Put),
+		"/c": nil,
+	})
+	Expect(txn.Commit(context.Background())).To(Succeed())
+
+	Expect(committed).To(HaveLen(3))
+	Expect(committed["/b"].GetChangeType()).To(Equal(datasync.Put))
+	Expect(committed["/c"].GetChangeType()).To(Equal(datasync.Delete))
+
+	value := &status.PluginStatus{}
+	Expect(committed["/b"].GetValue(value)).To(Succeed())
+	Expect(value.Name).To(Equal("b"))
+	Expect(committed["/a"].GetValue(value)).To(Succeed())
+	Expect(value.Name).To(Equal("a"))
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/kvproto"
+	"github.com/pkg/errors"
+)
+
+// RawAccessor is implemented by KV plugins which provide access to data as raw bytes.
+// Both the KV plugin and the local cache must implement it to mirror the data.
+type RawAccessor interface {
+	// RawAccess allows to access data in the database as raw bytes.
+	RawAccess() keyval.KvBytesPlugin
+}
+
+// cacheMirror copies values from the KV store into the local cache, so that the last
+// known configuration can be replayed when the KV store is not reachable at startup.
+type cacheMirror struct {
+	src keyval.BytesBroker
+	dst keyval.BytesBroker
+}
+
+// newCacheMirror returns mirror between KV plugin and cache for given key prefix, or nil
+// if any of them does not provide raw access to the data.
+func newCacheMirror(kv, cache keyval.KvProtoPlugin, keyPrefix string) *cacheMirror {
+	kvRaw, ok := kv.(RawAccessor)
+	if !ok {
+		return nil
+	}
+	cacheRaw, ok := cache.(RawAccessor)
+	if !ok {
+		return nil
+	}
+	return &cacheMirror{
+		src: kvRaw.RawAccess().NewBroker(keyPrefix),
+		dst: cacheRaw.RawAccess().NewBroker(keyPrefix),
+	}
+}
+
+// sync replaces cached values under the key prefix with the current values from the KV store.
+func (m *cacheMirror) sync(keyPrefix string) error {
+	it, err := m.src.ListValues(keyPrefix)
+	if err != nil {
+		return errors.WithMessagef(err, "list values for %s failed", keyPrefix)
+	}
+	current := make(map[string]struct{})
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		current[kv.GetKey()] = struct{}{}
+		if err := m.dst.Put(kv.GetKey(), kv.GetValue()); err != nil {
+			return errors.WithMessagef(err, "caching value for %s failed", kv.GetKey())
+		}
+	}
+
+	keys, err := m.dst.ListKeys(keyPrefix)
+	if err != nil {
+		return errors.WithMessagef(err, "list cached keys for %s failed", keyPrefix)
+	}
+	var obsolete []string
+	for {
+		key, _, stop := keys.GetNext()
+		if stop {
+			break
+		}
+		if _, ok := current[key]; !ok {
+			obsolete = append(obsolete, key)
+		}
+	}
+	for _, key := range obsolete {
+		if _, err := m.dst.Delete(key); err != nil {
+			return errors.WithMessagef(err, "removing cached value for %s failed", key)
+		}
+	}
+	return nil
+}
+
+// update propagates single change of the KV store into the cache. The serialized value
+// carried by the watch event is used if available, otherwise it is read from the KV store.
+func (m *cacheMirror) update(change datasync.ChangeValue) error {
+	key := change.GetKey()
+	if change.GetChangeType() == datasync.Delete {
+		_, err := m.dst.Delete(key)
+		return err
+	}
+	if raw, ok := change.(kvproto.RawValue); ok {
+		if data, _ := raw.GetRawValue(); data != nil {
+			return m.dst.Put(key, data)
+		}
+	}
+	data, found, _, err := m.src.GetValue(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		_, err = m.dst.Delete(key)
+		return err
+	}
+	return m.dst.Put(key, data)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/bolt"
+	. "github.com/onsi/gomega"
+)
+
+func newBoltClient(dir, name string) *bolt.Client {
+	client, err := bolt.NewClient(&bolt.Config{
+		DbPath:   filepath.Join(dir, name),
+		FileMode: 0600,
+	})
+	Expect(err).ToNot(HaveOccurred())
+	return client
+}
+
+// cachedValue returns cached value, or empty string if the key is not cached
+func cachedValue(broker keyval.BytesBroker, key string) string {
+	data, found, _, _ := broker.GetValue(key)
+	if !found {
+		return ""
+	}
+	return string(data)
+}
+
+func TestCacheMirror(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "kvdbsync")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	src := newBoltClient(dir, "src.db")
+	defer src.Close()
+	dst := newBoltClient(dir, "dst.db")
+	defer dst.Close()
+
+	mirror := &cacheMirror{src: src.NewBroker("/agent/"), dst: dst.NewBroker("/agent/")}
+	cache := dst.NewBroker("/agent/")
+
+	Expect(src.Put("/agent/config/a", []byte("a"))).To(Succeed())
+	Expect(src.Put("/agent/config/b", []byte("b"))).To(Succeed())
+	Expect(dst.Put("/agent/config/obsolete", []byte("x"))).To(Succeed())
+
+	// full sync replaces cached data
+	Expect(mirror.sync("config/")).To(Succeed())
+	Expect(cachedValue(cache, "config/a")).To(Equal("a"))
+	Expect(cachedValue(cache, "config/b")).To(Equal("b"))
+	Expect(cachedValue(cache, "config/obsolete")).To(BeEmpty())
+
+	// single changes without the serialized value are read from the KV store
+	Expect(src.Put("/agent/config/a", []byte("a2"))).To(Succeed())
+	Expect(mirror.update(syncbase.NewChange("config/a", nil, 0, datasync.Put))).To(Succeed())
+	Expect(cachedValue(cache, "config/a")).To(Equal("a2"))
+
+	// the serialized value carried by the change is used as is
+	Expect(mirror.update(&rawChange{syncbase.NewChange("config/a", nil, 0, datasync.Put), []byte("a3")})).To(Succeed())
+	Expect(cachedValue(cache, "config/a")).To(Equal("a3"))
+
+	Expect(mirror.update(syncbase.NewChange("config/b", nil, 0, datasync.Delete))).To(Succeed())
+	Expect(cachedValue(cache, "config/b")).To(BeEmpty())
+}
+
+// rawChange is a change carrying the serialized value, as watch events of KV plugins do
+type rawChange struct {
+	*syncbase.Change
+	data []byte
+}
+
+func (c *rawChange) GetRawValue() ([]byte, keyval.Serializer) {
+	return c.data, &keyval.SerializerJSON{}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging/logrus"
+)
+
+// MaintenanceConfig limits the changes queued in maintenance mode. It can be
+// loaded from the "maintenance" section of the plugin configuration file.
+type MaintenanceConfig struct {
+	// MaxQueuedChanges is the maximum number of changes queued in maintenance mode.
+	// Once exceeded, the queued changes are dropped and all watchers are
+	// resynchronized with the KV store when the mode is left.
+	MaxQueuedChanges int `json:"max-queued-changes"`
+}
+
+// DefaultMaintenanceConfig returns MaintenanceConfig with default values,
+// which are also used for unset (zero) values of the config.
+func DefaultMaintenanceConfig() MaintenanceConfig {
+	return MaintenanceConfig{
+		MaxQueuedChanges: 10000,
+	}
+}
+
+// withDefaults replaces unset values with defaults.
+func (c MaintenanceConfig) withDefaults() MaintenanceConfig {
+	if c.MaxQueuedChanges <= 0 {
+		c.MaxQueuedChanges = DefaultMaintenanceConfig().MaxQueuedChanges
+	}
+	return c
+}
+
+// MaintenanceStatus describes the state of the maintenance mode.
+type MaintenanceStatus struct {
+	Enabled        bool      `json:"enabled"`
+	Since          time.Time `json:"since,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	QueuedChanges  int       `json:"queued-changes"`
+	DroppedChanges int       `json:"dropped-changes"`
+	// Replaying is set while the queued changes are replayed after Exit,
+	// the mode stays enabled until they are all delivered.
+	Replaying       bool `json:"replaying"`
+	ReplayedChanges int  `json:"replayed-changes"`
+}
+
+// Maintenance implements the maintenance mode of the agent. While the mode
+// is enabled, changes (and resyncs) of all watched prefixes are accepted
+// from the KV store but their delivery to the watchers is queued. Leaving
+// the mode replays the queued deliveries in the order they were received
+// (in the background, the mode stays enabled until the replay is finished).
+// The number of queued changes is limited, changes exceeding the limit are
+// dropped and replaced by resync of all watchers.
+type Maintenance struct {
+	mu      sync.Mutex
+	cfg     MaintenanceConfig
+	enabled bool
+	since   time.Time
+	reason  string
+	queued  []func()
+	// dropped counts changes dropped after the queued changes exceeded the limit
+	dropped int
+	// resyncs of all watchers, started instead of replaying the dropped changes
+	resyncs []func()
+	// replaying is set while the queued changes are delivered after Exit
+	replaying bool
+	// pending counts changes taken from the queue but not delivered yet
+	pending int
+	// replayed counts changes delivered since Exit
+	replayed int
+	// loop runs the replay, the kvdbsync plugin replaces it with its own loop
+	// so that the replay is stopped when the plugin is closed
+	loop *infra.EventLoop
+}
+
+// NewMaintenance creates a new Maintenance with the mode disabled
+// and the default config (see DefaultMaintenanceConfig).
+func NewMaintenance() *Maintenance {
+	return &Maintenance{cfg: DefaultMaintenanceConfig(), loop: &infra.EventLoop{}}
+}
+
+// SetConfig changes limits of the maintenance mode, e.g. once loaded from the configuration file.
+func (m *Maintenance) SetConfig(cfg MaintenanceConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg.withDefaults()
+}
+
+// registerResync registers resync of a watcher, which is started when leaving
+// the mode if the queued changes were dropped.
+func (m *Maintenance) registerResync(resync func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resyncs = append(m.resyncs, resync)
+}
+
+// Enter enables the maintenance mode, returns false if it was already enabled.
+func (m *Maintenance) Enter(reason string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.enabled {
+		return false
+	}
+	m.enabled = true
+	m.since = time.Now()
+	m.reason = reason
+	logrus.DefaultLogger().Warnf("Entering maintenance mode (%s), changes are queued", reason)
+	return true
+}
+
+// Exit disables the maintenance mode once the queued changes are replayed in order
+// in the background. Changes received during the replay are queued behind and replayed
+// as well. If the queued changes were dropped, all watchers are resynchronized instead.
+// The progress of the replay is reported by Status, done (if not nil) is called
+// once the mode is left. It returns the number of changes queued for the replay
+// or -1 if the mode was not enabled (or it is being left).
+func (m *Maintenance) Exit(done func()) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled || m.replaying {
+		return -1
+	}
+	m.replaying = true
+	m.replayed = 0
+	m.loop.Go(func(ctx context.Context) {
+		m.replay(ctx, done)
+	})
+	return len(m.queued) + m.dropped
+}
+
+// replay delivers the queued changes until there are none left and disables the mode.
+// It returns early if the context is canceled, the remaining changes are not delivered.
+func (m *Maintenance) replay(ctx context.Context, done func()) {
+	var dropped int
+	for {
+		m.mu.Lock()
+		queued := m.queued
+		m.queued = nil
+		m.pending = len(queued)
+		var resyncs []func()
+		if m.dropped > 0 {
+			dropped += m.dropped
+			m.dropped = 0
+			resyncs = m.resyncs
+		}
+		if len(queued) == 0 && len(resyncs) == 0 {
+			m.enabled = false
+			m.replaying = false
+			m.reason = ""
+			replayed := m.replayed
+			m.mu.Unlock()
+
+			if dropped > 0 {
+				logrus.DefaultLogger().Warnf("Maintenance mode left, %d dropped changes replaced by resync, "+
+					"%d queued changes replayed", dropped, replayed)
+			} else {
+				logrus.DefaultLogger().Infof("Maintenance mode left, %d queued changes replayed", replayed)
+			}
+			if done != nil {
+				done()
+			}
+			return
+		}
+		m.mu.Unlock()
+
+		for _, resync := range resyncs {
+			if m.stopped(ctx) {
+				return
+			}
+			resync()
+		}
+		for _, deliver := range queued {
+			if m.stopped(ctx) {
+				return
+			}
+			deliver()
+			m.mu.Lock()
+			m.pending--
+			m.replayed++
+			m.mu.Unlock()
+		}
+	}
+}
+
+// stopped returns true if the replay was canceled, in which case it is no longer in progress.
+func (m *Maintenance) stopped(ctx context.Context) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replaying = false
+	m.pending = 0
+	logrus.DefaultLogger().Warnf("Replay of changes queued in maintenance mode stopped, "+
+		"%d changes replayed", m.replayed)
+	return true
+}
+
+// Enabled returns true while the maintenance mode is enabled (including the replay).
+func (m *Maintenance) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enabled
+}
+
+// Status returns the state of the maintenance mode.
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := MaintenanceStatus{
+		Enabled:         m.enabled,
+		QueuedChanges:   len(m.queued) + m.pending,
+		DroppedChanges:  m.dropped,
+		Replaying:       m.replaying,
+		ReplayedChanges: m.replayed,
+	}
+	if m.enabled {
+		status.Since = m.since
+		status.Reason = m.reason
+	}
+	return status
+}
+
+// hold queues the delivery if the maintenance mode is enabled. If the number
+// of queued changes exceeds the limit, they are all dropped together with
+// the following changes.
+func (m *Maintenance) hold(deliver func()) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		return false
+	}
+	max := m.cfg.withDefaults().MaxQueuedChanges
+	switch {
+	case m.dropped > 0:
+		m.dropped++
+	case len(m.queued) < max:
+		m.queued = append(m.queued, deliver)
+	default:
+		m.dropped = len(m.queued) + 1
+		m.queued = nil
+		logrus.DefaultLogger().Warnf("More than %d changes queued in maintenance mode, dropping them, "+
+			"watchers will be resynced once the mode is left", max)
+	}
+	return true
+}
+
+// admit delivers the change unless the maintenance mode is enabled,
+// in which case the delivery is queued.
+func (m *Maintenance) admit(deliver func()) {
+	if !m.hold(deliver) {
+		deliver()
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance exposes the kvdbsync maintenance mode over REST and
+// reports it to the status check.
+//
+// While the agent is in maintenance mode, changes received from the KV store
+// are accepted but queued instead of being applied, and the plugin reports
+// init state, so that the readiness probe fails. Leaving the mode replays
+// the queued changes in order in the background, the mode is left (and the
+// readiness restored) once they are all replayed.
+//
+// GET  /datasync/maintenance               returns the state of the maintenance mode,
+// POST /datasync/maintenance/enter?reason= enters the maintenance mode,
+// POST /datasync/maintenance/exit          starts replay of queued changes and leaves the mode once done.
+package maintenance
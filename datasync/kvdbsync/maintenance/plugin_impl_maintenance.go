@@ -0,0 +1,166 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/datasync/kvdbsync"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// StatusPath is URL path of the maintenance mode state.
+	StatusPath = "/datasync/maintenance"
+	// EnterPath is URL path used to enter the maintenance mode.
+	EnterPath = StatusPath + "/enter"
+	// ExitPath is URL path used to leave the maintenance mode.
+	ExitPath = StatusPath + "/exit"
+	// reasonParam is URL query parameter with the reason of the maintenance.
+	reasonParam = "reason"
+)
+
+var (
+	// ErrInMaintenance is the class of errors returned when entering the maintenance
+	// mode which is already enabled. It is also reported to the status check while
+	// the agent is in maintenance mode.
+	ErrInMaintenance = infra.RegisterErrorClass(infra.ErrorClass{
+		Code:       "kvdbsync/in-maintenance",
+		Plugin:     "maintenance",
+		Severity:   infra.SeverityWarning,
+		HTTPStatus: http.StatusConflict,
+	})
+	// ErrNotInMaintenance is the class of errors returned when leaving the maintenance
+	// mode which is not enabled.
+	ErrNotInMaintenance = infra.RegisterErrorClass(infra.ErrorClass{
+		Code:       "kvdbsync/not-in-maintenance",
+		Plugin:     "maintenance",
+		Severity:   infra.SeverityWarning,
+		HTTPStatus: http.StatusConflict,
+	})
+)
+
+// Plugin registers REST handlers of the maintenance mode.
+type Plugin struct {
+	Deps
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	HTTP        rest.HTTPHandlers              // inject
+	StatusCheck statuscheck.PluginStatusWriter // inject (optional) to fail readiness during maintenance
+	Maintenance *kvdbsync.Maintenance
+}
+
+// Result is returned after the maintenance mode is entered or left.
+type Result struct {
+	kvdbsync.MaintenanceStatus
+	// ReplayedQueued is the number of queued changes being replayed when leaving the mode,
+	// the progress is reported by the state of the maintenance mode.
+	ReplayedQueued int `json:"queued-for-replay"`
+}
+
+// Init registers the plugin to the status check.
+func (p *Plugin) Init() error {
+	if p.Maintenance == nil {
+		p.Log.Info("Maintenance is nil, maintenance mode is not available")
+		return nil
+	}
+	if p.StatusCheck != nil {
+		p.StatusCheck.Register(p.PluginName, nil)
+	}
+	return nil
+}
+
+// AfterInit registers the REST handlers and reports the current state.
+func (p *Plugin) AfterInit() error {
+	if p.Maintenance == nil {
+		return nil
+	}
+	p.reportState()
+
+	if p.HTTP == nil {
+		p.Log.Info("Unable to register maintenance handlers, HTTP is nil")
+		return nil
+	}
+	p.HTTP.RegisterHTTPHandler(StatusPath, p.statusHandler, http.MethodGet)
+	p.HTTP.RegisterHTTPHandler(EnterPath, p.enterHandler, http.MethodPost)
+	p.HTTP.RegisterHTTPHandler(ExitPath, p.exitHandler, http.MethodPost)
+
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// reportState reports init state while the agent is in maintenance mode.
+func (p *Plugin) reportState() {
+	if p.StatusCheck == nil {
+		return
+	}
+	status := p.Maintenance.Status()
+	if status.Enabled {
+		p.StatusCheck.ReportStateChange(p.PluginName, statuscheck.Init,
+			ErrInMaintenance.Errorf("agent is in maintenance mode: %s", status.Reason))
+		return
+	}
+	p.StatusCheck.ReportStateChange(p.PluginName, statuscheck.OK, nil)
+}
+
+// statusHandler returns the state of the maintenance mode.
+func (p *Plugin) statusHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, p.Maintenance.Status())
+	}
+}
+
+// enterHandler enters the maintenance mode.
+func (p *Plugin) enterHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		reason := req.URL.Query().Get(reasonParam)
+		if reason == "" {
+			reason = "requested over REST"
+		}
+		if !p.Maintenance.Enter(reason) {
+			rest.WriteError(formatter, w, ErrInMaintenance.Errorf("agent is already in maintenance mode"))
+			return
+		}
+		p.reportState()
+		formatter.JSON(w, http.StatusOK, Result{MaintenanceStatus: p.Maintenance.Status()})
+	}
+}
+
+// exitHandler starts replay of the queued changes, the maintenance mode is left
+// once they are all replayed.
+func (p *Plugin) exitHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		replaying := p.Maintenance.Exit(p.reportState)
+		if replaying < 0 {
+			rest.WriteError(formatter, w, ErrNotInMaintenance.Errorf("agent is not in maintenance mode "+
+				"or it is being left"))
+			return
+		}
+		formatter.JSON(w, http.StatusAccepted, Result{
+			MaintenanceStatus: p.Maintenance.Status(),
+			ReplayedQueued:    replaying,
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"testing"
+
+	"github.com/ligato/cn-infra/infra"
+	. "github.com/onsi/gomega"
+)
+
+func TestMaintenanceQueuesAndReplaysInOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMaintenance()
+	var delivered []string
+	deliver := func(key string) func() {
+		return func() { delivered = append(delivered, key) }
+	}
+
+	m.admit(deliver("a"))
+	Expect(m.Exit(nil)).To(Equal(-1))
+
+	Expect(m.Enter("upgrade")).To(BeTrue())
+	Expect(m.Enter("upgrade")).To(BeFalse())
+	m.admit(deliver("b"))
+	// change received during the replay is queued behind
+	m.admit(func() {
+		delivered = append(delivered, "c")
+		m.admit(deliver("d"))
+	})
+	Expect(delivered).To(Equal([]string{"a"}))
+
+	status := m.Status()
+	Expect(status.Enabled).To(BeTrue())
+	Expect(status.Reason).To(Equal("upgrade"))
+	Expect(status.QueuedChanges).To(Equal(2))
+
+	done := make(chan struct{})
+	Expect(m.Exit(func() { close(done) })).To(Equal(2))
+	Eventually(done).Should(BeClosed())
+	Expect(delivered).To(Equal([]string{"a", "b", "c", "d"}))
+	Expect(m.Enabled()).To(BeFalse())
+	Expect(m.Status().ReplayedChanges).To(Equal(3))
+
+	m.admit(deliver("e"))
+	Expect(delivered).To(HaveLen(5))
+}
+
+func TestMaintenanceDropsChangesOverLimit(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMaintenance()
+	m.SetConfig(MaintenanceConfig{MaxQueuedChanges: 2})
+	var delivered, resynced int
+	m.registerResync(func() {
+		resynced++
+		// change received during the resync is replayed after it
+		m.admit(func() { delivered++ })
+	})
+
+	Expect(m.Enter("upgrade")).To(BeTrue())
+	for i := 0; i < 4; i++ {
+		m.admit(func() { delivered++ })
+	}
+	status := m.Status()
+	Expect(status.QueuedChanges).To(BeZero())
+	Expect(status.DroppedChanges).To(Equal(4))
+
+	done := make(chan struct{})
+	Expect(m.Exit(func() { close(done) })).To(Equal(4))
+	Eventually(done).Should(BeClosed())
+	Expect(resynced).To(Equal(1))
+	Expect(delivered).To(Equal(1))
+	Expect(m.Status().DroppedChanges).To(BeZero())
+
+	m.SetConfig(MaintenanceConfig{})
+	Expect(m.cfg).To(Equal(DefaultMaintenanceConfig()))
+}
+
+func TestMaintenanceExitDoesNotWaitForSlowWatcher(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMaintenance()
+	changeChan := make(chan string)
+	deliver := func(key string) func() {
+		return func() { changeChan <- key }
+	}
+
+	Expect(m.Enter("upgrade")).To(BeTrue())
+	m.admit(deliver("a"))
+	m.admit(deliver("b"))
+
+	// the watcher is not reading, the replay continues in the background
+	Expect(m.Exit(nil)).To(Equal(2))
+	Expect(m.Exit(nil)).To(Equal(-1))
+	status := m.Status()
+	Expect(status.Enabled).To(BeTrue())
+	Expect(status.Replaying).To(BeTrue())
+	Expect(status.QueuedChanges).To(Equal(2))
+
+	Expect(<-changeChan).To(Equal("a"))
+	Eventually(func() int { return m.Status().ReplayedChanges }).Should(Equal(1))
+	Expect(m.Status().QueuedChanges).To(Equal(1))
+
+	Expect(<-changeChan).To(Equal("b"))
+	Eventually(m.Enabled).Should(BeFalse())
+	Expect(m.Status().Replaying).To(BeFalse())
+}
+
+func TestMaintenanceReplayStopsWithLoop(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMaintenance()
+	var loop infra.EventLoop
+	m.loop = &loop
+	started, release := make(chan struct{}), make(chan struct{})
+	var delivered []string
+
+	Expect(m.Enter("upgrade")).To(BeTrue())
+	m.admit(func() {
+		close(started)
+		<-release
+		delivered = append(delivered, "a")
+	})
+	m.admit(func() { delivered = append(delivered, "b") })
+	Expect(m.Exit(nil)).To(Equal(2))
+	Eventually(started).Should(BeClosed())
+
+	// the remaining change is not delivered once the loop is stopped
+	stopped := make(chan struct{})
+	go func() {
+		loop.Stop()
+		close(stopped)
+	}()
+	Eventually(loop.Context().Done()).Should(BeClosed())
+	close(release)
+	Eventually(stopped).Should(BeClosed())
+	Expect(delivered).To(Equal([]string{"a"}))
+	status := m.Status()
+	Expect(status.Replaying).To(BeFalse())
+	Expect(status.ReplayedChanges).To(Equal(1))
+}
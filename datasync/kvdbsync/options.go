@@ -17,8 +17,11 @@ package kvdbsync
 import (
 	"fmt"
 
+	"github.com/ligato/cn-infra/config"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/servicelabel"
 )
@@ -44,6 +47,9 @@ func NewPlugin(opts ...Option) *Plugin {
 	if p.Deps.Log == nil {
 		p.Deps.Log = logging.ForPlugin(p.String())
 	}
+	if p.Deps.Cfg == nil {
+		p.Deps.Cfg = config.ForPlugin(p.String())
+	}
 
 	return p
 }
@@ -64,3 +70,52 @@ func UseKV(kv keyval.KvProtoPlugin) Option {
 		p.KvPlugin = kv
 	}
 }
+
+// UseStatusCheck returns Option that enables detection of the KV store connectivity
+// loss and the "frozen NB" mode, see Deps.StatusCheck.
+func UseStatusCheck(sc statuscheck.PluginStatusWriter) Option {
+	return func(p *Plugin) {
+		p.StatusCheck = sc
+	}
+}
+
+// UseCache returns Option that sets local cache (e.g. Bolt plugin) mirroring the data
+// of the KvPlugin, which is used for resync if the KvPlugin is not connected at startup.
+func UseCache(cache keyval.KvProtoPlugin) Option {
+	return func(p *Plugin) {
+		p.Cache = cache
+	}
+}
+
+// UseChurnGuard returns Option that protects watched prefixes against abnormal churn,
+// see ChurnGuard.
+func UseChurnGuard(guard *ChurnGuard) Option {
+	return func(p *Plugin) {
+		p.ChurnGuard = guard
+	}
+}
+
+// UseQuota returns Option that limits the configuration under watched prefixes,
+// see Quota.
+func UseQuota(quota *Quota) Option {
+	return func(p *Plugin) {
+		p.Quota = quota
+	}
+}
+
+// UseMaintenance returns Option that allows to put the agent into maintenance mode,
+// see Maintenance.
+func UseMaintenance(maintenance *Maintenance) Option {
+	return func(p *Plugin) {
+		p.Maintenance = maintenance
+	}
+}
+
+// UsePrefixRegistry returns Option that enables validation of watched key prefixes.
+// Watch fails if the prefixes overlap with prefixes watched under a different
+// resync name (e.g. keyprefix.DefaultRegistry shared by all components).
+func UsePrefixRegistry(registry *keyprefix.Registry) Option {
+	return func(p *Plugin) {
+		p.PrefixRegistry = registry
+	}
+}
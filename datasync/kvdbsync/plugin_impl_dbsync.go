@@ -16,12 +16,16 @@ package kvdbsync
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/config"
 	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/datasync/syncbase"
 	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/servicelabel"
@@ -30,6 +34,10 @@ import (
 var (
 	// ErrNotReady is an error returned when KVDBSync plugin is being used before the KVPlugin is ready.
 	ErrNotReady = errors.New("transport adapter is not ready yet (probably called before AfterInit)")
+
+	// ErrBuffered is returned by Put and Delete while the KV store is unreachable ("frozen NB" mode).
+	// The write was not done yet, it is buffered and done once the KV store recovers.
+	ErrBuffered = errors.New("KV store is unreachable, the write is buffered until it recovers")
 )
 
 // Plugin dbsync implements synchronization between local memory and db.
@@ -40,6 +48,18 @@ type Plugin struct {
 
 	adapter  *watcher
 	registry *syncbase.Registry
+
+	// keys registered for resync using the local cache before the KV plugin connected
+	mu         sync.Mutex
+	connected  bool
+	cachedKeys map[string]*watchBrokerKeys
+
+	// writes buffered while the KV store is unreachable
+	nb frozenNB
+
+	// runs flushing of the buffered writes, delivery of changes confirmed by the churn guard
+	// and replay of changes queued in maintenance mode
+	loop infra.EventLoop
 }
 
 // Deps groups dependencies injected into the plugin so that they are
@@ -47,14 +67,68 @@ type Plugin struct {
 type Deps struct {
 	infra.PluginName
 	Log          logging.PluginLogger
+	Cfg          config.PluginConfig
 	KvPlugin     keyval.KvProtoPlugin // inject
 	ResyncOrch   resync.Subscriber
 	ServiceLabel servicelabel.ReaderAPI
+	// StatusCheck (optional) is used to detect loss of the KV store connectivity, in which case
+	// the plugin reports degraded state and buffers writes until the connectivity returns.
+	// See UseStatusCheck.
+	StatusCheck statuscheck.PluginStatusWriter
+	// Cache is an optional local KV store (e.g. Bolt) which mirrors the data of the KvPlugin.
+	// If the KvPlugin is not connected at startup, the cached data are used for resync
+	// until the connection is established.
+	Cache keyval.KvProtoPlugin
+	// ChurnGuard (optional) holds changes of watched prefixes in case of abnormal churn.
+	// Its thresholds can be set in the "churn-guard" section of the plugin config.
+	ChurnGuard *ChurnGuard
+	// Quota (optional) limits the number of keys and size of values under watched prefixes.
+	Quota *Quota
+	// Maintenance (optional) queues changes of watched prefixes while the agent is in maintenance mode.
+	// Its limits can be set in the "maintenance" section of the plugin config.
+	Maintenance *Maintenance
+	// PrefixRegistry (optional) is used to validate that key prefixes watched
+	// by different watchers do not overlap, nil disables the validation.
+	// See UsePrefixRegistry.
+	PrefixRegistry *keyprefix.Registry
+}
+
+// Config holds the configuration of the plugin.
+type Config struct {
+	// ChurnGuard sets thresholds of the injected churn guard.
+	ChurnGuard *ChurnGuardConfig `json:"churn-guard"`
+	// Maintenance sets limits of the injected maintenance mode.
+	Maintenance *MaintenanceConfig `json:"maintenance"`
 }
 
-// Init only initializes plugin.registry.
+// Init loads the configuration and initializes plugin.registry.
 func (p *Plugin) Init() error {
+	p.loop.Log = p.Log
+	if p.Cfg != nil && (p.ChurnGuard != nil || p.Maintenance != nil) {
+		var cfg Config
+		if _, err := p.Cfg.LoadValue(&cfg); err != nil {
+			return err
+		}
+		if cfg.ChurnGuard != nil && p.ChurnGuard != nil {
+			p.ChurnGuard.SetConfig(*cfg.ChurnGuard)
+		}
+		if cfg.Maintenance != nil && p.Maintenance != nil {
+			p.Maintenance.SetConfig(*cfg.Maintenance)
+		}
+	}
+
 	p.registry = syncbase.NewRegistry()
+	if p.Quota != nil {
+		p.Quota.nb = p
+		p.Quota.plugin = p.PluginName
+		p.Quota.log = p.Log
+	}
+	if p.ChurnGuard != nil {
+		p.ChurnGuard.loop = &p.loop
+	}
+	if p.Maintenance != nil {
+		p.Maintenance.loop = &p.loop
+	}
 
 	return nil
 }
@@ -78,9 +152,44 @@ func (p *Plugin) AfterInit() error {
 	// set function to be executed on KVPlugin connection
 	p.KvPlugin.OnConnect(p.initKvPlugin)
 
+	if p.StatusCheck != nil {
+		p.StatusCheck.Register(p.PluginName, p.probeKV)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.connected && p.isCacheEnabled() && p.ResyncOrch != nil {
+		p.Log.Infof("%s not connected, using local cache %s for resync", p.KvPlugin, p.Cache)
+		p.registerCachedResync()
+	}
+
 	return nil
 }
 
+func (p *Plugin) isCacheEnabled() bool {
+	return p.Cache != nil && !p.Cache.Disabled()
+}
+
+// registerCachedResync registers subscriptions for resync from the local cache
+func (p *Plugin) registerCachedResync() {
+	cache := &watcher{
+		db:          p.Cache.NewBroker(p.ServiceLabel.GetAgentPrefix()),
+		base:        p.registry,
+		quota:       p.Quota,
+		maintenance: p.Maintenance,
+	}
+	p.cachedKeys = make(map[string]*watchBrokerKeys)
+	for name, sub := range p.registry.Subscriptions() {
+		reg := p.ResyncOrch.Register(name)
+		keys, err := watchAndResyncBrokerKeys(reg, sub.ChangeChan, sub.ResyncChan, sub.CloseChan,
+			cache, sub.KeyPrefixes...)
+		if err != nil {
+			p.Log.Warnf("reading local cache for %s failed: %v", name, err)
+		}
+		p.cachedKeys[name] = keys
+	}
+}
+
 func (p *Plugin) isKvEnabled() bool {
 	return p.KvPlugin != nil && !p.KvPlugin.Disabled()
 }
@@ -91,13 +200,33 @@ func (p *Plugin) initKvPlugin() error {
 		return nil
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.connected = true
+
 	p.adapter = &watcher{
-		db:   p.KvPlugin.NewBroker(p.ServiceLabel.GetAgentPrefix()),
-		dbW:  p.KvPlugin.NewWatcher(p.ServiceLabel.GetAgentPrefix()),
-		base: p.registry,
+		db:          p.KvPlugin.NewBroker(p.ServiceLabel.GetAgentPrefix()),
+		dbW:         p.KvPlugin.NewWatcher(p.ServiceLabel.GetAgentPrefix()),
+		base:        p.registry,
+		guard:       p.ChurnGuard,
+		quota:       p.Quota,
+		maintenance: p.Maintenance,
+	}
+	if p.isCacheEnabled() {
+		p.adapter.mirror = newCacheMirror(p.KvPlugin, p.Cache, p.ServiceLabel.GetAgentPrefix())
+		if p.adapter.mirror == nil {
+			p.Log.Warnf("%s or %s does not provide raw data access, local cache is not updated", p.KvPlugin, p.Cache)
+		}
 	}
 
-	if p.ResyncOrch != nil {
+	if p.cachedKeys != nil {
+		// subscriptions are already registered for resync, switch them from the cache to the KV store
+		for name, sub := range p.registry.Subscriptions() {
+			if err := p.cachedKeys[name].switchAdapter(p.adapter, sub.CloseChan); err != nil {
+				return err
+			}
+		}
+	} else if p.ResyncOrch != nil {
 		for name, sub := range p.registry.Subscriptions() {
 			reg := p.ResyncOrch.Register(name)
 			_, err := watchAndResyncBrokerKeys(reg, sub.ChangeChan, sub.ResyncChan, sub.CloseChan,
@@ -119,13 +248,34 @@ func (p *Plugin) initKvPlugin() error {
 // This method is supposed to be called in Plugin.Init().
 // Calling this method later than kvdbsync.Plugin.AfterInit() will have no effect
 // (no notifications will be received).
+//
+// The key prefixes are declared in the PrefixRegistry (if set); an error is returned
+// if they overlap with prefixes watched under different resync name.
 func (p *Plugin) Watch(resyncName string, changeChan chan datasync.ChangeEvent,
 	resyncChan chan datasync.ResyncEvent, keyPrefixes ...string) (datasync.WatchRegistration, error) {
 
-	return p.registry.Watch(resyncName, changeChan, resyncChan, keyPrefixes...)
+	if p.PrefixRegistry == nil {
+		return p.registry.Watch(resyncName, changeChan, resyncChan, keyPrefixes...)
+	}
+
+	if _, found := p.registry.Subscriptions()[resyncName]; found {
+		// do not touch declarations of the existing subscription
+		return p.registry.Watch(resyncName, changeChan, resyncChan, keyPrefixes...)
+	}
+	if err := declarePrefixes(p.PrefixRegistry, resyncName, keyPrefixes...); err != nil {
+		return nil, err
+	}
+	reg, err := p.registry.Watch(resyncName, changeChan, resyncChan, keyPrefixes...)
+	if err != nil {
+		withdrawPrefixes(p.PrefixRegistry, resyncName, keyPrefixes...)
+		return nil, err
+	}
+	return newDeclaredWatchReg(reg, p.PrefixRegistry, resyncName, keyPrefixes...), nil
 }
 
 // Put propagates this call to a particular kvdb.Plugin unless the kvdb.Plugin is Disabled().
+// While the KV store is unreachable, the value is buffered, written once it recovers
+// and ErrBuffered is returned.
 //
 // This method is supposed to be called in Plugin.AfterInit() or later (even from different go routine).
 func (p *Plugin) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
@@ -133,6 +283,9 @@ func (p *Plugin) Put(key string, data proto.Message, opts ...datasync.PutOption)
 		return nil
 	}
 
+	if p.nb.buffer(key, &pendingWrite{value: proto.Clone(data), putOpts: opts}) {
+		return ErrBuffered
+	}
 	if p.adapter != nil {
 		return p.adapter.db.Put(key, data, opts...)
 	}
@@ -141,6 +294,8 @@ func (p *Plugin) Put(key string, data proto.Message, opts ...datasync.PutOption)
 }
 
 // Delete propagates this call to a particular kvdb.Plugin unless the kvdb.Plugin is Disabled().
+// While the KV store is unreachable, the delete is buffered, done once it recovers
+// and ErrBuffered is returned.
 //
 // This method is supposed to be called in Plugin.AfterInit() or later (even from different go routine).
 func (p *Plugin) Delete(key string, opts ...datasync.DelOption) (existed bool, err error) {
@@ -148,6 +303,9 @@ func (p *Plugin) Delete(key string, opts ...datasync.DelOption) (existed bool, e
 		return false, nil
 	}
 
+	if p.nb.buffer(key, &pendingWrite{delOpts: opts}) {
+		return false, ErrBuffered
+	}
 	if p.adapter != nil {
 		return p.adapter.db.Delete(key, opts...)
 	}
@@ -155,7 +313,10 @@ func (p *Plugin) Delete(key string, opts ...datasync.DelOption) (existed bool, e
 	return false, ErrNotReady
 }
 
-// Close resources.
+// Close resources. It waits until flushing of the writes buffered
+// in the frozen NB mode is finished and stops the delivery of changes
+// confirmed by the churn guard and the replay of changes queued in maintenance mode.
 func (p *Plugin) Close() error {
+	p.loop.Stop()
 	return nil
 }
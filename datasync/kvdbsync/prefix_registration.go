@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"sync"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
+)
+
+// declaredWatchReg withdraws key prefix declarations of the watcher
+// when they are unregistered from the underlying watch registration.
+// Only the declarations made through this registration are withdrawn.
+type declaredWatchReg struct {
+	datasync.WatchRegistration
+	prefixes *keyprefix.Registry
+
+	mu       sync.Mutex
+	declared []keyprefix.Declaration
+}
+
+// newDeclaredWatchReg wraps the watch registration of <keyPrefixes> already declared by <resyncName>.
+func newDeclaredWatchReg(reg datasync.WatchRegistration, prefixes *keyprefix.Registry,
+	resyncName string, keyPrefixes ...string) *declaredWatchReg {
+	declared := make([]keyprefix.Declaration, 0, len(keyPrefixes))
+	for _, keyPrefix := range keyPrefixes {
+		declared = append(declared, watcherDeclaration(resyncName, keyPrefix))
+	}
+	return &declaredWatchReg{
+		WatchRegistration: reg,
+		prefixes:          prefixes,
+		declared:          declared,
+	}
+}
+
+// watcherDeclaration returns declaration of <keyPrefix> watched by <resyncName>.
+func watcherDeclaration(resyncName, keyPrefix string) keyprefix.Declaration {
+	return keyprefix.Declaration{
+		Owner:  resyncName,
+		Kind:   keyprefix.Watcher,
+		Prefix: keyPrefix,
+	}
+}
+
+// withdrawPrefixes withdraws declarations of <keyPrefixes> made by <resyncName>.
+func withdrawPrefixes(prefixes *keyprefix.Registry, resyncName string, keyPrefixes ...string) {
+	for _, keyPrefix := range keyPrefixes {
+		prefixes.Withdraw(resyncName, keyprefix.Watcher, keyPrefix)
+	}
+}
+
+// declarePrefixes declares <keyPrefixes> watched by <resyncName> in the key prefix registry.
+// If any of them collides with the prefix of another watcher, the already declared
+// prefixes are withdrawn and the collision error is returned.
+func declarePrefixes(prefixes *keyprefix.Registry, resyncName string, keyPrefixes ...string) error {
+	for i, keyPrefix := range keyPrefixes {
+		if err := prefixes.Declare(watcherDeclaration(resyncName, keyPrefix)); err != nil {
+			withdrawPrefixes(prefixes, resyncName, keyPrefixes[:i]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// Register declares the prefix before it is registered.
+func (reg *declaredWatchReg) Register(resyncName string, keyPrefix string) error {
+	if err := declarePrefixes(reg.prefixes, resyncName, keyPrefix); err != nil {
+		return err
+	}
+	if err := reg.WatchRegistration.Register(resyncName, keyPrefix); err != nil {
+		reg.prefixes.Withdraw(resyncName, keyprefix.Watcher, keyPrefix)
+		return err
+	}
+	reg.mu.Lock()
+	reg.declared = append(reg.declared, watcherDeclaration(resyncName, keyPrefix))
+	reg.mu.Unlock()
+	return nil
+}
+
+// Unregister withdraws the prefix declaration made by the watcher which registered it.
+func (reg *declaredWatchReg) Unregister(keyPrefix string) error {
+	if err := reg.WatchRegistration.Unregister(keyPrefix); err != nil {
+		return err
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for i, declared := range reg.declared {
+		if declared.Prefix == keyPrefix {
+			reg.declared = append(reg.declared[:i], reg.declared[i+1:]...)
+			reg.prefixes.Withdraw(declared.Owner, declared.Kind, declared.Prefix)
+			break
+		}
+	}
+	return nil
+}
+
+// Close withdraws prefix declarations made through this registration.
+func (reg *declaredWatchReg) Close() error {
+	reg.mu.Lock()
+	for _, declared := range reg.declared {
+		reg.prefixes.Withdraw(declared.Owner, declared.Kind, declared.Prefix)
+	}
+	reg.declared = nil
+	reg.mu.Unlock()
+	return reg.WatchRegistration.Close()
+}
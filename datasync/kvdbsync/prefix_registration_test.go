@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
+	. "github.com/onsi/gomega"
+)
+
+func TestCloseWithdrawsOnlyOwnDeclarations(t *testing.T) {
+	RegisterTestingT(t)
+
+	prefixes := keyprefix.NewRegistry()
+	watch := func(p *Plugin, keyPrefix string) datasync.WatchRegistration {
+		Expect(p.Init()).To(Succeed())
+		reg, err := p.Watch("ifplugin", make(chan datasync.ChangeEvent), make(chan datasync.ResyncEvent), keyPrefix)
+		Expect(err).ToNot(HaveOccurred())
+		return reg
+	}
+	// the same resync name watches the same prefix in two KV stores
+	etcd := watch(NewPlugin(UsePrefixRegistry(prefixes)), "config/if/")
+	consul := watch(NewPlugin(UsePrefixRegistry(prefixes)), "config/if/")
+
+	Expect(etcd.Close()).To(Succeed())
+	Expect(prefixes.Lookup("config/if/loop0")).To(HaveLen(1))
+	Expect(consul.Close()).To(Succeed())
+	Expect(prefixes.Declarations()).To(BeEmpty())
+
+	// without the registry, overlapping prefixes of different watchers are allowed
+	p := NewPlugin()
+	Expect(p.PrefixRegistry).To(BeNil())
+	watch(p, "config/")
+	Expect(prefixes.Declarations()).To(BeEmpty())
+}
+
+// acceptingWatchReg accepts registration of any prefix under any name.
+type acceptingWatchReg struct{}
+
+func (acceptingWatchReg) Register(resyncName, keyPrefix string) error { return nil }
+func (acceptingWatchReg) Unregister(keyPrefix string) error           { return nil }
+func (acceptingWatchReg) Close() error                                { return nil }
+
+func TestUnregisterWithdrawsDeclarationOfRegisteringWatcher(t *testing.T) {
+	RegisterTestingT(t)
+
+	prefixes := keyprefix.NewRegistry()
+	Expect(declarePrefixes(prefixes, "ifplugin", "config/if/")).To(Succeed())
+	reg := newDeclaredWatchReg(acceptingWatchReg{}, prefixes, "ifplugin", "config/if/")
+
+	// the prefix is registered under a different name than the watcher
+	Expect(reg.Register("l3plugin", "config/route/")).To(Succeed())
+	declarations := prefixes.Lookup("config/route/0")
+	Expect(declarations).To(HaveLen(1))
+	Expect(declarations[0].Owner).To(Equal("l3plugin"))
+
+	Expect(reg.Unregister("config/route/")).To(Succeed())
+	Expect(prefixes.Lookup("config/route/0")).To(BeEmpty())
+	Expect(prefixes.Lookup("config/if/loop0")).To(HaveLen(1))
+
+	Expect(reg.Register("l3plugin", "config/route/")).To(Succeed())
+	Expect(reg.Close()).To(Succeed())
+	Expect(prefixes.Declarations()).To(BeEmpty())
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+)
+
+// QuotaErrorPrefix is the relative key prefix under which errors of values
+// rejected by the Quota are published (followed by the key of the rejected value).
+const QuotaErrorPrefix = "check/quota/v1/"
+
+// QuotaErrorKey returns the key of the error published for the rejected value.
+func QuotaErrorKey(key string) string {
+	return QuotaErrorPrefix + key
+}
+
+// ErrQuotaExceeded is the class of errors published for values rejected by the Quota.
+// The errors are published with the name of the kvdbsync plugin using the Quota
+// (e.g. kvdb-etcd-datasync).
+var ErrQuotaExceeded = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:     "kvdbsync/quota-exceeded",
+	Plugin:   "kvdb",
+	Severity: infra.SeverityError,
+})
+
+// QuotaConfig defines limits of the configuration under watched key prefixes.
+type QuotaConfig struct {
+	Prefixes []PrefixQuota `json:"prefixes"`
+}
+
+// PrefixQuota limits the configuration under a single key prefix.
+// Zero value of a limit means the limit is not applied.
+type PrefixQuota struct {
+	Prefix string `json:"prefix"`
+	// MaxKeys is the maximum number of keys under the prefix.
+	MaxKeys int `json:"max-keys"`
+	// MaxValueSize is the maximum size of a serialized value in bytes.
+	// Values of unknown size (the watcher does not provide the size of
+	// the serialized data) are rejected if the limit is set.
+	MaxValueSize int `json:"max-value-size"`
+}
+
+// QuotaUsage describes the usage of a prefix protected by the Quota.
+type QuotaUsage struct {
+	PrefixQuota
+	Keys     int      `json:"keys"`
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+// Quota protects the agent from accidental bulk writes of the configuration
+// (e.g. a controller bug) which would exhaust the memory. Values exceeding
+// the limits of their key prefix are not delivered to the watchers and the
+// error is published back to NB under QuotaErrorKey. Rejected update of
+// an already applied value keeps the previous value applied. The error is
+// withdrawn once the value is accepted or removed.
+//
+// The usage is tracked separately for every watch registration, since each
+// of them receives (and applies) the changes on its own.
+type Quota struct {
+	// nb, plugin and log are set by the kvdbsync plugin using the quota
+	nb     nbWriter
+	plugin infra.PluginName
+	log    logging.Logger
+	quotas map[string]PrefixQuota
+
+	mu       sync.Mutex
+	trackers []*quotaTracker
+	// rejected counts trackers rejecting the key, the error is published
+	// by the first of them and withdrawn once none rejects it
+	rejected map[string]int
+}
+
+// nbWriter publishes the errors back to NB.
+type nbWriter interface {
+	Put(key string, data proto.Message, opts ...datasync.PutOption) error
+	Delete(key string, opts ...datasync.DelOption) (existed bool, err error)
+}
+
+// valueSizer is implemented by the values providing size of the serialized data.
+type valueSizer interface {
+	GetValueSize() int
+}
+
+// quotaTracker tracks usage of the prefixes with a quota by a single watch registration.
+type quotaTracker struct {
+	quota *Quota
+	usage map[string]*prefixUsage
+}
+
+type prefixUsage struct {
+	quota    PrefixQuota
+	keys     map[string]struct{}
+	rejected map[string]error
+}
+
+// NewQuota creates a new Quota.
+func NewQuota(cfg QuotaConfig) *Quota {
+	q := &Quota{
+		log:      logrus.DefaultLogger(),
+		quotas:   make(map[string]PrefixQuota),
+		rejected: make(map[string]int),
+	}
+	for _, pq := range cfg.Prefixes {
+		q.quotas[pq.Prefix] = pq
+	}
+	return q
+}
+
+// newTracker creates tracker of the usage for a new watch registration.
+func (q *Quota) newTracker() *quotaTracker {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	t := &quotaTracker{quota: q, usage: make(map[string]*prefixUsage)}
+	for prefix, pq := range q.quotas {
+		t.usage[prefix] = &prefixUsage{
+			quota:    pq,
+			keys:     make(map[string]struct{}),
+			rejected: make(map[string]error),
+		}
+	}
+	q.trackers = append(q.trackers, t)
+	return t
+}
+
+func (q *Quota) prefixes() []string {
+	prefixes := make([]string, 0, len(q.quotas))
+	for prefix := range q.quotas {
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// usageOf returns usage of the quota prefix matching the key, nil if no limits apply.
+func (t *quotaTracker) usageOf(key string) *prefixUsage {
+	return t.usage[keyprefix.MatchLongest(key, t.quota.prefixes())]
+}
+
+// valueSize returns the size of the serialized value, -1 if unknown.
+func valueSize(val interface{}) int {
+	if sizer, ok := val.(valueSizer); ok {
+		return sizer.GetValueSize()
+	}
+	return -1
+}
+
+// reject records the key rejected by the tracker, it returns true if the error
+// should be published (no other tracker rejects the key).
+// The caller holds the lock.
+func (q *Quota) reject(u *prefixUsage, key string, err error) bool {
+	if _, ok := u.rejected[key]; !ok {
+		q.rejected[key]++
+	}
+	u.rejected[key] = err
+	return q.rejected[key] == 1
+}
+
+// accept forgets the key rejected by the tracker, it returns true if the error
+// should be withdrawn (the key is not rejected by any tracker anymore).
+// The caller holds the lock.
+func (q *Quota) accept(u *prefixUsage, key string) bool {
+	if _, ok := u.rejected[key]; !ok {
+		return false
+	}
+	delete(u.rejected, key)
+	q.rejected[key]--
+	if q.rejected[key] > 0 {
+		return false
+	}
+	delete(q.rejected, key)
+	return true
+}
+
+// admit returns true if the change of the key should be delivered to the watchers
+// of the registration.
+func (t *quotaTracker) admit(key string, op datasync.Op, size int) bool {
+	q := t.quota
+	q.mu.Lock()
+	u := t.usageOf(key)
+	if u == nil {
+		q.mu.Unlock()
+		return true
+	}
+	_, applied := u.keys[key]
+
+	if op == datasync.Delete {
+		delete(u.keys, key)
+		withdraw := q.accept(u, key)
+		q.mu.Unlock()
+		if withdraw {
+			q.withdraw(key)
+		}
+		return applied
+	}
+
+	var err error
+	if u.quota.MaxValueSize > 0 && size < 0 {
+		// the watcher does not provide size of the serialized values
+		err = ErrQuotaExceeded.Errorf("size of the value of %q is unknown, quota of %q limiting it to %d bytes cannot be enforced",
+			key, u.quota.Prefix, u.quota.MaxValueSize)
+	} else if u.quota.MaxValueSize > 0 && size > u.quota.MaxValueSize {
+		err = ErrQuotaExceeded.Errorf("value of %q has %d bytes, quota of %q allows %d bytes",
+			key, size, u.quota.Prefix, u.quota.MaxValueSize)
+	} else if !applied && u.quota.MaxKeys > 0 && len(u.keys) >= u.quota.MaxKeys {
+		err = ErrQuotaExceeded.Errorf("%q exceeds the quota of %d keys under %q",
+			key, u.quota.MaxKeys, u.quota.Prefix)
+	}
+	if err != nil {
+		publish := q.reject(u, key, err)
+		q.mu.Unlock()
+		if publish {
+			q.log.Warn(err)
+			q.publish(key, err)
+		}
+		return false
+	}
+
+	u.keys[key] = struct{}{}
+	withdraw := q.accept(u, key)
+	q.mu.Unlock()
+	if withdraw {
+		q.withdraw(key)
+	}
+	return true
+}
+
+// startResync forgets the keys under the watched prefix, the resync
+// replaces them with the current data.
+func (t *quotaTracker) startResync(keyPrefix string) {
+	t.quota.mu.Lock()
+	defer t.quota.mu.Unlock()
+	for _, u := range t.usage {
+		for key := range u.keys {
+			if strings.HasPrefix(key, keyPrefix) {
+				delete(u.keys, key)
+			}
+		}
+	}
+}
+
+// endResync withdraws errors of rejected keys under the watched prefix
+// which were not present in the resync data.
+func (t *quotaTracker) endResync(keyPrefix string, seen map[string]struct{}) {
+	q := t.quota
+	var stale []string
+	q.mu.Lock()
+	for _, u := range t.usage {
+		for key := range u.rejected {
+			if _, ok := seen[key]; !ok && strings.HasPrefix(key, keyPrefix) {
+				if q.accept(u, key) {
+					stale = append(stale, key)
+				}
+			}
+		}
+	}
+	q.mu.Unlock()
+	for _, key := range stale {
+		q.withdraw(key)
+	}
+}
+
+func (q *Quota) publish(key string, err error) {
+	if q.nb == nil {
+		return
+	}
+	details := &status.ErrorDetails{
+		Code:      ErrQuotaExceeded.Code,
+		Message:   err.Error(),
+		Plugin:    q.plugin.String(),
+		Retriable: ErrQuotaExceeded.Retriable,
+		Severity:  string(ErrQuotaExceeded.Severity),
+		Docs:      ErrQuotaExceeded.Docs,
+		Time:      time.Now().Unix(),
+	}
+	if err := q.nb.Put(QuotaErrorKey(key), details); err != nil {
+		q.log.Warnf("publishing quota error of %q failed: %v", key, err)
+	}
+}
+
+func (q *Quota) withdraw(key string) {
+	if q.nb == nil {
+		return
+	}
+	if _, err := q.nb.Delete(QuotaErrorKey(key)); err != nil {
+		q.log.Warnf("withdrawing quota error of %q failed: %v", key, err)
+	}
+}
+
+// Status returns the usage of all prefixes with a quota sorted by the prefix.
+// Keys is the highest number of keys applied under the prefix by a watch
+// registration, Rejected are keys rejected by any of them.
+func (q *Quota) Status() []QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := make([]QuotaUsage, 0, len(q.quotas))
+	for prefix, pq := range q.quotas {
+		usage := QuotaUsage{PrefixQuota: pq}
+		rejected := make(map[string]struct{})
+		for _, t := range q.trackers {
+			u := t.usage[prefix]
+			if len(u.keys) > usage.Keys {
+				usage.Keys = len(u.keys)
+			}
+			for key := range u.rejected {
+				rejected[key] = struct{}{}
+			}
+		}
+		for key := range rejected {
+			usage.Rejected = append(usage.Rejected, key)
+		}
+		sort.Strings(usage.Rejected)
+		status = append(status, usage)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Prefix < status[j].Prefix
+	})
+	return status
+}
+
+// quotaIterator skips values rejected by the quota during resync.
+type quotaIterator struct {
+	delegate  datasync.KeyValIterator
+	quota     *quotaTracker
+	keyPrefix string
+	seen      map[string]struct{}
+}
+
+func newQuotaIterator(delegate datasync.KeyValIterator, quota *quotaTracker, keyPrefix string) *quotaIterator {
+	quota.startResync(keyPrefix)
+	return &quotaIterator{
+		delegate:  delegate,
+		quota:     quota,
+		keyPrefix: keyPrefix,
+		seen:      make(map[string]struct{}),
+	}
+}
+
+// GetNext returns the next value admitted by the quota.
+func (it *quotaIterator) GetNext() (kv datasync.KeyVal, stop bool) {
+	for {
+		kv, stop = it.delegate.GetNext()
+		if stop {
+			it.quota.endResync(it.keyPrefix, it.seen)
+			return nil, stop
+		}
+		it.seen[kv.GetKey()] = struct{}{}
+		if it.quota.admit(kv.GetKey(), datasync.Put, valueSize(kv)) {
+			return kv, false
+		}
+	}
+}
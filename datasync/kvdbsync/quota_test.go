@@ -0,0 +1,216 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvdbsync
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+type nbMock struct {
+	errors map[string]*status.ErrorDetails
+}
+
+func (nb *nbMock) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	nb.errors[key] = data.(*status.ErrorDetails)
+	return nil
+}
+
+func (nb *nbMock) Delete(key string, opts ...datasync.DelOption) (existed bool, err error) {
+	_, existed = nb.errors[key]
+	delete(nb.errors, key)
+	return existed, nil
+}
+
+type sizedKeyVal struct {
+	datasync.KeyVal
+	key  string
+	size int
+}
+
+func (kv *sizedKeyVal) GetKey() string    { return kv.key }
+func (kv *sizedKeyVal) GetValueSize() int { return kv.size }
+
+type sliceIterator []datasync.KeyVal
+
+func (it *sliceIterator) GetNext() (kv datasync.KeyVal, stop bool) {
+	if len(*it) == 0 {
+		return nil, true
+	}
+	kv, *it = (*it)[0], (*it)[1:]
+	return kv, false
+}
+
+func TestQuotaChanges(t *testing.T) {
+	RegisterTestingT(t)
+
+	nb := &nbMock{errors: map[string]*status.ErrorDetails{}}
+	quota := NewQuota(QuotaConfig{Prefixes: []PrefixQuota{{Prefix: "/vnf/", MaxKeys: 2, MaxValueSize: 10}}})
+	quota.nb = nb
+	quota.plugin = "kvdb-etcd-datasync"
+	tracker := quota.newTracker()
+
+	Expect(tracker.admit("/vnf/a", datasync.Put, 5)).To(BeTrue())
+	Expect(tracker.admit("/vnf/b", datasync.Put, 5)).To(BeTrue())
+	Expect(tracker.admit("/other/x", datasync.Put, 100)).To(BeTrue())
+
+	// too many keys
+	Expect(tracker.admit("/vnf/c", datasync.Put, 5)).To(BeFalse())
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/c")))
+	Expect(nb.errors[QuotaErrorKey("/vnf/c")].Code).To(Equal(ErrQuotaExceeded.Code))
+	Expect(nb.errors[QuotaErrorKey("/vnf/c")].Plugin).To(Equal("kvdb-etcd-datasync"))
+
+	// too large value of an applied key keeps the previous value
+	Expect(tracker.admit("/vnf/a", datasync.Put, 50)).To(BeFalse())
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/a")))
+	Expect(quota.Status()).To(Equal([]QuotaUsage{{
+		PrefixQuota: PrefixQuota{Prefix: "/vnf/", MaxKeys: 2, MaxValueSize: 10},
+		Keys:        2,
+		Rejected:    []string{"/vnf/a", "/vnf/c"},
+	}}))
+
+	// delete of a rejected key is not delivered, the error is withdrawn
+	Expect(tracker.admit("/vnf/c", datasync.Delete, -1)).To(BeFalse())
+	Expect(nb.errors).ToNot(HaveKey(QuotaErrorKey("/vnf/c")))
+
+	// accepted update withdraws the error
+	Expect(tracker.admit("/vnf/a", datasync.Put, 5)).To(BeTrue())
+	Expect(nb.errors).To(BeEmpty())
+
+	// freed slot can be used
+	Expect(tracker.admit("/vnf/b", datasync.Delete, -1)).To(BeTrue())
+	Expect(tracker.admit("/vnf/c", datasync.Put, 5)).To(BeTrue())
+}
+
+func TestQuotaResync(t *testing.T) {
+	RegisterTestingT(t)
+
+	nb := &nbMock{errors: map[string]*status.ErrorDetails{}}
+	quota := NewQuota(QuotaConfig{Prefixes: []PrefixQuota{{Prefix: "/vnf/", MaxKeys: 2}}})
+	quota.nb = nb
+	tracker := quota.newTracker()
+
+	Expect(tracker.admit("/vnf/old", datasync.Put, 5)).To(BeTrue())
+	Expect(tracker.admit("/vnf/x", datasync.Put, 5)).To(BeTrue())
+	Expect(tracker.admit("/vnf/gone", datasync.Put, 5)).To(BeFalse())
+
+	it := newQuotaIterator(&sliceIterator{
+		&sizedKeyVal{key: "/vnf/a", size: 1},
+		&sizedKeyVal{key: "/vnf/b", size: 1},
+		&sizedKeyVal{key: "/vnf/c", size: 1},
+	}, tracker, "/vnf/")
+	var keys []string
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		keys = append(keys, kv.GetKey())
+	}
+	Expect(keys).To(Equal([]string{"/vnf/a", "/vnf/b"}))
+	Expect(nb.errors).To(HaveLen(1))
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/c")))
+	Expect(quota.Status()[0].Keys).To(Equal(2))
+}
+
+func TestQuotaUnknownValueSize(t *testing.T) {
+	RegisterTestingT(t)
+
+	nb := &nbMock{errors: map[string]*status.ErrorDetails{}}
+	quota := NewQuota(QuotaConfig{Prefixes: []PrefixQuota{
+		{Prefix: "/vnf/", MaxValueSize: 10},
+		{Prefix: "/route/", MaxKeys: 2},
+	}})
+	quota.nb = nb
+	tracker := quota.newTracker()
+
+	// value size limit cannot be enforced
+	Expect(tracker.admit("/vnf/a", datasync.Put, -1)).To(BeFalse())
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/a")))
+
+	// key limit does not depend on the value size
+	Expect(tracker.admit("/route/a", datasync.Put, -1)).To(BeTrue())
+	Expect(nb.errors).To(HaveLen(1))
+}
+
+func TestQuotaTwoWatchersOfLimitedPrefix(t *testing.T) {
+	RegisterTestingT(t)
+
+	nb := &nbMock{errors: map[string]*status.ErrorDetails{}}
+	quota := NewQuota(QuotaConfig{Prefixes: []PrefixQuota{{Prefix: "/vnf/", MaxKeys: 1}}})
+	quota.nb = nb
+	adapter := &watcher{base: syncbase.NewRegistry(), quota: quota}
+	newKeys := func() *watchBrokerKeys {
+		return &watchBrokerKeys{
+			changeChan: make(chan datasync.ChangeEvent, 10),
+			prefixes:   []string{"/vnf/"},
+			adapter:    adapter,
+		}
+	}
+	keys1, keys2 := newKeys(), newKeys()
+	notify := func(key string, op datasync.Op) {
+		change := watchResp{syncbase.NewChange(key, nil, 1, op)}
+		keys1.watchChanges(change)
+		keys2.watchChanges(change)
+	}
+	receive := func(keys *watchBrokerKeys) (string, datasync.Op) {
+		var change datasync.ChangeEvent
+		Expect(keys.changeChan).To(Receive(&change))
+		return change.GetChanges()[0].GetKey(), change.GetChanges()[0].GetChangeType()
+	}
+
+	notify("/vnf/a", datasync.Put)
+	notify("/vnf/b", datasync.Put)
+	Expect(nb.errors).To(HaveLen(1))
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/b")))
+	for _, keys := range []*watchBrokerKeys{keys1, keys2} {
+		key, _ := receive(keys)
+		Expect(key).To(Equal("/vnf/a"))
+		Expect(keys.changeChan).To(BeEmpty())
+	}
+
+	// both watchers receive the delete
+	notify("/vnf/a", datasync.Delete)
+	for _, keys := range []*watchBrokerKeys{keys1, keys2} {
+		key, op := receive(keys)
+		Expect(key).To(Equal("/vnf/a"))
+		Expect(op).To(Equal(datasync.Delete))
+	}
+
+	// resync of one watcher does not affect usage of the other
+	notify("/vnf/d", datasync.Put)
+	receive(keys1)
+	receive(keys2)
+	it := newQuotaIterator(&sliceIterator{&sizedKeyVal{key: "/vnf/d", size: 1}}, keys1.getQuota(), "/vnf/")
+	keys2.watchChanges(watchResp{syncbase.NewChange("/vnf/e", nil, 1, datasync.Put)})
+	Expect(keys2.changeChan).To(BeEmpty())
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/e")))
+	kv, stop := it.GetNext()
+	Expect(stop).To(BeFalse())
+	Expect(kv.GetKey()).To(Equal("/vnf/d"))
+	_, stop = it.GetNext()
+	Expect(stop).To(BeTrue())
+	Expect(quota.Status()[0].Keys).To(Equal(1))
+
+	// the error is withdrawn once no watcher rejects the key
+	Expect(nb.errors).To(HaveKey(QuotaErrorKey("/vnf/b")))
+	notify("/vnf/b", datasync.Delete)
+	Expect(nb.errors).ToNot(HaveKey(QuotaErrorKey("/vnf/b")))
+}
@@ -16,9 +16,12 @@ package kvdbsync
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
 	"github.com/ligato/cn-infra/datasync/resync"
 	"github.com/ligato/cn-infra/datasync/syncbase"
 	"github.com/ligato/cn-infra/db/keyval"
@@ -41,13 +44,27 @@ type watchBrokerKeys struct {
 	changeChan chan datasync.ChangeEvent
 	resyncChan chan datasync.ResyncEvent
 	prefixes   []string
-	adapter    *watcher
+
+	mu      sync.Mutex
+	adapter *watcher
+	// guarded is set once resync of the keys is registered to the churn guard
+	guarded bool
+	// quota tracks usage of the quota by this registration, nil if not used
+	quota *quotaTracker
 }
 
 type watcher struct {
 	db   keyval.ProtoBroker
 	dbW  keyval.ProtoWatcher
 	base *syncbase.Registry
+	// mirror copies data into the local cache, nil if the cache is not used
+	mirror *cacheMirror
+	// guard holds changes in case of abnormal churn, nil if not used
+	guard *ChurnGuard
+	// quota rejects values exceeding limits of their prefix, nil if not used
+	quota *Quota
+	// maintenance queues changes while the agent is in maintenance mode, nil if not used
+	maintenance *Maintenance
 }
 
 // WatchAndResyncBrokerKeys calls keyval watcher Watch() & resync Register().
@@ -62,6 +79,10 @@ func watchAndResyncBrokerKeys(resyncReg resync.Registration, changeChan chan dat
 		prefixes:   keyPrefixes,
 	}
 
+	if adapter.maintenance != nil {
+		adapter.maintenance.registerResync(keys.heldResync)
+	}
+
 	var wasErr error
 	if err := keys.resyncRev(); err != nil {
 		wasErr = err
@@ -69,25 +90,98 @@ func watchAndResyncBrokerKeys(resyncReg resync.Registration, changeChan chan dat
 	if resyncReg != nil {
 		go keys.watchResync(resyncReg)
 	}
-	if changeChan != nil {
-		if err := keys.adapter.dbW.Watch(keys.watchChanges, closeChan, keys.prefixes...); err != nil {
-			wasErr = err
-		}
+	if err := keys.watch(closeChan); err != nil {
+		wasErr = err
 	}
 	return keys, wasErr
 }
 
+// switchAdapter replaces adapter used by already registered keys (e.g. local cache replaced
+// by KV store once it is connected) and starts watching changes using the new adapter.
+func (keys *watchBrokerKeys) switchAdapter(adapter *watcher, closeChan chan string) error {
+	keys.mu.Lock()
+	keys.adapter = adapter
+	keys.mu.Unlock()
+
+	var wasErr error
+	if err := keys.resyncRev(); err != nil {
+		wasErr = err
+	}
+	if err := keys.watch(closeChan); err != nil {
+		wasErr = err
+	}
+	return wasErr
+}
+
+func (keys *watchBrokerKeys) getAdapter() *watcher {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	return keys.adapter
+}
+
+// getQuota returns tracker of the quota usage by the registration, nil if the quota is not used.
+func (keys *watchBrokerKeys) getQuota() *quotaTracker {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if keys.quota == nil && keys.adapter.quota != nil {
+		keys.quota = keys.adapter.quota.newTracker()
+	}
+	return keys.quota
+}
+
+// watch starts watching changes if the adapter supports it
+func (keys *watchBrokerKeys) watch(closeChan chan string) error {
+	adapter := keys.getAdapter()
+	if keys.changeChan == nil || adapter.dbW == nil {
+		return nil
+	}
+	keys.mu.Lock()
+	if adapter.guard != nil && !keys.guarded {
+		keys.guarded = true
+		adapter.guard.registerResync(keys.prefixes, keys.rejectedResync)
+	}
+	keys.mu.Unlock()
+	return adapter.dbW.Watch(keys.watchChanges, closeChan, keys.prefixes...)
+}
+
 func (keys *watchBrokerKeys) watchChanges(x datasync.ProtoWatchResp) {
-	var prev datasync.LazyValue
-	if datasync.Delete == x.GetChangeType() {
-		_, prev = keys.adapter.base.LastRev().Del(x.GetKey())
-	} else {
-		_, prev = keys.adapter.base.LastRev().PutWithRevision(x.GetKey(),
-			syncbase.NewKeyVal(x.GetKey(), x, x.GetRevision()))
+	adapter := keys.getAdapter()
+
+	if adapter.mirror != nil {
+		if err := adapter.mirror.update(x); err != nil {
+			logrus.DefaultLogger().Warnf("updating local cache for %q failed: %v", x.GetKey(), err)
+		}
+	}
+	if quota := keys.getQuota(); quota != nil && !quota.admit(x.GetKey(), x.GetChangeType(), valueSize(x)) {
+		// rejected values are not recorded, so that the previous value of the next
+		// change is the value the watchers have actually seen
+		return
 	}
 
-	ch := NewChangeWatchResp(context.Background(), x, prev)
-	keys.changeChan <- ch
+	// the revision is recorded only once the change is delivered, so that changes held
+	// (and possibly rejected) by the churn guard do not affect the previous values
+	deliver := func() {
+		var prev datasync.LazyValue
+		if datasync.Delete == x.GetChangeType() {
+			_, prev = adapter.base.LastRev().Del(x.GetKey())
+		} else {
+			_, prev = adapter.base.LastRev().PutWithRevision(x.GetKey(),
+				syncbase.NewKeyVal(x.GetKey(), x, x.GetRevision()))
+		}
+		keys.changeChan <- NewChangeWatchResp(context.Background(), x, prev)
+	}
+	if adapter.maintenance != nil {
+		// changes confirmed by the churn guard during maintenance are queued as well
+		send := deliver
+		deliver = func() {
+			adapter.maintenance.admit(send)
+		}
+	}
+	if adapter.guard != nil {
+		adapter.guard.admit(keyprefix.MatchLongest(x.GetKey(), keys.prefixes), x.GetKey(), x.GetChangeType(), deliver)
+		return
+	}
+	deliver()
 	// TODO NICE-to-HAVE publish the err using the transport asynchronously
 }
 
@@ -95,6 +189,16 @@ func (keys *watchBrokerKeys) watchChanges(x datasync.ProtoWatchResp) {
 func (keys *watchBrokerKeys) watchResync(resyncReg resync.Registration) {
 	for resyncStatus := range resyncReg.StatusChan() {
 		if resyncStatus.ResyncStatus() == resync.Started {
+			if guard := keys.getAdapter().guard; guard != nil && guard.holdIfPaused(keys.prefixes, keys.heldResync) {
+				logrus.DefaultLogger().Warnf("resync of %v held by churn guard", keys.prefixes)
+				resyncStatus.Ack()
+				continue
+			}
+			if m := keys.getAdapter().maintenance; m != nil && m.hold(keys.heldResync) {
+				logrus.DefaultLogger().Warnf("resync of %v queued until maintenance mode is left", keys.prefixes)
+				resyncStatus.Ack()
+				continue
+			}
 			err := keys.resync()
 			if err != nil {
 				// We are not able to propagate it somewhere else.
@@ -106,14 +210,37 @@ func (keys *watchBrokerKeys) watchResync(resyncReg resync.Registration) {
 	}
 }
 
+// heldResync is resync delayed by the churn guard until the held changes are confirmed
+// or rejected, or by the maintenance mode until it is left.
+func (keys *watchBrokerKeys) heldResync() {
+	if err := keys.resyncRev(); err != nil {
+		logrus.DefaultLogger().Errorf("refreshing revisions failed: %v", err)
+	}
+	if err := keys.resync(); err != nil {
+		logrus.DefaultLogger().Errorf("getting resync data failed: %v", err)
+	}
+}
+
+// rejectedResync resynchronizes the watchers with the KV store after the changes
+// held by the churn guard were dropped, or the fence of the prefix was lifted
+// (the watchers have not seen them).
+func (keys *watchBrokerKeys) rejectedResync() {
+	if m := keys.getAdapter().maintenance; m != nil && m.hold(keys.heldResync) {
+		return
+	}
+	keys.heldResync()
+}
+
 // ResyncRev fill the PrevRevision map. This step needs to be done even if resync is ommited
 func (keys *watchBrokerKeys) resyncRev() error {
+	adapter := keys.getAdapter()
 	for _, keyPrefix := range keys.prefixes {
-		revIt, err := keys.adapter.db.ListValues(keyPrefix)
+		revIt, err := adapter.db.ListValues(keyPrefix)
 		if err != nil {
 			return err
 		}
 		// if there are data for given prefix, register it
+		found := make(map[string]bool)
 		for {
 			data, stop := revIt.GetNext()
 			if stop {
@@ -121,9 +248,21 @@ func (keys *watchBrokerKeys) resyncRev() error {
 			}
 			logrus.DefaultLogger().Debugf("registering key found in KV: %q", data.GetKey())
 
-			keys.adapter.base.LastRev().PutWithRevision(data.GetKey(),
+			found[data.GetKey()] = true
+			adapter.base.LastRev().PutWithRevision(data.GetKey(),
 				syncbase.NewKeyVal(data.GetKey(), data, data.GetRevision()))
 		}
+		// forget keys removed from KV in the meantime
+		for _, key := range adapter.base.LastRev().ListKeys() {
+			if strings.HasPrefix(key, keyPrefix) && !found[key] {
+				adapter.base.LastRev().Del(key)
+			}
+		}
+		if adapter.mirror != nil {
+			if err := adapter.mirror.sync(keyPrefix); err != nil {
+				logrus.DefaultLogger().Warnf("updating local cache failed: %v", err)
+			}
+		}
 	}
 
 	return nil
@@ -131,13 +270,23 @@ func (keys *watchBrokerKeys) resyncRev() error {
 
 // Resync fills the resyncChan with the most recent snapshot (db.ListValues).
 func (keys *watchBrokerKeys) resync() error {
+	adapter := keys.getAdapter()
+	quota := keys.getQuota()
 	iterators := map[string]datasync.KeyValIterator{}
 	for _, keyPrefix := range keys.prefixes {
-		it, err := keys.adapter.db.ListValues(keyPrefix)
+		if adapter.mirror != nil {
+			if err := adapter.mirror.sync(keyPrefix); err != nil {
+				logrus.DefaultLogger().Warnf("updating local cache failed: %v", err)
+			}
+		}
+		it, err := adapter.db.ListValues(keyPrefix)
 		if err != nil {
 			return errors.WithMessagef(err, "list values for %s failed", keyPrefix)
 		}
 		iterators[keyPrefix] = NewIterator(it)
+		if quota != nil {
+			iterators[keyPrefix] = newQuotaIterator(iterators[keyPrefix], quota, keyPrefix)
+		}
 	}
 
 	resyncEvent := syncbase.NewResyncEventDB(context.Background(), iterators)
@@ -0,0 +1,33 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mergesync implements a KeyValProtoWatcher merging data of the same
+// key prefixes watched in multiple sources (e.g. etcd and a local override
+// file) into a single stream of change and resync events.
+//
+// Every source has a precedence. If the same key is present in multiple
+// sources, plugins see only the value from the source with the highest
+// precedence. Changes hidden by a value from a source with a higher precedence
+// are not propagated, removal of the overriding value propagates the value
+// from the next source instead.
+//
+// Resync of any source results in a resync event carrying the merged data
+// of all sources. The first resync event is delivered once every source has
+// finished its initial resync, changes received before are included in it:
+//
+//	watcher := mergesync.NewWatcher(
+//	    mergesync.Source{Name: "etcd", Precedence: 0, Watcher: &kvdbsync.DefaultPlugin},
+//	    mergesync.Source{Name: "override", Precedence: 1, Watcher: overrides},
+//	)
+package mergesync
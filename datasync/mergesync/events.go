@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mergesync
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+)
+
+// changeEvent is a merged change event, Done is propagated to the source event.
+type changeEvent struct {
+	ctx     context.Context
+	changes []datasync.ProtoWatchResp
+	done    func(error)
+}
+
+// GetContext returns the context of the source event.
+func (ev *changeEvent) GetContext() context.Context {
+	return ev.ctx
+}
+
+// GetChanges returns changes of the effective values.
+func (ev *changeEvent) GetChanges() []datasync.ProtoWatchResp {
+	return ev.changes
+}
+
+// Done propagates the result to the source event.
+func (ev *changeEvent) Done(err error) {
+	ev.done(err)
+}
+
+// resyncEvent is a merged resync event, Done is propagated to the source event.
+type resyncEvent struct {
+	ctx    context.Context
+	values map[string]datasync.KeyValIterator
+	done   func(error)
+}
+
+func newResyncEvent(ctx context.Context, values map[string][]datasync.KeyVal, done func(error)) *resyncEvent {
+	ev := &resyncEvent{
+		ctx:    ctx,
+		values: make(map[string]datasync.KeyValIterator, len(values)),
+		done:   done,
+	}
+	for prefix, kvs := range values {
+		sort.Slice(kvs, func(i, j int) bool {
+			return kvs[i].GetKey() < kvs[j].GetKey()
+		})
+		ev.values[prefix] = syncbase.NewKVIterator(kvs)
+	}
+	return ev
+}
+
+// GetContext returns the context of the source event.
+func (ev *resyncEvent) GetContext() context.Context {
+	return ev.ctx
+}
+
+// GetValues returns merged values of all sources.
+func (ev *resyncEvent) GetValues() map[string]datasync.KeyValIterator {
+	return ev.values
+}
+
+// Done propagates the result to the source event.
+func (ev *resyncEvent) Done(err error) {
+	ev.done(err)
+}
+
+// newChangeResp creates change of the effective value of the key.
+func newChangeResp(key string, prev, curr *entry) *syncbase.ChangeResp {
+	change := &syncbase.ChangeResp{
+		Key:        key,
+		ChangeType: datasync.Delete,
+	}
+	if prev != nil {
+		change.PrevVal = prev
+	}
+	if curr != nil {
+		change.ChangeType = datasync.Put
+		change.CurrVal = curr
+		change.CurrRev = curr.GetRevision()
+	} else {
+		change.CurrVal = emptyValue{}
+	}
+	return change
+}
+
+// emptyValue is the value of deleted keys.
+type emptyValue struct{}
+
+// GetValue leaves the value untouched.
+func (emptyValue) GetValue(proto.Message) error {
+	return nil
+}
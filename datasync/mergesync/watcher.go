@@ -0,0 +1,328 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mergesync
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/keyprefix"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+	"github.com/ligato/cn-infra/utils/safeclose"
+)
+
+// changeChanBuffer is the size of buffered channels receiving changes from sources.
+const changeChanBuffer = 100
+
+// Source is a data source merged by the Watcher.
+type Source struct {
+	// Name identifies the source in logs.
+	Name string
+	// Precedence of the source, values from sources with higher precedence
+	// override values of the same keys from sources with lower precedence.
+	Precedence int
+	// Watcher used to watch the source.
+	Watcher datasync.KeyValProtoWatcher
+}
+
+// Watcher merges data watched in multiple sources according to their precedence.
+// It implements datasync.KeyValProtoWatcher.
+type Watcher struct {
+	log     logging.Logger
+	sources []Source
+}
+
+// NewWatcher creates a new Watcher merging the given sources. If multiple
+// sources have the same precedence, the source listed first wins.
+func NewWatcher(sources ...Source) *Watcher {
+	sorted := append([]Source(nil), sources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Precedence > sorted[j].Precedence
+	})
+	return &Watcher{
+		log:     logrus.DefaultLogger(),
+		sources: sorted,
+	}
+}
+
+// Watch subscribes to all sources and delivers merged events into the given channels.
+func (w *Watcher) Watch(resyncName string, changeChan chan datasync.ChangeEvent,
+	resyncChan chan datasync.ResyncEvent, keyPrefixes ...string) (datasync.WatchRegistration, error) {
+
+	sub := &subscription{
+		log:        w.log,
+		sources:    w.sources,
+		changeChan: changeChan,
+		resyncChan: resyncChan,
+		prefixes:   append([]string(nil), keyPrefixes...),
+		values:     make(map[string][]*entry),
+		resynced:   make([]bool, len(w.sources)),
+		events:     make(chan sourceEvent),
+	}
+	sub.loop.Log = w.log
+
+	for idx, source := range w.sources {
+		srcChange := make(chan datasync.ChangeEvent, changeChanBuffer)
+		srcResync := make(chan datasync.ResyncEvent)
+		reg, err := source.Watcher.Watch(resyncName, srcChange, srcResync, keyPrefixes...)
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+		if reg != nil {
+			sub.Registrations = append(sub.Registrations, reg)
+		}
+		idx := idx
+		sub.loop.Go(func(ctx context.Context) {
+			sub.forward(ctx, idx, srcChange, srcResync)
+		})
+	}
+
+	sub.loop.Run(infra.OnReceive(sub.events, func(ctx context.Context, ev interface{}) {
+		sub.process(ctx, ev.(sourceEvent))
+	}))
+
+	return sub, nil
+}
+
+// subscription merges events of a single Watch call.
+type subscription struct {
+	datasync.AggregatedRegistration
+
+	log        logging.Logger
+	sources    []Source
+	changeChan chan datasync.ChangeEvent
+	resyncChan chan datasync.ResyncEvent
+
+	mu       sync.Mutex
+	prefixes []string
+
+	// values of keys in all sources (indexed as sources), accessed only from the event loop
+	values map[string][]*entry
+
+	// sources that finished the initial resync, accessed only from the event loop
+	resynced    []bool
+	initialized bool
+	// initial resyncs of sources waiting for the remaining sources,
+	// released by Close if the remaining sources never resync
+	heldResyncs []func(error)
+
+	events chan sourceEvent
+	// loop runs forwarding of events from the sources and their processing
+	loop infra.EventLoop
+}
+
+// entry is a value of a key in a single source.
+type entry struct {
+	datasync.KeyVal
+}
+
+// sourceEvent is an event received from the source with the given index.
+type sourceEvent struct {
+	source int
+	change datasync.ChangeEvent
+	resync datasync.ResyncEvent
+}
+
+// forward passes events from a single source into the event loop.
+func (sub *subscription) forward(ctx context.Context, idx int,
+	changeChan chan datasync.ChangeEvent, resyncChan chan datasync.ResyncEvent) {
+	for {
+		var ev sourceEvent
+		select {
+		case change := <-changeChan:
+			ev = sourceEvent{source: idx, change: change}
+		case resync := <-resyncChan:
+			ev = sourceEvent{source: idx, resync: resync}
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case sub.events <- ev:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process handles event of a source, events of all sources are processed one by one.
+func (sub *subscription) process(ctx context.Context, ev sourceEvent) {
+	if ev.resync != nil {
+		sub.processResync(ctx, ev.source, ev.resync)
+	} else {
+		sub.processChange(ctx, ev.source, ev.change)
+	}
+}
+
+// effective returns the value of the key from the source with the highest precedence.
+func (sub *subscription) effective(key string) *entry {
+	for _, val := range sub.values[key] {
+		if val != nil {
+			return val
+		}
+	}
+	return nil
+}
+
+// set updates the value of the key in the source, nil removes the value.
+func (sub *subscription) set(key string, source int, val *entry) {
+	vals, ok := sub.values[key]
+	if !ok {
+		if val == nil {
+			return
+		}
+		vals = make([]*entry, len(sub.sources))
+		sub.values[key] = vals
+	}
+	vals[source] = val
+	if val == nil && sub.effective(key) == nil {
+		delete(sub.values, key)
+	}
+}
+
+// processChange applies changes of the source and propagates changes
+// of the effective values. Before the initial resync of all sources,
+// the changes are only recorded, they become part of the merged resync.
+func (sub *subscription) processChange(ctx context.Context, source int, ev datasync.ChangeEvent) {
+	var changes []datasync.ProtoWatchResp
+	for _, change := range ev.GetChanges() {
+		key := change.GetKey()
+		prev := sub.effective(key)
+		if change.GetChangeType() == datasync.Delete {
+			sub.set(key, source, nil)
+		} else {
+			sub.set(key, source, &entry{change})
+		}
+		curr := sub.effective(key)
+		if curr == prev || !sub.initialized {
+			continue // hidden by a source with higher precedence
+		}
+		changes = append(changes, newChangeResp(key, prev, curr))
+	}
+
+	if len(changes) == 0 {
+		ev.Done(nil)
+		return
+	}
+	select {
+	case sub.changeChan <- &changeEvent{ctx: ev.GetContext(), changes: changes, done: ev.Done}:
+	case <-ctx.Done():
+	}
+}
+
+// processResync replaces all values of the source and propagates resync
+// with the merged values of all sources. The first resync is propagated only
+// after all sources finished their initial resync, so that the plugins do not
+// remove values of sources that were not read yet.
+func (sub *subscription) processResync(ctx context.Context, source int, ev datasync.ResyncEvent) {
+	for key := range sub.values {
+		sub.set(key, source, nil)
+	}
+	for _, it := range ev.GetValues() {
+		for {
+			kv, allReceived := it.GetNext()
+			if allReceived {
+				break
+			}
+			sub.set(kv.GetKey(), source, &entry{kv})
+		}
+	}
+	prefixes := sub.getPrefixes()
+	sub.log.Debugf("resync of %v from source %s", prefixes, sub.sources[source].Name)
+
+	done := ev.Done
+	if !sub.initialized {
+		sub.resynced[source] = true
+		sub.heldResyncs = append(sub.heldResyncs, ev.Done)
+		for idx, resynced := range sub.resynced {
+			if !resynced {
+				sub.log.Debugf("resync of %v waits for initial resync of source %s",
+					prefixes, sub.sources[idx].Name)
+				return
+			}
+		}
+		held := sub.heldResyncs
+		sub.initialized, sub.heldResyncs = true, nil
+		done = func(err error) {
+			for _, done := range held {
+				done(err)
+			}
+		}
+	}
+
+	values := make(map[string][]datasync.KeyVal)
+	for _, prefix := range prefixes {
+		values[prefix] = nil
+	}
+	for key := range sub.values {
+		if prefix := keyprefix.MatchLongest(key, prefixes); prefix != "" {
+			values[prefix] = append(values[prefix], sub.effective(key).KeyVal)
+		}
+	}
+
+	select {
+	case sub.resyncChan <- newResyncEvent(ev.GetContext(), values, done):
+	case <-ctx.Done():
+	}
+}
+
+func (sub *subscription) getPrefixes() []string {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return append([]string(nil), sub.prefixes...)
+}
+
+// Register adds the key prefix to all sources.
+func (sub *subscription) Register(resyncName, keyPrefix string) error {
+	sub.mu.Lock()
+	sub.prefixes = append(sub.prefixes, keyPrefix)
+	sub.mu.Unlock()
+
+	return sub.AggregatedRegistration.Register(resyncName, keyPrefix)
+}
+
+// Unregister removes the key prefix from all sources.
+func (sub *subscription) Unregister(keyPrefix string) error {
+	sub.mu.Lock()
+	for i, prefix := range sub.prefixes {
+		if prefix == keyPrefix {
+			sub.prefixes = append(sub.prefixes[:i], sub.prefixes[i+1:]...)
+			break
+		}
+	}
+	sub.mu.Unlock()
+
+	return sub.AggregatedRegistration.Unregister(keyPrefix)
+}
+
+// Close closes registrations in all sources and stops merging.
+func (sub *subscription) Close() error {
+	err := safeclose.Close(sub.Registrations)
+	sub.loop.Stop()
+
+	// the event loop is stopped, held resyncs can be released
+	sub.mu.Lock()
+	held := sub.heldResyncs
+	sub.heldResyncs = nil
+	sub.mu.Unlock()
+	for _, done := range held {
+		done(nil)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mergesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+const prefix = "/vnf/"
+
+func value(name string) *syncbase.Change {
+	return syncbase.NewChange(prefix+"a", &status.PluginStatus{Name: name}, 0, datasync.Put)
+}
+
+func deleted() *syncbase.Change {
+	return syncbase.NewChange(prefix+"a", nil, 0, datasync.Delete)
+}
+
+func propagate(src *syncbase.Registry, change *syncbase.Change) {
+	go src.PropagateChanges(context.Background(), map[string]datasync.ChangeValue{change.GetKey(): change})
+}
+
+func expectChange(changeChan chan datasync.ChangeEvent, op datasync.Op, name string) {
+	var ev datasync.ChangeEvent
+	Eventually(changeChan).Should(Receive(&ev))
+	ev.Done(nil)
+	Expect(ev.GetChanges()).To(HaveLen(1))
+	change := ev.GetChanges()[0]
+	Expect(change.GetChangeType()).To(Equal(op))
+	if op == datasync.Put {
+		val := &status.PluginStatus{}
+		Expect(change.GetValue(val)).To(Succeed())
+		Expect(val.Name).To(Equal(name))
+	}
+}
+
+func TestMergeWithPrecedence(t *testing.T) {
+	RegisterTestingT(t)
+
+	low, high := syncbase.NewRegistry(), syncbase.NewRegistry()
+	watcher := NewWatcher(
+		Source{Name: "low", Precedence: 0, Watcher: low},
+		Source{Name: "high", Precedence: 1, Watcher: high},
+	)
+	changeChan := make(chan datasync.ChangeEvent)
+	resyncChan := make(chan datasync.ResyncEvent)
+	reg, err := watcher.Watch("test", changeChan, resyncChan, prefix)
+	Expect(err).To(BeNil())
+	defer reg.Close()
+
+	// initial resync of both sources
+	go low.PropagateResync(context.Background(), map[string]datasync.ChangeValue{prefix + "a": value("low")})
+	go high.PropagateResync(context.Background(), map[string]datasync.ChangeValue{})
+	var resync datasync.ResyncEvent
+	Eventually(resyncChan).Should(Receive(&resync))
+	kv, allReceived := resync.GetValues()[prefix].GetNext()
+	Expect(allReceived).To(BeFalse())
+	resync.Done(nil)
+	val := &status.PluginStatus{}
+	Expect(kv.GetValue(val)).To(Succeed())
+	Expect(val.Name).To(Equal("low"))
+
+	// the high source overrides the value
+	propagate(high, value("high"))
+	expectChange(changeChan, datasync.Put, "high")
+
+	// changes of the overridden value are not propagated
+	propagate(low, value("low2"))
+	Consistently(changeChan, 100*time.Millisecond).ShouldNot(Receive())
+
+	// removal of the override reveals the value of the low source
+	propagate(high, deleted())
+	expectChange(changeChan, datasync.Put, "low2")
+
+	propagate(low, deleted())
+	expectChange(changeChan, datasync.Delete, "")
+}
+
+func TestInitialResyncWaitsForAllSources(t *testing.T) {
+	RegisterTestingT(t)
+
+	low, high := syncbase.NewRegistry(), syncbase.NewRegistry()
+	watcher := NewWatcher(
+		Source{Name: "low", Precedence: 0, Watcher: low},
+		Source{Name: "high", Precedence: 1, Watcher: high},
+	)
+	changeChan := make(chan datasync.ChangeEvent)
+	resyncChan := make(chan datasync.ResyncEvent)
+	reg, err := watcher.Watch("test", changeChan, resyncChan, prefix)
+	Expect(err).To(BeNil())
+	defer reg.Close()
+
+	lowDone := make(chan struct{})
+	go func() {
+		low.PropagateResync(context.Background(), map[string]datasync.ChangeValue{prefix + "a": value("low")})
+		close(lowDone)
+	}()
+	Consistently(resyncChan, 100*time.Millisecond).ShouldNot(Receive())
+
+	// changes before the initial resync of all sources are not propagated
+	propagate(low, value("low2"))
+	Consistently(changeChan, 100*time.Millisecond).ShouldNot(Receive())
+	Expect(lowDone).ToNot(BeClosed())
+
+	go high.PropagateResync(context.Background(), map[string]datasync.ChangeValue{})
+	var resync datasync.ResyncEvent
+	Eventually(resyncChan).Should(Receive(&resync))
+	kv, allReceived := resync.GetValues()[prefix].GetNext()
+	Expect(allReceived).To(BeFalse())
+	val := &status.PluginStatus{}
+	Expect(kv.GetValue(val)).To(Succeed())
+	Expect(val.Name).To(Equal("low2"))
+
+	// the held resync of the low source is done with the merged resync
+	resync.Done(nil)
+	Eventually(lowDone).Should(BeClosed())
+}
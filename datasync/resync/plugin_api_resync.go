@@ -14,6 +14,12 @@
 
 package resync
 
+import (
+	"time"
+
+	"github.com/ligato/cn-infra/utils/eventbus"
+)
+
 // Subscriber is an API used by plugins to register for notifications from the
 // RESYNC Orcherstrator.
 type Subscriber interface {
@@ -49,3 +55,18 @@ type StatusEvent interface {
 	// This is supposed to be called after the configuration was applied by the Plugin.
 	Ack()
 }
+
+// Completed is the topic of CompletedEvent published on eventbus.DefaultBus
+// at the end of every resync procedure.
+var Completed = eventbus.NewTopic("resync/completed", CompletedEvent{})
+
+// CompletedEvent describes a finished resync.
+type CompletedEvent struct {
+	// Registrations lists all registrations in the order they were resynced.
+	Registrations []string
+	// Duration of the whole resync procedure.
+	Duration time.Duration
+	// TimedOut lists registrations that failed to accept or acknowledge
+	// the resync in time.
+	TimedOut []string
+}
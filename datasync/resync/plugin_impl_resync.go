@@ -21,6 +21,7 @@ import (
 
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/utils/eventbus"
 )
 
 var (
@@ -104,21 +105,34 @@ func (p *Plugin) startResync() {
 
 	resyncStart := time.Now()
 
+	var timedOut []string
 	for _, regName := range p.regOrder {
 		if reg, found := p.registrations[regName]; found {
 			t := time.Now()
-			p.startSingleResync(regName, reg)
+			if !p.startSingleResync(regName, reg) {
+				timedOut = append(timedOut, regName)
+			}
 
 			took := time.Since(t).Round(time.Millisecond)
 			p.Log.Debugf("finished resync for %v took %v", regName, took)
 		}
 	}
 
-	p.Log.Infof("Resync done (took: %v)", time.Since(resyncStart).Round(time.Millisecond))
+	took := time.Since(resyncStart)
+	p.Log.Infof("Resync done (took: %v)", took.Round(time.Millisecond))
+
+	eventbus.DefaultBus.Publish(Completed, CompletedEvent{
+		Registrations: append([]string(nil), p.regOrder...),
+		Duration:      took,
+		TimedOut:      timedOut,
+	})
 
 	// TODO check if there ReportError (if not than report) if error occurred even during Resync
 }
-func (p *Plugin) startSingleResync(resyncName string, reg *registration) {
+
+// startSingleResync returns false if the registration did not accept
+// or acknowledge the resync in time.
+func (p *Plugin) startSingleResync(resyncName string, reg *registration) bool {
 	started := newStatusEvent(Started)
 
 	select {
@@ -126,13 +140,15 @@ func (p *Plugin) startSingleResync(resyncName string, reg *registration) {
 		// accept
 	case <-time.After(SingleResyncAcceptTimeout):
 		p.Log.WithField("regName", resyncName).Warn("Timeout of resync start!")
-		return
+		return false
 	}
 
 	select {
 	case <-started.ReceiveAck():
 		// ack
+		return true
 	case <-time.After(SingleResyncAckTimeout):
 		p.Log.WithField("regName", resyncName).Warn("Timeout of resync ACK!")
+		return false
 	}
 }
@@ -0,0 +1,33 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revstatus helps plugins publish status values stamped with the
+// northbound (NB) configuration revision and the transaction sequence number
+// they correspond to. Controllers pushing configuration can then reliably tell
+// whether the status feedback already reflects the version they pushed.
+//
+// Tracker records the applied configuration, Publisher wraps status values
+// into stamp.StampedStatus and writes them into the data store:
+//
+//	tracker := revstatus.NewTracker()
+//	publisher := revstatus.NewPublisher(&kvdbsync.DefaultPlugin, tracker)
+//
+//	case ev := <-changeChan:
+//	    err := p.applyChange(ev)
+//	    ev.Done(err)
+//	    tracker.ChangeApplied(ev)
+//	    publisher.Publish(statusKey, p.status())
+//
+// Controllers decode the published value using Unwrap.
+package revstatus
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revstatus
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/revstatus/model/stamp"
+)
+
+//go:generate protoc --proto_path=model/stamp --go_out=model/stamp model/stamp/stamp.proto
+
+// Publisher writes status values stamped with the current stamp of the tracker.
+type Publisher struct {
+	writer  datasync.KeyProtoValWriter
+	tracker *Tracker
+}
+
+// NewPublisher is a constructor.
+func NewPublisher(writer datasync.KeyProtoValWriter, tracker *Tracker) *Publisher {
+	return &Publisher{writer: writer, tracker: tracker}
+}
+
+// Publish puts the status stamped with the current stamp of the tracker
+// under the given key.
+func (p *Publisher) Publish(key string, status proto.Message, opts ...datasync.PutOption) error {
+	return p.PublishWithStamp(key, status, p.tracker.Current(), opts...)
+}
+
+// PublishWithStamp puts the status stamped with the given stamp under the key.
+// It is useful when the status was computed for an older transaction.
+func (p *Publisher) PublishWithStamp(key string, status proto.Message, st Stamp, opts ...datasync.PutOption) error {
+	stamped, err := Wrap(status, st)
+	if err != nil {
+		return err
+	}
+	return p.writer.Put(key, stamped, opts...)
+}
+
+// Wrap stamps the status value.
+func Wrap(status proto.Message, st Stamp) (*stamp.StampedStatus, error) {
+	value, err := ptypes.MarshalAny(status)
+	if err != nil {
+		return nil, err
+	}
+	return &stamp.StampedStatus{
+		NbRevision: st.Revision,
+		TxnSeq:     st.TxnSeq,
+		Published:  time.Now().UnixNano(),
+		Status:     value,
+	}, nil
+}
+
+// Unwrap fills <status> with the value of the stamped status and returns
+// the stamp. It fails if the stamped value is not of the type of <status>.
+func Unwrap(stamped *stamp.StampedStatus, status proto.Message) (Stamp, error) {
+	if err := ptypes.UnmarshalAny(stamped.GetStatus(), status); err != nil {
+		return Stamp{}, err
+	}
+	return Stamp{Revision: stamped.GetNbRevision(), TxnSeq: stamped.GetTxnSeq()}, nil
+}
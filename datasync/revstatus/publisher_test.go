@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revstatus
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/revstatus/model/stamp"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+type writerMock struct {
+	values map[string]proto.Message
+}
+
+func (w *writerMock) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	w.values[key] = data
+	return nil
+}
+
+func TestTracker(t *testing.T) {
+	RegisterTestingT(t)
+
+	tracker := NewTracker()
+	Expect(tracker.Current()).To(Equal(Stamp{}))
+
+	Expect(tracker.ResyncApplied(10)).To(Equal(Stamp{Revision: 10, TxnSeq: 1}))
+
+	ev := &syncbase.ChangeEvent{Changes: []datasync.ProtoWatchResp{
+		&syncbase.ChangeResp{Key: "a", ChangeType: datasync.Put, CurrRev: 12},
+		&syncbase.ChangeResp{Key: "b", ChangeType: datasync.Put, CurrRev: 11},
+	}}
+	Expect(tracker.ChangeApplied(ev)).To(Equal(Stamp{Revision: 12, TxnSeq: 2}))
+
+	// revision never decreases
+	Expect(tracker.Applied(5)).To(Equal(Stamp{Revision: 12, TxnSeq: 3}))
+	Expect(tracker.Current()).To(Equal(Stamp{Revision: 12, TxnSeq: 3}))
+}
+
+func TestPublish(t *testing.T) {
+	RegisterTestingT(t)
+
+	writer := &writerMock{values: map[string]proto.Message{}}
+	tracker := NewTracker()
+	publisher := NewPublisher(writer, tracker)
+
+	tracker.Applied(7)
+	pluginStatus := &status.PluginStatus{State: status.OperationalState_OK}
+	Expect(publisher.Publish("status/plugin", pluginStatus)).To(Succeed())
+
+	stamped, ok := writer.values["status/plugin"].(*stamp.StampedStatus)
+	Expect(ok).To(BeTrue())
+	Expect(stamped.Published).ToNot(BeZero())
+
+	// simulate transport: the controller decodes serialized value
+	data, err := proto.Marshal(stamped)
+	Expect(err).ToNot(HaveOccurred())
+	received := &stamp.StampedStatus{}
+	Expect(proto.Unmarshal(data, received)).To(Succeed())
+
+	decoded := &status.PluginStatus{}
+	st, err := Unwrap(received, decoded)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(st).To(Equal(Stamp{Revision: 7, TxnSeq: 1}))
+	Expect(decoded.State).To(Equal(status.OperationalState_OK))
+
+	_, err = Unwrap(received, &status.AgentStatus{})
+	Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revstatus
+
+import (
+	"sync"
+
+	"github.com/ligato/cn-infra/datasync"
+)
+
+// Stamp identifies the version of the NB configuration a status
+// corresponds to.
+type Stamp struct {
+	// Revision is the highest NB revision of the applied configuration.
+	Revision int64
+	// TxnSeq is the sequence number of the last applied transaction
+	// (change or resync).
+	TxnSeq uint64
+}
+
+// Tracker keeps the stamp of the last applied configuration.
+type Tracker struct {
+	mu      sync.Mutex
+	current Stamp
+}
+
+// NewTracker is a constructor.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Applied records a transaction applied with the given highest NB revision
+// and returns the new stamp. The revision never decreases, revision lower
+// than the current one (e.g. of a delete) only advances the sequence number.
+func (t *Tracker) Applied(revision int64) Stamp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current.TxnSeq++
+	if revision > t.current.Revision {
+		t.current.Revision = revision
+	}
+	return t.current
+}
+
+// ChangeApplied records an applied change event.
+func (t *Tracker) ChangeApplied(ev datasync.ChangeEvent) Stamp {
+	var revision int64
+	for _, change := range ev.GetChanges() {
+		if rev := change.GetRevision(); rev > revision {
+			revision = rev
+		}
+	}
+	return t.Applied(revision)
+}
+
+// ResyncApplied records an applied resync. Since the values of a resync
+// event can be iterated only once, the caller passes the highest revision
+// seen while applying the values.
+func (t *Tracker) ResyncApplied(revision int64) Stamp {
+	return t.Applied(revision)
+}
+
+// Current returns the stamp of the last applied configuration.
+func (t *Tracker) Current() Stamp {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
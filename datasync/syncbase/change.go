@@ -33,6 +33,15 @@ func NewChange(key string, value proto.Message, rev int64, changeType datasync.O
 	}
 }
 
+// NewLazyChange creates a new instance of Change with the value
+// unmarshaled on demand.
+func NewLazyChange(key string, value datasync.LazyValue, rev int64, changeType datasync.Op) *Change {
+	return &Change{
+		changeType: changeType,
+		KeyVal:     &KeyVal{key, value, rev},
+	}
+}
+
 // NewChangeBytes creates a new instance of NewChangeBytes.
 func NewChangeBytes(key string, value []byte, rev int64, changeType datasync.Op) *Change {
 	return &Change{
@@ -41,6 +50,14 @@ func NewChangeBytes(key string, value []byte, rev int64, changeType datasync.Op)
 	}
 }
 
+// GetProtoMessage returns the message carried by the value, if any.
+func (kv *Change) GetProtoMessage() proto.Message {
+	if value, ok := kv.KeyVal.(datasync.WithProtoMessage); ok {
+		return value.GetProtoMessage()
+	}
+	return nil
+}
+
 // GetChangeType returns type of the change.
 func (kv *Change) GetChangeType() datasync.Op {
 	return kv.changeType
@@ -67,6 +67,14 @@ func (kv *KeyVal) GetRevision() int64 {
 	return kv.rev
 }
 
+// GetProtoMessage returns the message carried by the value, if any.
+func (kv *KeyVal) GetProtoMessage() proto.Message {
+	if value, ok := kv.LazyValue.(datasync.WithProtoMessage); ok {
+		return value.GetProtoMessage()
+	}
+	return nil
+}
+
 type lazyProto struct {
 	val proto.Message
 }
@@ -79,6 +87,11 @@ func (lazy *lazyProto) GetValue(out proto.Message) error {
 	return nil
 }
 
+// GetProtoMessage returns the carried message.
+func (lazy *lazyProto) GetProtoMessage() proto.Message {
+	return lazy.val
+}
+
 // KeyValBytes represents a single key-value pair.
 type KeyValBytes struct {
 	key   string
@@ -0,0 +1,239 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Names of the supported encryption schemes
+const (
+	// SchemeRSA encrypts data directly with RSA-OAEP (default)
+	SchemeRSA = "rsa-oaep"
+	// SchemeAESGCM encrypts data with AES-GCM using random nonce
+	SchemeAESGCM = "aes-gcm"
+	// SchemeEnvelope encrypts data with random data key using AES-GCM, the data key is wrapped by master key
+	SchemeEnvelope = "envelope"
+)
+
+// dataKeySize is size of the random data keys (AES-256) used by the envelope encryption
+const dataKeySize = 32
+
+// Cipher encrypts and decrypts data using particular encryption scheme
+type Cipher interface {
+	// Encrypt encrypts input data
+	Encrypt(inData []byte) (data []byte, err error)
+	// Decrypt decrypts input data
+	Decrypt(inData []byte) (data []byte, err error)
+}
+
+// KeyWrapper wraps and unwraps data keys used by the envelope encryption (e.g. RSA master key or KMS)
+type KeyWrapper interface {
+	// WrapKey encrypts the data key with the master key
+	WrapKey(dataKey []byte) (wrapped []byte, err error)
+	// UnwrapKey decrypts the data key encrypted with the master key
+	UnwrapKey(wrapped []byte) (dataKey []byte, err error)
+}
+
+// PrefixCipher selects cipher used for values stored under the key prefix
+type PrefixCipher struct {
+	// Prefix of the keys
+	Prefix string
+	// Cipher used for values under the prefix
+	Cipher Cipher
+}
+
+// AESGCMCipher implements Cipher using AES in Galois/Counter mode. Random nonce is generated for every
+// encryption and it is prepended to the encrypted data.
+type AESGCMCipher struct {
+	aead   cipher.AEAD
+	reader io.Reader
+}
+
+// NewAESGCMCipher creates new AES-GCM cipher from provided key, which must be 16, 24 or 32 bytes long
+// to select AES-128, AES-192 or AES-256
+func NewAESGCMCipher(key []byte) (*AESGCMCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCipher{aead: aead, reader: rand.Reader}, nil
+}
+
+// Encrypt implements Cipher.Encrypt
+func (c *AESGCMCipher) Encrypt(inData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(c.reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, inData, nil), nil
+}
+
+// Decrypt implements Cipher.Decrypt
+func (c *AESGCMCipher) Decrypt(inData []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(inData) < nonceSize {
+		return nil, errors.New("failed to decrypt data due to missing nonce")
+	}
+	return c.aead.Open(nil, inData[:nonceSize], inData[nonceSize:], nil)
+}
+
+// WrapKey implements KeyWrapper.WrapKey, so that AES-GCM key can be used as master key
+func (c *AESGCMCipher) WrapKey(dataKey []byte) ([]byte, error) {
+	return c.Encrypt(dataKey)
+}
+
+// UnwrapKey implements KeyWrapper.UnwrapKey, so that AES-GCM key can be used as master key
+func (c *AESGCMCipher) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return c.Decrypt(wrapped)
+}
+
+// EnvelopeCipher implements Cipher using envelope encryption. Every value is encrypted with a new random
+// data key using AES-GCM and the data key is wrapped by the master key. The wrapped data key is stored
+// together with the encrypted data: [2 bytes wrapped key length][wrapped key][nonce][encrypted data].
+type EnvelopeCipher struct {
+	master KeyWrapper
+	reader io.Reader
+}
+
+// NewEnvelopeCipher creates new envelope cipher wrapping data keys with provided master key
+func NewEnvelopeCipher(master KeyWrapper) *EnvelopeCipher {
+	return &EnvelopeCipher{master: master, reader: rand.Reader}
+}
+
+// Encrypt implements Cipher.Encrypt
+func (c *EnvelopeCipher) Encrypt(inData []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(c.reader, dataKey); err != nil {
+		return nil, err
+	}
+	dataCipher, err := NewAESGCMCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := dataCipher.Encrypt(inData)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := c.master.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) > 0xffff {
+		return nil, errors.New("wrapped data key is too long")
+	}
+
+	data := make([]byte, 2, 2+len(wrapped)+len(encrypted))
+	binary.BigEndian.PutUint16(data, uint16(len(wrapped)))
+	data = append(data, wrapped...)
+	return append(data, encrypted...), nil
+}
+
+// Decrypt implements Cipher.Decrypt
+func (c *EnvelopeCipher) Decrypt(inData []byte) ([]byte, error) {
+	if len(inData) < 2 {
+		return nil, errors.New("failed to decrypt data due to missing data key")
+	}
+	keyLen := int(binary.BigEndian.Uint16(inData))
+	if len(inData) < 2+keyLen {
+		return nil, errors.New("failed to decrypt data due to truncated data key")
+	}
+	dataKey, err := c.master.UnwrapKey(inData[2 : 2+keyLen])
+	if err != nil {
+		return nil, err
+	}
+	dataCipher, err := NewAESGCMCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return dataCipher.Decrypt(inData[2+keyLen:])
+}
+
+// rsaCipher implements Cipher and KeyWrapper using RSA keys of the client. Data are encrypted with
+// the newest key and decrypted with any of the keys.
+type rsaCipher struct {
+	client *Client
+}
+
+// Encrypt implements Cipher.Encrypt
+func (c rsaCipher) Encrypt(inData []byte) ([]byte, error) {
+	c.client.keysLock.RLock()
+	if len(c.client.PrivateKeys) == 0 {
+		c.client.keysLock.RUnlock()
+		return nil, errors.New("failed to encrypt data due to no private key available")
+	}
+	pub := &c.client.PrivateKeys[0].PublicKey
+	c.client.keysLock.RUnlock()
+	return c.client.EncryptData(inData, pub)
+}
+
+// Decrypt implements Cipher.Decrypt
+func (c rsaCipher) Decrypt(inData []byte) ([]byte, error) {
+	return c.client.DecryptData(inData)
+}
+
+// WrapKey implements KeyWrapper.WrapKey
+func (c rsaCipher) WrapKey(dataKey []byte) ([]byte, error) {
+	return c.Encrypt(dataKey)
+}
+
+// UnwrapKey implements KeyWrapper.UnwrapKey
+func (c rsaCipher) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return c.Decrypt(wrapped)
+}
+
+// lookupCipher returns cipher registered for the longest prefix matching the key, or nil if there is none
+func lookupCipher(ciphers []PrefixCipher, key string) Cipher {
+	var (
+		found  Cipher
+		maxLen = -1
+	)
+	for _, pc := range ciphers {
+		if strings.HasPrefix(key, pc.Prefix) && len(pc.Prefix) > maxLen {
+			found, maxLen = pc.Cipher, len(pc.Prefix)
+		}
+	}
+	return found
+}
+
+// readSymmetricKey reads base64 encoded AES key from file
+func readSymmetricKey(path string) ([]byte, error) {
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode AES key from %s: %v", path, err)
+	}
+	switch len(data) {
+	case 16, 24, 32:
+		return data, nil
+	}
+	return nil, fmt.Errorf("invalid AES key length %d in %s (expected 16, 24 or 32 bytes)", len(data), path)
+}
@@ -22,6 +22,10 @@ import (
 	"io"
 	"hash"
 	"crypto/sha256"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/logging"
 )
 
 // ClientAPI handles encrypting/decrypting and wrapping data
@@ -36,19 +40,62 @@ type ClientAPI interface {
 	WrapProto(kvp keyval.KvProtoPlugin, decrypter ArbitraryDecrypter) keyval.KvProtoPlugin
 }
 
+// KeyedEncrypter is implemented by clients supporting encryption schemes selected per key prefix.
+// Resolve it from ClientAPI by type assertion.
+type KeyedEncrypter interface {
+	// EncryptDataForKey encrypts input data using the scheme selected for the key (the newest RSA key is used
+	// for the default scheme)
+	EncryptDataForKey(key string, inData []byte) (data []byte, err error)
+	// DecryptDataForKey decrypts input data using the scheme selected for the key
+	DecryptDataForKey(key string, inData []byte) (data []byte, err error)
+}
+
+// KeyRotator is implemented by clients supporting rotation of the private keys and re-encryption
+// of data encrypted with older keys. Resolve it from ClientAPI by type assertion.
+type KeyRotator interface {
+	// RotateKey makes provided private key the newest key used for re-encryption, older keys are still
+	// used for decrypting
+	RotateKey(key *rsa.PrivateKey)
+	// ReencryptData re-encrypts input data with the newest key if they were encrypted with older key
+	ReencryptData(inData []byte) (data []byte, rotated bool, err error)
+	// ReencryptBytes re-encrypts all values stored under the prefix that were encrypted with older key
+	ReencryptBytes(broker keyval.BytesBroker, prefix string, reencrypter ArbitraryReencrypter) (
+		count, skipped int, err error)
+	// ReencryptProto re-encrypts all proto values of the given type stored under the prefix that were encrypted
+	// with older key
+	ReencryptProto(broker keyval.BytesBroker, serializer keyval.Serializer, prefix string, msgType proto.Message,
+		reencrypter ArbitraryReencrypter) (count, skipped int, err error)
+}
+
 // ClientConfig is result of converting Config.PrivateKeyFile to PrivateKey
 type ClientConfig struct {
-	// Private key is used to decrypt encrypted keys while reading them from store
+	// Private key is used to decrypt encrypted keys while reading them from store. The first key
+	// is the newest one and it is used to re-encrypt data encrypted with the other keys.
 	PrivateKeys []*rsa.PrivateKey
 	// Reader used for encrypting/decrypting
 	Reader io.Reader
 	// Hash function used for hashing while encrypting
 	Hash hash.Hash
+	// LazyReencryption enables re-encryption of values encrypted with older key when they are read
+	// through the wrapped brokers
+	LazyReencryption bool
+	// Ciphers select encryption scheme for values stored under given key prefixes. Values under the other
+	// keys are encrypted directly with the RSA keys.
+	Ciphers []PrefixCipher
+	// Log is used to report values that failed to be re-encrypted lazily (optional)
+	Log logging.Logger
+	// Serializer of proto values in the wrapped KV stores, used to write back values re-encrypted
+	// lazily (default is keyval.SerializerJSON, the default of the KV store plugins)
+	Serializer keyval.Serializer
 }
 
-// Client implements ClientAPI and ClientConfig
+// Client implements ClientAPI, KeyedEncrypter, KeyRotator and ClientConfig
 type Client struct {
 	ClientConfig
+	// keysLock guards private keys that may be replaced while the client is in use
+	keysLock sync.RWMutex
+	// lazyStats counts values re-encrypted lazily by all wrappers created by the client
+	lazyStats reencryptCounter
 }
 
 // NewClient creates new client from provided config and reader
@@ -67,6 +114,10 @@ func NewClient(clientConfig ClientConfig) *Client {
 		client.Hash = sha256.New()
 	}
 
+	if clientConfig.Serializer == nil {
+		client.Serializer = &keyval.SerializerJSON{}
+	}
+
 	return client
 }
 
@@ -75,8 +126,19 @@ func (client *Client) EncryptData(inData []byte, pub *rsa.PublicKey) (data []byt
 	return rsa.EncryptOAEP(client.Hash, client.Reader, pub, inData, nil)
 }
 
+// SetPrivateKeys replaces private keys used for decrypting data (e.g. after they were refreshed
+// from an external key store)
+func (client *Client) SetPrivateKeys(keys []*rsa.PrivateKey) {
+	client.keysLock.Lock()
+	defer client.keysLock.Unlock()
+	client.PrivateKeys = keys
+}
+
 // DecryptData implements ClientAPI.DecryptData
 func (client *Client) DecryptData(inData []byte) (data []byte, err error) {
+	client.keysLock.RLock()
+	defer client.keysLock.RUnlock()
+
 	for _, key := range client.PrivateKeys {
 		data, err := rsa.DecryptOAEP(client.Hash, client.Reader, key, inData, nil)
 
@@ -88,12 +150,92 @@ func (client *Client) DecryptData(inData []byte) (data []byte, err error) {
 	return nil, errors.New("failed to decrypt data due to no private key matching")
 }
 
+// EncryptDataForKey implements KeyedEncrypter.EncryptDataForKey
+func (client *Client) EncryptDataForKey(key string, inData []byte) (data []byte, err error) {
+	return client.CipherForKey(key).Encrypt(inData)
+}
+
+// DecryptDataForKey implements KeyedEncrypter.DecryptDataForKey
+func (client *Client) DecryptDataForKey(key string, inData []byte) (data []byte, err error) {
+	return client.CipherForKey(key).Decrypt(inData)
+}
+
+// CipherForKey returns cipher used for the value stored under the key. Cipher registered for the longest
+// matching prefix is returned, or the cipher using RSA keys of the client if there is none.
+func (client *Client) CipherForKey(key string) Cipher {
+	if c := lookupCipher(client.Ciphers, key); c != nil {
+		return c
+	}
+	return rsaCipher{client: client}
+}
+
+// RotateKey implements KeyRotator.RotateKey
+func (client *Client) RotateKey(key *rsa.PrivateKey) {
+	client.keysLock.Lock()
+	defer client.keysLock.Unlock()
+	client.PrivateKeys = append([]*rsa.PrivateKey{key}, client.PrivateKeys...)
+}
+
+// ReencryptData implements KeyRotator.ReencryptData
+func (client *Client) ReencryptData(inData []byte) (data []byte, rotated bool, err error) {
+	client.keysLock.RLock()
+	defer client.keysLock.RUnlock()
+
+	for i, key := range client.PrivateKeys {
+		data, err := rsa.DecryptOAEP(client.Hash, client.Reader, key, inData, nil)
+		if err != nil {
+			continue
+		}
+		if i == 0 {
+			// already encrypted with the newest key
+			return inData, false, nil
+		}
+		data, err = client.EncryptData(data, &client.PrivateKeys[0].PublicKey)
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	}
+
+	return nil, false, errors.New("failed to re-encrypt data due to no private key matching")
+}
+
 // WrapBytes implements ClientAPI.WrapBytes
 func (client *Client) WrapBytes(cbw keyval.KvBytesPlugin, decrypter ArbitraryDecrypter) keyval.KvBytesPlugin {
-	return NewKvBytesPluginWrapper(cbw, decrypter, client.DecryptData)
+	wrapper := NewKvBytesPluginWrapper(cbw, decrypter, client.DecryptData)
+	if client.LazyReencryption {
+		wrapper.stats = &client.lazyStats
+		wrapper.WithLazyReencryption(client.ReencryptData).WithLogger(client.Log)
+	}
+	if len(client.Ciphers) > 0 {
+		wrapper.WithKeyCiphers(client.prefixCipher)
+	}
+	return wrapper
 }
 
-// WrapProto implements ClientAPI.WrapProto
+// WrapProto implements ClientAPI.WrapProto. Values re-encrypted lazily are written back only if the plugin
+// provides access to the underlying bytes store (RawAccess method, e.g. etcd plugin).
 func (client *Client) WrapProto(kvp keyval.KvProtoPlugin, decrypter ArbitraryDecrypter) keyval.KvProtoPlugin {
-	return NewKvProtoPluginWrapper(kvp, decrypter, client.DecryptData)
+	wrapper := NewKvProtoPluginWrapper(kvp, decrypter, client.DecryptData)
+	if client.LazyReencryption {
+		wrapper.stats = &client.lazyStats
+		wrapper.WithLazyReencryption(client.ReencryptData).WithLogger(client.Log)
+		if raw, ok := kvp.(interface{ RawAccess() keyval.KvBytesPlugin }); ok {
+			wrapper.WithRawAccess(raw.RawAccess(), client.Serializer)
+		}
+	}
+	if len(client.Ciphers) > 0 {
+		wrapper.WithKeyCiphers(client.prefixCipher)
+	}
+	return wrapper
+}
+
+// LazyReencryptStats returns numbers of values re-encrypted lazily by all wrappers created by the client.
+func (client *Client) LazyReencryptStats() ReencryptStats {
+	return client.lazyStats.get()
+}
+
+// prefixCipher returns cipher registered for the key prefix, or nil for the default scheme
+func (client *Client) prefixCipher(key string) Cipher {
+	return lookupCipher(client.Ciphers, key)
 }
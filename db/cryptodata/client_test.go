@@ -0,0 +1,278 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/kvtest"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestReencryptData(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}})
+
+	encrypted, err := client.EncryptData([]byte("secret"), &oldKey.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	// encrypted with the newest key
+	data, rotated, err := client.ReencryptData(encrypted)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rotated).To(BeFalse())
+	Expect(data).To(Equal(encrypted))
+
+	newKey := generateKey(t)
+	client.RotateKey(newKey)
+
+	data, rotated, err = client.ReencryptData(encrypted)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rotated).To(BeTrue())
+
+	decrypted, err := rsa.DecryptOAEP(client.Hash, rand.Reader, newKey, data, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(string(decrypted)).To(Equal("secret"))
+}
+
+func TestReencryptJSON(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}})
+
+	encrypted, err := client.EncryptData([]byte("secret"), &oldKey.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+	value := []byte(`{"encrypted":true,"value":{"payload":"$crypto$` +
+		base64.URLEncoding.EncodeToString(encrypted) + `"}}`)
+
+	decrypter := NewDecrypterJSON()
+	_, rotated, err := decrypter.Reencrypt(value, client.ReencryptData)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rotated).To(BeFalse())
+
+	client.RotateKey(generateKey(t))
+	reencrypted, rotated, err := decrypter.Reencrypt(value, client.ReencryptData)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rotated).To(BeTrue())
+	Expect(reencrypted).ToNot(Equal(value))
+
+	// re-encrypted value can be decrypted only with the newest key
+	client.SetPrivateKeys(client.PrivateKeys[:1])
+	decrypted, err := decrypter.Decrypt(reencrypted, client.DecryptData)
+	Expect(err).ToNot(HaveOccurred())
+
+	var parsed struct {
+		Value struct {
+			Payload string `json:"payload"`
+		} `json:"value"`
+	}
+	Expect(json.Unmarshal(decrypted.([]byte), &parsed)).To(Succeed())
+	Expect(parsed.Value.Payload).To(Equal("secret"))
+}
+
+func TestLazyReencryptionStats(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}, LazyReencryption: true})
+
+	encrypted, err := client.EncryptData([]byte("secret"), &oldKey.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+	value := []byte(`{"encrypted":true,"value":{"payload":"$crypto$` +
+		base64.URLEncoding.EncodeToString(encrypted) + `"}}`)
+
+	store := kvtest.NewStore()
+	Expect(store.Put("/vnf/secret", value)).To(Succeed())
+	broker := client.WrapBytes(store, NewDecrypterJSON()).NewBroker("/vnf/")
+	client.RotateKey(generateKey(t))
+
+	// failure to write the value back does not fail the read
+	store.InjectFault(kvtest.Fault{Op: kvtest.OpPut, Err: errors.New("put failed"), Times: 1})
+	data, found, _, err := broker.GetValue("secret")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(string(data)).To(ContainSubstring(`"payload":"secret"`))
+	Expect(client.LazyReencryptStats()).To(Equal(ReencryptStats{Failed: 1}))
+
+	_, _, _, err = broker.GetValue("secret")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(client.LazyReencryptStats()).To(Equal(ReencryptStats{Reencrypted: 1, Failed: 1}))
+
+	stored, _, _, err := store.GetValue("/vnf/secret")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(stored).ToNot(Equal(value))
+}
+
+// bytesBroker hides CompareAndSwap of the wrapped broker.
+type bytesBroker struct {
+	keyval.BytesBroker
+}
+
+func TestLazyReencryptionSkipsChangedValues(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}, LazyReencryption: true})
+	value := encryptJSON(t, client, &oldKey.PublicKey)
+
+	store := kvtest.NewStore()
+	Expect(store.Put("/vnf/secret", value)).To(Succeed())
+	broker := client.WrapBytes(store, NewDecrypterJSON()).NewBroker("/vnf/").(*BytesBrokerWrapper)
+	client.RotateKey(generateKey(t))
+
+	// the value was changed after it was read
+	Expect(store.Put("/vnf/secret", []byte(`{"changed":true}`))).To(Succeed())
+	broker.reencrypt("secret", value)
+	stored, _, _, _ := store.GetValue("/vnf/secret")
+	Expect(string(stored)).To(Equal(`{"changed":true}`))
+	Expect(client.LazyReencryptStats()).To(Equal(ReencryptStats{Skipped: 1}))
+
+	// the store does not support compare-and-swap
+	Expect(store.Put("/vnf/secret", value)).To(Succeed())
+	broker.BytesBroker = bytesBroker{broker.BytesBroker}
+	_, _, _, err := broker.GetValue("secret")
+	Expect(err).ToNot(HaveOccurred())
+	stored, _, _, _ = store.GetValue("/vnf/secret")
+	Expect(stored).To(Equal(value))
+	Expect(client.LazyReencryptStats()).To(Equal(ReencryptStats{Skipped: 2}))
+}
+
+func TestLazyReencryptionProto(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}, LazyReencryption: true})
+	encrypted, err := client.EncryptData([]byte("secret"), &oldKey.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	decrypter := NewDecrypterProto()
+	decrypter.RegisterMapping(&status.PluginStatus{}, []string{"Error"})
+	store := kvtest.NewStore()
+	plugin := kvtest.NewPlugin(store)
+	Expect(plugin.NewBroker("/vnf/").Put("secret", &status.PluginStatus{
+		Name:  "secret",
+		Error: base64.URLEncoding.EncodeToString(encrypted),
+	})).To(Succeed())
+	stored, _, _, _ := store.GetValue("/vnf/secret")
+
+	broker := client.WrapProto(plugin, decrypter).NewBroker("/vnf/")
+	client.RotateKey(generateKey(t))
+
+	var value status.PluginStatus
+	found, _, err := broker.GetValue("secret", &value)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(value.Error).To(Equal("secret"))
+	Expect(client.LazyReencryptStats()).To(Equal(ReencryptStats{Reencrypted: 1}))
+
+	reencrypted, _, _, _ := store.GetValue("/vnf/secret")
+	Expect(reencrypted).ToNot(Equal(stored))
+
+	// without raw access the value is not written back
+	plain := NewKvProtoPluginWrapper(plugin, decrypter, client.DecryptData).
+		WithLazyReencryption(client.ReencryptData)
+	client.RotateKey(generateKey(t))
+	_, _, err = plain.NewBroker("/vnf/").GetValue("secret", &value)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(plain.ReencryptStats()).To(Equal(ReencryptStats{Skipped: 1}))
+}
+
+func TestSweepReencryptBytes(t *testing.T) {
+	RegisterTestingT(t)
+
+	oldKey := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{oldKey}})
+	store := kvtest.NewStore()
+	Expect(store.Put("/vnf/a", encryptJSON(t, client, &oldKey.PublicKey))).To(Succeed())
+	Expect(store.Put("/vnf/b", encryptJSON(t, client, &oldKey.PublicKey))).To(Succeed())
+	client.RotateKey(generateKey(t))
+
+	// without compare-and-swap all values are skipped
+	count, skipped, err := client.ReencryptBytes(bytesBroker{store.NewBroker("/vnf/")}, "", NewDecrypterJSON())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(count).To(Equal(0))
+	Expect(skipped).To(Equal(2))
+
+	count, skipped, err = client.ReencryptBytes(store.NewBroker("/vnf/"), "", NewDecrypterJSON())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(count).To(Equal(2))
+	Expect(skipped).To(Equal(0))
+}
+
+// encryptJSON returns JSON value with payload encrypted with the key.
+func encryptJSON(t *testing.T, client *Client, key *rsa.PublicKey) []byte {
+	encrypted, err := client.EncryptData([]byte("secret"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(`{"encrypted":true,"value":{"payload":"$crypto$` +
+		base64.URLEncoding.EncodeToString(encrypted) + `"}}`)
+}
+
+func TestPrefixCiphers(t *testing.T) {
+	RegisterTestingT(t)
+
+	aesKey := make([]byte, 32)
+	_, err := rand.Read(aesKey)
+	Expect(err).ToNot(HaveOccurred())
+	aesCipher, err := NewAESGCMCipher(aesKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{generateKey(t)}})
+	client.Ciphers = []PrefixCipher{
+		{Prefix: "/secrets/", Cipher: aesCipher},
+		{Prefix: "/secrets/envelope/", Cipher: NewEnvelopeCipher(rsaCipher{client: client})},
+		{Prefix: "/secrets/rsa/", Cipher: nil},
+	}
+
+	Expect(client.CipherForKey("/secrets/a")).To(Equal(aesCipher))
+	Expect(client.CipherForKey("/secrets/envelope/a")).To(BeAssignableToTypeOf(&EnvelopeCipher{}))
+	Expect(client.CipherForKey("/secrets/rsa/a")).To(BeAssignableToTypeOf(rsaCipher{}))
+	Expect(client.CipherForKey("/other")).To(BeAssignableToTypeOf(rsaCipher{}))
+
+	for _, key := range []string{"/secrets/a", "/secrets/envelope/a", "/secrets/rsa/a"} {
+		encrypted, err := client.EncryptDataForKey(key, []byte("secret"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encrypted).ToNot(ContainSubstring("secret"))
+
+		decrypted, err := client.DecryptDataForKey(key, encrypted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(decrypted)).To(Equal("secret"))
+	}
+
+	// authenticated encryption detects tampering
+	encrypted, err := client.EncryptDataForKey("/secrets/a", []byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = client.DecryptDataForKey("/secrets/a", encrypted)
+	Expect(err).To(HaveOccurred())
+}
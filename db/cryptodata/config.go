@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ligato/cn-infra/config"
+)
+
+const (
+	// ConfigMarkerPrefix starts encrypted value in configuration file
+	ConfigMarkerPrefix = "$crypto{"
+	// ConfigMarkerSuffix ends encrypted value in configuration file
+	ConfigMarkerSuffix = "}"
+)
+
+// ConfigDecrypter is config.PluginConfig decorator that decrypts string values marked as
+// `$crypto{<base64 encoded encrypted data>}` after the configuration is loaded.
+type ConfigDecrypter struct {
+	config.PluginConfig
+	decryptFunc DecryptFunc
+}
+
+// NewConfigDecrypter wraps provided plugin config with decrypting of encrypted values using decryptFunc
+func NewConfigDecrypter(cfg config.PluginConfig, decryptFunc DecryptFunc) *ConfigDecrypter {
+	return &ConfigDecrypter{
+		PluginConfig: cfg,
+		decryptFunc:  decryptFunc,
+	}
+}
+
+// LoadValue loads configuration using the wrapped plugin config and decrypts all string values
+// (including values nested in structures, slices and maps) marked as encrypted.
+func (c *ConfigDecrypter) LoadValue(data interface{}) (found bool, err error) {
+	found, err = c.PluginConfig.LoadValue(data)
+	if !found || err != nil {
+		return found, err
+	}
+	if err = c.decryptValue(reflect.ValueOf(data)); err != nil {
+		return found, fmt.Errorf("failed to decrypt config %s: %v", c.GetConfigName(), err)
+	}
+	return found, nil
+}
+
+// decryptValue recursively navigates value and decrypts all marked string values
+func (c *ConfigDecrypter) decryptValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			if s, ok := v.Interface().(string); ok && v.CanSet() {
+				decrypted, err := c.decryptString(s)
+				if err != nil {
+					return err
+				}
+				v.Set(reflect.ValueOf(decrypted))
+				return nil
+			}
+		}
+		return c.decryptValue(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// unexported field
+				continue
+			}
+			if err := c.decryptValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := c.decryptValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// map values are not addressable, decrypt a copy and store it back
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := c.decryptValue(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		decrypted, err := c.decryptString(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(decrypted)
+	}
+	return nil
+}
+
+// decryptString decrypts the string if it is marked as encrypted, otherwise it is returned unchanged
+func (c *ConfigDecrypter) decryptString(s string) (string, error) {
+	if !strings.HasPrefix(s, ConfigMarkerPrefix) || !strings.HasSuffix(s, ConfigMarkerSuffix) {
+		return s, nil
+	}
+	encoded := strings.TrimSuffix(strings.TrimPrefix(s, ConfigMarkerPrefix), ConfigMarkerSuffix)
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return "", err
+		}
+	}
+	decrypted, err := c.decryptFunc(data)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/config"
+)
+
+type testConfig struct {
+	Endpoint string            `json:"endpoint"`
+	Password string            `json:"password"`
+	Users    []testUser        `json:"users"`
+	Extra    map[string]string `json:"extra"`
+}
+
+type testUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+type fileConfig string
+
+func (f fileConfig) LoadValue(data interface{}) (bool, error) {
+	return true, config.ParseConfigFromYamlFile(string(f), data)
+}
+
+func (f fileConfig) GetConfigName() string {
+	return string(f)
+}
+
+func TestConfigDecrypter(t *testing.T) {
+	RegisterTestingT(t)
+
+	key := generateKey(t)
+	client := NewClient(ClientConfig{PrivateKeys: []*rsa.PrivateKey{key}})
+	encrypt := func(s string) string {
+		data, err := client.EncryptData([]byte(s), &key.PublicKey)
+		Expect(err).ToNot(HaveOccurred())
+		return ConfigMarkerPrefix + base64.URLEncoding.EncodeToString(data) + ConfigMarkerSuffix
+	}
+
+	file, err := ioutil.TempFile("", "cryptodata-config")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`
+endpoint: 127.0.0.1:9191
+password: "` + encrypt("pass1") + `"
+users:
+  - name: admin
+    password: "` + encrypt("pass2") + `"
+extra:
+  token: "` + encrypt("pass3") + `"
+`)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(file.Close()).To(Succeed())
+
+	var cfg testConfig
+	found, err := NewConfigDecrypter(fileConfig(file.Name()), client.DecryptData).LoadValue(&cfg)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(cfg.Endpoint).To(Equal("127.0.0.1:9191"))
+	Expect(cfg.Password).To(Equal("pass1"))
+	Expect(cfg.Users).To(HaveLen(1))
+	Expect(cfg.Users[0].Password).To(Equal("pass2"))
+	Expect(cfg.Extra["token"]).To(Equal("pass3"))
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cryptodatarest exposes re-encryption of values stored with older
+// cryptodata keys over REST, so that the operator can trigger the sweep once
+// the key was rotated and check progress of the lazy re-encryption.
+//
+// GET  /cryptodata/reencrypt  returns numbers of values re-encrypted lazily,
+// POST /cryptodata/reencrypt  runs all sweeps registered by cryptodata.Plugin.RegisterSweep.
+package cryptodatarest
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodatarest
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/db/cryptodata"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+// ReencryptPath is URL path used to trigger re-encryption and read its statistics.
+const ReencryptPath = "/cryptodata/reencrypt"
+
+// ErrSweepUnavailable is the class of errors returned when the re-encryption
+// sweep cannot be run (i.e. the cryptodata plugin is disabled).
+var ErrSweepUnavailable = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "cryptodata/sweep-unavailable",
+	Plugin:     "cryptodata-rest",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusServiceUnavailable,
+})
+
+// Plugin registers REST handlers of the cryptodata re-encryption.
+type Plugin struct {
+	Deps
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	HTTP   rest.HTTPHandlers // inject
+	Crypto *cryptodata.Plugin
+}
+
+// SweepResponse is returned after the registered sweeps were run.
+type SweepResponse struct {
+	Sweeps []cryptodata.SweepResult  `json:"sweeps"`
+	Lazy   cryptodata.ReencryptStats `json:"lazy"`
+}
+
+// Init registers the REST handlers.
+func (p *Plugin) Init() error {
+	if p.HTTP == nil || p.Crypto == nil {
+		p.Log.Info("Unable to register cryptodata handlers, HTTP or Crypto is nil")
+		return nil
+	}
+	p.HTTP.RegisterHTTPHandler(ReencryptPath, p.statsHandler, http.MethodGet)
+	p.HTTP.RegisterHTTPHandler(ReencryptPath, p.sweepHandler, http.MethodPost)
+
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// statsHandler returns numbers of values re-encrypted lazily.
+func (p *Plugin) statsHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, p.Crypto.ReencryptStats())
+	}
+}
+
+// sweepHandler runs all registered sweeps.
+func (p *Plugin) sweepHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		results, err := p.Crypto.Sweep()
+		if err != nil {
+			rest.WriteError(formatter, w, ErrSweepUnavailable.Wrap(err))
+			return
+		}
+		formatter.JSON(w, http.StatusOK, SweepResponse{
+			Sweeps: results,
+			Lazy:   p.Crypto.ReencryptStats(),
+		})
+	}
+}
@@ -27,6 +27,10 @@ import (
 // DecryptFunc is function that decrypts input data
 type DecryptFunc func(inData []byte) (data []byte, err error)
 
+// ReencryptFunc is function that re-encrypts input data with the newest key. Rotated is false if the data were
+// already encrypted with the newest key and were returned unchanged.
+type ReencryptFunc func(inData []byte) (data []byte, rotated bool, err error)
+
 // ArbitraryDecrypter represents decrypter that looks for encrypted values inside arbitrary data and returns
 // the data with the values decrypted
 type ArbitraryDecrypter interface {
@@ -36,6 +40,55 @@ type ArbitraryDecrypter interface {
 	Decrypt(inData interface{}, decryptFunc DecryptFunc) (data interface{}, err error)
 }
 
+// ArbitraryReencrypter is ArbitraryDecrypter that is also able to re-encrypt the encrypted values found inside
+// arbitrary data, keeping them encrypted and encoded the same way as before
+type ArbitraryReencrypter interface {
+	ArbitraryDecrypter
+	// Reencrypt processes input data and re-encrypts specific fields using reencryptFunc. Rotated is true if at
+	// least one field was re-encrypted.
+	Reencrypt(inData interface{}, reencryptFunc ReencryptFunc) (data interface{}, rotated bool, err error)
+}
+
+// transformFunc transforms single encrypted string value found in arbitrary data
+type transformFunc func(value string) (string, error)
+
+// decryptTransform returns transformFunc which decodes and decrypts the value
+func decryptTransform(decryptFunc DecryptFunc) transformFunc {
+	return func(value string) (string, error) {
+		decoded, err := base64.URLEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+
+		decrypted, err := decryptFunc(decoded)
+		if err != nil {
+			return "", err
+		}
+		return string(decrypted), nil
+	}
+}
+
+// reencryptTransform returns transformFunc which decodes, re-encrypts and encodes the value back. Rotated
+// is set to true whenever any value was actually re-encrypted.
+func reencryptTransform(reencryptFunc ReencryptFunc, prefix string, rotated *bool) transformFunc {
+	return func(value string) (string, error) {
+		decoded, err := base64.URLEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+
+		reencrypted, changed, err := reencryptFunc(decoded)
+		if err != nil {
+			return "", err
+		}
+		if !changed {
+			return prefix + value, nil
+		}
+		*rotated = true
+		return prefix + base64.URLEncoding.EncodeToString(reencrypted), nil
+	}
+}
+
 // EncryptionCheck is used to check for data to contain encrypted marker
 type EncryptionCheck struct {
 	// IsEncrypted returns true if data was marked as encrypted
@@ -88,7 +141,7 @@ func (d DecrypterJSON) Decrypt(object interface{}, decryptFunc DecryptFunc) (int
 		return nil, err
 	}
 
-	err = d.decryptJSON(jsonData, decryptFunc)
+	err = d.processJSON(jsonData, decryptTransform(decryptFunc))
 	if err != nil {
 		return nil, err
 	}
@@ -96,26 +149,47 @@ func (d DecrypterJSON) Decrypt(object interface{}, decryptFunc DecryptFunc) (int
 	return json.Marshal(jsonData)
 }
 
-// decryptJSON recursively navigates JSON structure and tries to decrypt all string values with Prefix
-func (d DecrypterJSON) decryptJSON(data map[string]interface{}, decryptFunc DecryptFunc) error {
+// Reencrypt tries to find encrypted values in JSON data and re-encrypt them using provided re-encrypt function.
+// The re-encrypted values are base64 encoded and prefixed with `Prefix` again. If no value was re-encrypted,
+// the input data are returned unchanged.
+// This function can accept only []byte and return []byte
+func (d DecrypterJSON) Reencrypt(object interface{}, reencryptFunc ReencryptFunc) (interface{}, bool, error) {
+	if !d.IsEncrypted(object) {
+		return object, false, nil
+	}
+
+	inData := object.([]byte)
+	var jsonData map[string]interface{}
+	err := json.Unmarshal(inData, &jsonData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var rotated bool
+	err = d.processJSON(jsonData, reencryptTransform(reencryptFunc, d.prefix, &rotated))
+	if err != nil || !rotated {
+		return object, false, err
+	}
+
+	outData, err := json.Marshal(jsonData)
+	return outData, err == nil, err
+}
+
+// processJSON recursively navigates JSON structure and transforms all string values with Prefix
+func (d DecrypterJSON) processJSON(data map[string]interface{}, transform transformFunc) error {
 	for k, v := range data {
 		switch t := v.(type) {
 		case string:
 			if s := strings.TrimPrefix(t, d.prefix); s != t {
-				s, err := base64.URLEncoding.DecodeString(s)
+				transformed, err := transform(s)
 				if err != nil {
 					return err
 				}
 
-				decryptedData, err := decryptFunc(s)
-				if err != nil {
-					return err
-				}
-
-				data[k] = string(decryptedData)
+				data[k] = transformed
 			}
 		case map[string]interface{}:
-			err := d.decryptJSON(t, decryptFunc)
+			err := d.processJSON(t, transform)
 			if err != nil {
 				return err
 			}
@@ -162,7 +236,7 @@ func (d DecrypterProto) Decrypt(object interface{}, decryptFunc DecryptFunc) (in
 	}
 
 	for _, path := range d.mapping[reflect.TypeOf(object)] {
-		if err := d.decryptStruct(object, path, decryptFunc); err != nil {
+		if err := d.processStruct(object, path, decryptTransform(decryptFunc)); err != nil {
 			return nil, err
 		}
 	}
@@ -170,8 +244,26 @@ func (d DecrypterProto) Decrypt(object interface{}, decryptFunc DecryptFunc) (in
 	return object, nil
 }
 
-// decryptStruct recursively tries to decrypt fields in object on provided path using provided decryptFunc
-func (d DecrypterProto) decryptStruct(object interface{}, path []string, decryptFunc DecryptFunc) error {
+// Reencrypt tries to find encrypted values in protobuf data and re-encrypt them using provided re-encrypt
+// function. The re-encrypted values are base64 encoded again and are stored directly into the input data.
+// This function can accept only proto.Message and return proto.Message
+func (d DecrypterProto) Reencrypt(object interface{}, reencryptFunc ReencryptFunc) (interface{}, bool, error) {
+	if !d.IsEncrypted(object) {
+		return object, false, nil
+	}
+
+	var rotated bool
+	for _, path := range d.mapping[reflect.TypeOf(object)] {
+		if err := d.processStruct(object, path, reencryptTransform(reencryptFunc, "", &rotated)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return object, rotated, nil
+}
+
+// processStruct recursively tries to transform fields in object on provided path using provided transform
+func (d DecrypterProto) processStruct(object interface{}, path []string, transform transformFunc) error {
 	v, ok := object.(reflect.Value)
 	if !ok {
 		v = reflect.ValueOf(object)
@@ -196,7 +288,7 @@ func (d DecrypterProto) decryptStruct(object interface{}, path []string, decrypt
 					index++
 				}
 
-				if err := d.decryptStruct(val, path[index:], decryptFunc); err != nil {
+				if err := d.processStruct(val, path[index:], transform); err != nil {
 					return err
 				}
 			}
@@ -210,17 +302,12 @@ func (d DecrypterProto) decryptStruct(object interface{}, path []string, decrypt
 				continue
 			}
 
-			decoded, err := base64.URLEncoding.DecodeString(val)
-			if err != nil {
-				return err
-			}
-
-			decrypted, err := decryptFunc(decoded)
+			transformed, err := transform(val)
 			if err != nil {
 				return err
 			}
 
-			v.SetString(string(decrypted))
+			v.SetString(transformed)
 			return nil
 		}
 
@@ -14,4 +14,23 @@
 
 // Package cryptodata provides support for wrapping key-value store with
 // crypto layer that will automatically decrypt all data passing through.
+//
+// The plugin can be also used to decrypt secrets stored in configuration files
+// of other plugins. Values marked as `$crypto{<base64 encoded encrypted data>}`
+// are decrypted when the configuration is loaded by the wrapped plugin config:
+//
+//   etcdPlugin := etcd.NewPlugin(etcd.UseDeps(func(deps *etcd.Deps) {
+//       deps.Cfg = cryptodata.DefaultPlugin.WrapConfig(config.ForPlugin("etcd"))
+//   }))
+//
+// After the key was rotated, values encrypted with older keys are re-encrypted
+// lazily when read (lazy-reencryption option) and by sweeps registered with
+// RegisterSweep. Re-encrypted values are written back with compare-and-swap,
+// values changed meanwhile or stored in a store without compare-and-swap support
+// are skipped (and counted). Sweeps can be triggered by the operator over REST
+// using the cryptodatarest plugin:
+//
+//   cryptodata.DefaultPlugin.RegisterSweep("vnf", func() (int, int, error) {
+//       return cryptodata.DefaultPlugin.ReencryptBytes(rawBroker, "/vnf/", cryptodata.NewDecrypterJSON())
+//   })
 package cryptodata
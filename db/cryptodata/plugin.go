@@ -15,17 +15,46 @@
 package cryptodata
 
 import (
+	"context"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"sync"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/config"
+	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/utils/once"
 )
 
 // Config is used to read private key from file
 type Config struct {
-	// Private key file is used to create rsa.PrivateKey from this PEM path
+	// Private key file is used to create rsa.PrivateKey from this PEM path. The first key is considered
+	// to be the newest one and values encrypted with other keys are re-encrypted with it.
 	PrivateKeyFiles []string `json:"private-key-files"`
+	// LazyReencryption enables re-encryption of values encrypted with older key when they are read
+	LazyReencryption bool `json:"lazy-reencryption"`
+	// Vault is used to fetch private keys from HashiCorp Vault (optional). Keys from Vault precede
+	// the keys from private key files, so that a new key published in Vault becomes the newest one.
+	Vault *VaultConfig `json:"vault"`
+	// Schemes select encryption scheme for values stored under given key prefixes
+	Schemes []SchemeConfig `json:"schemes"`
+}
+
+// SchemeConfig selects encryption scheme for values stored under the key prefix
+type SchemeConfig struct {
+	// Prefix of the keys
+	Prefix string `json:"prefix"`
+	// Scheme is one of rsa-oaep (default), aes-gcm or envelope
+	Scheme string `json:"scheme"`
+	// KeyFile is path to file with base64 encoded AES key. It is required by the aes-gcm scheme,
+	// for the envelope scheme it is used as master key instead of RSA keys if set.
+	KeyFile string `json:"key-file"`
 }
 
 // Deps lists dependencies of the cryptodata plugin.
@@ -39,10 +68,50 @@ type Plugin struct {
 	ClientAPI
 	// Plugin is disabled if there is no config file available
 	disabled bool
+	// initOnce allows to initialize the plugin before Init when decrypting configuration of other plugins
+	initOnce once.ReturnError
+
+	client      *Client
+	vault       *VaultClient
+	keysLock    sync.Mutex
+	rotatedKeys []*rsa.PrivateKey
+	fileKeys    []*rsa.PrivateKey
+	vaultKeys   []*rsa.PrivateKey
+
+	loop infra.EventLoop
+
+	sweepsLock sync.Mutex
+	sweeps     []namedSweep
+}
+
+// namedSweep is a sweep registered by RegisterSweep
+type namedSweep struct {
+	name  string
+	sweep SweepFunc
 }
 
 // Init initializes cryptodata plugin.
 func (p *Plugin) Init() (err error) {
+	return p.initOnce.Do(p.init)
+}
+
+// WrapConfig wraps plugin config with decrypting of values marked as `$crypto{...}`. It allows to store
+// passwords and other secrets in plugin configuration files encrypted. Because configuration is usually loaded
+// in Init, which may be called before Init of this plugin, the plugin is initialized on the first use.
+func (p *Plugin) WrapConfig(cfg config.PluginConfig) config.PluginConfig {
+	return NewConfigDecrypter(cfg, func(inData []byte) ([]byte, error) {
+		if err := p.Init(); err != nil {
+			return nil, err
+		}
+		if p.disabled {
+			return nil, errors.New("cryptodata plugin is disabled, encrypted config value cannot be decrypted")
+		}
+		return p.DecryptData(inData)
+	})
+}
+
+// init loads configuration and private keys.
+func (p *Plugin) init() (err error) {
 	var config Config
 	found, err := p.Cfg.LoadValue(&config)
 	if err != nil {
@@ -56,7 +125,10 @@ func (p *Plugin) Init() (err error) {
 	}
 
 	// Read client config and create it
-	clientConfig := ClientConfig{}
+	clientConfig := ClientConfig{
+		LazyReencryption: config.LazyReencryption,
+		Log:              p.Log,
+	}
 	for _, file := range config.PrivateKeyFiles {
 		bytes, err := ioutil.ReadFile(file)
 		if err != nil {
@@ -64,41 +136,58 @@ func (p *Plugin) Init() (err error) {
 			return err
 		}
 
-		for {
-			block, rest := pem.Decode(bytes)
-			if block == nil {
-				break
-			}
-
-			privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
-			if err != nil {
-				p.Log.Infof("%v", err)
-				return err
-			}
-
-			err = privateKey.Validate()
-			if err != nil {
-				p.Log.Infof("%v", err)
-				return err
-			}
+		keys, err := parsePrivateKeys(bytes)
+		if err != nil {
+			p.Log.Infof("%v", err)
+			return err
+		}
+		p.fileKeys = append(p.fileKeys, keys...)
+	}
 
-			privateKey.Precompute()
-			clientConfig.PrivateKeys = append(clientConfig.PrivateKeys, privateKey)
+	// Fetch keys stored in Vault
+	if config.Vault != nil {
+		if p.vault, err = NewVaultClient(*config.Vault); err != nil {
+			return err
+		}
+		if err = p.vault.Login(); err != nil {
+			p.Log.Errorf("vault login failed: %v", err)
+			return err
+		}
+		if p.vaultKeys, err = p.vault.ReadPrivateKeys(); err != nil {
+			p.Log.Errorf("reading private keys from vault failed: %v", err)
+			return err
+		}
+		p.Log.Infof("loaded %d private key(s) from vault", len(p.vaultKeys))
+	}
 
-			if rest == nil {
-				break
-			}
+	p.keysLock.Lock()
+	clientConfig.PrivateKeys = p.privateKeys()
+	p.client = NewClient(clientConfig)
+	p.keysLock.Unlock()
+	p.ClientAPI = p.client
 
-			bytes = rest
+	// Prepare ciphers for prefixes with other than default scheme
+	for _, scheme := range config.Schemes {
+		c, err := p.newCipher(scheme)
+		if err != nil {
+			p.Log.Errorf("failed to prepare %s scheme for prefix %s: %v", scheme.Scheme, scheme.Prefix, err)
+			return err
 		}
+		p.client.Ciphers = append(p.client.Ciphers, PrefixCipher{Prefix: scheme.Prefix, Cipher: c})
 	}
 
-	p.ClientAPI = NewClient(clientConfig)
+	if p.vault != nil {
+		p.loop.Log = p.Log
+		p.loop.Go(func(ctx context.Context) {
+			p.watchVault(ctx, config.Vault.RefreshInterval)
+		})
+	}
 	return
 }
 
 // Close closes cryptodata plugin.
 func (p *Plugin) Close() error {
+	p.loop.Stop()
 	return nil
 }
 
@@ -106,3 +195,229 @@ func (p *Plugin) Close() error {
 func (p *Plugin) Disabled() bool {
 	return p.disabled
 }
+
+// newCipher creates cipher for the scheme configuration, nil cipher is returned for the default scheme
+func (p *Plugin) newCipher(scheme SchemeConfig) (Cipher, error) {
+	var symmetric *AESGCMCipher
+	if scheme.KeyFile != "" {
+		key, err := readSymmetricKey(scheme.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if symmetric, err = NewAESGCMCipher(key); err != nil {
+			return nil, err
+		}
+	}
+
+	switch scheme.Scheme {
+	case "", SchemeRSA:
+		return nil, nil
+	case SchemeAESGCM:
+		if symmetric == nil {
+			return nil, errors.New("key file is required for aes-gcm scheme")
+		}
+		return symmetric, nil
+	case SchemeEnvelope:
+		if symmetric != nil {
+			return NewEnvelopeCipher(symmetric), nil
+		}
+		return NewEnvelopeCipher(rsaCipher{client: p.client}), nil
+	}
+	return nil, fmt.Errorf("unknown encryption scheme %q", scheme.Scheme)
+}
+
+// RotateKey makes provided private key the newest key used for re-encryption. The key is kept
+// also when the keys are refreshed from Vault. Key rotated before the plugin is initialized
+// is used once the client is created, while for disabled plugin the key is ignored.
+func (p *Plugin) RotateKey(key *rsa.PrivateKey) {
+	if key == nil {
+		return
+	}
+	if p.disabled {
+		p.Log.Warn("cryptodata plugin is disabled, rotated key is ignored")
+		return
+	}
+
+	p.keysLock.Lock()
+	defer p.keysLock.Unlock()
+	p.rotatedKeys = append([]*rsa.PrivateKey{key}, p.rotatedKeys...)
+	if p.client != nil {
+		p.client.SetPrivateKeys(p.privateKeys())
+	}
+}
+
+// EncryptDataForKey implements KeyedEncrypter.EncryptDataForKey.
+func (p *Plugin) EncryptDataForKey(key string, inData []byte) (data []byte, err error) {
+	if p.client == nil {
+		return nil, errors.New("cryptodata plugin is disabled, data cannot be encrypted")
+	}
+	return p.client.EncryptDataForKey(key, inData)
+}
+
+// DecryptDataForKey implements KeyedEncrypter.DecryptDataForKey.
+func (p *Plugin) DecryptDataForKey(key string, inData []byte) (data []byte, err error) {
+	if p.client == nil {
+		return nil, errors.New("cryptodata plugin is disabled, data cannot be decrypted")
+	}
+	return p.client.DecryptDataForKey(key, inData)
+}
+
+// ReencryptData implements KeyRotator.ReencryptData.
+func (p *Plugin) ReencryptData(inData []byte) (data []byte, rotated bool, err error) {
+	if p.client == nil {
+		return nil, false, errors.New("cryptodata plugin is disabled, data cannot be re-encrypted")
+	}
+	return p.client.ReencryptData(inData)
+}
+
+// ReencryptBytes implements KeyRotator.ReencryptBytes.
+func (p *Plugin) ReencryptBytes(broker keyval.BytesBroker, prefix string, reencrypter ArbitraryReencrypter) (
+	count, skipped int, err error) {
+	if p.client == nil {
+		return 0, 0, errors.New("cryptodata plugin is disabled, values cannot be re-encrypted")
+	}
+	return p.client.ReencryptBytes(broker, prefix, reencrypter)
+}
+
+// ReencryptProto implements KeyRotator.ReencryptProto.
+func (p *Plugin) ReencryptProto(broker keyval.BytesBroker, serializer keyval.Serializer, prefix string,
+	msgType proto.Message, reencrypter ArbitraryReencrypter) (count, skipped int, err error) {
+	if p.client == nil {
+		return 0, 0, errors.New("cryptodata plugin is disabled, values cannot be re-encrypted")
+	}
+	return p.client.ReencryptProto(broker, serializer, prefix, msgType, reencrypter)
+}
+
+// RegisterSweep registers a sweep re-encrypting values of a store encrypted with older key. Registered
+// sweeps are run by Sweep, which can be triggered by the operator (see cryptodatarest).
+func (p *Plugin) RegisterSweep(name string, sweep SweepFunc) {
+	p.sweepsLock.Lock()
+	defer p.sweepsLock.Unlock()
+	p.sweeps = append(p.sweeps, namedSweep{name: name, sweep: sweep})
+}
+
+// Sweep runs all registered sweeps in the order of registration. Failure of a sweep does not prevent
+// the others from running, it is reported in its result.
+func (p *Plugin) Sweep() ([]SweepResult, error) {
+	if p.disabled || p.client == nil {
+		return nil, errors.New("cryptodata plugin is disabled, values cannot be re-encrypted")
+	}
+
+	p.sweepsLock.Lock()
+	defer p.sweepsLock.Unlock()
+
+	results := make([]SweepResult, 0, len(p.sweeps))
+	for _, s := range p.sweeps {
+		result := SweepResult{Name: s.name}
+		count, skipped, err := s.sweep()
+		result.Count, result.Skipped = count, skipped
+		if err != nil {
+			result.Error = err.Error()
+			p.Log.Warnf("re-encryption sweep %s failed after %d value(s): %v", s.name, count, err)
+		} else {
+			p.Log.Infof("re-encryption sweep %s re-encrypted %d value(s), skipped %d", s.name, count, skipped)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReencryptStats returns numbers of values re-encrypted lazily by the brokers wrapped by the plugin.
+func (p *Plugin) ReencryptStats() ReencryptStats {
+	if p.client == nil {
+		return ReencryptStats{}
+	}
+	return p.client.LazyReencryptStats()
+}
+
+// privateKeys returns all private keys (rotated at runtime, loaded from Vault and from files), the newest first
+func (p *Plugin) privateKeys() []*rsa.PrivateKey {
+	keys := make([]*rsa.PrivateKey, 0, len(p.rotatedKeys)+len(p.vaultKeys)+len(p.fileKeys))
+	keys = append(keys, p.rotatedKeys...)
+	keys = append(keys, p.vaultKeys...)
+	return append(keys, p.fileKeys...)
+}
+
+// watchVault keeps the Vault token alive and periodically refreshes cached private keys
+func (p *Plugin) watchVault(ctx context.Context, refreshInterval time.Duration) {
+	var refreshC <-chan time.Time
+	if refreshInterval > 0 {
+		refreshTicker := time.NewTicker(refreshInterval)
+		defer refreshTicker.Stop()
+		refreshC = refreshTicker.C
+	}
+
+	// the renewal timer is armed only once per token lifetime, refreshing keys must not postpone it
+	var (
+		renewTimer *time.Timer
+		renewC     <-chan time.Time
+	)
+	armRenewal := func() {
+		renewC = nil
+		if interval := p.vault.RenewInterval(); interval > 0 {
+			renewTimer = time.NewTimer(interval)
+			renewC = renewTimer.C
+		}
+	}
+	armRenewal()
+	defer func() {
+		if renewTimer != nil {
+			renewTimer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-renewC:
+			if err := p.vault.Renew(); err != nil {
+				p.Log.Errorf("vault token renewal failed: %v", err)
+			} else {
+				p.Log.Debug("vault token renewed")
+			}
+			armRenewal()
+		case <-refreshC:
+			keys, err := p.vault.ReadPrivateKeys()
+			if err != nil {
+				p.Log.Errorf("refreshing private keys from vault failed: %v", err)
+				continue
+			}
+			p.keysLock.Lock()
+			p.vaultKeys = keys
+			p.client.SetPrivateKeys(p.privateKeys())
+			p.keysLock.Unlock()
+			p.Log.Debugf("refreshed %d private key(s) from vault", len(keys))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// parsePrivateKeys parses all PEM encoded PKCS1 private keys from provided data
+func parsePrivateKeys(bytes []byte) (keys []*rsa.PrivateKey, err error) {
+	for {
+		block, rest := pem.Decode(bytes)
+		if block == nil {
+			break
+		}
+
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		err = privateKey.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey.Precompute()
+		keys = append(keys, privateKey)
+
+		if rest == nil {
+			break
+		}
+
+		bytes = rest
+	}
+	return keys, nil
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/pkg/errors"
+)
+
+// ReencryptStats counts values re-encrypted lazily when they were read through the wrapped brokers.
+type ReencryptStats struct {
+	// Reencrypted is the number of values re-encrypted with the newest key and written back
+	Reencrypted uint64 `json:"reencrypted"`
+	// Failed is the number of values that failed to be re-encrypted or written back
+	Failed uint64 `json:"failed"`
+	// Skipped is the number of re-encrypted values that were not written back, because the store
+	// does not support compare-and-swap or the value was changed since it was read
+	Skipped uint64 `json:"skipped"`
+}
+
+// reencryptCounter collects ReencryptStats, nil counter counts nothing.
+type reencryptCounter struct {
+	reencrypted uint64
+	failed      uint64
+	skipped     uint64
+}
+
+func (c *reencryptCounter) addReencrypted() {
+	if c != nil {
+		atomic.AddUint64(&c.reencrypted, 1)
+	}
+}
+
+func (c *reencryptCounter) addFailed() {
+	if c != nil {
+		atomic.AddUint64(&c.failed, 1)
+	}
+}
+
+func (c *reencryptCounter) addSkipped() {
+	if c != nil {
+		atomic.AddUint64(&c.skipped, 1)
+	}
+}
+
+func (c *reencryptCounter) get() ReencryptStats {
+	if c == nil {
+		return ReencryptStats{}
+	}
+	return ReencryptStats{
+		Reencrypted: atomic.LoadUint64(&c.reencrypted),
+		Failed:      atomic.LoadUint64(&c.failed),
+		Skipped:     atomic.LoadUint64(&c.skipped),
+	}
+}
+
+// SweepFunc re-encrypts all values of a store encrypted with older key, typically by calling ReencryptBytes
+// or ReencryptProto with the raw (not decrypting) broker. Returns number of values that were written back
+// and number of values that were skipped.
+type SweepFunc func() (count, skipped int, err error)
+
+// SweepResult is the outcome of a registered sweep.
+type SweepResult struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Skipped int    `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ReencryptBytes implements KeyRotator.ReencryptBytes. It goes through all values stored under the prefix
+// (the broker must not decrypt the data) and writes back those that were re-encrypted with the newest key.
+// Values are written back with compare-and-swap against the data that were read, so that concurrent
+// changes are not overwritten. Values changed meanwhile are skipped, as well as all re-encrypted values
+// if the broker does not implement keyval.BytesBrokerWithAtomic. Values under prefixes with other than
+// the default scheme are not re-encrypted. Returns number of values that were written back and number
+// of values that were skipped.
+func (client *Client) ReencryptBytes(broker keyval.BytesBroker, prefix string, reencrypter ArbitraryReencrypter) (
+	count, skipped int, err error) {
+	it, err := broker.ListValues(prefix)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		if client.prefixCipher(kv.GetKey()) != nil {
+			// key rotation is supported only for the default scheme
+			continue
+		}
+		objData, rotated, err := reencrypter.Reencrypt(kv.GetValue(), client.ReencryptData)
+		if err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to re-encrypt value %s", kv.GetKey())
+		}
+		if !rotated {
+			continue
+		}
+		outData, ok := objData.([]byte)
+		if !ok {
+			return count, skipped, errors.Errorf("re-encrypted value %s is not []byte", kv.GetKey())
+		}
+		swapped, err := compareAndSwap(broker, kv.GetKey(), kv.GetValue(), outData)
+		if err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to store re-encrypted value %s", kv.GetKey())
+		}
+		if !swapped {
+			skipped++
+			continue
+		}
+		count++
+	}
+
+	return count, skipped, nil
+}
+
+// ReencryptProto implements KeyRotator.ReencryptProto. It goes through all values stored under the prefix
+// (the broker must not decrypt the data), unmarshals them into new instances of msgType using the serializer
+// of the store and writes back those that were re-encrypted with the newest key. Values are written back
+// the same way as by ReencryptBytes. Returns number of values that were written back and number of values
+// that were skipped.
+func (client *Client) ReencryptProto(broker keyval.BytesBroker, serializer keyval.Serializer, prefix string,
+	msgType proto.Message, reencrypter ArbitraryReencrypter) (count, skipped int, err error) {
+	it, err := broker.ListValues(prefix)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	msgReflectType := reflect.TypeOf(msgType).Elem()
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			break
+		}
+		if client.prefixCipher(kv.GetKey()) != nil {
+			// key rotation is supported only for the default scheme
+			continue
+		}
+		value := reflect.New(msgReflectType).Interface().(proto.Message)
+		if err := serializer.Unmarshal(kv.GetValue(), value); err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to unmarshal value %s", kv.GetKey())
+		}
+		_, rotated, err := reencrypter.Reencrypt(value, client.ReencryptData)
+		if err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to re-encrypt value %s", kv.GetKey())
+		}
+		if !rotated {
+			continue
+		}
+		outData, err := serializer.Marshal(value)
+		if err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to marshal re-encrypted value %s", kv.GetKey())
+		}
+		swapped, err := compareAndSwap(broker, kv.GetKey(), kv.GetValue(), outData)
+		if err != nil {
+			return count, skipped, errors.Wrapf(err, "failed to store re-encrypted value %s", kv.GetKey())
+		}
+		if !swapped {
+			skipped++
+			continue
+		}
+		count++
+	}
+
+	return count, skipped, nil
+}
+
+// compareAndSwap writes the re-encrypted value only if the store still holds the data that were read.
+// It returns false if the value was changed meanwhile or the broker does not support compare-and-swap.
+func compareAndSwap(broker keyval.BytesBroker, key string, oldData, newData []byte) (swapped bool, err error) {
+	atomicBroker, ok := broker.(keyval.BytesBrokerWithAtomic)
+	if !ok {
+		return false, nil
+	}
+	return atomicBroker.CompareAndSwap(key, oldData, newData)
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/utils/clienttls"
+)
+
+const (
+	// defaultVaultKeyField is the name of the secret field holding PEM encoded private key
+	defaultVaultKeyField = "private-key"
+	// defaultVaultTimeout is timeout for a single request sent to Vault
+	defaultVaultTimeout = 10 * time.Second
+	// minVaultRenewInterval limits how often the token is renewed for tokens with very short TTL
+	minVaultRenewInterval = time.Second
+)
+
+// VaultConfig is used to fetch private keys from HashiCorp Vault
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://127.0.0.1:8200
+	Address string `json:"address"`
+	// Token used to authenticate with Vault (token auth method)
+	Token string `json:"token"`
+	// AppRole credentials used to authenticate with Vault (approle auth method), used if token is empty
+	AppRole *VaultAppRole `json:"approle"`
+	// KeyPaths are Vault paths of the secrets holding the private keys, e.g. secret/data/agent/key
+	KeyPaths []string `json:"key-paths"`
+	// KeyField is the name of the secret field containing PEM encoded private key (default "private-key")
+	KeyField string `json:"key-field"`
+	// RefreshInterval defines how often are the cached keys re-read from Vault (0 disables refreshing)
+	RefreshInterval time.Duration `json:"refresh-interval"`
+	// Timeout for a single request sent to Vault
+	Timeout time.Duration `json:"timeout"`
+	// TLS settings used to connect to Vault
+	TLS clienttls.TLS `json:"tls"`
+}
+
+// VaultAppRole holds credentials for the AppRole auth method
+type VaultAppRole struct {
+	// MountPath of the approle auth method (default "approle")
+	MountPath string `json:"mount-path"`
+	// RoleID of the approle
+	RoleID string `json:"role-id"`
+	// SecretID of the approle
+	SecretID string `json:"secret-id"`
+}
+
+// vaultAuth is the auth part of the Vault response
+type vaultAuth struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int64  `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// vaultResponse is generic response returned by Vault API
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Auth   *vaultAuth             `json:"auth"`
+	Errors []string               `json:"errors"`
+}
+
+// VaultClient fetches private keys from HashiCorp Vault and keeps the Vault token alive
+type VaultClient struct {
+	config VaultConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	renewable bool
+	ttl       time.Duration
+}
+
+// NewVaultClient creates new Vault client from provided config. The client is not authenticated until Login
+// is called.
+func NewVaultClient(config VaultConfig) (*VaultClient, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("vault address is not set")
+	}
+	if config.Token == "" && config.AppRole == nil {
+		return nil, fmt.Errorf("vault token or approle credentials must be set")
+	}
+	if config.KeyField == "" {
+		config.KeyField = defaultVaultKeyField
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultVaultTimeout
+	}
+	if config.AppRole != nil && config.AppRole.MountPath == "" {
+		config.AppRole.MountPath = "approle"
+	}
+
+	transport := &http.Transport{}
+	if config.TLS.Enabled {
+		tlsConfig, err := clienttls.CreateTLSConfig(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &VaultClient{
+		config: config,
+		client: &http.Client{Transport: transport, Timeout: config.Timeout},
+		token:  config.Token,
+	}, nil
+}
+
+// Login authenticates the client with Vault. For token auth method the token is looked up to learn its TTL,
+// for approle auth method a new token is issued.
+func (vc *VaultClient) Login() error {
+	var (
+		resp *vaultResponse
+		err  error
+	)
+	if vc.config.Token != "" {
+		vc.setToken(vc.config.Token)
+		resp, err = vc.request(http.MethodGet, "auth/token/lookup-self", nil)
+		if err != nil {
+			return err
+		}
+		ttl, _ := resp.Data["ttl"].(float64)
+		renewable, _ := resp.Data["renewable"].(bool)
+		vc.mu.Lock()
+		vc.ttl = time.Duration(ttl) * time.Second
+		vc.renewable = renewable
+		vc.mu.Unlock()
+		return nil
+	}
+
+	resp, err = vc.request(http.MethodPost, "auth/"+vc.config.AppRole.MountPath+"/login", map[string]string{
+		"role_id":   vc.config.AppRole.RoleID,
+		"secret_id": vc.config.AppRole.SecretID,
+	})
+	if err != nil {
+		return err
+	}
+	return vc.applyAuth(resp)
+}
+
+// Renew renews the Vault token. If the token cannot be renewed and approle credentials are available,
+// the client logs in again.
+func (vc *VaultClient) Renew() error {
+	vc.mu.Lock()
+	renewable := vc.renewable
+	vc.mu.Unlock()
+
+	if renewable {
+		resp, err := vc.request(http.MethodPost, "auth/token/renew-self", nil)
+		if err == nil {
+			return vc.applyAuth(resp)
+		}
+		if vc.config.AppRole == nil {
+			return err
+		}
+	}
+	if vc.config.AppRole != nil {
+		return vc.Login()
+	}
+	return nil
+}
+
+// RenewInterval returns the interval after which the token should be renewed, or zero if the token
+// does not expire and there is nothing to renew.
+func (vc *VaultClient) RenewInterval() time.Duration {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if vc.ttl == 0 || (!vc.renewable && vc.config.AppRole == nil) {
+		return 0
+	}
+	// renew in the half of the token lifetime
+	interval := vc.ttl / 2
+	if interval < minVaultRenewInterval {
+		interval = minVaultRenewInterval
+	}
+	return interval
+}
+
+// ReadPrivateKeys reads and parses private keys from all configured key paths
+func (vc *VaultClient) ReadPrivateKeys() ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	for _, path := range vc.config.KeyPaths {
+		pemData, err := vc.readSecretField(path, vc.config.KeyField)
+		if err != nil {
+			return nil, err
+		}
+		pathKeys, err := parsePrivateKeys([]byte(pemData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key from vault path %s: %v", path, err)
+		}
+		keys = append(keys, pathKeys...)
+	}
+	return keys, nil
+}
+
+// readSecretField reads single field of the secret stored under given path. Both KV secret engine
+// versions are supported.
+func (vc *VaultClient) readSecretField(path, field string) (string, error) {
+	resp, err := vc.request(http.MethodGet, strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+
+	data := resp.Data
+	// KV version 2 wraps the secret data with metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = inner
+		}
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("field %q not found in vault secret %s", field, path)
+	}
+	return value, nil
+}
+
+// applyAuth stores token received in auth part of the response
+func (vc *VaultClient) applyAuth(resp *vaultResponse) error {
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("vault response does not contain client token")
+	}
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	vc.token = resp.Auth.ClientToken
+	vc.ttl = time.Duration(resp.Auth.LeaseDuration) * time.Second
+	vc.renewable = resp.Auth.Renewable
+	return nil
+}
+
+// setToken sets token used for authenticating requests
+func (vc *VaultClient) setToken(token string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	vc.token = token
+}
+
+// request sends request to the Vault API and decodes the response
+func (vc *VaultClient) request(method, path string, body interface{}) (*vaultResponse, error) {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	url := strings.TrimSuffix(vc.config.Address, "/") + "/v1/" + path
+	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	vc.mu.Lock()
+	if vc.token != "" {
+		req.Header.Set("X-Vault-Token", vc.token)
+	}
+	vc.mu.Unlock()
+
+	res, err := vc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vaultResponse{}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		// error responses of proxies in front of vault need not be JSON
+		reason := strings.TrimSpace(string(data))
+		if err := json.Unmarshal(data, resp); err == nil && len(resp.Errors) > 0 {
+			reason = strings.Join(resp.Errors, ", ")
+		}
+		return nil, fmt.Errorf("vault request %s %s failed with status %d: %s",
+			method, path, res.StatusCode, reason)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, resp); err != nil {
+			return nil, fmt.Errorf("failed to decode vault response for %s: %v", path, err)
+		}
+	}
+	return resp, nil
+}
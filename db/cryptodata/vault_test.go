@@ -0,0 +1,403 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cryptodata
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+// vaultServer is a fake Vault API serving the handlers registered for given paths
+type vaultServer struct {
+	*httptest.Server
+	t        *testing.T
+	handlers map[string]func(w http.ResponseWriter, r *http.Request)
+	tokens   []string
+}
+
+func newVaultServer(t *testing.T) *vaultServer {
+	vs := &vaultServer{t: t, handlers: make(map[string]func(w http.ResponseWriter, r *http.Request))}
+	vs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vs.tokens = append(vs.tokens, r.Header.Get("X-Vault-Token"))
+		handler, ok := vs.handlers[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":["no handler for path"]}`))
+			return
+		}
+		handler(w, r)
+	}))
+	return vs
+}
+
+func (vs *vaultServer) handle(method, path string, resp interface{}) {
+	vs.handlers[method+" "+path] = func(w http.ResponseWriter, r *http.Request) {
+		vs.reply(w, resp)
+	}
+}
+
+func (vs *vaultServer) reply(w http.ResponseWriter, resp interface{}) {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		vs.t.Error(err)
+	}
+}
+
+func encodeKey(t *testing.T) string {
+	key := generateKey(t)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func TestVaultTokenLogin(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/auth/token/lookup-self", map[string]interface{}{
+		"data": map[string]interface{}{"ttl": 60, "renewable": true},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+	Expect(vs.tokens).To(Equal([]string{"root-token"}))
+	Expect(vc.RenewInterval()).To(Equal(30 * time.Second))
+}
+
+func TestVaultTokenLoginNotRenewable(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/auth/token/lookup-self", map[string]interface{}{
+		"data": map[string]interface{}{"ttl": 0, "renewable": false},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+	Expect(vc.RenewInterval()).To(BeZero())
+	// nothing to renew, no request is sent
+	Expect(vc.Renew()).To(Succeed())
+	Expect(vs.tokens).To(HaveLen(1))
+}
+
+func TestVaultAppRoleLogin(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	var credentials map[string]string
+	vs.handlers["POST /v1/auth/custom-approle/login"] = func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(body, &credentials)).To(Succeed())
+		vs.reply(w, map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "approle-token", "lease_duration": 10, "renewable": false},
+		})
+	}
+	vs.handle(http.MethodGet, "/v1/secret/agent/key", map[string]interface{}{
+		"data": map[string]interface{}{"private-key": encodeKey(t)},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{
+		Address:  vs.URL,
+		AppRole:  &VaultAppRole{MountPath: "custom-approle", RoleID: "role", SecretID: "secret"},
+		KeyPaths: []string{"secret/agent/key"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+	Expect(credentials).To(Equal(map[string]string{"role_id": "role", "secret_id": "secret"}))
+	// non-renewable approle token is renewed by logging in again
+	Expect(vc.RenewInterval()).To(Equal(5 * time.Second))
+
+	_, err = vc.ReadPrivateKeys()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vs.tokens).To(Equal([]string{"", "approle-token"}))
+}
+
+func TestVaultAppRoleLoginFailed(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handlers["POST /v1/auth/approle/login"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		vs.reply(w, map[string]interface{}{"errors": []string{"invalid secret id"}})
+	}
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, AppRole: &VaultAppRole{RoleID: "role"}})
+	Expect(err).ToNot(HaveOccurred())
+	err = vc.Login()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("status 400: invalid secret id"))
+}
+
+func TestVaultLoginNonJSONError(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handlers["POST /v1/auth/approle/login"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+	}
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, AppRole: &VaultAppRole{RoleID: "role"}})
+	Expect(err).ToNot(HaveOccurred())
+	err = vc.Login()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("status 502: <html><body>502 Bad Gateway</body></html>"))
+}
+
+func TestVaultRenewSelf(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/auth/token/lookup-self", map[string]interface{}{
+		"data": map[string]interface{}{"ttl": 4, "renewable": true},
+	})
+	vs.handle(http.MethodPost, "/v1/auth/token/renew-self", map[string]interface{}{
+		"auth": map[string]interface{}{"client_token": "renewed-token", "lease_duration": 120, "renewable": true},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+	Expect(vc.RenewInterval()).To(Equal(2 * time.Second))
+
+	Expect(vc.Renew()).To(Succeed())
+	Expect(vc.RenewInterval()).To(Equal(time.Minute))
+	Expect(vs.tokens).To(Equal([]string{"root-token", "root-token"}))
+}
+
+func TestVaultRenewSelfFailed(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/auth/token/lookup-self", map[string]interface{}{
+		"data": map[string]interface{}{"ttl": 60, "renewable": true},
+	})
+	vs.handlers["POST /v1/auth/token/renew-self"] = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		vs.reply(w, map[string]interface{}{"errors": []string{"permission denied"}})
+	}
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token"})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+	err = vc.Renew()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring("permission denied"))
+}
+
+func TestVaultReadKVv1(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/secret/agent/key", map[string]interface{}{
+		"data": map[string]interface{}{"pem": encodeKey(t) + encodeKey(t)},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{
+		Address:  vs.URL + "/",
+		Token:    "root-token",
+		KeyPaths: []string{"/secret/agent/key"},
+		KeyField: "pem",
+	})
+	Expect(err).ToNot(HaveOccurred())
+	keys, err := vc.ReadPrivateKeys()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(keys).To(HaveLen(2))
+}
+
+func TestVaultReadKVv2(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/secret/data/agent/key1", map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     map[string]interface{}{"private-key": encodeKey(t)},
+			"metadata": map[string]interface{}{"version": 3},
+		},
+	})
+	vs.handle(http.MethodGet, "/v1/secret/data/agent/key2", map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     map[string]interface{}{"private-key": encodeKey(t)},
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{
+		Address:  vs.URL,
+		Token:    "root-token",
+		KeyPaths: []string{"secret/data/agent/key1", "secret/data/agent/key2"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	keys, err := vc.ReadPrivateKeys()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(keys).To(HaveLen(2))
+}
+
+func TestVaultReadMissingField(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/secret/data/agent/key", map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     map[string]interface{}{"other": "value"},
+			"metadata": map[string]interface{}{},
+		},
+	})
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token", KeyPaths: []string{"secret/data/agent/key"}})
+	Expect(err).ToNot(HaveOccurred())
+	_, err = vc.ReadPrivateKeys()
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(ContainSubstring(`field "private-key" not found`))
+}
+
+func TestWatchVaultRenewsDespiteRefreshes(t *testing.T) {
+	RegisterTestingT(t)
+
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handle(http.MethodGet, "/v1/auth/token/lookup-self", map[string]interface{}{
+		"data": map[string]interface{}{"ttl": 2, "renewable": true},
+	})
+	vs.handle(http.MethodGet, "/v1/secret/agent/key", map[string]interface{}{
+		"data": map[string]interface{}{"private-key": encodeKey(t)},
+	})
+	var renewals int32
+	vs.handlers["POST /v1/auth/token/renew-self"] = func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&renewals, 1)
+		vs.reply(w, map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "root-token", "lease_duration": 2, "renewable": true},
+		})
+	}
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token", KeyPaths: []string{"secret/agent/key"}})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(vc.Login()).To(Succeed())
+
+	p := &Plugin{vault: vc, client: NewClient(ClientConfig{})}
+	p.Log = logging.ForPlugin("cryptodata-test")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.watchVault(ctx, 50*time.Millisecond)
+		close(done)
+	}()
+
+	// keys are refreshed far more often than the token is renewed, which must not postpone the renewal
+	Eventually(func() int32 { return atomic.LoadInt32(&renewals) }, 3*time.Second).Should(BeNumerically(">=", 2))
+	cancel()
+	Eventually(done).Should(BeClosed())
+}
+
+func TestVaultKeysPrecedeFileKeys(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		mu       sync.Mutex
+		vaultKey = generateKey(t)
+	)
+	vs := newVaultServer(t)
+	defer vs.Close()
+	vs.handlers["GET /v1/secret/agent/key"] = func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(vaultKey)})
+		vs.reply(w, map[string]interface{}{
+			"data": map[string]interface{}{"private-key": string(pemKey)},
+		})
+	}
+
+	vc, err := NewVaultClient(VaultConfig{Address: vs.URL, Token: "root-token", KeyPaths: []string{"secret/agent/key"}})
+	Expect(err).ToNot(HaveOccurred())
+	vaultKeys, err := vc.ReadPrivateKeys()
+	Expect(err).ToNot(HaveOccurred())
+
+	fileKey := generateKey(t)
+	p := &Plugin{vault: vc, fileKeys: []*rsa.PrivateKey{fileKey}, vaultKeys: vaultKeys}
+	p.Log = logging.ForPlugin("cryptodata-test")
+	p.client = NewClient(ClientConfig{PrivateKeys: p.privateKeys()})
+	Expect(p.privateKeys()).To(HaveLen(2))
+	Expect(p.privateKeys()[0].N).To(Equal(vaultKey.N))
+	Expect(p.privateKeys()[1]).To(Equal(fileKey))
+
+	// key published in Vault becomes the newest one once the keys are refreshed
+	newKey := generateKey(t)
+	mu.Lock()
+	vaultKey = newKey
+	mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.watchVault(ctx, 20*time.Millisecond)
+		close(done)
+	}()
+	newestKey := func() *rsa.PrivateKey {
+		p.client.keysLock.RLock()
+		defer p.client.keysLock.RUnlock()
+		return p.client.PrivateKeys[0]
+	}
+	Eventually(func() interface{} { return newestKey().N }).Should(Equal(newKey.N))
+	cancel()
+	Eventually(done).Should(BeClosed())
+
+	// values encrypted with the file key are re-encrypted with the key from Vault
+	encrypted, err := p.client.EncryptData([]byte("secret"), &fileKey.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+	reencrypted, rotated, err := p.client.ReencryptData(encrypted)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rotated).To(BeTrue())
+	decrypted, err := rsa.DecryptOAEP(p.client.Hash, p.client.Reader, newKey, reencrypted, nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(decrypted).To(Equal([]byte("secret")))
+}
+
+func TestRotateKeyBeforeInit(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := &Plugin{}
+	p.Log = logging.ForPlugin("cryptodata-test")
+	key := generateKey(t)
+	Expect(func() { p.RotateKey(key) }).ToNot(Panic())
+	Expect(p.privateKeys()).To(Equal([]*rsa.PrivateKey{key}))
+
+	p.disabled = true
+	Expect(func() { p.RotateKey(generateKey(t)) }).ToNot(Panic())
+	Expect(p.privateKeys()).To(HaveLen(1))
+}
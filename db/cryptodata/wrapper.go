@@ -14,10 +14,72 @@
 
 package cryptodata
 
+import "github.com/ligato/cn-infra/logging"
+
 // decryptData holds values required for decrypting
 type decryptData struct {
 	// Function used for decrypting arbitrary data later
 	decryptFunc DecryptFunc
 	// ArbitraryDecrypter is used to decrypt data
 	decrypter ArbitraryDecrypter
-}
\ No newline at end of file
+	// Function used for lazy re-encryption of data read with older key (nil if disabled)
+	reencryptFunc ReencryptFunc
+	// Counter of lazily re-encrypted values (nil if lazy re-encryption is disabled)
+	stats *reencryptCounter
+	// Logger used to report failures of lazy re-encryption (nil to disable)
+	log logging.Logger
+	// Function selecting cipher used for the key, nil cipher selects decryptFunc (nil if disabled)
+	cipherFor func(key string) Cipher
+	// Prefix prepended to keys by the broker or watcher
+	keyPrefix string
+}
+
+// forKey returns decrypt data used for the value stored under given key (relative to the key prefix)
+func (d decryptData) forKey(key string) decryptData {
+	if d.cipherFor == nil {
+		return d
+	}
+	if c := d.cipherFor(d.keyPrefix + key); c != nil {
+		d.decryptFunc = c.Decrypt
+		// lazy re-encryption is supported only for the default scheme
+		d.reencryptFunc = nil
+	}
+	return d
+}
+
+// withPrefix returns decrypt data for broker or watcher with given key prefix
+func (d decryptData) withPrefix(prefix string) decryptData {
+	d.keyPrefix += prefix
+	return d
+}
+
+// reencrypter returns decrypter as ArbitraryReencrypter if the lazy re-encryption is enabled and supported
+// by the decrypter
+func (d decryptData) reencrypter() (ArbitraryReencrypter, bool) {
+	if d.reencryptFunc == nil {
+		return nil, false
+	}
+	reencrypter, ok := d.decrypter.(ArbitraryReencrypter)
+	return reencrypter, ok
+}
+
+// reencrypted counts value that was re-encrypted and written back to the store
+func (d decryptData) reencrypted() {
+	d.stats.addReencrypted()
+}
+
+// reencryptSkipped counts re-encrypted value that was not written back
+func (d decryptData) reencryptSkipped(key string, reason string) {
+	d.stats.addSkipped()
+	if d.log != nil {
+		d.log.Debugf("re-encrypted value %s not written back: %s", d.keyPrefix+key, reason)
+	}
+}
+
+// reencryptFailed counts and logs failure to re-encrypt or write back value stored under the key
+func (d decryptData) reencryptFailed(key string, err error) {
+	d.stats.addFailed()
+	if d.log != nil {
+		d.log.Warnf("lazy re-encryption of value %s failed: %v", d.keyPrefix+key, err)
+	}
+}
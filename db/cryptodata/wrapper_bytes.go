@@ -14,7 +14,11 @@
 
 package cryptodata
 
-import "github.com/ligato/cn-infra/db/keyval"
+import (
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/pkg/errors"
+)
 
 // KvBytesPluginWrapper wraps keyval.KvBytesPlugin with additional support of reading encrypted data
 type KvBytesPluginWrapper struct {
@@ -86,11 +90,43 @@ func NewBytesWatcherWrapper(pb keyval.BytesWatcher, decrypter ArbitraryDecrypter
 	}
 }
 
+// WithLazyReencryption enables re-encryption of values that were encrypted with older key. Such values
+// are re-encrypted using provided function and written back to the store when read by GetValue of a broker
+// created by this wrapper.
+func (cbw *KvBytesPluginWrapper) WithLazyReencryption(reencryptFunc ReencryptFunc) *KvBytesPluginWrapper {
+	cbw.reencryptFunc = reencryptFunc
+	if cbw.stats == nil {
+		cbw.stats = &reencryptCounter{}
+	}
+	return cbw
+}
+
+// WithLogger sets logger used to report values that failed to be re-encrypted lazily.
+func (cbw *KvBytesPluginWrapper) WithLogger(log logging.Logger) *KvBytesPluginWrapper {
+	cbw.log = log
+	return cbw
+}
+
+// ReencryptStats returns numbers of values re-encrypted lazily by brokers created by this wrapper.
+func (cbw *KvBytesPluginWrapper) ReencryptStats() ReencryptStats {
+	return cbw.stats.get()
+}
+
+// WithKeyCiphers enables selection of the cipher by the key of the value. The function receives full key
+// of the value and returns nil if the default decrypt function should be used.
+func (cbw *KvBytesPluginWrapper) WithKeyCiphers(cipherFor func(key string) Cipher) *KvBytesPluginWrapper {
+	cbw.cipherFor = cipherFor
+	return cbw
+}
+
 // NewBroker returns a BytesBroker instance with support for decrypting values that prepends given <keyPrefix> to all
 // keys in its calls.
 // To avoid using a prefix, pass keyval.Root constant as argument.
 func (cbw *KvBytesPluginWrapper) NewBroker(prefix string) keyval.BytesBroker {
-	return NewBytesBrokerWrapper(cbw.KvBytesPlugin.NewBroker(prefix), cbw.decrypter, cbw.decryptFunc)
+	return &BytesBrokerWrapper{
+		BytesBroker: cbw.KvBytesPlugin.NewBroker(prefix),
+		decryptData: cbw.decryptData.withPrefix(prefix),
+	}
 }
 
 // NewWatcher returns a BytesWatcher instance with support for decrypting values that prepends given <keyPrefix> to all
@@ -98,14 +134,22 @@ func (cbw *KvBytesPluginWrapper) NewBroker(prefix string) keyval.BytesBroker {
 // The prefix is removed from the key retrieved by GetKey() in BytesWatchResp.
 // To avoid using a prefix, pass keyval.Root constant as argument.
 func (cbw *KvBytesPluginWrapper) NewWatcher(prefix string) keyval.BytesWatcher {
-	return NewBytesWatcherWrapper(cbw.KvBytesPlugin.NewWatcher(prefix), cbw.decrypter, cbw.decryptFunc)
+	return &BytesWatcherWrapper{
+		BytesWatcher: cbw.KvBytesPlugin.NewWatcher(prefix),
+		decryptData:  cbw.decryptData.withPrefix(prefix),
+	}
 }
 
-// GetValue retrieves and tries to decrypt one item under the provided key.
+// GetValue retrieves and tries to decrypt one item under the provided key. If lazy re-encryption is enabled,
+// the value encrypted with older key is re-encrypted and written back to the store.
 func (cbb *BytesBrokerWrapper) GetValue(key string) (data []byte, found bool, revision int64, err error) {
 	data, found, revision, err = cbb.BytesBroker.GetValue(key)
+	if err == nil && found {
+		data = cbb.reencrypt(key, data)
+	}
 	if err == nil {
-		objData, err := cbb.decrypter.Decrypt(data, cbb.decryptFunc)
+		d := cbb.forKey(key)
+		objData, err := d.decrypter.Decrypt(data, d.decryptFunc)
 		if err != nil {
 			return data, found, revision, err
 		}
@@ -118,6 +162,34 @@ func (cbb *BytesBrokerWrapper) GetValue(key string) (data []byte, found bool, re
 	return
 }
 
+// reencrypt re-encrypts data read from the store if they were encrypted with older key and writes them back
+// with compare-and-swap against the data that were read. Failure to re-encrypt is not fatal for the read,
+// it is logged and counted and original data are returned in such case.
+func (cbb *BytesBrokerWrapper) reencrypt(key string, data []byte) []byte {
+	d := cbb.forKey(key)
+	reencrypter, ok := d.reencrypter()
+	if !ok {
+		return data
+	}
+	objData, rotated, err := reencrypter.Reencrypt(data, d.reencryptFunc)
+	if err != nil {
+		d.reencryptFailed(key, err)
+		return data
+	}
+	if !rotated {
+		return data
+	}
+	outData, ok := objData.([]byte)
+	if !ok {
+		d.reencryptFailed(key, errors.New("re-encrypted value is not []byte"))
+		return data
+	}
+	if !writeBack(d, cbb.BytesBroker, key, data, outData) {
+		return data
+	}
+	return outData
+}
+
 // ListValues returns an iterator that enables to traverse all items stored
 // under the provided <key>.
 func (cbb *BytesBrokerWrapper) ListValues(key string) (keyval.BytesKeyValIterator, error) {
@@ -159,7 +231,8 @@ func (r *BytesWatchRespWrapper) GetPrevValue() []byte {
 // GetValue returns the value of the pair.
 func (r *BytesKeyValWrapper) GetValue() []byte {
 	value := r.BytesKeyVal.GetValue()
-	data, err := r.decrypter.Decrypt(value, r.decryptFunc)
+	d := r.forKey(r.GetKey())
+	data, err := d.decrypter.Decrypt(value, d.decryptFunc)
 	if err != nil {
 		return nil
 	}
@@ -173,7 +246,8 @@ func (r *BytesKeyValWrapper) GetValue() []byte {
 // GetPrevValue returns the previous value of the pair.
 func (r *BytesKeyValWrapper) GetPrevValue() []byte {
 	value := r.BytesKeyVal.GetPrevValue()
-	data, err := r.decrypter.Decrypt(value, r.decryptFunc)
+	d := r.forKey(r.GetKey())
+	data, err := d.decrypter.Decrypt(value, d.decryptFunc)
 	if err != nil {
 		return nil
 	}
@@ -197,3 +271,24 @@ func (r *BytesKeyValIteratorWrapper) GetNext() (kv keyval.BytesKeyVal, stop bool
 		decryptData: r.decryptData,
 	}, stop
 }
+
+// writeBack stores re-encrypted value only if the store still holds the data that were read. Values that were
+// changed meanwhile and values in stores without compare-and-swap support are skipped. Returns true if
+// the value was written.
+func writeBack(d decryptData, broker keyval.BytesBroker, key string, oldData, newData []byte) bool {
+	if _, ok := broker.(keyval.BytesBrokerWithAtomic); !ok {
+		d.reencryptSkipped(key, "compare-and-swap is not supported by the store")
+		return false
+	}
+	swapped, err := compareAndSwap(broker, key, oldData, newData)
+	if err != nil {
+		d.reencryptFailed(key, errors.Wrap(err, "failed to store re-encrypted value"))
+		return false
+	}
+	if !swapped {
+		d.reencryptSkipped(key, "value was changed since it was read")
+		return false
+	}
+	d.reencrypted()
+	return true
+}
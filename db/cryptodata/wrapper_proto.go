@@ -18,18 +18,30 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/pkg/errors"
 )
 
 // KvProtoPluginWrapper wraps keyval.KvProtoPlugin with additional support of reading encrypted data
 type KvProtoPluginWrapper struct {
 	keyval.KvProtoPlugin
 	decryptData
+	rawAccess
 }
 
 // ProtoBrokerWrapper wraps keyval.ProtoBroker with additional support of reading encrypted data
 type ProtoBrokerWrapper struct {
 	keyval.ProtoBroker
 	decryptData
+	// raw broker and serializer used to write back lazily re-encrypted values (nil if not available)
+	raw        keyval.BytesBroker
+	serializer keyval.Serializer
+}
+
+// rawAccess gives access to the bytes store underlying the proto plugin
+type rawAccess struct {
+	raw        keyval.KvBytesPlugin
+	serializer keyval.Serializer
 }
 
 // ProtoWatcherWrapper wraps keyval.ProtoWatcher with additional support of reading encrypted data
@@ -89,11 +101,57 @@ func NewProtoWatcherWrapper(pb keyval.ProtoWatcher, decrypter ArbitraryDecrypter
 	}
 }
 
+// WithLazyReencryption enables re-encryption of values that were encrypted with older key. Such values
+// are re-encrypted using provided function and written back to the store when read by GetValue of a broker
+// created by this wrapper.
+func (kvp *KvProtoPluginWrapper) WithLazyReencryption(reencryptFunc ReencryptFunc) *KvProtoPluginWrapper {
+	kvp.reencryptFunc = reencryptFunc
+	if kvp.stats == nil {
+		kvp.stats = &reencryptCounter{}
+	}
+	return kvp
+}
+
+// WithRawAccess sets the bytes store underlying the wrapped plugin and the serializer of its values.
+// Values re-encrypted lazily are written back to it with compare-and-swap against the data that were read.
+// Without raw access (or if the store does not support compare-and-swap), re-encrypted values are not
+// written back and they are counted as skipped.
+func (kvp *KvProtoPluginWrapper) WithRawAccess(raw keyval.KvBytesPlugin, serializer keyval.Serializer) *KvProtoPluginWrapper {
+	kvp.rawAccess = rawAccess{raw: raw, serializer: serializer}
+	return kvp
+}
+
+// WithLogger sets logger used to report values that failed to be re-encrypted lazily.
+func (kvp *KvProtoPluginWrapper) WithLogger(log logging.Logger) *KvProtoPluginWrapper {
+	kvp.log = log
+	return kvp
+}
+
+// ReencryptStats returns numbers of values re-encrypted lazily by brokers created by this wrapper.
+func (kvp *KvProtoPluginWrapper) ReencryptStats() ReencryptStats {
+	return kvp.stats.get()
+}
+
+// WithKeyCiphers enables selection of the cipher by the key of the value. The function receives full key
+// of the value and returns nil if the default decrypt function should be used.
+func (kvp *KvProtoPluginWrapper) WithKeyCiphers(cipherFor func(key string) Cipher) *KvProtoPluginWrapper {
+	kvp.cipherFor = cipherFor
+	return kvp
+}
+
 // NewBroker returns a ProtoBroker instance with support for decrypting values that prepends given <keyPrefix> to all
 // keys in its calls.
 // To avoid using a prefix, pass keyval.Root constant as argument.
 func (kvp *KvProtoPluginWrapper) NewBroker(prefix string) keyval.ProtoBroker {
-	return NewProtoBrokerWrapper(kvp.KvProtoPlugin.NewBroker(prefix), kvp.decrypter, kvp.decryptFunc)
+	broker := &ProtoBrokerWrapper{
+		ProtoBroker: kvp.KvProtoPlugin.NewBroker(prefix),
+		decryptData: kvp.decryptData.withPrefix(prefix),
+	}
+	if kvp.raw != nil && kvp.reencryptFunc != nil {
+		broker.raw = kvp.raw.NewBroker(prefix)
+		broker.serializer = kvp.serializer
+	}
+	return broker
 }
 
 // NewWatcher returns a ProtoWatcher instance with support for decrypting values that prepends given <keyPrefix> to all
@@ -101,21 +159,67 @@ func (kvp *KvProtoPluginWrapper) NewBroker(prefix string) keyval.ProtoBroker {
 // The prefix is removed from the key retrieved by GetKey() in ProtoWatchResp.
 // To avoid using a prefix, pass keyval.Root constant as argument.
 func (kvp *KvProtoPluginWrapper) NewWatcher(prefix string) keyval.ProtoWatcher {
-	return NewProtoWatcherWrapper(kvp.KvProtoPlugin.NewWatcher(prefix), kvp.decrypter, kvp.decryptFunc)
+	return &ProtoWatcherWrapper{
+		ProtoWatcher: kvp.KvProtoPlugin.NewWatcher(prefix),
+		decryptData:  kvp.decryptData.withPrefix(prefix),
+	}
 }
 
 // GetValue retrieves one item under the provided <key>. If the item exists,
-// it is unmarshaled into the <reqObj> and its fields are decrypted.
+// it is unmarshaled into the <reqObj> and its fields are decrypted. If lazy re-encryption
+// is enabled, the value encrypted with older key is re-encrypted and written back to the store.
 func (db *ProtoBrokerWrapper) GetValue(key string, reqObj proto.Message) (bool, int64, error) {
 	found, revision, err := db.ProtoBroker.GetValue(key, reqObj)
 	if !found || err != nil {
 		return found, revision, err
 	}
 
-	_, err = db.decrypter.Decrypt(reqObj, db.decryptFunc)
+	db.reencrypt(key, reqObj)
+	d := db.forKey(key)
+	_, err = d.decrypter.Decrypt(reqObj, d.decryptFunc)
 	return found, revision, err
 }
 
+// reencrypt re-encrypts the value stored under the key if it was encrypted with older key and writes it back.
+// The value is read again from the raw store, so that it can be written back with compare-and-swap against
+// the data that were read. Failure to re-encrypt is not fatal for the read, it is logged and counted.
+func (db *ProtoBrokerWrapper) reencrypt(key string, value proto.Message) {
+	d := db.forKey(key)
+	reencrypter, ok := d.reencrypter()
+	if !ok {
+		return
+	}
+	if db.raw == nil {
+		d.reencryptSkipped(key, "raw access to the store is not available")
+		return
+	}
+	data, found, _, err := db.raw.GetValue(key)
+	if err != nil || !found {
+		d.reencryptFailed(key, errors.Errorf("failed to read raw value (found: %v): %v", found, err))
+		return
+	}
+	msg := proto.Clone(value)
+	msg.Reset()
+	if err := db.serializer.Unmarshal(data, msg); err != nil {
+		d.reencryptFailed(key, errors.Wrap(err, "failed to unmarshal raw value"))
+		return
+	}
+	_, rotated, err := reencrypter.Reencrypt(msg, d.reencryptFunc)
+	if err != nil {
+		d.reencryptFailed(key, err)
+		return
+	}
+	if !rotated {
+		return
+	}
+	outData, err := db.serializer.Marshal(msg)
+	if err != nil {
+		d.reencryptFailed(key, errors.Wrap(err, "failed to marshal re-encrypted value"))
+		return
+	}
+	writeBack(d, db.raw, key, data, outData)
+}
+
 // ListValues returns an iterator that enables to traverse all items stored
 // under the provided <key>.
 func (db *ProtoBrokerWrapper) ListValues(key string) (keyval.ProtoKeyValIterator, error) {
@@ -160,7 +264,8 @@ func (r *ProtoKeyValWrapper) GetValue(value proto.Message) error {
 	if err != nil {
 		return err
 	}
-	_, err = r.decrypter.Decrypt(value, r.decryptFunc)
+	d := r.forKey(r.GetKey())
+	_, err = d.decrypter.Decrypt(value, d.decryptFunc)
 	return err
 }
 
@@ -170,7 +275,8 @@ func (r *ProtoKeyValWrapper) GetPrevValue(prevValue proto.Message) (prevValueExi
 	if !exists || err != nil {
 		return exists, err
 	}
-	_, err = r.decrypter.Decrypt(prevValue, r.decryptFunc)
+	d := r.forKey(r.GetKey())
+	_, err = d.decrypter.Decrypt(prevValue, d.decryptFunc)
 	return exists, err
 }
 
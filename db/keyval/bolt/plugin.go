@@ -102,6 +102,11 @@ func (p *Plugin) NewWatcher(keyPrefix string) keyval.ProtoWatcher {
 	return p.protoWrapper.NewWatcher(keyPrefix)
 }
 
+// RawAccess allows to access data in the database as raw bytes (i.e. not formatted by protobuf).
+func (p *Plugin) RawAccess() keyval.KvBytesPlugin {
+	return p.boltClient
+}
+
 func (p *Plugin) getConfig() (*Config, error) {
 	var cfg Config
 	found, err := p.Cfg.LoadValue(&cfg)
@@ -36,6 +36,10 @@ type BytesConnectionEtcd struct {
 	lessor     clientv3.Lease
 	session    *concurrency.Session
 	opTimeout  time.Duration
+	// readClients are additional connections used only for reading
+	readClients []*clientv3.Client
+	// reads is used for reading large prefixes, nil if not configured
+	reads *readPool
 }
 
 // BytesBrokerWatcherEtcd uses BytesConnectionEtcd to access the datastore.
@@ -50,6 +54,7 @@ type BytesBrokerWatcherEtcd struct {
 	kv        clientv3.KV
 	watcher   clientv3.Watcher
 	opTimeout time.Duration
+	reads     *readPool
 }
 
 // NewEtcdConnectionWithBytes creates new connection to etcd based on the given
@@ -74,6 +79,16 @@ func NewEtcdConnectionWithBytes(config ClientConfig, log logging.Logger) (*Bytes
 	}
 	conn.opTimeout = config.OpTimeout
 
+	for i := 1; i < config.ReadConnections; i++ {
+		readClient, err := clientv3.New(*config.Config)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.readClients = append(conn.readClients, readClient)
+	}
+	conn.setupReads(config.ParallelReads, config.MaxConcurrentReads)
+
 	conn.session, err = concurrency.NewSession(etcdClient, concurrency.WithTTL(config.SessionTTL))
 	if err != nil {
 		return nil, err
@@ -95,8 +110,17 @@ func NewEtcdConnectionUsingClient(etcdClient *clientv3.Client, log logging.Logge
 	return &conn, nil
 }
 
+// setupReads configures parallel reads of large prefixes (see readPool).
+func (db *BytesConnectionEtcd) setupReads(parallel, maxConcurrent int) {
+	clients := append([]*clientv3.Client{db.etcdClient}, db.readClients...)
+	db.reads = newReadPool(clients, parallel, maxConcurrent)
+}
+
 // Close closes the connection to ETCD.
 func (db *BytesConnectionEtcd) Close() error {
+	for _, readClient := range db.readClients {
+		readClient.Close()
+	}
 	if db.etcdClient != nil {
 		return db.etcdClient.Close()
 	}
@@ -116,6 +140,7 @@ func (db *BytesConnectionEtcd) NewBroker(prefix string) keyval.BytesBroker {
 		lessor:    db.lessor,
 		opTimeout: db.opTimeout,
 		watcher:   namespace.NewWatcher(db.etcdClient, prefix),
+		reads:     db.reads.withPrefix(prefix),
 	}
 }
 
@@ -132,6 +157,7 @@ func (db *BytesConnectionEtcd) NewWatcher(prefix string) keyval.BytesWatcher {
 		lessor:    db.lessor,
 		opTimeout: db.opTimeout,
 		watcher:   namespace.NewWatcher(db.etcdClient, prefix),
+		reads:     db.reads.withPrefix(prefix),
 	}
 }
 
@@ -158,6 +184,9 @@ func (pdb *BytesBrokerWatcherEtcd) GetValue(key string) (data []byte, found bool
 // KeyPrefix defined in constructor is prepended to the key argument.
 // The prefix is removed from the keys of the returned values.
 func (pdb *BytesBrokerWatcherEtcd) ListValues(key string) (keyval.BytesKeyValIterator, error) {
+	if pdb.reads != nil {
+		return pdb.reads.listValues(pdb.Logger, pdb.opTimeout, key)
+	}
 	return listValuesInternal(pdb.Logger, pdb.kv, pdb.opTimeout, key)
 }
 
@@ -493,6 +522,9 @@ func getValueRevInternal(log logging.Logger, kv clientv3.KV, opTimeout time.Dura
 // ListValues returns an iterator that enables traversing values stored under
 // the provided <key>.
 func (db *BytesConnectionEtcd) ListValues(key string) (keyval.BytesKeyValIterator, error) {
+	if db.reads != nil {
+		return db.reads.listValues(db.Logger, db.opTimeout, key)
+	}
 	return listValuesInternal(db.Logger, db.etcdClient, db.opTimeout, key)
 }
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/pkg/tlsutil"
+	"github.com/ligato/cn-infra/utils/retry"
 )
 
 // Config represents a part of the etcd configuration that can be
@@ -42,8 +43,12 @@ type Config struct {
 	ReconnectResync       bool          `json:"resync-after-reconnect"`
 	AllowDelayedStart     bool          `json:"allow-delayed-start"`
 	ReconnectInterval     time.Duration `json:"reconnect-interval"`
+	ReconnectBackoff      *retry.Config `json:"reconnect-backoff"`
 	SessionTTL            int           `json:"session-ttl"`
 	ExpandEnvVars         bool          `json:"expand-env-variables"`
+	ReadConnections       int           `json:"read-connections"`
+	ParallelReads         int           `json:"parallel-reads"`
+	MaxConcurrentReads    int           `json:"max-concurrent-reads"`
 }
 
 // ClientConfig extends clientv3.Config with configuration options introduced
@@ -63,6 +68,18 @@ type ClientConfig struct {
 	// data according to the values of the current environment variables. References to undefined variables are replaced
 	// by the empty string.
 	ExpandEnvVars bool
+
+	// ReadConnections is the number of gRPC connections to etcd used to read
+	// large prefixes. Additional connections are used only for reading.
+	ReadConnections int
+
+	// ParallelReads is the maximal number of range readers used by ListValues
+	// to read a large prefix in parallel. 0 or 1 disables parallel reads.
+	ParallelReads int
+
+	// MaxConcurrentReads limits the number of concurrent read requests
+	// of parallel range readers, 0 means unlimited.
+	MaxConcurrentReads int
 }
 
 const (
@@ -105,7 +122,8 @@ func ConfigToClient(yc *Config) (*ClientConfig, error) {
 		Endpoints:   yc.Endpoints,
 		DialTimeout: dialTimeout,
 	}
-	cfg := &ClientConfig{Config: clientv3Cfg, OpTimeout: opTimeout, SessionTTL: sessionTTL}
+	cfg := &ClientConfig{Config: clientv3Cfg, OpTimeout: opTimeout, SessionTTL: sessionTTL,
+		ReadConnections: yc.ReadConnections, ParallelReads: yc.ParallelReads, MaxConcurrentReads: yc.MaxConcurrentReads}
 
 	if len(cfg.Endpoints) == 0 {
 		if ep := os.Getenv("ETCD_ENDPOINTS"); ep != "" {
@@ -95,6 +95,10 @@
 //       }
 //    }
 //
+// Values of large prefixes (e.g. during resync) can be read by multiple range
+// readers in parallel, optionally using dedicated connections, see options
+// read-connections, parallel-reads and max-concurrent-reads.
+//
 // To retrieve values in specified key range:
 //    itr, err := db.ListValues(key)
 //    if err != nil {
@@ -0,0 +1,158 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/namespace"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/logging"
+	"golang.org/x/net/context"
+)
+
+// minKeysPerReader is the minimal number of keys read by each of the parallel
+// range readers. Smaller prefixes are read with a single request.
+const minKeysPerReader = 1000
+
+// readPool distributes reads of large prefixes among multiple range readers
+// and gRPC connections to etcd.
+type readPool struct {
+	kvs      []clientv3.KV // connections used for reading, picked in round-robin
+	next     uint32        // index of the next connection
+	parallel int           // maximal number of range readers per prefix
+	sem      chan struct{} // limits concurrent reads, nil means unlimited
+}
+
+// newReadPool creates readPool reading through the given clients. It returns nil
+// if neither parallel reads nor the concurrency limit are enabled.
+func newReadPool(clients []*clientv3.Client, parallel, maxConcurrent int) *readPool {
+	if len(clients) <= 1 && parallel <= 1 && maxConcurrent <= 0 {
+		return nil
+	}
+	pool := &readPool{parallel: parallel}
+	for _, client := range clients {
+		pool.kvs = append(pool.kvs, client)
+	}
+	if maxConcurrent > 0 {
+		pool.sem = make(chan struct{}, maxConcurrent)
+	}
+	return pool
+}
+
+// withPrefix returns readPool sharing the concurrency limit, reading keys under the prefix.
+func (pool *readPool) withPrefix(prefix string) *readPool {
+	if pool == nil {
+		return nil
+	}
+	prefixed := &readPool{parallel: pool.parallel, sem: pool.sem}
+	for _, kv := range pool.kvs {
+		prefixed.kvs = append(prefixed.kvs, namespace.NewKV(kv, prefix))
+	}
+	return prefixed
+}
+
+// get reads the range using the next connection, respecting the concurrency limit.
+func (pool *readPool) get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	if pool.sem != nil {
+		select {
+		case pool.sem <- struct{}{}:
+			defer func() { <-pool.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	kv := pool.kvs[int(atomic.AddUint32(&pool.next, 1))%len(pool.kvs)]
+	return kv.Get(ctx, key, opts...)
+}
+
+// listValues reads all values under the key. Large prefixes are split into ranges
+// with the same number of keys, which are read in parallel at the same revision.
+func (pool *readPool) listValues(log logging.Logger, opTimeout time.Duration, key string) (keyval.BytesKeyValIterator, error) {
+	deadline := time.Now().Add(opTimeout)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	resp, err := pool.listRanges(ctx, key)
+	if err != nil {
+		log.Error("etcd error: ", err)
+		return nil, err
+	}
+
+	return &bytesKeyValIterator{len: len(resp.Kvs), resp: resp}, nil
+}
+
+func (pool *readPool) listRanges(ctx context.Context, key string) (*clientv3.GetResponse, error) {
+	if pool.parallel <= 1 {
+		// the prefix is never split, there is no need to count the keys
+		return pool.get(ctx, key, clientv3.WithPrefix())
+	}
+	countResp, err := pool.get(ctx, key, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return nil, err
+	}
+	rev := countResp.Header.Revision
+
+	readers := pool.parallel
+	if max := int(countResp.Count / minKeysPerReader); readers > max {
+		readers = max
+	}
+	if readers <= 1 {
+		return pool.get(ctx, key, clientv3.WithPrefix(), clientv3.WithRev(rev))
+	}
+
+	// split the prefix into ranges using keys read at the same revision
+	keysResp, err := pool.get(ctx, key, clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithRev(rev))
+	if err != nil {
+		return nil, err
+	}
+	bounds := []string{key}
+	for i := 1; i < readers; i++ {
+		bounds = append(bounds, string(keysResp.Kvs[i*len(keysResp.Kvs)/readers].Key))
+	}
+	bounds = append(bounds, clientv3.GetPrefixRangeEnd(key))
+
+	var (
+		wg     sync.WaitGroup
+		ranges = make([][]*mvccpb.KeyValue, readers)
+		errs   = make([]error, readers)
+	)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := pool.get(ctx, bounds[i], clientv3.WithRange(bounds[i+1]), clientv3.WithRev(rev))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			ranges[i] = resp.Kvs
+		}(i)
+	}
+	wg.Wait()
+
+	resp := &clientv3.GetResponse{Header: countResp.Header, Count: countResp.Count}
+	for i := range ranges {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		resp.Kvs = append(resp.Kvs, ranges[i]...)
+	}
+	return resp, nil
+}
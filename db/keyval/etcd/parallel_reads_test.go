@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3client"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/etcd/mocks"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+func TestParallelListValues(t *testing.T) {
+	var embedded mocks.Embedded
+	embedded.Start(t)
+	defer embedded.Stop()
+	RegisterTestingT(t)
+
+	conn, err := NewEtcdConnectionUsingClient(v3client.New(embedded.ETCD.Server), logrus.DefaultLogger())
+	Expect(err).To(BeNil())
+	conn.setupReads(4, 2)
+	defer conn.Close()
+
+	const count = 3*minKeysPerReader + 1
+	for i := 0; i < count; i++ {
+		Expect(conn.Put(fmt.Sprintf("/parallel/key/%05d", i), []byte{byte(i)})).To(Succeed())
+	}
+	Expect(conn.Put("/parallel/other", []byte{0})).To(Succeed())
+
+	for _, list := range []func() (int, []string){
+		func() (int, []string) { return collectKeys(conn.ListValues("/parallel/key/")) },
+		func() (int, []string) { return collectKeys(conn.NewBroker("/parallel/").ListValues("key/")) },
+	} {
+		n, keys := list()
+		Expect(n).To(Equal(count))
+		Expect(keys[0]).To(HaveSuffix("key/00000"))
+		Expect(keys[count-1]).To(HaveSuffix(fmt.Sprintf("key/%05d", count-1)))
+		for i := 1; i < n; i++ {
+			Expect(keys[i] > keys[i-1]).To(BeTrue())
+		}
+	}
+}
+
+func TestListValuesWithoutParallelReadsDoesNotCount(t *testing.T) {
+	var embedded mocks.Embedded
+	embedded.Start(t)
+	defer embedded.Stop()
+	RegisterTestingT(t)
+
+	conn, err := NewEtcdConnectionUsingClient(v3client.New(embedded.ETCD.Server), logrus.DefaultLogger())
+	Expect(err).To(BeNil())
+	// only the concurrency limit is enabled
+	conn.setupReads(1, 2)
+	defer conn.Close()
+	kv := &countingKV{KV: conn.reads.kvs[0]}
+	conn.reads.kvs[0] = kv
+
+	for i := 0; i < 3; i++ {
+		Expect(conn.Put(fmt.Sprintf("/serial/key/%d", i), []byte{byte(i)})).To(Succeed())
+	}
+	n, _ := collectKeys(conn.ListValues("/serial/key/"))
+	Expect(n).To(Equal(3))
+	Expect(kv.gets).To(Equal(1))
+	Expect(kv.counts).To(BeZero())
+}
+
+// countingKV counts Get requests and those reading only the number of keys.
+type countingKV struct {
+	clientv3.KV
+	gets, counts int
+}
+
+func (kv *countingKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	kv.gets++
+	if clientv3.OpGet(key, opts...).IsCountOnly() {
+		kv.counts++
+	}
+	return kv.KV.Get(ctx, key, opts...)
+}
+
+func collectKeys(itr keyval.BytesKeyValIterator, err error) (int, []string) {
+	Expect(err).To(BeNil())
+	var keys []string
+	for {
+		kv, stop := itr.GetNext()
+		if stop {
+			return len(keys), keys
+		}
+		keys = append(keys, kv.GetKey())
+	}
+}
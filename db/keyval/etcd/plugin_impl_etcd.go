@@ -25,7 +25,7 @@ import (
 	"github.com/ligato/cn-infra/db/keyval/kvproto"
 	"github.com/ligato/cn-infra/health/statuscheck"
 	"github.com/ligato/cn-infra/infra"
-	"github.com/ligato/cn-infra/utils/safeclose"
+	"github.com/ligato/cn-infra/utils/retry"
 )
 
 const (
@@ -58,8 +58,10 @@ type Plugin struct {
 	// functions are executed.
 	onConnection []func() error
 
-	autoCompactDone chan struct{}
-	lastConnErr     error
+	lastConnErr error
+
+	// runs reconnection and auto compact loops until the plugin is closed
+	loop infra.EventLoop
 }
 
 // Deps lists dependencies of the etcd plugin.
@@ -81,6 +83,8 @@ type Deps struct {
 // Check clientv3.New from coreos/etcd for possible errors returned in case
 // the connection cannot be established.
 func (p *Plugin) Init() (err error) {
+	p.loop.Log = p.Log
+
 	// Read ETCD configuration file. Returns error if does not exists.
 	p.config, err = p.getEtcdConfig()
 	if err != nil || p.disabled {
@@ -99,7 +103,9 @@ func (p *Plugin) Init() (err error) {
 	if err != nil && p.config.AllowDelayedStart {
 		// If the connection cannot be established during init, keep trying in another goroutine (if allowed) and
 		// end the init
-		go p.etcdReconnectionLoop(etcdClientCfg)
+		p.loop.Go(func(ctx context.Context) {
+			p.etcdReconnectionLoop(ctx, etcdClientCfg)
+		})
 		return nil
 	} else if err != nil {
 		// If delayed start is not allowed, return error
@@ -127,7 +133,8 @@ func (p *Plugin) AfterInit() error {
 
 // Close shutdowns the connection.
 func (p *Plugin) Close() error {
-	return safeclose.Close(p.autoCompactDone)
+	p.loop.Stop()
+	return nil
 }
 
 // NewBroker creates new instance of prefixed broker that provides API with arguments of type proto.Message.
@@ -197,26 +204,56 @@ func (p *Plugin) Compact(rev ...int64) (toRev int64, err error) {
 
 // Method starts loop which attempt to connect to the ETCD. If successful, send signal callback with resync,
 // which will be started when datasync confirms successful registration
-func (p *Plugin) etcdReconnectionLoop(clientCfg *ClientConfig) {
-	var err error
-	// Set reconnect interval
+func (p *Plugin) etcdReconnectionLoop(ctx context.Context, clientCfg *ClientConfig) {
+	// Set reconnect backoff, by default constant reconnect interval is used
 	interval := p.config.ReconnectInterval
 	if interval == 0 {
 		interval = defaultReconnectInterval
 	}
-	p.Log.Infof("ETCD server %s not reachable in init phase. Agent will continue to try to connect every %d second(s)",
-		p.config.Endpoints, interval)
-	for {
-		time.Sleep(interval)
+	backoff := retry.ConstantBackoff(interval)
+	maxAttempts := retry.Unlimited
+	if p.config.ReconnectBackoff != nil {
+		backoff = p.config.ReconnectBackoff.Backoff()
+		// unlike other retries, reconnecting is not limited unless explicitly set
+		if p.config.ReconnectBackoff.MaxAttempts != 0 {
+			maxAttempts = p.config.ReconnectBackoff.MaxAttempts
+		}
+	}
+	p.Log.Infof("ETCD server %s not reachable in init phase. Agent will continue to try to connect",
+		p.config.Endpoints)
+
+	// the first attempt failed in init phase, wait before the next one
+	timer := time.NewTimer(backoff.Delay(1))
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return
+	case <-timer.C:
+	}
 
+	err := retry.Do(ctx, func() error {
 		p.Log.Infof("Connecting to ETCD %v ...", p.config.Endpoints)
-		p.connection, err = NewEtcdConnectionWithBytes(*clientCfg, p.Log)
+		connection, err := NewEtcdConnectionWithBytes(*clientCfg, p.Log)
 		if err != nil {
-			continue
+			return err
 		}
-		p.setupPostInitConnection(clientCfg.ExpandEnvVars)
+		p.connection = connection
+		return nil
+	}, retry.WithBackoff(retry.BackoffFunc(func(attempt int) time.Duration {
+		return backoff.Delay(attempt + 1)
+	})), retry.WithMaxAttempts(maxAttempts),
+		retry.OnRetry(func(attempt int, delay time.Duration, err error) {
+			p.Log.Debugf("Connecting to ETCD failed (attempt %d): %v, next attempt in %v", attempt, err, delay)
+		}))
+	if err != nil {
+		// the plugin is closed
+		if ctx.Err() != nil {
+			return
+		}
+		p.Log.Errorf("Connecting to ETCD %v failed: %v", p.config.Endpoints, err)
 		return
 	}
+	p.setupPostInitConnection(clientCfg.ExpandEnvVars)
 }
 
 func (p *Plugin) setupPostInitConnection(expandEnvVars bool) {
@@ -297,21 +334,13 @@ func (p *Plugin) getEtcdConfig() (*Config, error) {
 }
 
 func (p *Plugin) startPeriodicAutoCompact(period time.Duration) {
-	p.autoCompactDone = make(chan struct{})
-	go func() {
-		p.Log.Infof("Starting periodic auto compacting every %v", period)
-		for {
-			select {
-			case <-time.After(period):
-				p.Log.Debugf("Executing auto compact")
-				if toRev, err := p.connection.Compact(); err != nil {
-					p.Log.Errorf("Periodic auto compacting failed: %v", err)
-				} else {
-					p.Log.Infof("Auto compacting finished (to revision %v)", toRev)
-				}
-			case <-p.autoCompactDone:
-				return
-			}
+	p.Log.Infof("Starting periodic auto compacting every %v", period)
+	p.loop.Run(infra.OnTick(period, func(ctx context.Context) {
+		p.Log.Debugf("Executing auto compact")
+		if toRev, err := p.connection.Compact(); err != nil {
+			p.Log.Errorf("Periodic auto compacting failed: %v", err)
+		} else {
+			p.Log.Infof("Auto compacting finished (to revision %v)", toRev)
 		}
-	}()
+	}))
 }
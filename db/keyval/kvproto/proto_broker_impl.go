@@ -44,6 +44,14 @@ type protoKeyIterator struct {
 	delegate keyval.BytesKeyIterator
 }
 
+// RawValue is implemented by values of watch and resync events, which hold
+// the data as read from the data store (e.g. etcd or bolt). It allows to write
+// the values back without knowing their proto message type.
+type RawValue interface {
+	// GetRawValue returns the serialized value and the serializer used.
+	GetRawValue() (data []byte, serializer keyval.Serializer)
+}
+
 // protoKeyVal represents single key-value pair.
 type protoKeyVal struct {
 	pair       keyval.BytesKeyVal
@@ -267,6 +275,16 @@ func (kv *protoKeyVal) GetValue(msg proto.Message) error {
 	return nil
 }
 
+// GetRawValue returns the serialized value of the pair and its serializer.
+func (kv *protoKeyVal) GetRawValue() (data []byte, serializer keyval.Serializer) {
+	return kv.pair.GetValue(), kv.serializer
+}
+
+// GetValueSize returns the size of the serialized value of the pair.
+func (kv *protoKeyVal) GetValueSize() int {
+	return len(kv.pair.GetValue())
+}
+
 // GetPrevValue returns the previous value of the pair.
 func (kv *protoKeyVal) GetPrevValue(msg proto.Message) (prevValueExist bool, err error) {
 	prevVal := kv.pair.GetPrevValue()
@@ -16,9 +16,12 @@ package kvproto
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/pkg/errors"
 )
 
 // protoTxn represents a transaction.
@@ -48,6 +51,40 @@ func (tx *protoTxn) Put(key string, value proto.Message) keyval.ProtoTxn {
 	return tx
 }
 
+// SetValues adds 'put' operations of multiple values to a previously
+// created transaction, nil value adds 'delete' operation. Values carrying
+// the proto message (see datasync.WithProtoMessage), e.g. values of syncbase
+// changes, are serialized. Values of watch and resync events (see RawValue)
+// are written as they are, if they were serialized the same way as
+// the transaction does. Any other value fails the transaction.
+func (tx *protoTxn) SetValues(values map[string]datasync.LazyValue) keyval.ProtoTxn {
+	for key, value := range values {
+		if tx.err != nil {
+			return tx
+		}
+		if value == nil {
+			tx.Delete(key)
+			continue
+		}
+		if withMsg, ok := value.(datasync.WithProtoMessage); ok && withMsg.GetProtoMessage() != nil {
+			tx.Put(key, withMsg.GetProtoMessage())
+			continue
+		}
+		raw, ok := value.(RawValue)
+		if !ok {
+			tx.err = errors.Errorf("value of %s cannot be serialized, it carries neither proto message nor raw data", key)
+			return tx
+		}
+		data, serializer := raw.GetRawValue()
+		if reflect.TypeOf(serializer) != reflect.TypeOf(tx.serializer) {
+			tx.err = errors.Errorf("value of %s is serialized by %T, transaction uses %T", key, serializer, tx.serializer)
+			return tx
+		}
+		tx.txn = tx.txn.Put(key, data)
+	}
+	return tx
+}
+
 // Delete adds a new 'delete' operation to a previously created
 // transaction.
 func (tx *protoTxn) Delete(key string) keyval.ProtoTxn {
@@ -0,0 +1,81 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvproto_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/kvproto"
+	"github.com/ligato/cn-infra/db/keyval/kvtest"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+// listValues reads values under the prefix as they are delivered by resync.
+func listValues(broker keyval.ProtoBroker, prefix string) map[string]datasync.LazyValue {
+	it, err := broker.ListValues(prefix)
+	Expect(err).ToNot(HaveOccurred())
+	values := make(map[string]datasync.LazyValue)
+	for {
+		kv, stop := it.GetNext()
+		if stop {
+			return values
+		}
+		values[kv.GetKey()] = kv
+	}
+}
+
+func TestSetValuesWithRawValues(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	src := kvproto.NewProtoWrapper(store).NewBroker("/src/")
+	Expect(src.Put("a", &status.PluginStatus{Name: "a"})).To(Succeed())
+	Expect(src.Put("b", &status.PluginStatus{Name: "b"})).To(Succeed())
+	values := listValues(src, "")
+	Expect(values).To(HaveLen(2))
+	values["c"] = nil
+
+	dst := kvproto.NewProtoWrapper(store).NewBroker("/dst/")
+	Expect(dst.Put("c", &status.PluginStatus{Name: "c"})).To(Succeed())
+	Expect(dst.NewTxn().SetValues(values).Commit(context.Background())).To(Succeed())
+
+	var stored status.PluginStatus
+	found, _, err := dst.GetValue("a", &stored)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(stored.Name).To(Equal("a"))
+	found, _, _ = dst.GetValue("b", &stored)
+	Expect(found).To(BeTrue())
+	found, _, _ = dst.GetValue("c", &stored)
+	Expect(found).To(BeFalse())
+}
+
+func TestSetValuesWithDifferentSerializer(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := kvtest.NewStore()
+	src := kvproto.NewProtoWrapper(store).NewBroker("/src/")
+	Expect(src.Put("a", &status.PluginStatus{Name: "a"})).To(Succeed())
+
+	// proto data cannot be written by transaction using JSON
+	dst := kvproto.NewProtoWrapper(store, &keyval.SerializerJSON{}).NewBroker("/dst/")
+	err := dst.NewTxn().SetValues(listValues(src, "")).Commit(context.Background())
+	Expect(err).To(HaveOccurred())
+	Expect(store.Len()).To(Equal(1))
+}
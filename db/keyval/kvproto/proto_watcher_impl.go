@@ -54,6 +54,16 @@ func (wr *protoWatchResp) GetValue(msg proto.Message) error {
 	return wr.serializer.Unmarshal(wr.BytesWatchResp.GetValue(), msg)
 }
 
+// GetRawValue returns the serialized value after the change and its serializer.
+func (wr *protoWatchResp) GetRawValue() (data []byte, serializer keyval.Serializer) {
+	return wr.BytesWatchResp.GetValue(), wr.serializer
+}
+
+// GetValueSize returns the size of the serialized value after the change.
+func (wr *protoWatchResp) GetValueSize() int {
+	return len(wr.BytesWatchResp.GetValue())
+}
+
 // GetPrevValue returns the previous value after the change.
 func (wr *protoWatchResp) GetPrevValue(msg proto.Message) (prevValueExist bool, err error) {
 	prevVal := wr.BytesWatchResp.GetPrevValue()
@@ -0,0 +1,277 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+)
+
+// broker accesses the Store with keys prefixed by the broker prefix.
+type broker struct {
+	store  *Store
+	prefix string
+}
+
+// Put stores <data> under the prefixed <key>.
+func (b *broker) Put(key string, data []byte, opts ...datasync.PutOption) error {
+	key = b.prefix + key
+	if err := b.store.call(OpPut, key); err != nil {
+		return err
+	}
+
+	b.store.mu.Lock()
+	ev := b.store.put(key, data)
+	b.store.mu.Unlock()
+
+	b.store.notify(ev)
+	return nil
+}
+
+// GetValue returns the value stored under the prefixed <key>.
+func (b *broker) GetValue(key string) (data []byte, found bool, revision int64, err error) {
+	key = b.prefix + key
+	if err := b.store.call(OpGet, key); err != nil {
+		return nil, false, 0, err
+	}
+
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	stored, ok := b.store.data[key]
+	if !ok {
+		return nil, false, 0, nil
+	}
+	return copyBytes(stored.value), true, stored.revision, nil
+}
+
+// ListValues returns values of all keys with the given prefix ordered by key.
+// The broker prefix is removed from the returned keys.
+func (b *broker) ListValues(key string) (keyval.BytesKeyValIterator, error) {
+	prefix := b.prefix + key
+	if err := b.store.call(OpList, prefix); err != nil {
+		return nil, err
+	}
+
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	it := &keyValIterator{}
+	for _, k := range b.store.keys(prefix) {
+		stored := b.store.data[k]
+		it.pairs = append(it.pairs, &keyVal{
+			key:      strings.TrimPrefix(k, b.prefix),
+			value:    copyBytes(stored.value),
+			revision: stored.revision,
+		})
+	}
+	return it, nil
+}
+
+// ListKeys returns all keys with the given prefix in alphabetical order.
+// The broker prefix is removed from the returned keys.
+func (b *broker) ListKeys(prefix string) (keyval.BytesKeyIterator, error) {
+	prefix = b.prefix + prefix
+	if err := b.store.call(OpList, prefix); err != nil {
+		return nil, err
+	}
+
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+
+	it := &keyIterator{}
+	for _, k := range b.store.keys(prefix) {
+		it.keys = append(it.keys, &keyVal{
+			key:      strings.TrimPrefix(k, b.prefix),
+			revision: b.store.data[k].revision,
+		})
+	}
+	return it, nil
+}
+
+// Delete removes the prefixed <key> (or all keys with the given prefix
+// if datasync.WithPrefix is used).
+func (b *broker) Delete(key string, opts ...datasync.DelOption) (existed bool, err error) {
+	key = b.prefix + key
+	if err := b.store.call(OpDelete, key); err != nil {
+		return false, err
+	}
+	keys := []string{key}
+
+	b.store.mu.Lock()
+	for _, o := range opts {
+		if _, ok := o.(*datasync.WithPrefixOpt); ok {
+			keys = b.store.keys(key)
+		}
+	}
+	var events []*watchEvent
+	for _, k := range keys {
+		if ev := b.store.delete(k); ev != nil {
+			events = append(events, ev)
+		}
+	}
+	b.store.mu.Unlock()
+
+	b.store.notify(events...)
+	return len(events) > 0, nil
+}
+
+// NewTxn creates a transaction operating on prefixed keys.
+func (b *broker) NewTxn() keyval.BytesTxn {
+	return &txn{broker: b}
+}
+
+// PutIfNotExists stores <data> under the prefixed <key> only if the key does not exist.
+func (b *broker) PutIfNotExists(key string, data []byte) (succeeded bool, err error) {
+	key = b.prefix + key
+	if err := b.store.call(OpPut, key); err != nil {
+		return false, err
+	}
+
+	b.store.mu.Lock()
+	if _, exists := b.store.data[key]; exists {
+		b.store.mu.Unlock()
+		return false, nil
+	}
+	ev := b.store.put(key, data)
+	b.store.mu.Unlock()
+
+	b.store.notify(ev)
+	return true, nil
+}
+
+// CompareAndSwap replaces <oldData> stored under the prefixed <key> with <newData>.
+func (b *broker) CompareAndSwap(key string, oldData, newData []byte) (swapped bool, err error) {
+	key = b.prefix + key
+	if err := b.store.call(OpPut, key); err != nil {
+		return false, err
+	}
+
+	b.store.mu.Lock()
+	if !equalValues(b.store.data[key], oldData) {
+		b.store.mu.Unlock()
+		return false, nil
+	}
+	ev := b.store.put(key, newData)
+	b.store.mu.Unlock()
+
+	b.store.notify(ev)
+	return true, nil
+}
+
+// CompareAndDelete removes the prefixed <key> if it stores <data>.
+func (b *broker) CompareAndDelete(key string, data []byte) (deleted bool, err error) {
+	key = b.prefix + key
+	if err := b.store.call(OpDelete, key); err != nil {
+		return false, err
+	}
+
+	b.store.mu.Lock()
+	if !equalValues(b.store.data[key], data) {
+		b.store.mu.Unlock()
+		return false, nil
+	}
+	ev := b.store.delete(key)
+	b.store.mu.Unlock()
+
+	b.store.notify(ev)
+	return true, nil
+}
+
+// txn collects operations applied atomically on commit.
+type txn struct {
+	broker *broker
+	ops    []txnOp
+}
+
+type txnOp struct {
+	key    string
+	value  []byte
+	delete bool
+}
+
+// Put adds put operation into the transaction.
+func (t *txn) Put(key string, data []byte) keyval.BytesTxn {
+	t.ops = append(t.ops, txnOp{key: t.broker.prefix + key, value: data})
+	return t
+}
+
+// Delete adds delete operation into the transaction.
+func (t *txn) Delete(key string) keyval.BytesTxn {
+	t.ops = append(t.ops, txnOp{key: t.broker.prefix + key, delete: true})
+	return t
+}
+
+// Commit applies all operations of the transaction atomically.
+func (t *txn) Commit(ctx context.Context) error {
+	return t.broker.store.commit(ctx, t.ops)
+}
+
+// keyVal is a key-value pair returned by iterators.
+type keyVal struct {
+	key      string
+	value    []byte
+	revision int64
+}
+
+// GetKey returns the key of the pair.
+func (kv *keyVal) GetKey() string {
+	return kv.key
+}
+
+// GetValue returns the value of the pair.
+func (kv *keyVal) GetValue() []byte {
+	return kv.value
+}
+
+// GetPrevValue returns nil, previous values are not tracked for stored pairs.
+func (kv *keyVal) GetPrevValue() []byte {
+	return nil
+}
+
+// GetRevision returns the revision of the last change of the pair.
+func (kv *keyVal) GetRevision() int64 {
+	return kv.revision
+}
+
+type keyValIterator struct {
+	pairs []*keyVal
+}
+
+// GetNext returns the following pair.
+func (it *keyValIterator) GetNext() (kv keyval.BytesKeyVal, stop bool) {
+	if len(it.pairs) == 0 {
+		return nil, true
+	}
+	kv, it.pairs = it.pairs[0], it.pairs[1:]
+	return kv, false
+}
+
+type keyIterator struct {
+	keys []*keyVal
+}
+
+// GetNext returns the following key.
+func (it *keyIterator) GetNext() (key string, rev int64, stop bool) {
+	if len(it.keys) == 0 {
+		return "", 0, true
+	}
+	kv := it.keys[0]
+	it.keys = it.keys[1:]
+	return kv.key, kv.revision, false
+}
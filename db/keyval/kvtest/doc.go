@@ -0,0 +1,31 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kvtest provides an in-memory key-value store implementing the keyval
+// APIs, intended for unit tests of plugins which would otherwise need a real
+// etcd (or another data store).
+//
+// The Store supports prefixed brokers and watchers, transactions, atomic
+// operations and revisions. Watch events are delivered synchronously before
+// the write operation returns, so tests can assert on them right away.
+// Faults can be injected to make selected operations fail, slow them down
+// or to drop watch notifications:
+//
+//	store := kvtest.NewStore()
+//	store.InjectFault(kvtest.Fault{Op: kvtest.OpPut, Key: "/config/", Err: errors.New("etcd down"), Times: 1})
+//
+//	p := myplugin.NewPlugin(myplugin.UseDeps(func(deps *myplugin.Deps) {
+//		deps.KvStore = kvtest.NewPlugin(store)
+//	}))
+package kvtest
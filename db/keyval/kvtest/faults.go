@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"strings"
+	"time"
+)
+
+// Op identifies the store operation affected by a Fault.
+type Op string
+
+const (
+	// OpGet matches GetValue.
+	OpGet Op = "get"
+	// OpList matches ListValues and ListKeys.
+	OpList Op = "list"
+	// OpPut matches Put, PutIfNotExists and CompareAndSwap.
+	OpPut Op = "put"
+	// OpDelete matches Delete and CompareAndDelete.
+	OpDelete Op = "delete"
+	// OpCommit matches commit of a transaction.
+	OpCommit Op = "commit"
+	// OpWatch matches Watch.
+	OpWatch Op = "watch"
+	// OpNotify matches delivery of watch events. Matching events are dropped.
+	OpNotify Op = "notify"
+)
+
+// Fault describes a failure injected into the Store.
+type Fault struct {
+	// Op is the affected operation.
+	Op Op
+	// Key limits the fault to keys with the given prefix (including the broker prefix).
+	// Empty key matches all keys.
+	Key string
+	// Err is returned by the affected operation (ignored for OpNotify).
+	Err error
+	// Delay is applied before the affected operation is executed.
+	Delay time.Duration
+	// Times is the number of operations affected by the fault (0 means unlimited).
+	Times int
+}
+
+// InjectFault adds the fault. Faults are evaluated in the order of injection
+// and the first matching fault is applied.
+func (s *Store) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Store) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// fault returns the fault matching the operation on the key and consumes
+// one of its occurrences. Must be called with the lock held.
+func (s *Store) fault(op Op, key string) *Fault {
+	for i, f := range s.faults {
+		if f.Op != op || !strings.HasPrefix(key, f.Key) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// checkFault applies the fault matching the operation on the key, if any.
+func (s *Store) checkFault(op Op, key string) error {
+	s.mu.Lock()
+	f := s.fault(op, key)
+	s.mu.Unlock()
+
+	if f == nil {
+		return nil
+	}
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+	return f.Err
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/db/keyval/kvproto"
+	"github.com/ligato/cn-infra/logging"
+)
+
+// Plugin exposes the Store as keyval.KvProtoPlugin, so that it can be injected
+// into plugins instead of etcd or other key-value plugins.
+type Plugin struct {
+	store        *Store
+	protoWrapper *kvproto.ProtoWrapper
+}
+
+// NewPlugin creates a Plugin backed by the given Store. Values are serialized
+// as JSON, the same way as the etcd plugin does by default.
+func NewPlugin(store *Store, serializer ...keyval.Serializer) *Plugin {
+	if len(serializer) == 0 {
+		serializer = []keyval.Serializer{&keyval.SerializerJSON{}}
+	}
+	return &Plugin{
+		store:        store,
+		protoWrapper: kvproto.NewProtoWrapper(store, serializer...),
+	}
+}
+
+// NewBroker returns a broker for proto-modelled data prefixed by <keyPrefix>.
+func (p *Plugin) NewBroker(keyPrefix string) keyval.ProtoBroker {
+	return p.protoWrapper.NewBroker(keyPrefix)
+}
+
+// NewWatcher returns a watcher for proto-modelled data prefixed by <keyPrefix>.
+func (p *Plugin) NewWatcher(keyPrefix string) keyval.ProtoWatcher {
+	return p.protoWrapper.NewWatcher(keyPrefix)
+}
+
+// RawAccess returns the underlying Store.
+func (p *Plugin) RawAccess() keyval.KvBytesPlugin {
+	return p.store
+}
+
+// Store returns the underlying Store.
+func (p *Plugin) Store() *Store {
+	return p.store
+}
+
+// Disabled always returns false.
+func (p *Plugin) Disabled() bool {
+	return false
+}
+
+// OnConnect executes the callback immediately, the store is always connected.
+func (p *Plugin) OnConnect(callback func() error) {
+	if err := callback(); err != nil {
+		logging.DefaultLogger.Error(err)
+	}
+}
+
+// String returns the name of the plugin.
+func (p *Plugin) String() string {
+	return "kvtest"
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+)
+
+// Store is an in-memory key-value store implementing keyval.CoreBrokerWatcher
+// and keyval.BytesBrokerWithAtomic.
+type Store struct {
+	mu       sync.Mutex
+	data     map[string]*entry
+	revision int64
+	faults   []*Fault
+	watches  []*watch
+	closers  map[chan string]struct{}
+	ops      map[Op]int
+}
+
+type entry struct {
+	value    []byte
+	revision int64
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		data:    make(map[string]*entry),
+		closers: make(map[chan string]struct{}),
+		ops:     make(map[Op]int),
+	}
+}
+
+// Revision returns the current revision of the store, incremented by every change.
+func (s *Store) Revision() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.revision
+}
+
+// Len returns the number of stored keys.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.data)
+}
+
+// Calls returns the number of executed calls of the given operation
+// (including the failed ones).
+func (s *Store) Calls(op Op) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ops[op]
+}
+
+// NewBroker returns a broker prepending <prefix> to all keys.
+func (s *Store) NewBroker(prefix string) keyval.BytesBroker {
+	return &broker{store: s, prefix: prefix}
+}
+
+// NewWatcher returns a watcher prepending <prefix> to all watched keys.
+// The prefix is removed from the keys of watch events.
+func (s *Store) NewWatcher(prefix string) keyval.BytesWatcher {
+	return &broker{store: s, prefix: prefix}
+}
+
+// Close is no-op.
+func (s *Store) Close() error {
+	return nil
+}
+
+// Put stores <data> under the <key>.
+func (s *Store) Put(key string, data []byte, opts ...datasync.PutOption) error {
+	return s.root().Put(key, data, opts...)
+}
+
+// GetValue returns the value stored under the <key>.
+func (s *Store) GetValue(key string) (data []byte, found bool, revision int64, err error) {
+	return s.root().GetValue(key)
+}
+
+// ListValues returns values of all keys with the given prefix ordered by key.
+func (s *Store) ListValues(key string) (keyval.BytesKeyValIterator, error) {
+	return s.root().ListValues(key)
+}
+
+// ListKeys returns all keys with the given prefix in alphabetical order.
+func (s *Store) ListKeys(prefix string) (keyval.BytesKeyIterator, error) {
+	return s.root().ListKeys(prefix)
+}
+
+// Delete removes the <key> (or all keys with the given prefix if datasync.WithPrefix is used).
+func (s *Store) Delete(key string, opts ...datasync.DelOption) (existed bool, err error) {
+	return s.root().Delete(key, opts...)
+}
+
+// NewTxn creates a transaction.
+func (s *Store) NewTxn() keyval.BytesTxn {
+	return s.root().NewTxn()
+}
+
+// PutIfNotExists stores <data> under the <key> only if the key does not exist.
+func (s *Store) PutIfNotExists(key string, data []byte) (succeeded bool, err error) {
+	return s.root().PutIfNotExists(key, data)
+}
+
+// CompareAndSwap replaces <oldData> stored under the <key> with <newData>.
+func (s *Store) CompareAndSwap(key string, oldData, newData []byte) (swapped bool, err error) {
+	return s.root().CompareAndSwap(key, oldData, newData)
+}
+
+// CompareAndDelete removes the <key> if it stores <data>.
+func (s *Store) CompareAndDelete(key string, data []byte) (deleted bool, err error) {
+	return s.root().CompareAndDelete(key, data)
+}
+
+// Watch subscribes for changes of keys with the given prefixes.
+func (s *Store) Watch(resp func(keyval.BytesWatchResp), closeChan chan string, keys ...string) error {
+	return s.root().Watch(resp, closeChan, keys...)
+}
+
+func (s *Store) root() *broker {
+	return &broker{store: s}
+}
+
+// call counts the operation and applies the matching fault.
+func (s *Store) call(op Op, key string) error {
+	s.mu.Lock()
+	s.ops[op]++
+	s.mu.Unlock()
+
+	return s.checkFault(op, key)
+}
+
+// put stores the value and returns the watch event. Must be called with the lock held.
+func (s *Store) put(key string, value []byte) *watchEvent {
+	s.revision++
+	ev := &watchEvent{op: datasync.Put, key: key, value: copyBytes(value), revision: s.revision}
+	if prev, ok := s.data[key]; ok {
+		ev.prevValue = prev.value
+	}
+	s.data[key] = &entry{value: ev.value, revision: s.revision}
+	return ev
+}
+
+// delete removes the key and returns the watch event. Must be called with the lock held.
+func (s *Store) delete(key string) *watchEvent {
+	prev, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	s.revision++
+	delete(s.data, key)
+	return &watchEvent{op: datasync.Delete, key: key, prevValue: prev.value, revision: s.revision}
+}
+
+// keys returns sorted keys with the given prefix. Must be called with the lock held.
+func (s *Store) keys(prefix string) []string {
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commit applies operations of the transaction atomically.
+func (s *Store) commit(ctx context.Context, ops []txnOp) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var key string
+	if len(ops) > 0 {
+		key = ops[0].key
+	}
+	if err := s.call(OpCommit, key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	var events []*watchEvent
+	for _, op := range ops {
+		var ev *watchEvent
+		if op.delete {
+			ev = s.delete(op.key)
+		} else {
+			ev = s.put(op.key, op.value)
+		}
+		if ev != nil {
+			events = append(events, ev)
+		}
+	}
+	s.mu.Unlock()
+
+	s.notify(events...)
+	return nil
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte{}, data...)
+}
+
+func equalValues(stored *entry, data []byte) bool {
+	return stored != nil && bytes.Equal(stored.value, data)
+}
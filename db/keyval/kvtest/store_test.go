@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/datasync/syncbase"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+)
+
+func TestBrokerAndWatcher(t *testing.T) {
+	RegisterTestingT(t)
+
+	var store keyval.CoreBrokerWatcher = NewStore()
+	broker := store.NewBroker("/config/")
+
+	var events []keyval.BytesWatchResp
+	closeCh := make(chan string)
+	Expect(store.NewWatcher("/config/").Watch(func(resp keyval.BytesWatchResp) {
+		events = append(events, resp)
+	}, closeCh, "a")).To(Succeed())
+
+	Expect(broker.Put("a/1", []byte("x"))).To(Succeed())
+	Expect(broker.Put("a/1", []byte("y"))).To(Succeed())
+	Expect(broker.Put("b/1", []byte("z"))).To(Succeed())
+
+	data, found, rev, err := broker.GetValue("a/1")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(string(data)).To(Equal("y"))
+	Expect(rev).To(BeEquivalentTo(2))
+
+	// watch events are delivered before the write returns
+	Expect(events).To(HaveLen(2))
+	Expect(events[1].GetKey()).To(Equal("a/1"))
+	Expect(string(events[1].GetPrevValue())).To(Equal("x"))
+
+	it, err := store.ListKeys("/config/")
+	Expect(err).ToNot(HaveOccurred())
+	key, _, stop := it.GetNext()
+	Expect(stop).To(BeFalse())
+	Expect(key).To(Equal("/config/a/1"))
+
+	Expect(broker.NewTxn().Put("a/2", []byte("v")).Delete("b/1").Commit(context.Background())).To(Succeed())
+	Expect(events).To(HaveLen(3))
+	existed, err := broker.Delete("a", datasync.WithPrefix())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(existed).To(BeTrue())
+	Expect(events).To(HaveLen(5))
+	Expect(events[4].GetChangeType()).To(Equal(datasync.Delete))
+	Expect(store.(*Store).Len()).To(BeZero())
+
+	// cancel the watch
+	closeCh <- "a"
+	Eventually(func() int {
+		store.(*Store).mu.Lock()
+		defer store.(*Store).mu.Unlock()
+		return len(store.(*Store).watches)
+	}).Should(BeZero())
+}
+
+func TestAtomic(t *testing.T) {
+	RegisterTestingT(t)
+
+	var broker keyval.BytesBrokerWithAtomic = NewStore()
+	Expect(broker.PutIfNotExists("k", []byte("1"))).To(BeTrue())
+	Expect(broker.PutIfNotExists("k", []byte("2"))).To(BeFalse())
+	Expect(broker.CompareAndSwap("k", []byte("2"), []byte("3"))).To(BeFalse())
+	Expect(broker.CompareAndSwap("k", []byte("1"), []byte("3"))).To(BeTrue())
+	Expect(broker.CompareAndDelete("k", []byte("1"))).To(BeFalse())
+	Expect(broker.CompareAndDelete("k", []byte("3"))).To(BeTrue())
+}
+
+func TestFaults(t *testing.T) {
+	RegisterTestingT(t)
+
+	store := NewStore()
+	errDown := errors.New("down")
+	store.InjectFault(Fault{Op: OpPut, Key: "/config/", Err: errDown, Times: 1})
+	store.InjectFault(Fault{Op: OpNotify, Key: "/config/b"})
+
+	var events int
+	Expect(store.Watch(func(keyval.BytesWatchResp) { events++ }, nil, "/")).To(Succeed())
+
+	Expect(store.Put("/config/a", []byte("1"))).To(Equal(errDown))
+	Expect(store.Put("/other", []byte("1"))).To(Succeed())
+	Expect(store.Put("/config/a", []byte("1"))).To(Succeed())
+	Expect(store.Put("/config/b", []byte("1"))).To(Succeed())
+	Expect(events).To(Equal(2))
+	Expect(store.Calls(OpPut)).To(Equal(4))
+
+	store.ClearFaults()
+	Expect(store.Put("/config/b", []byte("2"))).To(Succeed())
+	Expect(events).To(Equal(3))
+}
+
+func TestProtoPlugin(t *testing.T) {
+	RegisterTestingT(t)
+
+	var plugin keyval.KvProtoPlugin = NewPlugin(NewStore())
+	broker := plugin.NewBroker("/status/")
+	Expect(broker.Put("etcd", &status.PluginStatus{Name: "etcd", State: status.OperationalState_OK})).To(Succeed())
+
+	value := &status.PluginStatus{}
+	found, _, err := broker.GetValue("etcd", value)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(value.State).To(Equal(status.OperationalState_OK))
+}
+
+func TestProtoTxnSetValues(t *testing.T) {
+	RegisterTestingT(t)
+
+	broker := NewPlugin(NewStore()).NewBroker("/status/")
+	Expect(broker.Put("old", &status.PluginStatus{Name: "old"})).To(Succeed())
+
+	Expect(broker.NewTxn().SetValues(map[string]datasync.LazyValue{
+		"etcd": syncbase.NewChange("etcd", &status.PluginStatus{Name: "etcd"}, 0, datasync.Put),
+		"old":  nil,
+	}).Commit(context.Background())).To(Succeed())
+
+	value := &status.PluginStatus{}
+	found, _, err := broker.GetValue("etcd", value)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(found).To(BeTrue())
+	Expect(value.Name).To(Equal("etcd"))
+	found, _, _ = broker.GetValue("old", value)
+	Expect(found).To(BeFalse())
+
+	// values without proto message cannot be serialized
+	err = broker.NewTxn().SetValues(map[string]datasync.LazyValue{
+		"raw": syncbase.NewKeyValBytes("raw", []byte("{}"), 0),
+	}).Commit(context.Background())
+	Expect(err).To(HaveOccurred())
+}
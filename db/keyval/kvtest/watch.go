@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvtest
+
+import (
+	"strings"
+
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+)
+
+// watch is a subscription for changes of keys with the given prefix.
+type watch struct {
+	key     string // watched key as passed to Watch (without the broker prefix)
+	prefix  string // full watched prefix
+	strip   string // broker prefix removed from keys of events
+	cb      func(keyval.BytesWatchResp)
+	closeCh chan string
+}
+
+// watchEvent implements keyval.BytesWatchResp.
+type watchEvent struct {
+	op        datasync.Op
+	key       string
+	value     []byte
+	prevValue []byte
+	revision  int64
+}
+
+// GetChangeType returns the type of the change.
+func (ev *watchEvent) GetChangeType() datasync.Op {
+	return ev.op
+}
+
+// GetKey returns the changed key.
+func (ev *watchEvent) GetKey() string {
+	return ev.key
+}
+
+// GetValue returns the new value (nil for delete).
+func (ev *watchEvent) GetValue() []byte {
+	return ev.value
+}
+
+// GetPrevValue returns the previous value.
+func (ev *watchEvent) GetPrevValue() []byte {
+	return ev.prevValue
+}
+
+// GetRevision returns the revision of the change.
+func (ev *watchEvent) GetRevision() int64 {
+	return ev.revision
+}
+
+// Watch subscribes for changes of keys with the given prefixes. The broker
+// prefix is prepended to watched keys and removed from the keys of events.
+// Sending a key into <closeChan> cancels watching of that key, closing the
+// channel cancels all watches registered with it.
+func (b *broker) Watch(resp func(keyval.BytesWatchResp), closeChan chan string, keys ...string) error {
+	s := b.store
+	for _, key := range keys {
+		if err := s.call(OpWatch, b.prefix+key); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.watches = append(s.watches, &watch{
+			key:     key,
+			prefix:  b.prefix + key,
+			strip:   b.prefix,
+			cb:      resp,
+			closeCh: closeChan,
+		})
+	}
+	if _, running := s.closers[closeChan]; !running && closeChan != nil {
+		s.closers[closeChan] = struct{}{}
+		go s.watchClose(closeChan)
+	}
+	return nil
+}
+
+// watchClose removes watches cancelled through the close channel.
+func (s *Store) watchClose(closeCh chan string) {
+	for {
+		key, ok := <-closeCh
+
+		s.mu.Lock()
+		var remaining []*watch
+		for _, w := range s.watches {
+			if w.closeCh != closeCh || (ok && w.key != key) {
+				remaining = append(remaining, w)
+			}
+		}
+		s.watches = remaining
+		if !ok {
+			delete(s.closers, closeCh)
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// notify delivers events to matching watches.
+func (s *Store) notify(events ...*watchEvent) {
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		s.mu.Lock()
+		s.ops[OpNotify]++
+		dropped := s.fault(OpNotify, ev.key) != nil
+		var watches []*watch
+		if !dropped {
+			for _, w := range s.watches {
+				if strings.HasPrefix(ev.key, w.prefix) {
+					watches = append(watches, w)
+				}
+			}
+		}
+		s.mu.Unlock()
+
+		for _, w := range watches {
+			resp := *ev
+			resp.key = strings.TrimPrefix(ev.key, w.strip)
+			w.cb(&resp)
+		}
+	}
+}
@@ -51,6 +51,13 @@ type ProtoTxn interface {
 	// Put adds put operation (write formatted <data> under the given <key>)
 	// into the transaction.
 	Put(key string, data proto.Message) ProtoTxn
+	// SetValues adds put operations of multiple values into the transaction
+	// in a single call (e.g. when staging values of a resync). Nil value
+	// is added as delete operation. Transactions serializing the values
+	// into the data store accept only values carrying the proto message
+	// (datasync.WithProtoMessage) and values read from the data store with
+	// the same serializer (kvproto.RawValue), other values fail the Commit.
+	SetValues(values map[string]datasync.LazyValue) ProtoTxn
 	// Delete adds delete operation (removal of <data> under the given <key>)
 	// into the transaction.
 	Delete(key string) ProtoTxn
@@ -93,6 +93,25 @@ type Program struct {
 	// manipulate CPU scheduling during startup, this option allows to "bypass" it,
 	// waiting until the process is fully loaded and then lock it.
 	CPUAffinitySetupDelay time.Duration `json:"cpu-affinity-setup-delay"`
+
+	// MicroserviceLabel is passed to the program as MICROSERVICE_LABEL environment variable,
+	// allowing to run multiple agent instances with different service labels
+	MicroserviceLabel string `json:"microservice-label"`
+
+	// ConfigDir is passed to the program as CONFIG_DIR environment variable, so that every
+	// agent instance can use its own set of config files
+	ConfigDir string `json:"config-dir"`
+
+	// Env is a list of additional environment variables in form "KEY=value"
+	Env []string `json:"env"`
+
+	// StartAfter is a list of program names which must be running before this program
+	// is started. Programs are stopped in reverse order.
+	StartAfter []string `json:"start-after"`
+
+	// StartupTimeout defines how long to wait for programs listed in StartAfter to start
+	// running (default 30s). The program is not started if the timeout expires.
+	StartupTimeout time.Duration `json:"startup-timeout"`
 }
 
 // Hook is a procedure called when a program gets into certain state.
@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supervisor
+
+import (
+	"github.com/pkg/errors"
+)
+
+// startOrder returns programs sorted so that every program follows all programs
+// it should be started after. Order of the config file is preserved otherwise.
+func startOrder(programs []Program) ([]Program, error) {
+	byName := make(map[string]int, len(programs))
+	for i, program := range programs {
+		if _, ok := byName[program.Name]; ok {
+			return nil, errors.Errorf("duplicate program name %s", program.Name)
+		}
+		byName[program.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state   = make([]int, len(programs))
+		ordered []Program
+		visit   func(i int, path []string) error
+	)
+	visit = func(i int, path []string) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("cyclic program dependency: %v", append(path, programs[i].Name))
+		}
+		state[i] = visiting
+		for _, dep := range programs[i].StartAfter {
+			j, ok := byName[dep]
+			if !ok {
+				return errors.Errorf("program %s should start after unknown program %s", programs[i].Name, dep)
+			}
+			if err := visit(j, append(path, programs[i].Name)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		ordered = append(ordered, programs[i])
+		return nil
+	}
+
+	for i := range programs {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supervisor
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func names(programs []Program) (names []string) {
+	for _, program := range programs {
+		names = append(names, program.Name)
+	}
+	return names
+}
+
+func TestStartOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	ordered, err := startOrder([]Program{
+		{Name: "agent1", StartAfter: []string{"vpp", "etcd"}},
+		{Name: "vpp"},
+		{Name: "agent2", StartAfter: []string{"agent1"}},
+		{Name: "etcd"},
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(names(ordered)).To(Equal([]string{"vpp", "etcd", "agent1", "agent2"}))
+}
+
+func TestStartOrderErrors(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := startOrder([]Program{
+		{Name: "a", StartAfter: []string{"b"}},
+		{Name: "b", StartAfter: []string{"a"}},
+	})
+	Expect(err).To(MatchError(ContainSubstring("cyclic")))
+
+	_, err = startOrder([]Program{{Name: "a", StartAfter: []string{"c"}}})
+	Expect(err).To(MatchError(ContainSubstring("unknown program c")))
+
+	_, err = startOrder([]Program{{Name: "a"}, {Name: "a"}})
+	Expect(err).To(MatchError(ContainSubstring("duplicate")))
+}
+
+func TestValidPrograms(t *testing.T) {
+	RegisterTestingT(t)
+
+	valid, skipped := validPrograms([]Program{
+		{Name: "agent1", ExecutablePath: "/bin/agent", StartAfter: []string{"vpp"}},
+		{Name: "vpp"},
+		{Name: "agent2", ExecutablePath: "/bin/agent", StartAfter: []string{"etcd", "agent1"}},
+		{Name: "etcd", ExecutablePath: "/bin/etcd"},
+	})
+	Expect(names(valid)).To(Equal([]string{"etcd"}))
+	Expect(skipped).To(HaveLen(3))
+	Expect(skipped[0].name).To(Equal("vpp"))
+	Expect(skipped[0].err).To(MatchError(ContainSubstring("executable is not defined")))
+	Expect(skipped[1].name).To(Equal("agent1"))
+	Expect(skipped[1].err).To(MatchError(ContainSubstring("after program vpp")))
+	Expect(skipped[2].name).To(Equal("agent2"))
+	Expect(skipped[2].err).To(MatchError(ContainSubstring("after program agent1")))
+
+	_, err := startOrder(valid)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+func TestProgramEnv(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(programEnv(&Program{})).To(BeNil())
+	env := programEnv(&Program{MicroserviceLabel: "agent1", ConfigDir: "/opt/agent1", Env: []string{"A=b"}})
+	Expect(env).To(ContainElement("MICROSERVICE_LABEL=agent1"))
+	Expect(env).To(ContainElement("CONFIG_DIR=/opt/agent1"))
+	Expect(env).To(ContainElement("A=b"))
+}
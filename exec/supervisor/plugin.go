@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/ligato/cn-infra/exec/processmanager/status"
 
@@ -28,6 +29,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// defaultStartupTimeout is used to wait for programs the started program depends on
+	defaultStartupTimeout = 30 * time.Second
+	// environment variables used by agents to set service label and to locate config files
+	// (servicelabel and config packages are not imported to avoid registering their flags)
+	microserviceLabelEnvVar = "MICROSERVICE_LABEL"
+	configDirEnvVar         = "CONFIG_DIR"
+)
+
 // Supervisor is a simple interface to gather information about running programs
 type Supervisor interface {
 	// GetProgramNames returns names of all running program instances
@@ -42,6 +52,14 @@ type Plugin struct {
 	// the entry is still present with last returned state.
 	mx       sync.Mutex
 	programs map[string]*processWithStateChan
+	// names of started programs in the order they were started
+	order []string
+	// readiness of all configured programs
+	ready map[string]*readiness
+
+	// closed when the plugin is closing to interrupt starting of programs
+	stopChan      chan struct{}
+	startDoneChan chan struct{}
 
 	// hook channel where all executed programs send their process state
 	hookEventChan chan *processEvent
@@ -70,6 +88,16 @@ type processWithStateChan struct {
 	svLogger  *SvLogger
 }
 
+// readiness is closed once the program is running for the first time
+type readiness struct {
+	ch   chan struct{}
+	once sync.Once
+}
+
+func (r *readiness) set() {
+	r.once.Do(func() { close(r.ch) })
+}
+
 // helper structure with program name, status and event type. The object is passed to the hook
 // resolver by every process watcher
 type processEvent struct {
@@ -87,9 +115,25 @@ func (p *Plugin) Init() error {
 	if p.config == nil || len(p.config.Programs) == 0 {
 		return errors.Errorf("supervisor config file not defined or does not contain any program")
 	}
+	programs, skipped := validPrograms(p.config.Programs)
+	for _, program := range skipped {
+		p.Log.Errorf("cannot start program %s: %v", program.name, program.err)
+	}
+	programs, err := startOrder(programs)
+	if err != nil {
+		return errors.Errorf("invalid supervisor config: %v", err)
+	}
+	p.config.Programs = programs
+
 	p.programs = make(map[string]*processWithStateChan)
+	p.ready = make(map[string]*readiness)
+	for _, program := range programs {
+		p.ready[program.Name] = &readiness{ch: make(chan struct{})}
+	}
 	p.hookEventChan = make(chan *processEvent)
 	p.hookDoneChan = make(chan struct{})
+	p.stopChan = make(chan struct{})
+	p.startDoneChan = make(chan struct{})
 
 	if p.config.SvCPUAffinityMask != "" {
 		p.setSupervisorCPUAffinity(os.Getpid(), p.config.SvCPUAffinityMask)
@@ -104,8 +148,18 @@ func (p *Plugin) Init() error {
 
 // Close local resources
 func (p *Plugin) Close() error {
+	if p.stopChan == nil {
+		// init failed before any program was started
+		return nil
+	}
+	// interrupt starting of programs and wait until it is done
+	close(p.stopChan)
+	<-p.startDoneChan
+
 	p.Log.Info("stopping programs")
-	for _, program := range p.programs {
+	// stop programs in reverse order
+	for i := len(p.order) - 1; i >= 0; i-- {
+		program := p.programs[p.order[i]]
 		if program.process.IsAlive() {
 			if _, err := program.process.StopAndWait(); err != nil {
 				p.Log.Errorf("failed to stop program %s: %v", program.process.GetName(), err)
@@ -153,8 +207,10 @@ func (p *Plugin) GetProgramByName(reqName string) pm.ProcessInstance {
 }
 
 func (p *Plugin) startPrograms() {
+	defer close(p.startDoneChan)
+
 	for _, program := range p.config.Programs {
-		if err := validate(&program); err != nil {
+		if err := p.waitForDependencies(&program); err != nil {
 			p.Log.Errorf("cannot start program %s: %v", program.Name, err)
 			continue
 		}
@@ -166,6 +222,30 @@ func (p *Plugin) startPrograms() {
 	}
 }
 
+// waitForDependencies blocks until all programs the given program should start after are running
+func (p *Plugin) waitForDependencies(program *Program) error {
+	if len(program.StartAfter) == 0 {
+		return nil
+	}
+	timeout := program.StartupTimeout
+	if timeout == 0 {
+		timeout = defaultStartupTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for _, dep := range program.StartAfter {
+		select {
+		case <-p.ready[dep].ch:
+		case <-timer.C:
+			return errors.Errorf("program %s is not running after %v", dep, timeout)
+		case <-p.stopChan:
+			return errors.Errorf("supervisor is closing")
+		}
+	}
+	return nil
+}
+
 func (p *Plugin) execute(program *Program) error {
 	if _, ok := p.programs[program.Name]; ok {
 		return errors.Errorf("process with name %s already exists", program.Name)
@@ -182,35 +262,34 @@ func (p *Plugin) execute(program *Program) error {
 	p.wg.Add(1)
 	go p.watch(stateChan, doneChan, program.Name)
 
-	var process pm.ProcessInstance
+	options := []pm.POption{
+		pm.Args(program.ExecutableArgs...),
+		pm.Writer(svLogger, svLogger),
+		pm.Notify(stateChan),
+		pm.AutoTerminate(),
+		pm.CPUAffinityMask(program.CPUAffinityMask, program.CPUAffinitySetupDelay),
+	}
 	if program.Restarts > 0 {
-		process = p.PM.NewProcess(program.Name, program.ExecutablePath,
-			pm.Args(program.ExecutableArgs...),
-			pm.Writer(svLogger, svLogger),
-			pm.Notify(stateChan),
-			pm.Restarts(int32(program.Restarts)),
-			pm.AutoTerminate(),
-			pm.CPUAffinityMask(program.CPUAffinityMask, program.CPUAffinitySetupDelay),
-		)
-	} else {
-		process = p.PM.NewProcess(program.Name, program.ExecutablePath,
-			pm.Args(program.ExecutableArgs...),
-			pm.Writer(svLogger, svLogger),
-			pm.Notify(stateChan),
-			pm.AutoTerminate(),
-			pm.CPUAffinityMask(program.CPUAffinityMask, program.CPUAffinitySetupDelay),
-		)
+		options = append(options, pm.Restarts(int32(program.Restarts)))
+	}
+	if env := programEnv(program); env != nil {
+		options = append(options, pm.EnvVar(env))
 	}
+	process := p.PM.NewProcess(program.Name, program.ExecutablePath, options...)
 	if err := process.Start(); err != nil {
+		close(doneChan)
 		return errors.Errorf("error starting process: %v", err)
 	}
 
+	p.mx.Lock()
 	p.programs[program.Name] = &processWithStateChan{
 		process:   process,
 		stateChan: stateChan,
 		doneChan:  doneChan,
 		svLogger:  svLogger,
 	}
+	p.order = append(p.order, program.Name)
+	p.mx.Unlock()
 
 	return nil
 }
@@ -224,6 +303,9 @@ func (p *Plugin) watch(stateChan chan status.ProcessStatus, doneChan chan struct
 			if !ok {
 				return
 			}
+			if state == status.Running || state == status.Sleeping || state == status.Idle {
+				p.ready[name].set()
+			}
 
 			// forward info to the hook
 			p.hookEventChan <- &processEvent{
@@ -249,6 +331,69 @@ func (p *Plugin) setSupervisorCPUAffinity(pid int, affinity string) {
 	p.Log.Debugf("CPU affinity of the supervisor changed to %s", affinity)
 }
 
+// programEnv returns environment of the program, or nil if the supervisor environment is used
+func programEnv(program *Program) []string {
+	var env []string
+	if program.MicroserviceLabel != "" {
+		env = append(env, microserviceLabelEnvVar+"="+program.MicroserviceLabel)
+	}
+	if program.ConfigDir != "" {
+		env = append(env, configDirEnvVar+"="+program.ConfigDir)
+	}
+	env = append(env, program.Env...)
+	if len(env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), env...)
+}
+
+// skippedProgram is a program which cannot be started, with the reason.
+type skippedProgram struct {
+	name string
+	err  error
+}
+
+// validPrograms returns programs with valid configuration. Programs which should
+// start after a program that cannot be started are skipped as well.
+func validPrograms(programs []Program) (valid []Program, skipped []skippedProgram) {
+	invalid := make(map[string]struct{})
+	for _, program := range programs {
+		if err := validate(&program); err != nil {
+			invalid[program.Name] = struct{}{}
+			skipped = append(skipped, skippedProgram{name: program.Name, err: err})
+			continue
+		}
+		valid = append(valid, program)
+	}
+	// skip dependents until there are no more programs depending on a skipped one
+	for removed := true; removed; {
+		removed = false
+		remaining := valid[:0]
+		for _, program := range valid {
+			if dep, ok := dependsOn(program, invalid); ok {
+				invalid[program.Name] = struct{}{}
+				skipped = append(skipped, skippedProgram{name: program.Name,
+					err: errors.Errorf("it should start after program %s which cannot be started", dep)})
+				removed = true
+				continue
+			}
+			remaining = append(remaining, program)
+		}
+		valid = remaining
+	}
+	return valid, skipped
+}
+
+// dependsOn returns the first program from <names> the program should start after.
+func dependsOn(program Program, names map[string]struct{}) (string, bool) {
+	for _, dep := range program.StartAfter {
+		if _, ok := names[dep]; ok {
+			return dep, true
+		}
+	}
+	return "", false
+}
+
 func validate(program *Program) error {
 	if program.Name == "" && program.ExecutablePath == "" {
 		return errors.Errorf("invalid program configuration: neither program name nor binary is defined")
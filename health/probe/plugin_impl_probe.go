@@ -101,7 +101,8 @@ func (p *Plugin) readinessProbeHandler(formatter *render.Render) http.HandlerFun
 		agentStat := p.getAgentStatus()
 		agentStat.InterfaceStats = &ifStat
 		agentStatJSON, _ := json.Marshal(agentStat)
-		if agentStat.State == status.OperationalState_OK {
+		// degraded agent keeps serving with the last known configuration
+		if agentStat.State == status.OperationalState_OK || agentStat.State == status.OperationalState_DEGRADED {
 			w.WriteHeader(http.StatusOK)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -116,7 +117,8 @@ func (p *Plugin) livenessProbeHandler(formatter *render.Render) http.HandlerFunc
 		stat := p.getAgentStatus()
 		statJSON, _ := json.Marshal(stat)
 
-		if stat.State == status.OperationalState_INIT || stat.State == status.OperationalState_OK {
+		if stat.State == status.OperationalState_INIT || stat.State == status.OperationalState_OK ||
+			stat.State == status.OperationalState_DEGRADED {
 			w.WriteHeader(http.StatusOK)
 			w.Write(statJSON)
 		} else {
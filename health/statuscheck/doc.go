@@ -30,4 +30,11 @@
 //   statuscheck.ReportStateChange(PluginID, statuscheck.OK, nil)
 //
 // The default status of a plugin after registering is Init.
+//
+// The status of every plugin is described by the structured model defined
+// in model/status: state, details of the last error (including metadata of
+// its infra.ErrorClass), plugin-specific counters reported through
+// ReportCounters() and time of the last change/update. The status is
+// published into the KV store and can be exposed via REST and gRPC using
+// the statusapi plugin.
 package statuscheck
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statuscheck
+
+import (
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/utils/eventbus"
+)
+
+// PluginStateChanged is the topic of PluginStateEvent published
+// on eventbus.DefaultBus whenever the state or error of a plugin changes.
+var PluginStateChanged = eventbus.NewTopic("statuscheck/plugin-state", PluginStateEvent{})
+
+// AgentStateChanged is the topic of AgentStateEvent published
+// on eventbus.DefaultBus whenever the overall state of the agent changes.
+var AgentStateChanged = eventbus.NewTopic("statuscheck/agent-state", AgentStateEvent{})
+
+// PluginStateEvent describes a change of the plugin status.
+type PluginStateEvent struct {
+	Plugin    string
+	State     status.OperationalState
+	PrevState status.OperationalState
+	// Error is nil if the plugin reported no error.
+	Error *status.ErrorDetails
+}
+
+// AgentStateEvent describes a change of the overall agent state.
+type AgentStateEvent struct {
+	State     status.OperationalState
+	PrevState status.OperationalState
+}
+
+// stateEvents collects events to be published once the plugin data are unlocked.
+type stateEvents struct {
+	plugin *PluginStateEvent
+	agent  *AgentStateEvent
+}
+
+func (e *stateEvents) agentChanged(prev, state status.OperationalState) {
+	if prev != state {
+		e.agent = &AgentStateEvent{State: state, PrevState: prev}
+	}
+}
+
+func (e *stateEvents) publish() {
+	if e.plugin != nil {
+		eventbus.DefaultBus.Publish(PluginStateChanged, *e.plugin)
+	}
+	if e.agent != nil {
+		eventbus.DefaultBus.Publish(AgentStateChanged, *e.agent)
+	}
+}
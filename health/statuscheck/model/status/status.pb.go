@@ -6,8 +6,12 @@
 package status
 
 import (
+	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	math "math"
 )
 
@@ -25,21 +29,24 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 type OperationalState int32
 
 const (
-	OperationalState_INIT  OperationalState = 0
-	OperationalState_OK    OperationalState = 1
-	OperationalState_ERROR OperationalState = 2
+	OperationalState_INIT     OperationalState = 0
+	OperationalState_OK       OperationalState = 1
+	OperationalState_ERROR    OperationalState = 2
+	OperationalState_DEGRADED OperationalState = 3
 )
 
 var OperationalState_name = map[int32]string{
 	0: "INIT",
 	1: "OK",
 	2: "ERROR",
+	3: "DEGRADED",
 }
 
 var OperationalState_value = map[string]int32{
-	"INIT":  0,
-	"OK":    1,
-	"ERROR": 2,
+	"INIT":     0,
+	"OK":       1,
+	"ERROR":    2,
+	"DEGRADED": 3,
 }
 
 func (x OperationalState) String() string {
@@ -154,14 +161,17 @@ func (m *AgentStatus) GetPlugins() []*PluginStatus {
 }
 
 type PluginStatus struct {
-	Name                 string           `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	State                OperationalState `protobuf:"varint,2,opt,name=state,proto3,enum=status.OperationalState" json:"state,omitempty"`
-	LastChange           int64            `protobuf:"varint,3,opt,name=last_change,json=lastChange,proto3" json:"last_change,omitempty"`
-	LastUpdate           int64            `protobuf:"varint,4,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"`
-	Error                string           `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+	Name                 string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	State                OperationalState  `protobuf:"varint,2,opt,name=state,proto3,enum=status.OperationalState" json:"state,omitempty"`
+	LastChange           int64             `protobuf:"varint,3,opt,name=last_change,json=lastChange,proto3" json:"last_change,omitempty"`
+	LastUpdate           int64             `protobuf:"varint,4,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"`
+	Error                string            `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode            string            `protobuf:"bytes,6,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ErrorDetails         *ErrorDetails     `protobuf:"bytes,7,opt,name=error_details,json=errorDetails,proto3" json:"error_details,omitempty"`
+	Counters             map[string]uint64 `protobuf:"bytes,8,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *PluginStatus) Reset()         { *m = PluginStatus{} }
@@ -224,6 +234,115 @@ func (m *PluginStatus) GetError() string {
 	return ""
 }
 
+func (m *PluginStatus) GetErrorCode() string {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return ""
+}
+
+func (m *PluginStatus) GetErrorDetails() *ErrorDetails {
+	if m != nil {
+		return m.ErrorDetails
+	}
+	return nil
+}
+
+func (m *PluginStatus) GetCounters() map[string]uint64 {
+	if m != nil {
+		return m.Counters
+	}
+	return nil
+}
+
+// ErrorDetails describes the last error seen in a plugin.
+type ErrorDetails struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Plugin               string   `protobuf:"bytes,3,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Retriable            bool     `protobuf:"varint,4,opt,name=retriable,proto3" json:"retriable,omitempty"`
+	Severity             string   `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	Docs                 string   `protobuf:"bytes,6,opt,name=docs,proto3" json:"docs,omitempty"`
+	Time                 int64    `protobuf:"varint,7,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ErrorDetails) Reset()         { *m = ErrorDetails{} }
+func (m *ErrorDetails) String() string { return proto.CompactTextString(m) }
+func (*ErrorDetails) ProtoMessage()    {}
+func (*ErrorDetails) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dfe4fce6682daf5b, []int{2}
+}
+
+func (m *ErrorDetails) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ErrorDetails.Unmarshal(m, b)
+}
+func (m *ErrorDetails) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ErrorDetails.Marshal(b, m, deterministic)
+}
+func (m *ErrorDetails) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ErrorDetails.Merge(m, src)
+}
+func (m *ErrorDetails) XXX_Size() int {
+	return xxx_messageInfo_ErrorDetails.Size(m)
+}
+func (m *ErrorDetails) XXX_DiscardUnknown() {
+	xxx_messageInfo_ErrorDetails.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ErrorDetails proto.InternalMessageInfo
+
+func (m *ErrorDetails) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *ErrorDetails) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *ErrorDetails) GetPlugin() string {
+	if m != nil {
+		return m.Plugin
+	}
+	return ""
+}
+
+func (m *ErrorDetails) GetRetriable() bool {
+	if m != nil {
+		return m.Retriable
+	}
+	return false
+}
+
+func (m *ErrorDetails) GetSeverity() string {
+	if m != nil {
+		return m.Severity
+	}
+	return ""
+}
+
+func (m *ErrorDetails) GetDocs() string {
+	if m != nil {
+		return m.Docs
+	}
+	return ""
+}
+
+func (m *ErrorDetails) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
 type InterfaceStats struct {
 	Interfaces           []*InterfaceStats_Interface `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                    `json:"-"`
@@ -235,7 +354,7 @@ func (m *InterfaceStats) Reset()         { *m = InterfaceStats{} }
 func (m *InterfaceStats) String() string { return proto.CompactTextString(m) }
 func (*InterfaceStats) ProtoMessage()    {}
 func (*InterfaceStats) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dfe4fce6682daf5b, []int{2}
+	return fileDescriptor_dfe4fce6682daf5b, []int{3}
 }
 
 func (m *InterfaceStats) XXX_Unmarshal(b []byte) error {
@@ -278,7 +397,7 @@ func (m *InterfaceStats_Interface) Reset()         { *m = InterfaceStats_Interfa
 func (m *InterfaceStats_Interface) String() string { return proto.CompactTextString(m) }
 func (*InterfaceStats_Interface) ProtoMessage()    {}
 func (*InterfaceStats_Interface) Descriptor() ([]byte, []int) {
-	return fileDescriptor_dfe4fce6682daf5b, []int{2, 0}
+	return fileDescriptor_dfe4fce6682daf5b, []int{3, 0}
 }
 
 func (m *InterfaceStats_Interface) XXX_Unmarshal(b []byte) error {
@@ -334,45 +453,367 @@ func (m *InterfaceStats_Interface) GetMacAddress() string {
 	return ""
 }
 
+type AgentStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AgentStatusRequest) Reset()         { *m = AgentStatusRequest{} }
+func (m *AgentStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*AgentStatusRequest) ProtoMessage()    {}
+func (*AgentStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dfe4fce6682daf5b, []int{4}
+}
+
+func (m *AgentStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AgentStatusRequest.Unmarshal(m, b)
+}
+func (m *AgentStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AgentStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *AgentStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AgentStatusRequest.Merge(m, src)
+}
+func (m *AgentStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_AgentStatusRequest.Size(m)
+}
+func (m *AgentStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AgentStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AgentStatusRequest proto.InternalMessageInfo
+
+type PluginStatusRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PluginStatusRequest) Reset()         { *m = PluginStatusRequest{} }
+func (m *PluginStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*PluginStatusRequest) ProtoMessage()    {}
+func (*PluginStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dfe4fce6682daf5b, []int{5}
+}
+
+func (m *PluginStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PluginStatusRequest.Unmarshal(m, b)
+}
+func (m *PluginStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PluginStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *PluginStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PluginStatusRequest.Merge(m, src)
+}
+func (m *PluginStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_PluginStatusRequest.Size(m)
+}
+func (m *PluginStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PluginStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PluginStatusRequest proto.InternalMessageInfo
+
+func (m *PluginStatusRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type ListPluginStatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListPluginStatusRequest) Reset()         { *m = ListPluginStatusRequest{} }
+func (m *ListPluginStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPluginStatusRequest) ProtoMessage()    {}
+func (*ListPluginStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dfe4fce6682daf5b, []int{6}
+}
+
+func (m *ListPluginStatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPluginStatusRequest.Unmarshal(m, b)
+}
+func (m *ListPluginStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPluginStatusRequest.Marshal(b, m, deterministic)
+}
+func (m *ListPluginStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPluginStatusRequest.Merge(m, src)
+}
+func (m *ListPluginStatusRequest) XXX_Size() int {
+	return xxx_messageInfo_ListPluginStatusRequest.Size(m)
+}
+func (m *ListPluginStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPluginStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPluginStatusRequest proto.InternalMessageInfo
+
+type ListPluginStatusResponse struct {
+	Plugins              []*PluginStatus `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListPluginStatusResponse) Reset()         { *m = ListPluginStatusResponse{} }
+func (m *ListPluginStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*ListPluginStatusResponse) ProtoMessage()    {}
+func (*ListPluginStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dfe4fce6682daf5b, []int{7}
+}
+
+func (m *ListPluginStatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPluginStatusResponse.Unmarshal(m, b)
+}
+func (m *ListPluginStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPluginStatusResponse.Marshal(b, m, deterministic)
+}
+func (m *ListPluginStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPluginStatusResponse.Merge(m, src)
+}
+func (m *ListPluginStatusResponse) XXX_Size() int {
+	return xxx_messageInfo_ListPluginStatusResponse.Size(m)
+}
+func (m *ListPluginStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPluginStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListPluginStatusResponse proto.InternalMessageInfo
+
+func (m *ListPluginStatusResponse) GetPlugins() []*PluginStatus {
+	if m != nil {
+		return m.Plugins
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("status.OperationalState", OperationalState_name, OperationalState_value)
 	proto.RegisterType((*AgentStatus)(nil), "status.AgentStatus")
 	proto.RegisterType((*PluginStatus)(nil), "status.PluginStatus")
+	proto.RegisterMapType((map[string]uint64)(nil), "status.PluginStatus.CountersEntry")
+	proto.RegisterType((*ErrorDetails)(nil), "status.ErrorDetails")
 	proto.RegisterType((*InterfaceStats)(nil), "status.InterfaceStats")
 	proto.RegisterType((*InterfaceStats_Interface)(nil), "status.InterfaceStats.Interface")
+	proto.RegisterType((*AgentStatusRequest)(nil), "status.AgentStatusRequest")
+	proto.RegisterType((*PluginStatusRequest)(nil), "status.PluginStatusRequest")
+	proto.RegisterType((*ListPluginStatusRequest)(nil), "status.ListPluginStatusRequest")
+	proto.RegisterType((*ListPluginStatusResponse)(nil), "status.ListPluginStatusResponse")
 }
 
 func init() { proto.RegisterFile("status.proto", fileDescriptor_dfe4fce6682daf5b) }
 
 var fileDescriptor_dfe4fce6682daf5b = []byte{
-	// 457 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x93, 0xd1, 0x8a, 0xd3, 0x40,
-	0x14, 0x86, 0x9d, 0xb6, 0xc9, 0x6e, 0x4e, 0xbb, 0xb5, 0x0c, 0x65, 0x19, 0x04, 0x31, 0xd4, 0x9b,
-	0xe0, 0x45, 0x85, 0xfa, 0x00, 0xba, 0xa8, 0x60, 0x11, 0xb6, 0x32, 0xae, 0xde, 0x86, 0xd9, 0x64,
-	0x6c, 0x07, 0x92, 0x49, 0x98, 0x99, 0x8a, 0x4f, 0xe3, 0x03, 0xf8, 0x7a, 0xfa, 0x00, 0x32, 0x67,
-	0x92, 0xb6, 0xbb, 0x0a, 0x7a, 0x97, 0xff, 0x3f, 0x7f, 0xe6, 0xcc, 0xf9, 0x72, 0x02, 0x13, 0xeb,
-	0x84, 0xdb, 0xdb, 0x65, 0x6b, 0x1a, 0xd7, 0xd0, 0x38, 0xa8, 0xc5, 0xaf, 0x01, 0x8c, 0xaf, 0xb6,
-	0x52, 0xbb, 0x8f, 0xa8, 0xe9, 0x53, 0xb8, 0xb8, 0xdd, 0xab, 0xaa, 0xcc, 0xbf, 0x4a, 0x63, 0x55,
-	0xa3, 0x19, 0x49, 0x49, 0x96, 0xf0, 0x09, 0x9a, 0x9f, 0x83, 0x47, 0x1f, 0x03, 0x84, 0x50, 0x29,
-	0x9c, 0x64, 0x03, 0x4c, 0x24, 0xe8, 0xbc, 0x11, 0x4e, 0xd2, 0x25, 0x44, 0xfe, 0x74, 0xc9, 0x86,
-	0x29, 0xc9, 0xa6, 0x2b, 0xb6, 0xec, 0x3a, 0x6f, 0x5a, 0x69, 0x84, 0x53, 0x8d, 0x16, 0x95, 0xef,
-	0x26, 0x79, 0x88, 0xf9, 0xe3, 0xac, 0x13, 0xc6, 0xe5, 0x4e, 0xd5, 0x92, 0x8d, 0x52, 0x92, 0x0d,
-	0x79, 0x82, 0xce, 0x8d, 0xaa, 0x25, 0x7d, 0x02, 0xe3, 0x4a, 0x58, 0x97, 0x17, 0x3b, 0xa1, 0xb7,
-	0x92, 0x45, 0x58, 0x07, 0x6f, 0xbd, 0x46, 0xe7, 0x10, 0xd8, 0xb7, 0x78, 0x9f, 0xf8, 0x18, 0xf8,
-	0x84, 0x0e, 0x7d, 0x09, 0x0f, 0x95, 0x76, 0xd2, 0x7c, 0x11, 0x85, 0xcc, 0x7d, 0x4f, 0xcb, 0xce,
-	0x52, 0x92, 0x8d, 0x57, 0x97, 0xfd, 0xd5, 0xd6, 0x7d, 0xd9, 0x5f, 0xcc, 0xf2, 0xa9, 0xba, 0xa3,
-	0x7d, 0x87, 0xa2, 0xa9, 0x6b, 0xe5, 0xf2, 0x9d, 0xb0, 0x3b, 0x76, 0x8e, 0x13, 0x43, 0xb0, 0xde,
-	0x09, 0xbb, 0xa3, 0x4b, 0x38, 0x6b, 0xab, 0xfd, 0x56, 0x69, 0xcb, 0x92, 0x74, 0x98, 0x8d, 0x57,
-	0xf3, 0xfe, 0xe4, 0x0f, 0x68, 0x07, 0xba, 0xbc, 0x0f, 0x2d, 0x7e, 0x10, 0x98, 0x9c, 0x56, 0x28,
-	0x85, 0x91, 0x16, 0xb5, 0xec, 0x70, 0xe3, 0xf3, 0x91, 0xe3, 0xe0, 0xff, 0x38, 0xde, 0x03, 0x35,
-	0xfc, 0x17, 0xa8, 0xd1, 0x1f, 0xa0, 0xe6, 0x10, 0x49, 0x63, 0x1a, 0x83, 0x90, 0x13, 0x1e, 0xc4,
-	0xe2, 0x27, 0x81, 0xe9, 0x5d, 0x40, 0xf4, 0x15, 0xc0, 0x01, 0x91, 0x65, 0x04, 0x47, 0x4e, 0xff,
-	0x0e, 0xf3, 0x28, 0xf9, 0xc9, 0x3b, 0x8f, 0xbe, 0x13, 0x48, 0x0e, 0x15, 0xbf, 0x76, 0x58, 0xd3,
-	0xa2, 0xca, 0x4f, 0x38, 0x4c, 0x7a, 0xf3, 0xda, 0xf3, 0x98, 0x43, 0xa4, 0x74, 0x29, 0xbf, 0xe1,
-	0x64, 0x17, 0x3c, 0x08, 0x7a, 0x09, 0xdd, 0x2e, 0xe3, 0x3c, 0x09, 0xef, 0x94, 0xdf, 0x2a, 0xd5,
-	0xe6, 0xa2, 0x2c, 0x8d, 0xb4, 0xb6, 0x1b, 0x28, 0x51, 0xed, 0x55, 0x30, 0x3c, 0x8b, 0x5a, 0x14,
-	0x87, 0x7a, 0x1c, 0x3e, 0x69, 0x2d, 0x8a, 0x2e, 0xf0, 0xec, 0x39, 0xcc, 0xee, 0x83, 0xa6, 0xe7,
-	0x30, 0x5a, 0x5f, 0xaf, 0x6f, 0x66, 0x0f, 0x68, 0x0c, 0x83, 0xcd, 0xfb, 0x19, 0xa1, 0x09, 0x44,
-	0x6f, 0x39, 0xdf, 0xf0, 0xd9, 0xe0, 0x36, 0xc6, 0x3f, 0xeb, 0xc5, 0xef, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0x32, 0x82, 0x14, 0x91, 0x69, 0x03, 0x00, 0x00,
+	// 751 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x95, 0xcd, 0x6e, 0x1b, 0x37,
+	0x10, 0xc7, 0xbb, 0xfa, 0xde, 0xd1, 0x87, 0x05, 0x5a, 0x70, 0xb7, 0x6a, 0x0b, 0x0b, 0xdb, 0x8b,
+	0xda, 0x83, 0x0e, 0xea, 0xa5, 0x1f, 0x68, 0x13, 0xc1, 0x12, 0x1c, 0x27, 0x81, 0x1d, 0xd0, 0x76,
+	0xae, 0x0b, 0x7a, 0x77, 0x22, 0x11, 0xd9, 0x0f, 0x65, 0x49, 0x09, 0xf1, 0x31, 0x2f, 0x92, 0xc7,
+	0xc8, 0x6b, 0xe5, 0x90, 0x3c, 0x40, 0x40, 0x72, 0x57, 0x5a, 0xd9, 0x32, 0xe2, 0x1b, 0xe7, 0xff,
+	0x1f, 0x91, 0x9c, 0x1f, 0x67, 0x56, 0xd0, 0x12, 0x92, 0xc9, 0x95, 0x18, 0x2d, 0xd3, 0x44, 0x26,
+	0xa4, 0x66, 0x22, 0xf7, 0x6b, 0x09, 0x9a, 0x93, 0x39, 0xc6, 0xf2, 0x52, 0xc7, 0xe4, 0x37, 0x68,
+	0xdf, 0xac, 0x78, 0x18, 0x78, 0x6b, 0x4c, 0x05, 0x4f, 0x62, 0xc7, 0x1a, 0x58, 0x43, 0x9b, 0xb6,
+	0xb4, 0xf8, 0xda, 0x68, 0xe4, 0x57, 0x00, 0x93, 0x14, 0x30, 0x89, 0x4e, 0x49, 0x67, 0xd8, 0x5a,
+	0x99, 0x32, 0x89, 0x64, 0x04, 0x55, 0xb5, 0x3b, 0x3a, 0xe5, 0x81, 0x35, 0xec, 0x8c, 0x9d, 0x51,
+	0x76, 0xf2, 0xc5, 0x12, 0x53, 0x26, 0x79, 0x12, 0xb3, 0x50, 0x9d, 0x86, 0xd4, 0xa4, 0xa9, 0xed,
+	0x84, 0x64, 0xa9, 0xf4, 0x24, 0x8f, 0xd0, 0xa9, 0x0c, 0xac, 0x61, 0x99, 0xda, 0x5a, 0xb9, 0xe2,
+	0x11, 0x92, 0x63, 0x68, 0x86, 0x4c, 0x48, 0xcf, 0x5f, 0xb0, 0x78, 0x8e, 0x4e, 0x55, 0xfb, 0xa0,
+	0xa4, 0x13, 0xad, 0x6c, 0x12, 0x56, 0x4b, 0x7d, 0x9f, 0xda, 0x36, 0xe1, 0x5a, 0x2b, 0xe4, 0x09,
+	0x1c, 0xf0, 0x58, 0x62, 0xfa, 0x86, 0xf9, 0xe8, 0xa9, 0x33, 0x85, 0x53, 0x1f, 0x58, 0xc3, 0xe6,
+	0xf8, 0x28, 0xbf, 0xda, 0x59, 0x6e, 0xab, 0x8b, 0x09, 0xda, 0xe1, 0x3b, 0xb1, 0x3a, 0xc1, 0x4f,
+	0xa2, 0x88, 0x4b, 0x6f, 0xc1, 0xc4, 0xc2, 0x69, 0xe8, 0x8a, 0xc1, 0x48, 0xcf, 0x98, 0x58, 0x90,
+	0x11, 0xd4, 0x97, 0xe1, 0x6a, 0xce, 0x63, 0xe1, 0xd8, 0x83, 0xf2, 0xb0, 0x39, 0xee, 0xe5, 0x3b,
+	0xbf, 0xd2, 0xb2, 0xa1, 0x4b, 0xf3, 0x24, 0xf7, 0x43, 0x19, 0x5a, 0x45, 0x87, 0x10, 0xa8, 0xc4,
+	0x2c, 0xc2, 0x0c, 0xb7, 0x5e, 0x6f, 0x39, 0x96, 0x1e, 0xc7, 0xf1, 0x0e, 0xa8, 0xf2, 0xf7, 0x40,
+	0x55, 0xee, 0x81, 0xea, 0x41, 0x15, 0xd3, 0x34, 0x49, 0x35, 0x64, 0x9b, 0x9a, 0x40, 0xbd, 0x8f,
+	0x5e, 0x78, 0x7e, 0x12, 0x18, 0xbc, 0x36, 0xb5, 0xb5, 0x72, 0x92, 0x04, 0x48, 0xfe, 0x86, 0xb6,
+	0xb1, 0x03, 0x94, 0x8c, 0x87, 0x39, 0xdb, 0x0d, 0x81, 0x99, 0x32, 0xa7, 0xc6, 0xa3, 0x2d, 0x2c,
+	0x44, 0xe4, 0x7f, 0x68, 0xf8, 0xc9, 0x4a, 0xb1, 0x16, 0x4e, 0x43, 0x73, 0x73, 0xf7, 0x71, 0x1b,
+	0x9d, 0x64, 0x49, 0xb3, 0x58, 0xa6, 0xb7, 0x74, 0xf3, 0x9b, 0xfe, 0xbf, 0xd0, 0xde, 0xb1, 0x48,
+	0x17, 0xca, 0x6f, 0xf1, 0x36, 0xa3, 0xa8, 0x96, 0xaa, 0xa4, 0x35, 0x0b, 0x57, 0x06, 0x62, 0x85,
+	0x9a, 0xe0, 0x9f, 0xd2, 0x5f, 0x96, 0xfb, 0xc9, 0x82, 0x56, 0xf1, 0x6e, 0xea, 0x0d, 0x74, 0x85,
+	0xd9, 0x1b, 0xa8, 0x35, 0x71, 0xa0, 0x1e, 0xa1, 0x10, 0x6c, 0x9e, 0xf7, 0x79, 0x1e, 0x92, 0x23,
+	0xa8, 0x99, 0xd7, 0xd4, 0xa0, 0x6d, 0x9a, 0x45, 0xe4, 0x17, 0xb0, 0x53, 0x94, 0x29, 0x67, 0x37,
+	0xa1, 0x41, 0xdc, 0xa0, 0x5b, 0x81, 0xf4, 0xa1, 0x21, 0x70, 0x8d, 0x29, 0x97, 0xb7, 0x19, 0xe4,
+	0x4d, 0xac, 0xce, 0x0f, 0x12, 0x5f, 0x64, 0x84, 0xf5, 0x5a, 0x69, 0x7a, 0x2a, 0xea, 0xfa, 0xad,
+	0xf4, 0xda, 0xfd, 0x62, 0x41, 0x67, 0xb7, 0x61, 0xc9, 0x53, 0x80, 0x4d, 0xcb, 0x0a, 0xc7, 0xd2,
+	0x28, 0x07, 0xfb, 0x9b, 0x7b, 0x1b, 0xd2, 0xc2, 0x6f, 0xfa, 0x1f, 0x2d, 0xb0, 0x37, 0x8e, 0xfa,
+	0x0c, 0x68, 0x2f, 0x66, 0xa1, 0x57, 0xe8, 0xcb, 0x56, 0x2e, 0x9e, 0xab, 0xfe, 0xec, 0x41, 0x95,
+	0xc7, 0x01, 0xbe, 0xd7, 0x00, 0xda, 0xd4, 0x04, 0x8a, 0x8b, 0x39, 0x57, 0x17, 0x6f, 0xd3, 0x2c,
+	0x52, 0x5d, 0xc4, 0x97, 0x1e, 0x0b, 0x82, 0x14, 0x85, 0xc8, 0x6a, 0xb7, 0xf9, 0x72, 0x62, 0x04,
+	0xd5, 0x9b, 0x11, 0xf3, 0x37, 0xbe, 0x61, 0x00, 0x11, 0xf3, 0xb3, 0x04, 0xb7, 0x07, 0xa4, 0xf0,
+	0xa1, 0xa2, 0xf8, 0x6e, 0x85, 0x42, 0xba, 0xbf, 0xc3, 0xe1, 0xce, 0x84, 0x19, 0x79, 0xdf, 0x38,
+	0xb9, 0x3f, 0xc1, 0x8f, 0x2f, 0xb9, 0x90, 0x7b, 0xd2, 0xdd, 0xe7, 0xe0, 0xdc, 0xb7, 0xc4, 0x32,
+	0x89, 0x05, 0x16, 0x47, 0xdb, 0x7a, 0xc4, 0x68, 0xff, 0xf1, 0x1f, 0x74, 0xef, 0x0e, 0x28, 0x69,
+	0x40, 0xe5, 0xec, 0xfc, 0xec, 0xaa, 0xfb, 0x03, 0xa9, 0x41, 0xe9, 0xe2, 0x45, 0xd7, 0x22, 0x36,
+	0x54, 0x67, 0x94, 0x5e, 0xd0, 0x6e, 0x89, 0xb4, 0xa0, 0x31, 0x9d, 0x9d, 0xd2, 0xc9, 0x74, 0x36,
+	0xed, 0x96, 0xc7, 0x9f, 0x2d, 0x68, 0x9b, 0x2d, 0x2f, 0x31, 0x5d, 0x73, 0x1f, 0xc9, 0x04, 0x3a,
+	0xa7, 0x28, 0x8b, 0x1f, 0xe9, 0x7e, 0x7e, 0x83, 0xfb, 0x40, 0xfa, 0x87, 0x7b, 0x3c, 0x32, 0x85,
+	0x83, 0x53, 0xdc, 0x29, 0x8f, 0xfc, 0xbc, 0xb7, 0x8a, 0x6c, 0x93, 0xbd, 0x25, 0x92, 0x6b, 0xe8,
+	0xde, 0xa5, 0x44, 0x8e, 0xf3, 0xcc, 0x07, 0xd0, 0xf6, 0x07, 0x0f, 0x27, 0x18, 0xc0, 0x37, 0x35,
+	0xfd, 0x8f, 0xf4, 0xe7, 0xb7, 0x01, 0x00, 0x12, 0x0b, 0x86, 0x44, 0xa1, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// StatusServiceClient is the client API for StatusService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StatusServiceClient interface {
+	// GetAgentStatus returns the overall status of the agent.
+	GetAgentStatus(ctx context.Context, in *AgentStatusRequest, opts ...grpc.CallOption) (*AgentStatus, error)
+	// GetPluginStatus returns status of a single plugin.
+	GetPluginStatus(ctx context.Context, in *PluginStatusRequest, opts ...grpc.CallOption) (*PluginStatus, error)
+	// ListPluginStatus returns status of all registered plugins.
+	ListPluginStatus(ctx context.Context, in *ListPluginStatusRequest, opts ...grpc.CallOption) (*ListPluginStatusResponse, error)
+}
+
+type statusServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStatusServiceClient(cc *grpc.ClientConn) StatusServiceClient {
+	return &statusServiceClient{cc}
+}
+
+func (c *statusServiceClient) GetAgentStatus(ctx context.Context, in *AgentStatusRequest, opts ...grpc.CallOption) (*AgentStatus, error) {
+	out := new(AgentStatus)
+	err := c.cc.Invoke(ctx, "/status.StatusService/GetAgentStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusServiceClient) GetPluginStatus(ctx context.Context, in *PluginStatusRequest, opts ...grpc.CallOption) (*PluginStatus, error) {
+	out := new(PluginStatus)
+	err := c.cc.Invoke(ctx, "/status.StatusService/GetPluginStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusServiceClient) ListPluginStatus(ctx context.Context, in *ListPluginStatusRequest, opts ...grpc.CallOption) (*ListPluginStatusResponse, error) {
+	out := new(ListPluginStatusResponse)
+	err := c.cc.Invoke(ctx, "/status.StatusService/ListPluginStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatusServiceServer is the server API for StatusService service.
+type StatusServiceServer interface {
+	// GetAgentStatus returns the overall status of the agent.
+	GetAgentStatus(context.Context, *AgentStatusRequest) (*AgentStatus, error)
+	// GetPluginStatus returns status of a single plugin.
+	GetPluginStatus(context.Context, *PluginStatusRequest) (*PluginStatus, error)
+	// ListPluginStatus returns status of all registered plugins.
+	ListPluginStatus(context.Context, *ListPluginStatusRequest) (*ListPluginStatusResponse, error)
+}
+
+// UnimplementedStatusServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedStatusServiceServer struct {
+}
+
+func (*UnimplementedStatusServiceServer) GetAgentStatus(ctx context.Context, req *AgentStatusRequest) (*AgentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAgentStatus not implemented")
+}
+func (*UnimplementedStatusServiceServer) GetPluginStatus(ctx context.Context, req *PluginStatusRequest) (*PluginStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPluginStatus not implemented")
+}
+func (*UnimplementedStatusServiceServer) ListPluginStatus(ctx context.Context, req *ListPluginStatusRequest) (*ListPluginStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPluginStatus not implemented")
+}
+
+func RegisterStatusServiceServer(s *grpc.Server, srv StatusServiceServer) {
+	s.RegisterService(&_StatusService_serviceDesc, srv)
+}
+
+func _StatusService_GetAgentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServiceServer).GetAgentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/status.StatusService/GetAgentStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServiceServer).GetAgentStatus(ctx, req.(*AgentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusService_GetPluginStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServiceServer).GetPluginStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/status.StatusService/GetPluginStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServiceServer).GetPluginStatus(ctx, req.(*PluginStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusService_ListPluginStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPluginStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServiceServer).ListPluginStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/status.StatusService/ListPluginStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServiceServer).ListPluginStatus(ctx, req.(*ListPluginStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _StatusService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "status.StatusService",
+	HandlerType: (*StatusServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAgentStatus",
+			Handler:    _StatusService_GetAgentStatus_Handler,
+		},
+		{
+			MethodName: "GetPluginStatus",
+			Handler:    _StatusService_GetPluginStatus_Handler,
+		},
+		{
+			MethodName: "ListPluginStatus",
+			Handler:    _StatusService_ListPluginStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "status.proto",
 }
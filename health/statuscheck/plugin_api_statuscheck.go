@@ -20,7 +20,7 @@ import (
 	"github.com/ligato/cn-infra/infra"
 )
 
-//go:generate protoc --proto_path=model/status --go_out=model/status model/status/status.proto
+//go:generate protoc --proto_path=model/status --go_out=plugins=grpc:model/status model/status/status.proto
 
 // PluginState is a data type used to describe the current operational state
 // of a plugin.
@@ -33,6 +33,10 @@ const (
 	OK PluginState = "ok"
 	// Error state means that some error has occurred in the plugin.
 	Error PluginState = "error"
+	// Degraded state means that the plugin keeps working with limited
+	// functionality, e.g. with the last known configuration while
+	// the KV store is unreachable.
+	Degraded PluginState = "degraded"
 )
 
 // PluginStateProbe defines parameters of a function used for plugin state
@@ -60,6 +64,16 @@ type PluginStatusWriter interface {
 	ReportStateChangeWithMeta(pluginName infra.PluginName, state PluginState, lastError error, meta proto.Message)
 }
 
+// PluginCountersWriter allows plugins to publish their counters
+// as a part of the plugin status.
+type PluginCountersWriter interface {
+	// ReportCounters sets counters of a previously registered plugin.
+	// Counters with the same name are overwritten, other counters are kept.
+	// Unlike state changes, counters are published with the next periodic
+	// update of the status data.
+	ReportCounters(pluginName infra.PluginName, counters map[string]uint64)
+}
+
 // AgentStatusReader allows to lookup agent status by other plugins.
 type AgentStatusReader interface {
 	// GetAgentStatus returns the current global operational state of the agent.
@@ -77,3 +91,17 @@ type StatusReader interface {
 	InterfaceStatusReader
 	GetAllPluginStatus() map[string]*status.PluginStatus
 }
+
+// ReadinessReader allows plugins to gate their REST handlers until they are ready.
+type ReadinessReader interface {
+	// ReadinessProbe returns function reporting whether the plugin is ready,
+	// usable to gate REST handlers of the plugin (see rest.WhenReady).
+	ReadinessProbe(pluginName infra.PluginName) func() (ready bool, state string)
+}
+
+// PluginStatusReader extends StatusReader with lookup of a single plugin status.
+type PluginStatusReader interface {
+	StatusReader
+	// GetPluginStatus returns a copy of the status of a single plugin.
+	GetPluginStatus(pluginName string) (*status.PluginStatus, bool)
+}
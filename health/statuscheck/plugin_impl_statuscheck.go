@@ -16,6 +16,7 @@ package statuscheck
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/ligato/cn-infra/health/statuscheck/model/status"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/utils/handover"
 )
 
 var (
@@ -44,10 +46,9 @@ type Plugin struct {
 	interfaceStat *status.InterfaceStats          // interfaces' overall status
 	pluginStat    map[string]*status.PluginStatus // plugin's status
 	pluginProbe   map[string]PluginStateProbe     // registered status probes
+	afterInit     bool                            // AfterInit has finished
 
-	ctx    context.Context
-	cancel context.CancelFunc // cancel can be used to cancel all goroutines and their jobs inside of the plugin
-	wg     sync.WaitGroup     // wait group that allows to wait until all goroutines of the plugin have finished
+	loop infra.EventLoop // manages goroutines of the plugin
 }
 
 // Deps lists the dependencies of statuscheck plugin.
@@ -78,8 +79,7 @@ func (p *Plugin) Init() error {
 	// init map with plugin state probes
 	p.pluginProbe = make(map[string]PluginStateProbe)
 
-	// prepare context for all go routines
-	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.loop.Log = p.Log
 
 	return nil
 }
@@ -87,31 +87,36 @@ func (p *Plugin) Init() error {
 // AfterInit starts go routines for periodic probing and periodic updates.
 // Initial state data are published via the injected transport.
 func (p *Plugin) AfterInit() error {
+	var events stateEvents
+	defer events.publish()
 	p.access.Lock()
 	defer p.access.Unlock()
 
-	// do periodic status probing for plugins that have provided the probe function
-	go p.periodicProbing(p.ctx)
-
-	// do periodic updates of the state data in ETCD
-	go p.periodicUpdates(p.ctx)
+	p.loop.Run(
+		// do periodic status probing for plugins that have provided the probe function
+		infra.OnTick(PeriodicProbingTimeout, p.periodicProbing),
+		// do periodic updates of the state data in ETCD
+		infra.OnTick(PeriodicWriteTimeout, p.periodicUpdates),
+	)
 
 	p.publishAgentData()
 
 	// transition to OK state if there are no plugins
 	if len(p.pluginStat) == 0 {
+		events.agentChanged(p.agentStat.State, status.OperationalState_OK)
 		p.agentStat.State = status.OperationalState_OK
 		p.agentStat.LastChange = time.Now().Unix()
 		p.publishAgentData()
 	}
+	p.afterInit = true
+	p.notifyReady()
 
 	return nil
 }
 
 // Close stops go routines for periodic probing and periodic updates.
 func (p *Plugin) Close() error {
-	p.cancel()
-	p.wg.Wait()
+	p.loop.Stop()
 
 	return nil
 }
@@ -122,6 +127,7 @@ func (p *Plugin) Register(pluginName infra.PluginName, probe PluginStateProbe) {
 	defer p.access.Unlock()
 
 	stat := &status.PluginStatus{
+		Name:       string(pluginName),
 		State:      status.OperationalState_INIT,
 		LastChange: time.Now().Unix(),
 	}
@@ -156,6 +162,8 @@ func (p *Plugin) ReportStateChangeWithMeta(pluginName infra.PluginName, state Pl
 }
 
 func (p *Plugin) reportStateChange(pluginName infra.PluginName, state PluginState, lastError error) {
+	var events stateEvents
+	defer events.publish()
 	p.access.Lock()
 	defer p.access.Unlock()
 
@@ -171,7 +179,7 @@ func (p *Plugin) reportStateChange(pluginName infra.PluginName, state PluginStat
 		if lastError == nil && stat.Error == "" {
 			changed = false
 		}
-		if lastError != nil && lastError.Error() == stat.Error {
+		if lastError != nil && lastError.Error() == stat.Error && infra.ErrorCode(lastError) == stat.ErrorCode {
 			changed = false
 		}
 	}
@@ -182,6 +190,13 @@ func (p *Plugin) reportStateChange(pluginName infra.PluginName, state PluginStat
 	p.Log.WithFields(map[string]interface{}{"plugin": pluginName, "state": state, "lastErr": lastError}).
 		Info("Agent plugin state update.")
 
+	events.plugin = &PluginStateEvent{
+		Plugin:    pluginName.String(),
+		State:     stateToProto(state),
+		PrevState: stat.State,
+		Error:     errorDetails(lastError),
+	}
+
 	// update plugin state
 	stat.State = stateToProto(state)
 	stat.LastChange = time.Now().Unix()
@@ -190,9 +205,12 @@ func (p *Plugin) reportStateChange(pluginName infra.PluginName, state PluginStat
 	} else {
 		stat.Error = ""
 	}
+	stat.ErrorCode = infra.ErrorCode(lastError)
+	stat.ErrorDetails = errorDetails(lastError)
 	p.publishPluginData(pluginName, stat)
 
 	// update global state
+	events.agentChanged(p.agentStat.State, stateToProto(state))
 	p.agentStat.State = stateToProto(state)
 	p.agentStat.LastChange = time.Now().Unix()
 	// Status for existing plugin
@@ -206,17 +224,93 @@ func (p *Plugin) reportStateChange(pluginName infra.PluginName, state PluginStat
 			pluginStatusExists = true
 			pluginStatus.State = stateToProto(state)
 			pluginStatus.Error = lastErr
+			pluginStatus.ErrorCode = stat.ErrorCode
+			pluginStatus.ErrorDetails = stat.ErrorDetails
 		}
 	}
 	// Status for new plugin
 	if !pluginStatusExists {
 		p.agentStat.Plugins = append(p.agentStat.Plugins, &status.PluginStatus{
-			Name:  pluginName.String(),
-			State: stateToProto(state),
-			Error: lastErr,
+			Name:         pluginName.String(),
+			State:        stateToProto(state),
+			Error:        lastErr,
+			ErrorCode:    stat.ErrorCode,
+			ErrorDetails: stat.ErrorDetails,
+			Counters:     stat.Counters,
 		})
 	}
 	p.publishAgentData()
+	p.notifyReady()
+}
+
+// ReportCounters sets counters of a previously registered plugin.
+func (p *Plugin) ReportCounters(pluginName infra.PluginName, counters map[string]uint64) {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	stat, ok := p.pluginStat[string(pluginName)]
+	if !ok {
+		p.Log.Errorf("Unregistered plugin %s is reporting counters, ignoring.", pluginName)
+		return
+	}
+	if stat.Counters == nil {
+		stat.Counters = make(map[string]uint64, len(counters))
+		for _, pluginStatus := range p.agentStat.Plugins {
+			if pluginStatus.Name == pluginName.String() {
+				pluginStatus.Counters = stat.Counters
+			}
+		}
+	}
+	for name, value := range counters {
+		stat.Counters[name] = value
+	}
+}
+
+// errorDetails converts the error into the structured error details,
+// including the metadata of the error class if the error is classified.
+func errorDetails(err error) *status.ErrorDetails {
+	if err == nil {
+		return nil
+	}
+	details := &status.ErrorDetails{
+		Message: err.Error(),
+		Time:    time.Now().Unix(),
+	}
+	if class := infra.ErrorClassOf(err); class != nil {
+		details.Code = class.Code
+		details.Plugin = string(class.Plugin)
+		details.Retriable = class.Retriable
+		details.Severity = string(class.Severity)
+		details.Docs = class.Docs
+	}
+	return details
+}
+
+// notifyReady lets the old agent process know that this process is ready to serve
+// once all registered plugins are in OK state, if the agent was started by an upgrade.
+// Must be called with the lock held.
+func (p *Plugin) notifyReady() {
+	if !p.allPluginsOK() || !handover.Inherited() {
+		return
+	}
+	if err := handover.Ready(); err != nil {
+		p.Log.Errorf("failed to notify agent process being upgraded: %v", err)
+	}
+}
+
+// allPluginsOK returns true once AfterInit has finished and all registered plugins
+// are in OK state. The agent state alone is not enough, it only reflects the plugin
+// which reported last. Must be called with the lock held.
+func (p *Plugin) allPluginsOK() bool {
+	if !p.afterInit {
+		return false
+	}
+	for _, stat := range p.pluginStat {
+		if stat.State != status.OperationalState_OK {
+			return false
+		}
+	}
+	return true
 }
 
 func (p *Plugin) reportInterfaceStateChange(data *status.InterfaceStats_Interface) {
@@ -284,25 +378,11 @@ func (p *Plugin) publishAllData() {
 // periodicProbing does periodic status probing for all plugins
 // that have registered probe functions.
 func (p *Plugin) periodicProbing(ctx context.Context) {
-	p.wg.Add(1)
-	defer p.wg.Done()
-
-	for {
-		select {
-		case <-time.After(PeriodicProbingTimeout):
-			for pluginName, probe := range p.pluginProbe {
-				state, lastErr := probe()
-				p.ReportStateChange(infra.PluginName(pluginName), state, lastErr)
-				// just check in-between probes if the plugin is closing
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					continue
-				}
-			}
-
-		case <-ctx.Done():
+	for pluginName, probe := range p.pluginProbe {
+		state, lastErr := probe()
+		p.ReportStateChange(infra.PluginName(pluginName), state, lastErr)
+		// just check in-between probes if the plugin is closing
+		if ctx.Err() != nil {
 			return
 		}
 	}
@@ -310,18 +390,7 @@ func (p *Plugin) periodicProbing(ctx context.Context) {
 
 // periodicUpdates does periodic writes of state data into ETCD.
 func (p *Plugin) periodicUpdates(ctx context.Context) {
-	p.wg.Add(1)
-	defer p.wg.Done()
-
-	for {
-		select {
-		case <-time.After(PeriodicWriteTimeout):
-			p.publishAllData()
-
-		case <-ctx.Done():
-			return
-		}
-	}
+	p.publishAllData()
 }
 
 // getAgentState return current global operational state of the agent.
@@ -331,13 +400,57 @@ func (p *Plugin) getAgentState() status.OperationalState {
 	return p.agentStat.State
 }
 
-// GetAllPluginStatus returns a map containing pluginname and its status, for all plugins
+// GetAllPluginStatus returns a map containing pluginname and a copy of its status, for all plugins
 func (p *Plugin) GetAllPluginStatus() map[string]*status.PluginStatus {
-	//TODO - used currently, will be removed after incoporating improvements for exposing copy of map
 	p.access.Lock()
 	defer p.access.Unlock()
 
-	return p.pluginStat
+	pluginStat := make(map[string]*status.PluginStatus, len(p.pluginStat))
+	for name, stat := range p.pluginStat {
+		pluginStat[name] = proto.Clone(stat).(*status.PluginStatus)
+	}
+	return pluginStat
+}
+
+// GetPluginStatus returns a copy of the status of a single plugin.
+func (p *Plugin) GetPluginStatus(pluginName string) (*status.PluginStatus, bool) {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	stat, ok := p.pluginStat[pluginName]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(stat).(*status.PluginStatus), true
+}
+
+// ReadinessProbe returns function reporting whether the plugin is ready, i.e. its
+// state is OK or degraded. Plugins which have not registered yet are considered
+// to be in the init state.
+func (p *Plugin) ReadinessProbe(pluginName infra.PluginName) func() (ready bool, state string) {
+	return func() (bool, string) {
+		p.access.Lock()
+		defer p.access.Unlock()
+
+		stat, ok := p.pluginStat[string(pluginName)]
+		if !ok {
+			return false, strings.ToLower(status.OperationalState_INIT.String())
+		}
+		ready := stat.State == status.OperationalState_OK || stat.State == status.OperationalState_DEGRADED
+		return ready, strings.ToLower(stat.State.String())
+	}
+}
+
+// PluginHealth returns the state of the plugin or empty string if the plugin
+// is not registered (for the agent boot report).
+func (p *Plugin) PluginHealth(pluginName infra.PluginName) string {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	if stat, ok := p.pluginStat[string(pluginName)]; ok {
+		return strings.ToLower(stat.State.String())
+	}
+	return ""
 }
 
 // GetInterfaceStats returns current global operational status of interfaces
@@ -345,14 +458,14 @@ func (p *Plugin) GetInterfaceStats() status.InterfaceStats {
 	p.access.Lock()
 	defer p.access.Unlock()
 
-	return *p.interfaceStat
+	return *proto.Clone(p.interfaceStat).(*status.InterfaceStats)
 }
 
 // GetAgentStatus return current global operational state of the agent.
 func (p *Plugin) GetAgentStatus() status.AgentStatus {
 	p.access.Lock()
 	defer p.access.Unlock()
-	return *p.agentStat
+	return *proto.Clone(p.agentStat).(*status.AgentStatus)
 }
 
 // stateToProto converts agent state type into protobuf agent state type.
@@ -362,6 +475,8 @@ func stateToProto(state PluginState) status.OperationalState {
 		return status.OperationalState_INIT
 	case OK:
 		return status.OperationalState_OK
+	case Degraded:
+		return status.OperationalState_DEGRADED
 	default:
 		return status.OperationalState_ERROR
 	}
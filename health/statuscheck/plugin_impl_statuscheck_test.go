@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statuscheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/infra"
+	. "github.com/onsi/gomega"
+)
+
+var errTestClass = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:      "statuscheck/test",
+	Plugin:    "test",
+	Retriable: true,
+})
+
+func TestStructuredPluginStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin()
+	Expect(p.Init()).To(Succeed())
+	p.Register("test", nil)
+
+	p.ReportCounters("test", map[string]uint64{"resyncs": 1})
+	p.ReportStateChange("test", Error, errTestClass.Errorf("failed"))
+	p.ReportCounters("test", map[string]uint64{"errors": 2})
+
+	stat, ok := p.GetPluginStatus("test")
+	Expect(ok).To(BeTrue())
+	Expect(stat.Name).To(Equal("test"))
+	Expect(stat.State).To(Equal(status.OperationalState_ERROR))
+	Expect(stat.Counters).To(Equal(map[string]uint64{"resyncs": 1, "errors": 2}))
+	Expect(stat.ErrorDetails.Code).To(Equal("statuscheck/test"))
+	Expect(stat.ErrorDetails.Message).To(Equal("failed"))
+	Expect(stat.ErrorDetails.Retriable).To(BeTrue())
+	Expect(stat.ErrorDetails.Severity).To(Equal(string(infra.SeverityError)))
+
+	agentStatus := p.GetAgentStatus()
+	Expect(agentStatus.Plugins).To(HaveLen(1))
+	Expect(agentStatus.Plugins[0].Counters).To(HaveKeyWithValue("errors", uint64(2)))
+
+	p.ReportStateChange("test", OK, nil)
+	stat, _ = p.GetPluginStatus("test")
+	Expect(stat.ErrorDetails).To(BeNil())
+
+	_, ok = p.GetPluginStatus("unknown")
+	Expect(ok).To(BeFalse())
+}
+
+func TestErrorDetailsOfUnclassifiedError(t *testing.T) {
+	RegisterTestingT(t)
+
+	details := errorDetails(errors.New("plain"))
+	Expect(details.Code).To(BeEmpty())
+	Expect(details.Message).To(Equal("plain"))
+	Expect(errorDetails(nil)).To(BeNil())
+}
+
+func TestReadinessProbe(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin()
+	Expect(p.Init()).To(Succeed())
+	probe := p.ReadinessProbe("test")
+
+	ready, state := probe()
+	Expect(ready).To(BeFalse())
+	Expect(state).To(Equal("init"))
+
+	p.Register("test", nil)
+	p.ReportStateChange("test", OK, nil)
+	ready, state = probe()
+	Expect(ready).To(BeTrue())
+	Expect(state).To(Equal("ok"))
+
+	p.ReportStateChange("test", Error, errors.New("failed"))
+	ready, state = probe()
+	Expect(ready).To(BeFalse())
+	Expect(state).To(Equal("error"))
+}
+
+func TestAllPluginsOK(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin()
+	Expect(p.Init()).To(Succeed())
+	p.Register("a", nil)
+	p.Register("b", nil)
+	p.ReportStateChange("a", OK, nil)
+	Expect(p.allPluginsOK()).To(BeFalse())
+
+	Expect(p.AfterInit()).To(Succeed())
+	defer p.Close()
+	// the agent state follows the last report, but plugin b is still initializing
+	Expect(p.GetAgentStatus().State).To(Equal(status.OperationalState_OK))
+	Expect(p.allPluginsOK()).To(BeFalse())
+
+	p.ReportStateChange("b", Error, errors.New("failed"))
+	Expect(p.allPluginsOK()).To(BeFalse())
+
+	p.ReportStateChange("b", OK, nil)
+	Expect(p.allPluginsOK()).To(BeTrue())
+}
+
+func TestStatusReadersReturnCopies(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin()
+	Expect(p.Init()).To(Succeed())
+	p.Register("test", nil)
+	p.ReportCounters("test", map[string]uint64{"resyncs": 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint64(0); i < 100; i++ {
+			p.ReportCounters("test", map[string]uint64{"resyncs": i})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		for _, stat := range p.GetAllPluginStatus() {
+			_ = stat.Counters["resyncs"]
+		}
+		agentStatus := p.GetAgentStatus()
+		for _, stat := range agentStatus.Plugins {
+			_ = stat.Counters["resyncs"]
+		}
+	}
+	<-done
+
+	p.GetAllPluginStatus()["test"].Counters["resyncs"] = 1000
+	stat, _ := p.GetPluginStatus("test")
+	Expect(stat.Counters).To(HaveKeyWithValue("resyncs", uint64(99)))
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statusapi exposes the structured status of the agent and its plugins
+// collected by the statuscheck plugin over REST and gRPC.
+//
+// REST API:
+//
+//	GET /status                  returns status of the agent (status.AgentStatus)
+//	GET /status/plugins          returns status of all plugins (status.ListPluginStatusResponse)
+//	GET /status/plugins/{plugin} returns status of a single plugin (status.PluginStatus)
+//
+// If the GRPC dependency is injected, the status.StatusService is registered
+// on the gRPC server.
+package statusapi
@@ -0,0 +1,47 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusapi
+
+import (
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// statusService implements status.StatusServiceServer.
+type statusService struct {
+	p *Plugin
+}
+
+// GetAgentStatus returns the overall status of the agent.
+func (s *statusService) GetAgentStatus(ctx context.Context, req *status.AgentStatusRequest) (*status.AgentStatus, error) {
+	agentStatus := s.p.StatusCheck.GetAgentStatus()
+	return &agentStatus, nil
+}
+
+// GetPluginStatus returns status of a single plugin.
+func (s *statusService) GetPluginStatus(ctx context.Context, req *status.PluginStatusRequest) (*status.PluginStatus, error) {
+	stat, ok := s.p.StatusCheck.GetPluginStatus(req.GetName())
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.NotFound, "plugin %q is not registered", req.GetName())
+	}
+	return stat, nil
+}
+
+// ListPluginStatus returns status of all registered plugins.
+func (s *statusService) ListPluginStatus(ctx context.Context, req *status.ListPluginStatusRequest) (*status.ListPluginStatusResponse, error) {
+	return &status.ListPluginStatusResponse{Plugins: s.p.listPluginStatus()}, nil
+}
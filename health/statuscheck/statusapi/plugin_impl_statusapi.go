@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statusapi
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/rpc/grpc"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	// AgentStatusPath is URL path of the agent status.
+	AgentStatusPath = "/status"
+	// PluginStatusPath is URL path of the status of all plugins.
+	PluginStatusPath = AgentStatusPath + "/plugins"
+	// pluginVarName is the name of the plugin variable in URL path.
+	pluginVarName = "plugin"
+)
+
+// ErrUnknownPlugin is the class of errors returned when status of a plugin
+// not registered to the statuscheck is requested.
+var ErrUnknownPlugin = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "statuscheck/unknown-plugin",
+	Plugin:     "status-api",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusNotFound,
+})
+
+// Plugin exposes the status collected by the statuscheck over REST and gRPC.
+type Plugin struct {
+	Deps
+}
+
+// Deps lists dependencies of the plugin.
+type Deps struct {
+	infra.PluginDeps
+	StatusCheck statuscheck.PluginStatusReader // inject
+	HTTP        rest.HTTPHandlers              // inject
+	GRPC        grpc.Server                    // inject (optional) to expose the status.StatusService
+}
+
+// Init registers the REST handlers and the gRPC service.
+func (p *Plugin) Init() error {
+	if p.HTTP != nil {
+		p.HTTP.RegisterHTTPHandler(AgentStatusPath, p.agentStatusHandler, http.MethodGet)
+		p.HTTP.RegisterHTTPHandler(PluginStatusPath, p.pluginStatusListHandler, http.MethodGet)
+		p.HTTP.RegisterHTTPHandler(PluginStatusPath+"/{"+pluginVarName+"}", p.pluginStatusHandler, http.MethodGet)
+	} else {
+		p.Log.Info("Unable to register status REST handlers, HTTP is nil")
+	}
+	if p.GRPC != nil && !p.GRPC.IsDisabled() {
+		status.RegisterStatusServiceServer(p.GRPC.GetServer(), &statusService{p: p})
+	}
+
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// listPluginStatus returns copies of status of all plugins sorted by the plugin name.
+func (p *Plugin) listPluginStatus() []*status.PluginStatus {
+	pluginStat := p.StatusCheck.GetAllPluginStatus()
+	names := make([]string, 0, len(pluginStat))
+	for name := range pluginStat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plugins := make([]*status.PluginStatus, 0, len(names))
+	for _, name := range names {
+		plugins = append(plugins, pluginStat[name])
+	}
+	return plugins
+}
+
+// agentStatusHandler returns the status of the agent.
+func (p *Plugin) agentStatusHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		agentStatus := p.StatusCheck.GetAgentStatus()
+		formatter.JSON(w, http.StatusOK, &agentStatus)
+	}
+}
+
+// pluginStatusListHandler returns the status of all plugins.
+func (p *Plugin) pluginStatusListHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		formatter.JSON(w, http.StatusOK, &status.ListPluginStatusResponse{
+			Plugins: p.listPluginStatus(),
+		})
+	}
+}
+
+// pluginStatusHandler returns the status of a single plugin.
+func (p *Plugin) pluginStatusHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := mux.Vars(req)[pluginVarName]
+		stat, ok := p.StatusCheck.GetPluginStatus(name)
+		if !ok {
+			rest.WriteError(formatter, w, ErrUnknownPlugin.Errorf("plugin %q is not registered", name))
+			return
+		}
+		formatter.JSON(w, http.StatusOK, stat)
+	}
+}
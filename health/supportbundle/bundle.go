@@ -0,0 +1,115 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Collector writes data of a single bundle section.
+type Collector func(w io.Writer) error
+
+// Manifest describes the content of the bundle.
+type Manifest struct {
+	CreatedAt time.Time        `json:"created-at"`
+	Hostname  string           `json:"hostname,omitempty"`
+	Sections  []*SectionResult `json:"sections"`
+}
+
+// SectionResult describes the outcome of a single section collection.
+type SectionResult struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// bundleFile is a single file of the bundle.
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// section collects one or more bundle files via the add callback.
+type section struct {
+	name    string
+	collect func(add func(file string, data []byte)) error
+}
+
+// collectorSection adapts Collector to a section stored in a single file.
+func collectorSection(name string, collect Collector) section {
+	return section{
+		name: name,
+		collect: func(add func(string, []byte)) error {
+			var buf bytes.Buffer
+			if err := collect(&buf); err != nil {
+				return err
+			}
+			add("sections/"+name, buf.Bytes())
+			return nil
+		},
+	}
+}
+
+// collect runs all sections. Failure of a section is recorded in the manifest
+// and does not prevent collection of the others.
+func collect(sections []section, manifest *Manifest) (files []bundleFile) {
+	for _, s := range sections {
+		result := &SectionResult{Name: s.name}
+		err := s.collect(func(file string, data []byte) {
+			result.Files = append(result.Files, file)
+			files = append(files, bundleFile{name: file, data: data})
+		})
+		if err != nil {
+			result.Error = err.Error()
+		}
+		manifest.Sections = append(manifest.Sections, result)
+	}
+	return files
+}
+
+// writeBundle writes the manifest followed by the files as tar.gz archive.
+func writeBundle(w io.Writer, manifest *Manifest, files []bundleFile) error {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append([]bundleFile{{name: "manifest.json", data: manifestData}}, files...)
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    f.name,
+			Mode:    0644,
+			Size:    int64(len(f.data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
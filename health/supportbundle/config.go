@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config holds the configuration of the support bundle plugin.
+type Config struct {
+	// LogTailLines is the number of the most recent log entries kept
+	// for the bundle. Zero disables collection of logs.
+	LogTailLines int `json:"log-tail-lines"`
+
+	// MaxCPUProfile is the upper bound of the CPU profile duration
+	// that can be requested.
+	MaxCPUProfile time.Duration `json:"max-cpu-profile"`
+
+	// ConfigDir is the directory with configuration files included
+	// in the bundle. Defaults to the agent config directory.
+	ConfigDir string `json:"config-dir"`
+
+	// MaskedKeys is a list of substrings of configuration keys (and log
+	// entry fields) whose values are masked in the bundle (case-insensitive).
+	MaskedKeys []string `json:"masked-keys"`
+}
+
+// DefaultConfig returns Config with default values.
+func DefaultConfig() *Config {
+	return &Config{
+		LogTailLines:  1000,
+		MaxCPUProfile: 30 * time.Second,
+		MaskedKeys: []string{
+			"password", "passwd", "secret", "token", "credential", "private-key", "key-file", "auth",
+		},
+	}
+}
+
+// Validate checks the configuration.
+func (c *Config) Validate() error {
+	if c.LogTailLines < 0 {
+		return fmt.Errorf("log-tail-lines must not be negative: %d", c.LogTailLines)
+	}
+	if c.MaxCPUProfile < 0 {
+		return fmt.Errorf("max-cpu-profile must not be negative: %v", c.MaxCPUProfile)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package supportbundle implements the admin REST action which gathers
+// troubleshooting data of the agent into a single downloadable tarball.
+//
+// The bundle (tar.gz) is served on:
+//
+//	GET /debug/support-bundle[?cpu-profile=<duration>]
+//
+// and contains:
+//   - manifest.json: list of collected sections and errors of the sections
+//     that could not be collected,
+//   - health/agent.json, health/plugins.json: agent and plugin status
+//     from the status check plugin,
+//   - config/*: effective configuration files from the config directory
+//     with values of sensitive keys (passwords, tokens, ...) masked,
+//     config files of plugins located elsewhere (e.g. set by -<plugin>-config
+//     flag) are put into config/<plugin>/,
+//   - logs/tail.log: last N log entries of all loggers in the registry,
+//     with values of sensitive fields masked the same way,
+//   - profiles/*: goroutine dump, heap profile and optionally CPU profile
+//     taken for the requested duration.
+//
+// Other plugins can contribute their own sections (e.g. transaction history
+// or state dumps) via RegisterSection.
+package supportbundle
@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logTail is a logrus hook which keeps the last formatted log entries
+// in a ring buffer.
+type logTail struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func newLogTail(size int) *logTail {
+	return &logTail{lines: make([]string, size)}
+}
+
+// Levels returns all log levels, entries are filtered by loggers themselves.
+func (t *logTail) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire stores the formatted entry.
+func (t *logTail) Fire(entry *logrus.Entry) error {
+	line, err := entry.String()
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.lines[t.next] = line
+	t.next++
+	if t.next == len(t.lines) {
+		t.next = 0
+		t.full = true
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// String is used by the registry when logging the added hook.
+func (t *logTail) String() string {
+	return "support-bundle-log-tail"
+}
+
+// Lines returns stored entries from the oldest to the newest.
+func (t *logTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]string(nil), t.lines[:t.next]...)
+	}
+	lines := make([]string, 0, len(t.lines))
+	lines = append(lines, t.lines[t.next:]...)
+	return append(lines, t.lines[:t.next]...)
+}
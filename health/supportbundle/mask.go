@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// maskedValue replaces values of sensitive configuration keys.
+const maskedValue = "*****"
+
+// maskConfig parses YAML configuration and returns it with values
+// of sensitive keys masked. Configuration which cannot be parsed is not
+// returned at all since it cannot be safely masked.
+func maskConfig(data []byte, maskedKeys []string) ([]byte, error) {
+	var cfg interface{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(maskValue(cfg, maskedKeys))
+}
+
+func maskValue(val interface{}, maskedKeys []string) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isMaskedKey(key, maskedKeys) {
+				v[key] = maskAll(item)
+				continue
+			}
+			v[key] = maskValue(item, maskedKeys)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskValue(item, maskedKeys)
+		}
+	}
+	return val
+}
+
+// maskAll masks all scalars of the value regardless of their type,
+// numeric or boolean secrets (e.g. PINs) are masked as well.
+// Only null values are kept.
+func maskAll(val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = maskAll(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskAll(item)
+		}
+	default:
+		return maskedValue
+	}
+	return val
+}
+
+func isMaskedKey(key string, maskedKeys []string) bool {
+	key = strings.ToLower(key)
+	for _, masked := range maskedKeys {
+		if strings.Contains(key, strings.ToLower(masked)) {
+			return true
+		}
+	}
+	return false
+}
+
+// logFieldRe matches keys of key=value fields of text formatted log entries
+// and of "key":value fields of JSON formatted log entries.
+var logFieldRe = regexp.MustCompile(`([\w.-]+)"?(?:=|:\s*)`)
+
+// maskLogLine returns the formatted log entry with values of sensitive keys
+// masked. Fields are looked up also inside the message.
+func maskLogLine(line string, maskedKeys []string) string {
+	var masked strings.Builder
+	last := 0
+	for _, m := range logFieldRe.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] < last || !isMaskedKey(line[m[2]:m[3]], maskedKeys) {
+			continue
+		}
+		end := logValueEnd(line, m[1])
+		if end == m[1] {
+			continue
+		}
+		masked.WriteString(line[last:m[1]])
+		if line[m[1]] == '"' {
+			masked.WriteString(`"` + maskedValue + `"`)
+		} else {
+			masked.WriteString(maskedValue)
+		}
+		last = end
+	}
+	masked.WriteString(line[last:])
+	return masked.String()
+}
+
+// logValueEnd returns the end of the log field value starting at the given
+// position. The value is either quoted or ends with a space, comma or bracket.
+func logValueEnd(line string, start int) int {
+	if start < len(line) && line[start] == '"' {
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(line)
+	}
+	end := start
+	for end < len(line) && !strings.ContainsRune(" \t\r\n,}]", rune(line[end])) {
+		end++
+	}
+	return end
+}
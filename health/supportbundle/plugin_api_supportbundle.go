@@ -0,0 +1,23 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+// API allows other plugins to contribute sections to the support bundle.
+type API interface {
+	// RegisterSection adds a section collected into file "sections/<name>"
+	// of every support bundle. Error returned by the collector is recorded
+	// in the bundle manifest.
+	RegisterSection(name string, collect Collector) error
+}
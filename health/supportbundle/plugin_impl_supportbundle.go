@@ -0,0 +1,318 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/config"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/unrolled/render"
+)
+
+const (
+	bundlePath      = "/debug/support-bundle" // support bundle URL
+	cpuProfileParam = "cpu-profile"           // query parameter with CPU profile duration
+)
+
+// ErrInvalidRequest is the class of errors returned for malformed support bundle requests.
+var ErrInvalidRequest = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "supportbundle/invalid-request",
+	Plugin:     "support-bundle",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusBadRequest,
+})
+
+// Plugin serves support bundles with troubleshooting data of the agent.
+type Plugin struct {
+	Deps
+
+	config  *Config
+	logTail *logTail
+	// configFiles returns config files of plugins indexed by plugin name
+	configFiles func() map[string]string
+
+	mu       sync.Mutex
+	sections []section
+}
+
+// Deps lists dependencies of the support bundle plugin.
+type Deps struct {
+	infra.PluginDeps
+	HTTP        rest.HTTPHandlers        // inject
+	StatusCheck statuscheck.StatusReader // inject (optional) to include the health status
+	LogRegistry logging.Registry         // inject (optional) to include the log tail
+}
+
+// Init loads the configuration and starts to record the log tail.
+func (p *Plugin) Init() error {
+	if p.config == nil {
+		p.config = DefaultConfig()
+		if _, err := p.Cfg.LoadValue(p.config); err != nil {
+			return err
+		}
+	}
+	if err := p.config.Validate(); err != nil {
+		return err
+	}
+	if p.config.ConfigDir == "" {
+		dir, err := config.Dir()
+		if err != nil {
+			return err
+		}
+		p.config.ConfigDir = dir
+	}
+	if p.configFiles == nil {
+		p.configFiles = config.PluginConfigFiles
+	}
+
+	if p.LogRegistry != nil && p.config.LogTailLines > 0 {
+		p.logTail = newLogTail(p.config.LogTailLines)
+		p.LogRegistry.AddHook(p.logTail)
+	}
+	return nil
+}
+
+// AfterInit registers the HTTP handler of the support bundle.
+func (p *Plugin) AfterInit() error {
+	if p.HTTP == nil {
+		p.Log.Info("Unable to register support bundle handler, HTTP is nil")
+		return nil
+	}
+	p.HTTP.RegisterHTTPHandler(bundlePath, p.bundleHandler, http.MethodGet)
+	return nil
+}
+
+// Close does nothing.
+func (p *Plugin) Close() error {
+	return nil
+}
+
+// RegisterSection adds a section contributed by another plugin.
+func (p *Plugin) RegisterSection(name string, collect Collector) error {
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("invalid support bundle section name: %q", name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.sections {
+		if s.name == name {
+			return fmt.Errorf("support bundle section %q already registered", name)
+		}
+	}
+	p.sections = append(p.sections, collectorSection(name, collect))
+	return nil
+}
+
+// bundleHandler collects the bundle and sends it as an attachment.
+func (p *Plugin) bundleHandler(formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var cpuProfile time.Duration
+		if val := req.URL.Query().Get(cpuProfileParam); val != "" {
+			d, err := time.ParseDuration(val)
+			if err != nil || d < 0 {
+				rest.WriteError(formatter, w, ErrInvalidRequest.Errorf("invalid %s duration: %q", cpuProfileParam, val))
+				return
+			}
+			if d > p.config.MaxCPUProfile {
+				rest.WriteError(formatter, w, ErrInvalidRequest.Errorf("%s duration %v exceeds the limit %v",
+					cpuProfileParam, d, p.config.MaxCPUProfile))
+				return
+			}
+			cpuProfile = d
+		}
+
+		manifest := &Manifest{CreatedAt: time.Now().UTC()}
+		manifest.Hostname, _ = os.Hostname()
+
+		var buf bytes.Buffer
+		if err := writeBundle(&buf, manifest, collect(p.bundleSections(cpuProfile), manifest)); err != nil {
+			rest.WriteError(formatter, w, err)
+			return
+		}
+
+		fileName := fmt.Sprintf("support-bundle-%s.tar.gz", manifest.CreatedAt.Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
+
+// bundleSections returns built-in sections followed by the registered ones.
+func (p *Plugin) bundleSections(cpuProfile time.Duration) []section {
+	sections := []section{
+		{name: "health", collect: p.collectHealth},
+		{name: "config", collect: p.collectConfig},
+		{name: "logs", collect: p.collectLogs},
+		{name: "profiles", collect: func(add func(string, []byte)) error {
+			return collectProfiles(add, cpuProfile)
+		}},
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append(sections, p.sections...)
+}
+
+func (p *Plugin) collectHealth(add func(string, []byte)) error {
+	if p.StatusCheck == nil {
+		return fmt.Errorf("status check is not available")
+	}
+	// both getters return copies made under the lock of the status check,
+	// so marshalling does not race with status updates
+	agentStatus, err := json.MarshalIndent(p.StatusCheck.GetAgentStatus(), "", "  ")
+	if err != nil {
+		return err
+	}
+	add("health/agent.json", agentStatus)
+	pluginStatus, err := json.MarshalIndent(p.StatusCheck.GetAllPluginStatus(), "", "  ")
+	if err != nil {
+		return err
+	}
+	add("health/plugins.json", pluginStatus)
+	return nil
+}
+
+// collectConfig adds masked configuration files from the config directory
+// and config files of plugins located elsewhere (e.g. set by -<plugin>-config flag).
+// Files which cannot be parsed are skipped since they cannot be masked.
+func (p *Plugin) collectConfig(add func(string, []byte)) error {
+	files, err := p.configFilePaths()
+	if err != nil {
+		return err
+	}
+	var skipped []string
+	for _, name := range sortedKeys(files) {
+		data, err := ioutil.ReadFile(files[name])
+		if err == nil {
+			data, err = maskConfig(data, p.config.MaskedKeys)
+		}
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s (%v)", files[name], err))
+			continue
+		}
+		add("config/"+name, data)
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped config files: %s", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// configFilePaths returns paths of config files indexed by their names in the bundle.
+// Files from the config directory keep their names, config files of plugins located
+// elsewhere are put into directory named after the plugin.
+func (p *Plugin) configFilePaths() (map[string]string, error) {
+	entries, err := ioutil.ReadDir(p.config.ConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	included := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !isConfigFile(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(p.config.ConfigDir, entry.Name())
+		files[entry.Name()] = path
+		included[absPath(path)] = true
+	}
+	for plugin, path := range p.configFiles() {
+		if included[absPath(path)] {
+			continue
+		}
+		files[plugin+"/"+filepath.Base(path)] = path
+		included[absPath(path)] = true
+	}
+	return files, nil
+}
+
+// absPath returns absolute path used to compare paths of config files.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isConfigFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".conf", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func (p *Plugin) collectLogs(add func(string, []byte)) error {
+	if p.logTail == nil {
+		return fmt.Errorf("log tail is not recorded")
+	}
+	lines := p.logTail.Lines()
+	for i, line := range lines {
+		lines[i] = maskLogLine(line, p.config.MaskedKeys)
+	}
+	add("logs/tail.log", []byte(strings.Join(lines, "")))
+	return nil
+}
+
+// collectProfiles adds goroutine dump, heap profile and, if requested,
+// CPU profile taken for the given duration.
+func collectProfiles(add func(string, []byte), cpuProfile time.Duration) error {
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		return err
+	}
+	add("profiles/goroutines.txt", goroutines.Bytes())
+
+	var heap bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+		return err
+	}
+	add("profiles/heap.pprof", heap.Bytes())
+
+	if cpuProfile > 0 {
+		var cpu bytes.Buffer
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			return fmt.Errorf("CPU profile: %v", err)
+		}
+		time.Sleep(cpuProfile)
+		pprof.StopCPUProfile()
+		add("profiles/cpu.pprof", cpu.Bytes())
+	}
+	return nil
+}
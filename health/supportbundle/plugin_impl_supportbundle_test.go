@@ -0,0 +1,194 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/gomega"
+	"github.com/unrolled/render"
+)
+
+func TestMaskConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	data, err := maskConfig([]byte(`
+endpoint: 127.0.0.1:2379
+password: top-secret
+enable-token-auth: true
+secret-pin: 1234
+auth-token:
+tls:
+  key-file: /etc/key.pem
+  ca-file: /etc/ca.pem
+users:
+  - name: admin
+    password_hash: abc
+basic-auth:
+  - "user:pass"
+`), DefaultConfig().MaskedKeys)
+	Expect(err).ToNot(HaveOccurred())
+
+	var cfg map[string]interface{}
+	Expect(yaml.Unmarshal(data, &cfg)).To(Succeed())
+	Expect(cfg["endpoint"]).To(Equal("127.0.0.1:2379"))
+	Expect(cfg["password"]).To(Equal(maskedValue))
+	Expect(cfg["enable-token-auth"]).To(Equal(maskedValue))
+	Expect(cfg["secret-pin"]).To(Equal(maskedValue))
+	Expect(cfg["auth-token"]).To(BeNil())
+	Expect(cfg["tls"]).To(HaveKeyWithValue("key-file", maskedValue))
+	Expect(cfg["tls"]).To(HaveKeyWithValue("ca-file", "/etc/ca.pem"))
+	Expect(cfg["users"].([]interface{})[0]).To(HaveKeyWithValue("password_hash", maskedValue))
+	Expect(cfg["basic-auth"]).To(ConsistOf(maskedValue))
+
+	_, err = maskConfig([]byte("password: [unterminated"), DefaultConfig().MaskedKeys)
+	Expect(err).To(HaveOccurred())
+}
+
+func TestMaskLogLine(t *testing.T) {
+	RegisterTestingT(t)
+
+	masked := DefaultConfig().MaskedKeys
+	Expect(maskLogLine(`level=info msg="login as admin" password=top-secret user=admin`+"\n", masked)).
+		To(Equal(`level=info msg="login as admin" password=***** user=admin` + "\n"))
+	Expect(maskLogLine(`level=debug msg="connecting with token=abc, retry" auth-token="a b\" c" x=1`, masked)).
+		To(Equal(`level=debug msg="connecting with token=*****, retry" auth-token="*****" x=1`))
+	Expect(maskLogLine(`{"level":"info","msg":"ok","secret":"s3","count":3}`, masked)).
+		To(Equal(`{"level":"info","msg":"ok","secret":"*****","count":3}`))
+	Expect(maskLogLine(`time="2026-10-16 20:21:14" level=info msg="no secrets here"`, masked)).
+		To(Equal(`time="2026-10-16 20:21:14" level=info msg="no secrets here"`))
+}
+
+func TestLogTail(t *testing.T) {
+	RegisterTestingT(t)
+
+	tail := newLogTail(2)
+	Expect(tail.Lines()).To(BeEmpty())
+	tail.lines[0], tail.next = "a", 1
+	Expect(tail.Lines()).To(Equal([]string{"a"}))
+	tail.lines[1], tail.next, tail.full = "b", 0, true
+	tail.lines[0], tail.next = "c", 1
+	Expect(tail.Lines()).To(Equal([]string{"b", "c"}))
+}
+
+func TestBundleHandler(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "support-bundle")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	Expect(ioutil.WriteFile(filepath.Join(dir, "etcd.conf"), []byte("password: secret\n"), 0644)).To(Succeed())
+	Expect(ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)).To(Succeed())
+
+	// config files of plugins set by flags may be located outside the config dir
+	otherDir, err := ioutil.TempDir("", "support-bundle-flags")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(otherDir)
+	Expect(ioutil.WriteFile(filepath.Join(otherDir, "grpc.conf"), []byte("endpoint: 0.0.0.0:9111\n"), 0644)).To(Succeed())
+
+	cfg := DefaultConfig()
+	cfg.ConfigDir = dir
+	p := NewPlugin(UseConf(*cfg), UseDeps(func(deps *Deps) {
+		deps.HTTP = nil
+		deps.StatusCheck = nil
+		deps.LogRegistry = nil
+	}))
+	p.configFiles = func() map[string]string {
+		return map[string]string{
+			"etcd": filepath.Join(dir, "etcd.conf"),
+			"grpc": filepath.Join(otherDir, "grpc.conf"),
+		}
+	}
+	Expect(p.Init()).To(Succeed())
+	Expect(p.RegisterSection("txn-history", func(w io.Writer) error {
+		_, err := w.Write([]byte("txn 1"))
+		return err
+	})).To(Succeed())
+	Expect(p.RegisterSection("txn-history", nil)).ToNot(Succeed())
+	Expect(p.RegisterSection("graph", func(w io.Writer) error {
+		return errors.New("graph unavailable")
+	})).To(Succeed())
+
+	handler := p.bundleHandler(render.New())
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", bundlePath+"?cpu-profile=1h", nil))
+	Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", bundlePath, nil))
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(rec.Header().Get("Content-Type")).To(Equal("application/gzip"))
+
+	files := readBundle(rec.Body)
+	Expect(files).To(HaveKeyWithValue("config/etcd.conf", "password: '*****'\n"))
+	Expect(files).To(HaveKeyWithValue("sections/txn-history", "txn 1"))
+	Expect(files).To(HaveKey("profiles/goroutines.txt"))
+	Expect(files).To(HaveKey("profiles/heap.pprof"))
+	Expect(files).ToNot(HaveKey("config/notes.txt"))
+	Expect(files).To(HaveKeyWithValue("config/grpc/grpc.conf", "endpoint: 0.0.0.0:9111\n"))
+	Expect(files).ToNot(HaveKey("config/etcd/etcd.conf"))
+
+	var manifest Manifest
+	Expect(json.Unmarshal([]byte(files["manifest.json"]), &manifest)).To(Succeed())
+	errs := map[string]string{}
+	for _, s := range manifest.Sections {
+		errs[s.Name] = s.Error
+	}
+	Expect(errs).To(HaveKeyWithValue("config", ""))
+	Expect(errs).To(HaveKeyWithValue("health", "status check is not available"))
+	Expect(errs).To(HaveKeyWithValue("logs", "log tail is not recorded"))
+	Expect(errs).To(HaveKeyWithValue("graph", "graph unavailable"))
+}
+
+func readBundle(r io.Reader) map[string]string {
+	gr, err := gzip.NewReader(r)
+	Expect(err).ToNot(HaveOccurred())
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(tr)
+		Expect(err).ToNot(HaveOccurred())
+		files[hdr.Name] = string(data)
+	}
+}
+
+func TestBundleLogsMasked(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(*DefaultConfig()))
+	p.logTail = newLogTail(2)
+	p.logTail.lines[0], p.logTail.next = "level=info msg=\"vault login\" token=s.abc\n", 1
+
+	var logs string
+	Expect(p.collectLogs(func(name string, data []byte) { logs = string(data) })).To(Succeed())
+	Expect(logs).To(Equal("level=info msg=\"vault login\" token=*****\n"))
+}
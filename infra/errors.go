@@ -0,0 +1,159 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Severity of errors of an ErrorClass.
+type Severity string
+
+const (
+	// SeverityWarning is used for errors which do not affect the agent functionality.
+	SeverityWarning Severity = "warning"
+	// SeverityError is used for errors which break a part of the agent functionality.
+	SeverityError Severity = "error"
+	// SeverityCritical is used for errors which render the agent unusable.
+	SeverityCritical Severity = "critical"
+)
+
+// ErrorClass is a typed error code registered by a plugin together
+// with metadata allowing machine clients to react to the error class
+// instead of parsing error messages.
+type ErrorClass struct {
+	// Code uniquely identifies the error class, e.g. "logmanager/invalid-level".
+	Code string `json:"code"`
+	// Plugin which has registered the error class.
+	Plugin PluginName `json:"plugin"`
+	// Retriable is true if the failed operation may succeed when retried.
+	Retriable bool `json:"retriable"`
+	// Severity of the errors.
+	Severity Severity `json:"severity"`
+	// Docs is an optional link to the documentation of the error class.
+	Docs string `json:"docs,omitempty"`
+	// HTTPStatus is an optional HTTP status code used in REST responses.
+	HTTPStatus int `json:"-"`
+}
+
+var errorClasses = struct {
+	sync.RWMutex
+	byCode map[string]*ErrorClass
+}{byCode: make(map[string]*ErrorClass)}
+
+// RegisterErrorClass registers the error class, typically assigned to a package level
+// variable of the plugin. It panics if an error class with the same code already exists.
+func RegisterErrorClass(class ErrorClass) *ErrorClass {
+	errorClasses.Lock()
+	defer errorClasses.Unlock()
+
+	if class.Code == "" {
+		panic("error class registered without code")
+	}
+	if _, exists := errorClasses.byCode[class.Code]; exists {
+		panic(fmt.Sprintf("error class %q already registered", class.Code))
+	}
+	if class.Severity == "" {
+		class.Severity = SeverityError
+	}
+	c := &class
+	errorClasses.byCode[c.Code] = c
+
+	return c
+}
+
+// LookupErrorClass returns error class registered with the given code or nil.
+func LookupErrorClass(code string) *ErrorClass {
+	errorClasses.RLock()
+	defer errorClasses.RUnlock()
+
+	return errorClasses.byCode[code]
+}
+
+// ErrorClasses returns all registered error classes sorted by the code.
+func ErrorClasses() []ErrorClass {
+	errorClasses.RLock()
+	defer errorClasses.RUnlock()
+
+	classes := make([]ErrorClass, 0, len(errorClasses.byCode))
+	for _, c := range errorClasses.byCode {
+		classes = append(classes, *c)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].Code < classes[j].Code
+	})
+
+	return classes
+}
+
+// Errorf returns a new error of the class.
+func (c *ErrorClass) Errorf(format string, args ...interface{}) error {
+	return &ClassifiedError{Class: c, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap assigns the class to the given error, nil is returned for nil error.
+func (c *ErrorClass) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: c, Err: err}
+}
+
+// Match returns true if the error (or any error it was created from) belongs to the class.
+func (c *ErrorClass) Match(err error) bool {
+	return ErrorClassOf(err) == c
+}
+
+// ClassifiedError is an error with assigned ErrorClass.
+type ClassifiedError struct {
+	Class *ErrorClass
+	Err   error
+}
+
+// Error returns message of the underlying error.
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *ClassifiedError) Cause() error {
+	return e.Err
+}
+
+// ErrorClassOf returns the class of the error or nil if the error is not classified.
+// Errors wrapped using github.com/pkg/errors are inspected as well.
+func ErrorClassOf(err error) *ErrorClass {
+	for err != nil {
+		if classified, ok := err.(*ClassifiedError); ok {
+			return classified.Class
+		}
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			return nil
+		}
+		err = causer.Cause()
+	}
+	return nil
+}
+
+// ErrorCode returns code of the error class or empty string if the error is not classified.
+func ErrorCode(err error) string {
+	if class := ErrorClassOf(err); class != nil {
+		return class.Code
+	}
+	return ""
+}
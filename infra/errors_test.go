@@ -0,0 +1,49 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// forgetErrorClass removes the class registered by a test from the global registry,
+// so that the test can be repeated.
+func forgetErrorClass(code string) {
+	errorClasses.Lock()
+	defer errorClasses.Unlock()
+	delete(errorClasses.byCode, code)
+}
+
+func TestErrorClass(t *testing.T) {
+	RegisterTestingT(t)
+
+	defer forgetErrorClass("test/not-found")
+	class := RegisterErrorClass(ErrorClass{Code: "test/not-found", Plugin: "test", Retriable: true})
+	Expect(class.Severity).To(Equal(SeverityError))
+	Expect(LookupErrorClass("test/not-found")).To(Equal(class))
+	Expect(func() { RegisterErrorClass(ErrorClass{Code: "test/not-found"}) }).To(Panic())
+
+	err := class.Errorf("item %s not found", "x")
+	Expect(err.Error()).To(Equal("item x not found"))
+	Expect(class.Match(err)).To(BeTrue())
+	Expect(ErrorCode(pkgerrors.WithMessage(err, "get failed"))).To(Equal("test/not-found"))
+	Expect(ErrorClassOf(errors.New("plain"))).To(BeNil())
+	Expect(class.Wrap(nil)).To(BeNil())
+	Expect(ErrorClasses()).To(ContainElement(*class))
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import (
+	"context"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+)
+
+// EventLoop manages the lifecycle of goroutines started by a plugin. It replaces
+// the context/wait group boilerplate: goroutines are usually started in AfterInit
+// and stopped by calling Stop in Close. Panics in goroutines and event handlers are
+// recovered and logged. The zero value is ready to use. All goroutines are stopped
+// at once, plugins that need to stop them in a particular order (e.g. supervisor
+// stopping programs before the hooks watching their state) do so explicitly.
+//
+//	type Plugin struct {
+//	    Deps
+//	    loop infra.EventLoop
+//	}
+//
+//	func (p *Plugin) AfterInit() error {
+//	    p.loop.Log = p.Log
+//	    p.loop.Run(
+//	        infra.OnTick(time.Second, p.refresh),
+//	        infra.OnReceive(p.notifCh, p.handleNotification),
+//	    )
+//	    return nil
+//	}
+//
+//	func (p *Plugin) Close() error {
+//	    p.loop.Stop()
+//	    return nil
+//	}
+type EventLoop struct {
+	// Log is used to report recovered panics (default logger is used if not set).
+	Log logging.Logger
+
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// EventHandler defines a source of events handled by the event loop together with
+// the function called for every event. Use OnTick or OnReceive to create it.
+type EventHandler struct {
+	interval time.Duration
+	channel  reflect.Value
+	handle   func(ctx context.Context, event interface{})
+}
+
+// OnTick creates handler called periodically with the given interval.
+func OnTick(interval time.Duration, handle func(ctx context.Context)) EventHandler {
+	return EventHandler{
+		interval: interval,
+		handle: func(ctx context.Context, _ interface{}) {
+			handle(ctx)
+		},
+	}
+}
+
+// OnReceive creates handler called for every value received from the channel. The channel
+// can be of any type, the received value is passed to the handler. The handler is no longer
+// called once the channel is closed.
+func OnReceive(channel interface{}, handle func(ctx context.Context, value interface{})) EventHandler {
+	ch := reflect.ValueOf(channel)
+	if ch.Kind() != reflect.Chan || ch.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("infra.OnReceive: receive channel expected, got " + ch.Kind().String())
+	}
+	return EventHandler{channel: ch, handle: handle}
+}
+
+// Context returns the context which is canceled when the event loop is stopped.
+func (l *EventLoop) Context() context.Context {
+	l.init()
+	return l.ctx
+}
+
+// Go starts the function in a new goroutine. The function should return when the given
+// context is canceled.
+func (l *EventLoop) Go(fn func(ctx context.Context)) {
+	l.init()
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		l.safeCall(func() { fn(l.ctx) })
+	}()
+}
+
+// Run starts a goroutine multiplexing events from all given handlers. Handlers are
+// called sequentially, so they do not need to synchronize with each other.
+func (l *EventLoop) Run(handlers ...EventHandler) {
+	l.Go(func(ctx context.Context) {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		}
+		var active []EventHandler
+		for _, h := range handlers {
+			ch := h.channel
+			if h.interval > 0 {
+				ticker := time.NewTicker(h.interval)
+				defer ticker.Stop()
+				ch = reflect.ValueOf(ticker.C)
+			}
+			if !ch.IsValid() {
+				continue
+			}
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: ch})
+			active = append(active, h)
+		}
+
+		for {
+			chosen, value, ok := reflect.Select(cases)
+			if chosen == 0 {
+				return
+			}
+			if !ok {
+				// channel was closed, stop selecting from it
+				cases[chosen].Chan = reflect.Value{}
+				continue
+			}
+			h := active[chosen-1]
+			l.safeCall(func() { h.handle(ctx, value.Interface()) })
+		}
+	})
+}
+
+// Stop cancels the context of the event loop and waits until all goroutines
+// have returned.
+func (l *EventLoop) Stop() {
+	l.init()
+	l.cancel()
+	l.wg.Wait()
+}
+
+func (l *EventLoop) init() {
+	l.once.Do(func() {
+		l.ctx, l.cancel = context.WithCancel(context.Background())
+	})
+}
+
+// safeCall calls the function and recovers from panic
+func (l *EventLoop) safeCall(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log := l.Log
+			if log == nil {
+				log = logging.DefaultLogger
+			}
+			log.Errorf("recovered from panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infra
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEventLoopRun(t *testing.T) {
+	RegisterTestingT(t)
+
+	var loop EventLoop
+	values := make(chan string, 2)
+	received := make(chan string, 2)
+	ticks := make(chan struct{}, 10)
+
+	loop.Run(
+		OnReceive(values, func(ctx context.Context, value interface{}) {
+			received <- value.(string)
+		}),
+		OnTick(time.Millisecond, func(ctx context.Context) {
+			select {
+			case ticks <- struct{}{}:
+			default:
+			}
+		}),
+	)
+	values <- "a"
+	values <- "b"
+	close(values)
+
+	Eventually(received).Should(Receive(Equal("a")))
+	Eventually(received).Should(Receive(Equal("b")))
+	Eventually(ticks).Should(Receive())
+
+	loop.Stop()
+	Expect(loop.Context().Err()).To(Equal(context.Canceled))
+}
+
+func TestEventLoopRecoversPanic(t *testing.T) {
+	RegisterTestingT(t)
+
+	var loop EventLoop
+	values := make(chan int)
+	handled := make(chan int, 1)
+
+	loop.Run(OnReceive(values, func(ctx context.Context, value interface{}) {
+		if value.(int) == 0 {
+			panic("handler failed")
+		}
+		handled <- value.(int)
+	}))
+	values <- 0
+	values <- 1
+	Eventually(handled).Should(Receive(Equal(1)))
+
+	loop.Go(func(ctx context.Context) {
+		panic("goroutine failed")
+	})
+	loop.Stop()
+}
+
+func TestEventLoopStopWaits(t *testing.T) {
+	RegisterTestingT(t)
+
+	var loop EventLoop
+	finished := false
+	loop.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	})
+	loop.Stop()
+	Expect(finished).To(BeTrue())
+}
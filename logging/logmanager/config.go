@@ -15,6 +15,10 @@ type Config struct {
 	DefaultLevel string                `json:"default-level"`
 	Loggers      []LoggerConfig        `json:"loggers"`
 	Hooks        map[string]HookConfig `json:"hooks"`
+	// GRPCHooks allows to add hooks using the logs.LogManager gRPC service. Added hook
+	// sends logs to any address, so it should be enabled only if the gRPC server
+	// authenticates its clients (TLS with client certificates verified by ca-files).
+	GRPCHooks bool `json:"grpc-hooks"`
 }
 
 // LoggerConfig is configuration of a particular logger.
@@ -13,5 +13,9 @@
 // limitations under the License.
 
 // Package logmanager implements the log manager that allows users to set
-// log levels at run-time via a REST API.
+// log levels at run-time via a REST API. If the GRPC dependency is injected,
+// the loggers can be also managed using the logs.LogManager gRPC service
+// (list loggers, get/set levels with streamed confirmation, add hooks). Adding
+// hooks over gRPC must be enabled by the grpc-hooks option, which should be used
+// only together with gRPC client authentication.
 package logmanager
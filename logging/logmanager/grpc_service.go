@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmanager
+
+import (
+	"io"
+
+	"github.com/ligato/cn-infra/logging/logmanager/model/logs"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --proto_path=model/logs --go_out=plugins=grpc:model/logs model/logs/logs.proto
+
+// logService implements logs.LogManagerServer on top of the plugin.
+type logService struct {
+	p *Plugin
+}
+
+// ListLoggers returns all registered loggers.
+func (s *logService) ListLoggers(ctx context.Context, req *logs.ListLoggersRequest) (*logs.ListLoggersResponse, error) {
+	resp := &logs.ListLoggersResponse{}
+	for _, logger := range s.p.listLoggers() {
+		resp.Loggers = append(resp.Loggers, &logs.Logger{
+			Name:  logger.Logger,
+			Level: logger.Level,
+		})
+	}
+	return resp, nil
+}
+
+// GetLevel returns the level of the requested logger.
+func (s *logService) GetLevel(ctx context.Context, req *logs.GetLevelRequest) (*logs.Logger, error) {
+	level, err := s.p.LogRegistry.GetLevel(req.GetName())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &logs.Logger{Name: req.GetName(), Level: level}, nil
+}
+
+// SetLevels sets levels of the loggers received in the stream and confirms
+// every change (or reports the error) in the response stream.
+func (s *logService) SetLevels(stream logs.LogManager_SetLevelsServer) error {
+	for {
+		logger, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &logs.SetLevelResult{Logger: logger}
+		if err := s.p.setLoggerLogLevel(logger.GetName(), logger.GetLevel()); err != nil {
+			result.Error = err.Error()
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// AddHook adds a hook sending logs to the requested target. Hooks can be added only
+// if enabled by the grpc-hooks option.
+func (s *logService) AddHook(ctx context.Context, req *logs.Hook) (*logs.AddHookResponse, error) {
+	if s.p.Config == nil || !s.p.Config.GRPCHooks {
+		return nil, status.Error(codes.PermissionDenied, "adding log hooks over gRPC is disabled")
+	}
+	err := s.p.addHook(req.GetName(), HookConfig{
+		Protocol: req.GetProtocol(),
+		Address:  req.GetAddress(),
+		Port:     int(req.GetPort()),
+		Levels:   req.GetLevels(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &logs.AddHookResponse{}, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmanager
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ligato/cn-infra/logging/logmanager/model/logs"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type setLevelsStreamMock struct {
+	grpc.ServerStream
+	requests []*logs.Logger
+	results  []*logs.SetLevelResult
+}
+
+func (s *setLevelsStreamMock) Recv() (*logs.Logger, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *setLevelsStreamMock) Send(result *logs.SetLevelResult) error {
+	s.results = append(s.results, result)
+	return nil
+}
+
+func TestLogService(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(Config{}))
+	p.LogRegistry = logrus.NewLogRegistry()
+	p.LogRegistry.NewLogger("grpc-test")
+	svc := &logService{p: p}
+
+	stream := &setLevelsStreamMock{requests: []*logs.Logger{
+		{Name: "grpc-test", Level: "debug"},
+		{Name: "grpc-test", Level: "verbose"},
+	}}
+	Expect(svc.SetLevels(stream)).To(Succeed())
+	Expect(stream.results).To(HaveLen(2))
+	Expect(stream.results[0].Error).To(BeEmpty())
+	Expect(stream.results[1].Error).NotTo(BeEmpty())
+
+	logger, err := svc.GetLevel(context.Background(), &logs.GetLevelRequest{Name: "grpc-test"})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(logger.Level).To(Equal("debug"))
+
+	_, err = svc.GetLevel(context.Background(), &logs.GetLevelRequest{Name: "unknown"})
+	Expect(err).To(HaveOccurred())
+
+	list, err := svc.ListLoggers(context.Background(), &logs.ListLoggersRequest{})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(list.Loggers).To(ContainElement(&logs.Logger{Name: "grpc-test", Level: "debug"}))
+}
+
+func TestLogServiceAddHookDisabled(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(Config{}))
+	svc := &logService{p: p}
+
+	// hooks cannot be added unless enabled
+	_, err := svc.AddHook(context.Background(), &logs.Hook{Name: "unknown"})
+	Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+
+	p.Config.GRPCHooks = true
+	_, err = svc.AddHook(context.Background(), &logs.Hook{Name: "unknown"})
+	Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+}
@@ -18,10 +18,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logmanager/model/logs"
+	"github.com/ligato/cn-infra/rpc/grpc"
 	"github.com/ligato/cn-infra/rpc/rest"
 	"github.com/ligato/cn-infra/servicelabel"
 	"github.com/unrolled/render"
@@ -33,6 +36,21 @@ type LoggerData struct {
 	Level  string `json:"level"`
 }
 
+// ErrInvalidLogLevel is the class of errors returned when setting an unknown log level.
+var ErrInvalidLogLevel = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:     "logmanager/invalid-level",
+	Plugin:   "logs",
+	Severity: infra.SeverityWarning,
+})
+
+// errorResponse is the JSON body of error responses of the log level handler.
+// It keeps the "Error" key (and status 500) used by the API before
+// rest.ErrorResponse was introduced, the class code is added.
+type errorResponse struct {
+	Error string
+	Code  string `json:"code,omitempty"`
+}
+
 // Variable names in logger registry URLs
 const (
 	loggerVarName = "logger"
@@ -53,6 +71,7 @@ type Deps struct {
 	ServiceLabel servicelabel.ReaderAPI
 	LogRegistry  logging.Registry
 	HTTP         rest.HTTPHandlers
+	GRPC         grpc.Server // inject (optional) to expose the logs.LogManager service
 }
 
 // Init does nothing
@@ -109,6 +128,10 @@ func (p *Plugin) Init() error {
 		}
 	}
 
+	if p.GRPC != nil && !p.GRPC.IsDisabled() {
+		logs.RegisterLogManagerServer(p.GRPC.GetServer(), &logService{p: p})
+	}
+
 	return nil
 }
 
@@ -146,7 +169,18 @@ func (p *Plugin) listLoggers() (loggers []LoggerData) {
 func (p *Plugin) setLoggerLogLevel(name string, level string) error {
 	p.Log.Debugf("SetLogLevel name %q, level %q", name, level)
 
-	return p.LogRegistry.SetLevel(name, level)
+	err := p.LogRegistry.SetLevel(name, level)
+	if err != nil && !isLogLevel(level) {
+		return ErrInvalidLogLevel.Wrap(err)
+	}
+	return err
+}
+
+// isLogLevel returns true if the level is one of the levels defined by the logging package.
+func isLogLevel(level string) bool {
+	// unknown levels are parsed as InfoLevel
+	lvl := logging.ParseLogLevel(level)
+	return lvl != logging.InfoLevel || strings.EqualFold(level, lvl.String())
 }
 
 // logLevelHandler processes requests to set log level on loggers in a plugin
@@ -161,8 +195,10 @@ func (p *Plugin) logLevelHandler(formatter *render.Render) http.HandlerFunc {
 		}
 		err := p.setLoggerLogLevel(vars[loggerVarName], vars[levelVarName])
 		if err != nil {
-			formatter.JSON(w, http.StatusInternalServerError,
-				struct{ Error string }{err.Error()})
+			formatter.JSON(w, http.StatusInternalServerError, errorResponse{
+				Error: err.Error(),
+				Code:  infra.ErrorCode(err),
+			})
 			return
 		}
 
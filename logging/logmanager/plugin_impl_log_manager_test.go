@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+	"github.com/unrolled/render"
+)
+
+func TestLogLevelHandlerError(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(Config{}))
+	p.LogRegistry = logrus.NewLogRegistry()
+	p.LogRegistry.NewLogger("rest-test")
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/log/rest-test/verbose", nil),
+		map[string]string{loggerVarName: "rest-test", levelVarName: "verbose"})
+	rec := httptest.NewRecorder()
+	p.logLevelHandler(render.New())(rec, req)
+
+	// the error is reported under the "Error" key as before error classes
+	Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+	var body map[string]string
+	Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+	Expect(body).To(HaveKey("Error"))
+	Expect(body["Error"]).NotTo(BeEmpty())
+	Expect(body).To(HaveKeyWithValue("code", ErrInvalidLogLevel.Code))
+}
+
+// failingRegistry fails to set levels of all loggers.
+type failingRegistry struct {
+	logging.Registry
+}
+
+func (r failingRegistry) SetLevel(logger, level string) error {
+	return errors.New("logger not found")
+}
+
+func TestLogLevelHandlerUnclassifiedError(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := NewPlugin(UseConf(Config{}))
+	p.LogRegistry = failingRegistry{logrus.NewLogRegistry()}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/log/rest-test/debug", nil),
+		map[string]string{loggerVarName: "rest-test", levelVarName: "debug"})
+	rec := httptest.NewRecorder()
+	p.logLevelHandler(render.New())(rec, req)
+
+	// only invalid levels are reported with the code of ErrInvalidLogLevel
+	Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+	var body map[string]string
+	Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+	Expect(body).To(HaveKeyWithValue("Error", "logger not found"))
+	Expect(body).NotTo(HaveKey("code"))
+}
@@ -225,11 +225,7 @@ func (conn *BytesManualConnectionStr) StopConsumingPartition(topic string, parti
 
 //SendSyncMessage sends a message using the sync API and default partitioner
 func (conn *BytesConnectionStr) SendSyncMessage(topic string, key client.Encoder, value client.Encoder) (offset int64, err error) {
-	msg, err := conn.multiplexer.hashSyncProducer.SendMsgToPartition(topic, DefPartition, key, value)
-	if err != nil {
-		return 0, err
-	}
-	return msg.Offset, err
+	return conn.multiplexer.sendSyncMessage(conn.multiplexer.hashSyncProducer, topic, DefPartition, key, value)
 }
 
 // SendAsyncMessage sends a message using the async API and default partitioner
@@ -240,11 +236,7 @@ func (conn *BytesConnectionStr) SendAsyncMessage(topic string, key client.Encode
 
 //SendSyncMessageToPartition sends a message using the sync API and default partitioner
 func (conn *BytesManualConnectionStr) SendSyncMessageToPartition(topic string, partition int32, key client.Encoder, value client.Encoder) (offset int64, err error) {
-	msg, err := conn.multiplexer.manSyncProducer.SendMsgToPartition(topic, partition, key, value)
-	if err != nil {
-		return 0, err
-	}
-	return msg.Offset, err
+	return conn.multiplexer.sendSyncMessage(conn.multiplexer.manSyncProducer, topic, partition, key, value)
 }
 
 // SendAsyncMessageToPartition sends a message using the async API and default partitioner
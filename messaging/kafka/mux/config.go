@@ -6,6 +6,7 @@ import (
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/messaging/kafka/client"
 	"github.com/ligato/cn-infra/utils/clienttls"
+	"github.com/ligato/cn-infra/utils/retry"
 	"time"
 )
 
@@ -25,6 +26,10 @@ type Config struct {
 	Addrs   []string      `json:"addrs"`
 	GroupID string        `json:"group_id"`
 	TLS     clienttls.TLS `json:"tls"`
+	// PublishRetry enables retrying of messages published using the sync API (disabled if not set).
+	// Messages are sent retry.DefaultMaxAttempts times if max-attempts is not set, -1 retries until
+	// the multiplexer is closed.
+	PublishRetry *retry.Config `json:"publish-retry"`
 }
 
 // ConsumerFactory produces a consumer for the selected topics in a specified consumer group.
@@ -64,7 +69,7 @@ func getConsumerFactory(config *client.Config) ConsumerFactory {
 func InitMultiplexer(configFile string, name string, log logging.Logger) (*Multiplexer, error) {
 	var err error
 	var tls clienttls.TLS
-	cfg := &Config{[]string{DefAddress}, "", tls}
+	cfg := &Config{Addrs: []string{DefAddress}, TLS: tls}
 	if configFile != "" {
 		cfg, err = ConfigFromFile(configFile)
 		if err != nil {
@@ -100,7 +105,12 @@ func InitMultiplexer(configFile string, name string, log logging.Logger) (*Multi
 	}
 
 	// todo client is currently set always as hash
-	return InitMultiplexerWithConfig(clientCfg, sClientHash, sClientManual, name, log)
+	mux, err := InitMultiplexerWithConfig(clientCfg, sClientHash, sClientManual, name, log)
+	if err != nil {
+		return nil, err
+	}
+	mux.SetPublishRetry(cfg.PublishRetry)
+	return mux, nil
 }
 
 // InitMultiplexerWithConfig initialize and returns new kafka multiplexer based on the supplied mux configuration.
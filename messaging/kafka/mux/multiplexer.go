@@ -1,14 +1,17 @@
 package mux
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
 
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/logging"
 	"github.com/ligato/cn-infra/messaging/kafka/client"
+	"github.com/ligato/cn-infra/utils/retry"
 	"github.com/ligato/cn-infra/utils/safeclose"
 )
 
@@ -47,6 +50,13 @@ type Multiplexer struct {
 
 	// factory that crates Consumer used in the Multiplexer
 	consumerFactory func(topics []string, groupId string) (*client.Consumer, error)
+
+	// options used to retry messages published using the sync API, nil if retrying is disabled
+	publishRetry []retry.Option
+
+	// closeCtx is canceled when the Multiplexer is closed to stop pending retries
+	closeCtx    context.Context
+	cancelClose context.CancelFunc
 }
 
 // ConsumerSubscription contains all information about subscribed kafka consumer/watcher
@@ -101,6 +111,7 @@ func NewMultiplexer(consumerFactory ConsumerFactory, producers multiplexerProduc
 		multiplexerProducers: producers,
 		config:               clientCfg,
 	}
+	cl.closeCtx, cl.cancelClose = context.WithCancel(context.Background())
 
 	go cl.watchAsyncProducerChannels()
 	if producers.manAsyncProducer != nil && producers.manAsyncProducer.Config != nil {
@@ -229,8 +240,39 @@ func (mux *Multiplexer) Start() error {
 	return err
 }
 
+// SetPublishRetry enables retrying of messages published using the sync API. Retrying is disabled
+// if the config is nil.
+func (mux *Multiplexer) SetPublishRetry(cfg *retry.Config) {
+	if cfg == nil {
+		mux.publishRetry = nil
+		return
+	}
+	mux.publishRetry = append(cfg.Options(), retry.OnRetry(func(attempt int, delay time.Duration, err error) {
+		mux.Debugf("Publishing kafka message failed (attempt %d): %v, next attempt in %v", attempt, err, delay)
+	}))
+}
+
+// sendSyncMessage sends a message using the given sync producer. The message is re-sent according
+// to the publish retry settings if sending fails. Retrying stops once the Multiplexer is closed.
+func (mux *Multiplexer) sendSyncMessage(producer *client.SyncProducer, topic string, partition int32,
+	key sarama.Encoder, value sarama.Encoder) (offset int64, err error) {
+	send := func() error {
+		msg, err := producer.SendMsgToPartition(topic, partition, key, value)
+		if err != nil {
+			return err
+		}
+		offset = msg.Offset
+		return nil
+	}
+	if mux.publishRetry == nil {
+		return offset, send()
+	}
+	return offset, retry.Do(mux.closeCtx, send, mux.publishRetry...)
+}
+
 // Close cleans up the resources used by the Multiplexer
 func (mux *Multiplexer) Close() {
+	mux.cancelClose()
 	safeclose.Close(
 		mux.Consumer,
 		mux.hashSyncProducer,
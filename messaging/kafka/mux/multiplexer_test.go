@@ -15,13 +15,16 @@
 package mux
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/examples/model"
 	"github.com/ligato/cn-infra/messaging"
 	"github.com/ligato/cn-infra/messaging/kafka/client"
+	"github.com/ligato/cn-infra/utils/retry"
 	"github.com/onsi/gomega"
 )
 
@@ -284,6 +287,29 @@ func TestSendSync(t *testing.T) {
 	mock.Mux.Close()
 }
 
+func TestSendSyncRetryStopsOnClose(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	mock := Mock(t)
+	gomega.Expect(mock.Mux).NotTo(gomega.BeNil())
+
+	// unlimited attempts, the next one would not happen before the mux is closed
+	mock.Mux.SetPublishRetry(&retry.Config{MaxAttempts: retry.Unlimited, InitialInterval: time.Hour})
+	c1 := mock.Mux.NewBytesConnection("c1")
+
+	mock.Mux.Start()
+	mock.SyncPub.ExpectSendMessageAndFail(errors.New("send failed"))
+
+	sent := make(chan error, 1)
+	go func() {
+		_, err := c1.SendSyncString("topic", "key", "value")
+		sent <- err
+	}()
+	gomega.Consistently(sent, 100*time.Millisecond).ShouldNot(gomega.Receive())
+
+	mock.Mux.Close()
+	gomega.Eventually(sent).Should(gomega.Receive(gomega.MatchError("send failed")))
+}
+
 func TestSendProtoSync(t *testing.T) {
 	gomega.RegisterTestingT(t)
 	mock := Mock(t)
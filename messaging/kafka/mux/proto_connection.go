@@ -313,18 +313,11 @@ func (conn *ProtoConnectionFields) sendSyncMessage(topic string, partition int32
 		return 0, err
 	}
 
+	producer := conn.multiplexer.hashSyncProducer
 	if manualMode {
-		msg, err := conn.multiplexer.manSyncProducer.SendMsgToPartition(topic, partition, sarama.StringEncoder(key), sarama.ByteEncoder(data))
-		if err != nil {
-			return 0, err
-		}
-		return msg.Offset, err
-	}
-	msg, err := conn.multiplexer.hashSyncProducer.SendMsgToPartition(topic, partition, sarama.StringEncoder(key), sarama.ByteEncoder(data))
-	if err != nil {
-		return 0, err
+		producer = conn.multiplexer.manSyncProducer
 	}
-	return msg.Offset, err
+	return conn.multiplexer.sendSyncMessage(producer, topic, partition, sarama.StringEncoder(key), sarama.ByteEncoder(data))
 }
 
 // sendAsyncMessage sends a message using the async API. If manual mode is chosen, the appropriate producer will be used.
@@ -102,6 +102,7 @@ func (p *Plugin) Init() (err error) {
 		if err != nil {
 			return err
 		}
+		p.mux.SetPublishRetry(muxCfg.PublishRetry)
 		p.Log.Debug("Default multiplexer initialized")
 	}
 
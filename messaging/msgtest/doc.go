@@ -0,0 +1,25 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package msgtest provides an in-memory implementation of messaging.Mux
+// intended for unit tests of plugins which would otherwise need a real Kafka.
+//
+// Published messages are kept per topic and partition and delivered
+// synchronously to all watchers before Put returns. Partition watchers can
+// replay messages from a given offset. Faults can be injected to make
+// publishing or committing fail, slow it down or to drop delivered messages:
+//
+//	mux := msgtest.NewMux()
+//	mux.InjectFault(msgtest.Fault{Op: msgtest.OpPublish, Topic: "events", Err: errors.New("kafka down")})
+package msgtest
@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgtest
+
+import (
+	"time"
+)
+
+// Op identifies the messaging operation affected by a Fault.
+type Op string
+
+const (
+	// OpPublish matches publishing of a message (sync or async).
+	OpPublish Op = "publish"
+	// OpWatch matches Watch and WatchPartition.
+	OpWatch Op = "watch"
+	// OpCommit matches CommitOffsets.
+	OpCommit Op = "commit"
+	// OpDeliver matches delivery of a message to watchers. Matching messages
+	// are stored but not delivered.
+	OpDeliver Op = "deliver"
+)
+
+// Fault describes a failure injected into the Mux.
+type Fault struct {
+	// Op is the affected operation.
+	Op Op
+	// Topic limits the fault to the given topic. Empty topic matches all topics
+	// (faults of OpCommit must not set the topic, offsets are committed for all topics at once).
+	Topic string
+	// Err is returned by the affected operation (ignored for OpDeliver).
+	Err error
+	// Delay is applied before the affected operation is executed.
+	Delay time.Duration
+	// Times is the number of operations affected by the fault (0 means unlimited).
+	Times int
+}
+
+// InjectFault adds the fault. Faults are evaluated in the order of injection
+// and the first matching fault is applied.
+func (m *Mux) InjectFault(fault Fault) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := fault
+	m.faults = append(m.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (m *Mux) ClearFaults() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.faults = nil
+}
+
+// fault returns the fault matching the operation on the topic and consumes
+// one of its occurrences. Must be called with the lock held.
+func (m *Mux) fault(op Op, topic string) *Fault {
+	for i, f := range m.faults {
+		if f.Op != op || (f.Topic != "" && f.Topic != topic) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				m.faults = append(m.faults[:i], m.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// checkFault applies the fault matching the operation on the topic, if any.
+func (m *Mux) checkFault(op Op, topic string) error {
+	m.mu.Lock()
+	f := m.fault(op, topic)
+	m.mu.Unlock()
+
+	if f == nil {
+		return nil
+	}
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+	return f.Err
+}
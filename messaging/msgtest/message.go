@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgtest
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// Message is a published message implementing messaging.ProtoMessage.
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       string
+	Value     []byte
+}
+
+// GetTopic returns the topic the message was published to.
+func (msg *Message) GetTopic() string {
+	return msg.Topic
+}
+
+// GetPartition returns the partition the message was published to.
+func (msg *Message) GetPartition() int32 {
+	return msg.Partition
+}
+
+// GetOffset returns the offset of the message within the partition.
+func (msg *Message) GetOffset() int64 {
+	return msg.Offset
+}
+
+// GetKey returns the key of the message.
+func (msg *Message) GetKey() string {
+	return msg.Key
+}
+
+// GetValue unmarshals the message value into <value>.
+func (msg *Message) GetValue(value proto.Message) error {
+	return proto.Unmarshal(msg.Value, value)
+}
+
+// GetPrevValue returns false, messages have no previous value.
+func (msg *Message) GetPrevValue(prevValue proto.Message) (prevValueExist bool, err error) {
+	return false, nil
+}
+
+// messageErr is a message which failed to be published.
+type messageErr struct {
+	*Message
+	err error
+}
+
+// Error returns the cause of the failed delivery.
+func (msg *messageErr) Error() error {
+	return msg.err
+}
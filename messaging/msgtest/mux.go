@@ -0,0 +1,327 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgtest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/messaging"
+)
+
+// Mux is an in-memory implementation of messaging.Mux.
+type Mux struct {
+	mu            sync.Mutex
+	partitions    map[string]map[int32][]*Message // topic -> partition -> messages
+	subscriptions []*subscription
+	marked        map[string]map[offsetKey]int64 // subscriber -> marked offsets
+	committed     map[string]map[offsetKey]int64 // subscriber -> committed offsets
+	faults        []*Fault
+	disabled      bool
+}
+
+type offsetKey struct {
+	topic     string
+	partition int32
+}
+
+type subscription struct {
+	subscriber string
+	topic      string
+	partition  int32
+	offset     int64
+	anyPart    bool // subscribed to all partitions of the topic
+	callback   func(messaging.ProtoMessage)
+}
+
+// NewMux creates an empty Mux.
+func NewMux() *Mux {
+	return &Mux{
+		partitions: make(map[string]map[int32][]*Message),
+		marked:     make(map[string]map[offsetKey]int64),
+		committed:  make(map[string]map[offsetKey]int64),
+	}
+}
+
+// SetDisabled sets the value returned by Disabled.
+func (m *Mux) SetDisabled(disabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.disabled = disabled
+}
+
+// Disabled returns true if the Mux was disabled by SetDisabled.
+func (m *Mux) Disabled() (disabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.disabled
+}
+
+// Messages returns all messages published to the topic ordered by partition and offset.
+func (m *Mux) Messages(topic string) []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var partitions []int32
+	for partition := range m.partitions[topic] {
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i] < partitions[j]
+	})
+	var msgs []*Message
+	for _, partition := range partitions {
+		msgs = append(msgs, m.partitions[topic][partition]...)
+	}
+	return msgs
+}
+
+// Committed returns the offset committed by the subscriber for the given topic and partition.
+func (m *Mux) Committed(subscriber string, topic string, partition int32) (offset int64, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offset, found = m.committed[subscriber][offsetKey{topic, partition}]
+	return offset, found
+}
+
+// NewSyncPublisher creates a publisher sending messages to partition 0 of the topic.
+func (m *Mux) NewSyncPublisher(connName string, topic string) (messaging.ProtoPublisher, error) {
+	return &publisher{mux: m, topic: topic}, nil
+}
+
+// NewSyncPublisherToPartition creates a publisher sending messages to the given topic and partition.
+func (m *Mux) NewSyncPublisherToPartition(connName string, topic string, partition int32) (messaging.ProtoPublisher, error) {
+	return &publisher{mux: m, topic: topic, partition: partition}, nil
+}
+
+// NewAsyncPublisher creates a publisher sending messages to partition 0 of the topic
+// and reporting the result through the callbacks.
+func (m *Mux) NewAsyncPublisher(connName string, topic string, successClb func(messaging.ProtoMessage),
+	errorClb func(err messaging.ProtoMessageErr)) (messaging.ProtoPublisher, error) {
+	return &publisher{mux: m, topic: topic, async: true, successClb: successClb, errorClb: errorClb}, nil
+}
+
+// NewAsyncPublisherToPartition creates a publisher sending messages to the given topic
+// and partition and reporting the result through the callbacks.
+func (m *Mux) NewAsyncPublisherToPartition(connName string, topic string, partition int32,
+	successClb func(messaging.ProtoMessage), errorClb func(err messaging.ProtoMessageErr)) (messaging.ProtoPublisher, error) {
+	return &publisher{mux: m, topic: topic, partition: partition, async: true,
+		successClb: successClb, errorClb: errorClb}, nil
+}
+
+// NewWatcher creates a watcher consuming all partitions of the watched topics.
+func (m *Mux) NewWatcher(subscriberName string) messaging.ProtoWatcher {
+	return &watcher{mux: m, subscriber: subscriberName}
+}
+
+// NewPartitionWatcher creates a watcher consuming selected partitions from the given offset.
+func (m *Mux) NewPartitionWatcher(subscriberName string) messaging.ProtoPartitionWatcher {
+	return &watcher{mux: m, subscriber: subscriberName}
+}
+
+// publish stores the message and delivers it to the subscriptions.
+func (m *Mux) publish(topic string, partition int32, key string, data proto.Message) (*Message, error) {
+	if err := m.checkFault(OpPublish, topic); err != nil {
+		return &Message{Topic: topic, Partition: partition, Key: key}, err
+	}
+	value, err := proto.Marshal(data)
+	if err != nil {
+		return &Message{Topic: topic, Partition: partition, Key: key}, err
+	}
+
+	m.mu.Lock()
+	if m.partitions[topic] == nil {
+		m.partitions[topic] = make(map[int32][]*Message)
+	}
+	msg := &Message{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    int64(len(m.partitions[topic][partition])),
+		Key:       key,
+		Value:     value,
+	}
+	m.partitions[topic][partition] = append(m.partitions[topic][partition], msg)
+
+	var callbacks []func(messaging.ProtoMessage)
+	if m.fault(OpDeliver, topic) == nil {
+		for _, sub := range m.subscriptions {
+			if sub.topic == topic && (sub.anyPart || sub.partition == partition) && msg.Offset >= sub.offset {
+				callbacks = append(callbacks, sub.callback)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(msg)
+	}
+	return msg, nil
+}
+
+// subscribe adds the subscription and returns messages to replay.
+func (m *Mux) subscribe(sub *subscription) ([]*Message, error) {
+	if err := m.checkFault(OpWatch, sub.topic); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.subscriptions {
+		if s.subscriber == sub.subscriber && s.topic == sub.topic && s.anyPart == sub.anyPart &&
+			s.partition == sub.partition && s.offset == sub.offset {
+			return nil, fmt.Errorf("%s is already watching topic %s", sub.subscriber, sub.topic)
+		}
+	}
+	m.subscriptions = append(m.subscriptions, sub)
+
+	if sub.anyPart {
+		return nil, nil
+	}
+	var replay []*Message
+	for _, msg := range m.partitions[sub.topic][sub.partition] {
+		if msg.Offset >= sub.offset {
+			replay = append(replay, msg)
+		}
+	}
+	return replay, nil
+}
+
+// unsubscribe removes subscriptions matching the predicate.
+func (m *Mux) unsubscribe(match func(*subscription) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var remaining []*subscription
+	for _, s := range m.subscriptions {
+		if !match(s) {
+			remaining = append(remaining, s)
+		}
+	}
+	if len(remaining) == len(m.subscriptions) {
+		return fmt.Errorf("subscription not found")
+	}
+	m.subscriptions = remaining
+	return nil
+}
+
+// publisher implements messaging.ProtoPublisher.
+type publisher struct {
+	mux        *Mux
+	topic      string
+	partition  int32
+	async      bool
+	successClb func(messaging.ProtoMessage)
+	errorClb   func(messaging.ProtoMessageErr)
+}
+
+// Put publishes the message. Asynchronous publishers report the result
+// through the callbacks and always return nil.
+func (p *publisher) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	msg, err := p.mux.publish(p.topic, p.partition, key, data)
+	if !p.async {
+		return err
+	}
+	if err != nil {
+		if p.errorClb != nil {
+			p.errorClb(&messageErr{Message: msg, err: err})
+		}
+	} else if p.successClb != nil {
+		p.successClb(msg)
+	}
+	return nil
+}
+
+// watcher implements messaging.ProtoWatcher and messaging.ProtoPartitionWatcher.
+type watcher struct {
+	mux        *Mux
+	subscriber string
+}
+
+// Watch starts consuming all partitions of the topics. Only messages
+// published after the call are delivered.
+func (w *watcher) Watch(msgCallback func(messaging.ProtoMessage), topics ...string) error {
+	for _, topic := range topics {
+		if _, err := w.mux.subscribe(&subscription{subscriber: w.subscriber, topic: topic, anyPart: true,
+			callback: msgCallback}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopWatch cancels consuming of the topic.
+func (w *watcher) StopWatch(topic string) error {
+	return w.mux.unsubscribe(func(s *subscription) bool {
+		return s.subscriber == w.subscriber && s.topic == topic && s.anyPart
+	})
+}
+
+// WatchPartition starts consuming the partition of the topic from the offset.
+// Already published messages with offset >= <offset> are delivered first.
+func (w *watcher) WatchPartition(msgCallback func(messaging.ProtoMessage), topic string, partition int32, offset int64) error {
+	replay, err := w.mux.subscribe(&subscription{subscriber: w.subscriber, topic: topic, partition: partition,
+		offset: offset, callback: msgCallback})
+	if err != nil {
+		return err
+	}
+	for _, msg := range replay {
+		msgCallback(msg)
+	}
+	return nil
+}
+
+// StopWatchPartition cancels consuming of the partition.
+func (w *watcher) StopWatchPartition(topic string, partition int32, offset int64) error {
+	return w.mux.unsubscribe(func(s *subscription) bool {
+		return s.subscriber == w.subscriber && s.topic == topic && !s.anyPart &&
+			s.partition == partition && s.offset == offset
+	})
+}
+
+// MarkOffset marks the message as processed.
+func (w *watcher) MarkOffset(msg messaging.ProtoMessage, metadata string) {
+	w.mux.mu.Lock()
+	defer w.mux.mu.Unlock()
+
+	if w.mux.marked[w.subscriber] == nil {
+		w.mux.marked[w.subscriber] = make(map[offsetKey]int64)
+	}
+	w.mux.marked[w.subscriber][offsetKey{msg.GetTopic(), msg.GetPartition()}] = msg.GetOffset()
+}
+
+// CommitOffsets commits the marked offsets.
+func (w *watcher) CommitOffsets() error {
+	if err := w.mux.checkFault(OpCommit, ""); err != nil {
+		return err
+	}
+
+	w.mux.mu.Lock()
+	defer w.mux.mu.Unlock()
+
+	if w.mux.committed[w.subscriber] == nil {
+		w.mux.committed[w.subscriber] = make(map[offsetKey]int64)
+	}
+	for key, offset := range w.mux.marked[w.subscriber] {
+		w.mux.committed[w.subscriber][key] = offset
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msgtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/messaging"
+	. "github.com/onsi/gomega"
+)
+
+func TestPublishAndWatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	var mux messaging.Mux = NewMux()
+	pub, err := mux.NewSyncPublisher("conn", "events")
+	Expect(err).ToNot(HaveOccurred())
+
+	var received []messaging.ProtoMessage
+	watcher := mux.NewWatcher("test")
+	Expect(watcher.Watch(func(msg messaging.ProtoMessage) {
+		received = append(received, msg)
+	}, "events")).To(Succeed())
+
+	Expect(pub.Put("etcd", &status.PluginStatus{Name: "etcd"})).To(Succeed())
+	Expect(received).To(HaveLen(1))
+	value := &status.PluginStatus{}
+	Expect(received[0].GetValue(value)).To(Succeed())
+	Expect(value.Name).To(Equal("etcd"))
+	Expect(received[0].GetOffset()).To(BeZero())
+
+	watcher.MarkOffset(received[0], "")
+	Expect(watcher.CommitOffsets()).To(Succeed())
+	offset, found := mux.(*Mux).Committed("test", "events", 0)
+	Expect(found).To(BeTrue())
+	Expect(offset).To(BeZero())
+
+	Expect(watcher.StopWatch("events")).To(Succeed())
+	Expect(pub.Put("etcd", &status.PluginStatus{Name: "etcd"})).To(Succeed())
+	Expect(received).To(HaveLen(1))
+	Expect(mux.(*Mux).Messages("events")).To(HaveLen(2))
+}
+
+func TestWatchPartitionReplay(t *testing.T) {
+	RegisterTestingT(t)
+
+	mux := NewMux()
+	pub, _ := mux.NewSyncPublisherToPartition("conn", "events", 1)
+	for i := 0; i < 3; i++ {
+		Expect(pub.Put("k", &status.PluginStatus{})).To(Succeed())
+	}
+
+	var offsets []int64
+	Expect(mux.NewPartitionWatcher("test").WatchPartition(func(msg messaging.ProtoMessage) {
+		offsets = append(offsets, msg.GetOffset())
+	}, "events", 1, 1)).To(Succeed())
+	Expect(pub.Put("k", &status.PluginStatus{})).To(Succeed())
+	Expect(offsets).To(Equal([]int64{1, 2, 3}))
+}
+
+func TestFaults(t *testing.T) {
+	RegisterTestingT(t)
+
+	mux := NewMux()
+	errDown := errors.New("down")
+	mux.InjectFault(Fault{Op: OpPublish, Topic: "events", Err: errDown, Times: 1})
+	mux.InjectFault(Fault{Op: OpDeliver, Times: 1})
+
+	var failed []messaging.ProtoMessageErr
+	var succeeded int
+	pub, _ := mux.NewAsyncPublisher("conn", "events", func(messaging.ProtoMessage) {
+		succeeded++
+	}, func(msg messaging.ProtoMessageErr) {
+		failed = append(failed, msg)
+	})
+	var received int
+	Expect(mux.NewWatcher("test").Watch(func(messaging.ProtoMessage) { received++ }, "events")).To(Succeed())
+
+	Expect(pub.Put("k", &status.PluginStatus{})).To(Succeed())
+	Expect(failed).To(HaveLen(1))
+	Expect(failed[0].Error()).To(Equal(errDown))
+
+	// published, but delivery dropped
+	Expect(pub.Put("k", &status.PluginStatus{})).To(Succeed())
+	Expect(pub.Put("k", &status.PluginStatus{})).To(Succeed())
+	Expect(succeeded).To(Equal(2))
+	Expect(received).To(Equal(1))
+
+	mux.InjectFault(Fault{Op: OpCommit, Err: errDown})
+	Expect(mux.NewWatcher("test").CommitOffsets()).To(Equal(errDown))
+	mux.ClearFaults()
+	Expect(mux.NewWatcher("test").CommitOffsets()).To(Succeed())
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/ligato/cn-infra/utils/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// default timeout of a single health check
+	defaultHealthCheckTimeout = time.Second
+	// default upper bound of the delay between reconnect attempts
+	defaultReconnectMaxDelay = 10 * time.Second
+)
+
+// Config is a configuration of the GRPC client plugin.
+type Config struct {
+	// Connections lists outbound connections managed by the plugin.
+	Connections []*ConnectionConfig `json:"connections"`
+}
+
+// ConnectionConfig describes a single outbound GRPC connection.
+type ConnectionConfig struct {
+	// Name identifies the connection for the plugins using it.
+	Name string `json:"name"`
+
+	// Endpoints lists server addresses; calls are balanced across them in round-robin fashion.
+	Endpoints []string `json:"endpoints"`
+
+	// DialTimeout, if set, makes Init wait until the connection is established.
+	DialTimeout time.Duration `json:"dial-timeout"`
+
+	// ReconnectMaxDelay limits the delay between reconnect attempts (10s by default).
+	ReconnectMaxDelay time.Duration `json:"reconnect-max-delay"`
+
+	// CallRetry enables retries of unary calls of the listed (idempotent) methods failing
+	// with Unavailable code.
+	CallRetry *CallRetryConfig `json:"call-retry"`
+
+	// HealthCheck enables periodic probing of the connection using the GRPC health checking protocol.
+	HealthCheck *HealthCheckConfig `json:"health-check"`
+
+	// KeepaliveTime is the interval of pinging the server when there is no activity (0 disables pings).
+	KeepaliveTime time.Duration `json:"keepalive-time"`
+	// KeepaliveTimeout is the time to wait for the ping acknowledgement before closing the connection.
+	KeepaliveTimeout time.Duration `json:"keepalive-timeout"`
+
+	// MaxMsgSize limits the size of inbound messages in bytes (GRPC default 4MB is used if not set).
+	MaxMsgSize int `json:"max-msg-size"`
+
+	// TLS info:
+	InsecureTransport     bool   `json:"insecure-transport"`
+	InsecureSkipTLSVerify bool   `json:"insecure-skip-tls-verify"`
+	ServerName            string `json:"server-name"`
+	Certfile              string `json:"cert-file"`
+	Keyfile               string `json:"key-file"`
+	CAfile                string `json:"ca-file"`
+}
+
+// CallRetryConfig configures retries of unary calls failing with Unavailable code.
+// GRPC may return Unavailable even after the server has received the request,
+// so only calls of idempotent methods can be retried and they have to be listed.
+// Calls are attempted retry.DefaultMaxAttempts times if max-attempts is not set,
+// -1 retries until the call context is done.
+type CallRetryConfig struct {
+	retry.Config `json:",squash"`
+	// Methods lists full names of the retried methods ("/package.Service/Method"),
+	// all methods of a service are selected by its name ending with '/' ("/package.Service/").
+	Methods []string `json:"methods"`
+}
+
+// retried returns true if calls of the method should be retried.
+func (cfg *CallRetryConfig) retried(method string) bool {
+	for _, m := range cfg.Methods {
+		if m == method || (strings.HasSuffix(m, "/") && strings.HasPrefix(method, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthCheckConfig configures periodic health checking of a connection.
+type HealthCheckConfig struct {
+	// Interval between health checks.
+	Interval time.Duration `json:"interval"`
+	// Timeout of a single health check (1s by default).
+	Timeout time.Duration `json:"timeout"`
+	// Service is the name of the service to check (empty string checks the server as a whole).
+	Service string `json:"service"`
+}
+
+// Validate checks the configuration for errors.
+func (cfg *Config) Validate() error {
+	names := make(map[string]struct{})
+	for _, conn := range cfg.Connections {
+		if conn.Name == "" {
+			return fmt.Errorf("connection without name")
+		}
+		if _, dup := names[conn.Name]; dup {
+			return fmt.Errorf("duplicate connection %q", conn.Name)
+		}
+		names[conn.Name] = struct{}{}
+		if len(conn.Endpoints) == 0 {
+			return fmt.Errorf("connection %q has no endpoints", conn.Name)
+		}
+		if conn.HealthCheck != nil && conn.HealthCheck.Interval <= 0 {
+			return fmt.Errorf("connection %q: health check interval must be positive", conn.Name)
+		}
+		if conn.CallRetry != nil && len(conn.CallRetry.Methods) == 0 {
+			return fmt.Errorf("connection %q: call retry does not list any methods", conn.Name)
+		}
+	}
+	return nil
+}
+
+// dialOptions transforms the connection config into GRPC dial options.
+func (cfg *ConnectionConfig) dialOptions() ([]grpc.DialOption, error) {
+	maxDelay := cfg.ReconnectMaxDelay
+	if maxDelay == 0 {
+		maxDelay = defaultReconnectMaxDelay
+	}
+	opts := []grpc.DialOption{
+		grpc.WithBackoffConfig(grpc.BackoffConfig{MaxDelay: maxDelay}),
+	}
+
+	tlsConfig, err := cfg.getTLS()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if len(cfg.Endpoints) > 1 {
+		opts = append(opts, grpc.WithBalancerName(roundRobin))
+	}
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    cfg.KeepaliveTime,
+			Timeout: cfg.KeepaliveTimeout,
+		}))
+	}
+	if cfg.MaxMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxMsgSize)))
+	}
+	if cfg.CallRetry != nil {
+		opts = append(opts, grpc.WithUnaryInterceptor(retryInterceptor(*cfg.CallRetry)))
+	}
+	return opts, nil
+}
+
+func (cfg *ConnectionConfig) getTLS() (*tls.Config, error) {
+	// Check if explicitly disabled.
+	if cfg.InsecureTransport {
+		return nil, nil
+	}
+	// TLS is enabled by any of the TLS-related options.
+	if cfg.Certfile == "" && cfg.Keyfile == "" && cfg.CAfile == "" &&
+		cfg.ServerName == "" && !cfg.InsecureSkipTLSVerify {
+		return nil, nil
+	}
+
+	tc := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipTLSVerify,
+	}
+	if cfg.Certfile != "" || cfg.Keyfile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.Certfile, cfg.Keyfile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAfile != "" {
+		ca, err := ioutil.ReadFile(cfg.CAfile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("unable to add CA from '%s' file", cfg.CAfile)
+		}
+		tc.RootCAs = caCertPool
+	}
+	return tc, nil
+}
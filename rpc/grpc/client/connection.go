@@ -0,0 +1,145 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ConnectionStatus describes the state of a managed connection.
+type ConnectionStatus struct {
+	// Name of the connection.
+	Name string `json:"name"`
+	// Endpoints the connection is balanced across.
+	Endpoints []string `json:"endpoints"`
+	// State of the underlying GRPC connection (IDLE, CONNECTING, READY, TRANSIENT_FAILURE, SHUTDOWN).
+	State string `json:"state"`
+	// Healthy is false if the last health check failed or the connection
+	// is in transient failure.
+	Healthy bool `json:"healthy"`
+	// LastError is the error of the last failed health check.
+	LastError string `json:"last-error,omitempty"`
+	// LastCheck is the time of the last health check (zero if health checking is disabled).
+	LastCheck time.Time `json:"last-check,omitempty"`
+}
+
+// connection wraps GRPC client connection with its health state.
+type connection struct {
+	cfg  *ConnectionConfig
+	conn *grpc.ClientConn
+	log  logging.Logger
+
+	mu        sync.Mutex
+	healthErr error
+	lastCheck time.Time
+
+	loop infra.EventLoop // runs health checks
+}
+
+// dial creates the connection. Unless the dial timeout is configured,
+// the connection is established in the background.
+func dial(cfg *ConnectionConfig, log logging.Logger) (*connection, error) {
+	opts, err := cfg.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if cfg.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.DialTimeout)
+		defer cancel()
+		opts = append(opts, grpc.WithBlock())
+	}
+	conn, err := grpc.DialContext(ctx, dialTarget(cfg.Endpoints), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %v failed: %v", cfg.Endpoints, err)
+	}
+	c := &connection{
+		cfg:  cfg,
+		conn: conn,
+		log:  log,
+	}
+	if cfg.HealthCheck != nil {
+		// the server is probed right away and then periodically
+		c.loop.Log = log
+		c.loop.Go(c.checkHealth)
+		c.loop.Run(infra.OnTick(cfg.HealthCheck.Interval, c.checkHealth))
+	}
+	return c, nil
+}
+
+// checkHealth probes the server using the health checking protocol.
+func (c *connection) checkHealth(ctx context.Context) {
+	timeout := c.cfg.HealthCheck.Timeout
+	if timeout == 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := grpc_health_v1.NewHealthClient(c.conn).Check(ctx,
+		&grpc_health_v1.HealthCheckRequest{Service: c.cfg.HealthCheck.Service})
+	if err == nil && resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		err = fmt.Errorf("service %q is %v", c.cfg.HealthCheck.Service, resp.Status)
+	}
+	if c.loop.Context().Err() != nil {
+		// check interrupted by closing the connection
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil && c.healthErr == nil {
+		c.log.Warnf("Health check of GRPC connection %q failed: %v", c.cfg.Name, err)
+	} else if err == nil && c.healthErr != nil {
+		c.log.Infof("GRPC connection %q is healthy again", c.cfg.Name)
+	}
+	c.healthErr = err
+	c.lastCheck = time.Now()
+}
+
+// status returns the current status of the connection.
+func (c *connection) status() ConnectionStatus {
+	state := c.conn.GetState()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := ConnectionStatus{
+		Name:      c.cfg.Name,
+		Endpoints: c.cfg.Endpoints,
+		State:     state.String(),
+		Healthy:   c.healthErr == nil && state != connectivity.TransientFailure && state != connectivity.Shutdown,
+		LastCheck: c.lastCheck,
+	}
+	if c.healthErr != nil {
+		s.LastError = c.healthErr.Error()
+	}
+	return s
+}
+
+// close stops health checking and closes the connection.
+func (c *connection) close() error {
+	c.loop.Stop()
+	return c.conn.Close()
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client implements a plugin managing outbound GRPC client
+// connections. Connections are described in the plugin configuration file
+// and dialed during Init; other plugins get them by name:
+//
+//	conn, err := p.GRPCClient.Connection("vpp-agent")
+//	client := mypb.NewMyServiceClient(conn)
+//
+// Every connection may list multiple endpoints across which calls are
+// balanced in round-robin fashion. Broken connections are re-established
+// by GRPC with exponential backoff, unary calls of idempotent methods listed
+// in the connection's call-retry settings are retried if they fail with
+// Unavailable and, when
+// enabled, each connection is periodically probed using the standard GRPC
+// health checking protocol. The aggregated health of all connections is
+// reported to statuscheck.
+package client
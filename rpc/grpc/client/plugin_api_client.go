@@ -0,0 +1,33 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"google.golang.org/grpc"
+)
+
+// API of the GRPC client plugin is used by other plugins to get outbound
+// connections defined in the plugin configuration.
+type API interface {
+	// Connection returns the client connection with the given name.
+	// The returned connection is shared, callers must not close it.
+	Connection(name string) (*grpc.ClientConn, error)
+
+	// Status returns the current status of the connection with the given name.
+	Status(name string) (ConnectionStatus, bool)
+
+	// ListStatus returns statuses of all managed connections.
+	ListStatus() []ConnectionStatus
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/infra"
+	"google.golang.org/grpc"
+)
+
+// Plugin manages outbound GRPC client connections.
+type Plugin struct {
+	Deps
+
+	config *Config
+
+	mu          sync.RWMutex
+	connections map[string]*connection
+}
+
+// Deps lists dependencies of the GRPC client plugin.
+// If injected, the plugin will use StatusCheck to signal the health of connections.
+type Deps struct {
+	infra.PluginDeps
+	StatusCheck statuscheck.PluginStatusWriter // inject
+}
+
+// Init loads the configuration and dials all configured connections.
+func (p *Plugin) Init() (err error) {
+	p.connections = make(map[string]*connection)
+
+	if p.config == nil {
+		p.config = &Config{}
+		found, err := p.Cfg.LoadValue(p.config)
+		if err != nil {
+			return err
+		}
+		if !found {
+			p.Log.Info("GRPC client config not found, no connections will be created")
+		}
+	}
+	if err := p.config.Validate(); err != nil {
+		return err
+	}
+
+	for _, cfg := range p.config.Connections {
+		conn, err := dial(cfg, p.Log)
+		if err != nil {
+			p.closeConnections()
+			return fmt.Errorf("GRPC connection %q: %v", cfg.Name, err)
+		}
+		p.connections[cfg.Name] = conn
+		p.Log.Infof("GRPC connection %q to %v created", cfg.Name, cfg.Endpoints)
+	}
+	return nil
+}
+
+// AfterInit registers the plugin to status check if needed.
+func (p *Plugin) AfterInit() error {
+	if p.StatusCheck != nil && len(p.connections) > 0 {
+		p.StatusCheck.Register(p.PluginName, p.statusCheckProbe)
+	}
+	return nil
+}
+
+// Close closes all connections.
+func (p *Plugin) Close() error {
+	return p.closeConnections()
+}
+
+// Connection returns the client connection with the given name.
+func (p *Plugin) Connection(name string) (*grpc.ClientConn, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	conn, ok := p.connections[name]
+	if !ok {
+		return nil, fmt.Errorf("GRPC connection %q is not configured", name)
+	}
+	return conn.conn, nil
+}
+
+// Status returns the current status of the connection with the given name.
+func (p *Plugin) Status(name string) (ConnectionStatus, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	conn, ok := p.connections[name]
+	if !ok {
+		return ConnectionStatus{}, false
+	}
+	return conn.status(), true
+}
+
+// ListStatus returns statuses of all managed connections ordered by name.
+func (p *Plugin) ListStatus() []ConnectionStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	list := make([]ConnectionStatus, 0, len(p.connections))
+	for _, conn := range p.connections {
+		list = append(list, conn.status())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// statusCheckProbe reports error if any of the connections is unhealthy.
+func (p *Plugin) statusCheckProbe() (statuscheck.PluginState, error) {
+	var unhealthy []string
+	for _, s := range p.ListStatus() {
+		if !s.Healthy {
+			unhealthy = append(unhealthy, s.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return statuscheck.Error, fmt.Errorf("unhealthy GRPC connections: %s", strings.Join(unhealthy, ", "))
+	}
+	return statuscheck.OK, nil
+}
+
+func (p *Plugin) closeConnections() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []string
+	for name, conn := range p.connections {
+		if err := conn.close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+		delete(p.connections, name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("closing GRPC connections failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/config"
+	"github.com/ligato/cn-infra/utils/retry"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type testServer struct {
+	srv    *grpc.Server
+	health *health.Server
+	addr   string
+	calls  int32
+}
+
+func startServer(t *testing.T) *testServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{health: health.NewServer(), addr: lis.Addr().String()}
+	s.srv = grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(&s.calls, 1)
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(s.srv, s.health)
+	go s.srv.Serve(lis)
+	return s
+}
+
+func newTestPlugin(conns ...*ConnectionConfig) *Plugin {
+	p := NewPlugin(UseConf(Config{Connections: conns}))
+	p.StatusCheck = nil
+	return p
+}
+
+func TestRoundRobin(t *testing.T) {
+	RegisterTestingT(t)
+
+	s1, s2 := startServer(t), startServer(t)
+	defer s1.srv.Stop()
+	defer s2.srv.Stop()
+
+	p := newTestPlugin(&ConnectionConfig{
+		Name:              "test",
+		Endpoints:         []string{s1.addr, s2.addr},
+		DialTimeout:       5 * time.Second,
+		InsecureTransport: true,
+	})
+	Expect(p.Init()).To(Succeed())
+	defer p.Close()
+
+	conn, err := p.Connection("test")
+	Expect(err).ToNot(HaveOccurred())
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// wait until both sub-connections are ready
+	Eventually(func() bool {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		Expect(err).ToNot(HaveOccurred())
+		return atomic.LoadInt32(&s1.calls) > 0 && atomic.LoadInt32(&s2.calls) > 0
+	}, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+
+	_, err = p.Connection("unknown")
+	Expect(err).To(HaveOccurred())
+}
+
+func TestHealthCheck(t *testing.T) {
+	RegisterTestingT(t)
+
+	s := startServer(t)
+	defer s.srv.Stop()
+
+	p := newTestPlugin(&ConnectionConfig{
+		Name:              "test",
+		Endpoints:         []string{s.addr},
+		InsecureTransport: true,
+		HealthCheck:       &HealthCheckConfig{Interval: 10 * time.Millisecond, Service: "svc"},
+	})
+	Expect(p.Init()).To(Succeed())
+	defer p.Close()
+
+	healthy := func() bool {
+		status, ok := p.Status("test")
+		Expect(ok).To(BeTrue())
+		return status.Healthy
+	}
+
+	s.health.SetServingStatus("svc", grpc_health_v1.HealthCheckResponse_SERVING)
+	Eventually(healthy, 5*time.Second).Should(BeTrue())
+
+	s.health.SetServingStatus("svc", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	Eventually(healthy, 5*time.Second).Should(BeFalse())
+	_, err := p.statusCheckProbe()
+	Expect(err).To(HaveOccurred())
+
+	s.health.SetServingStatus("svc", grpc_health_v1.HealthCheckResponse_SERVING)
+	Eventually(healthy, 5*time.Second).Should(BeTrue())
+	Expect(p.ListStatus()).To(HaveLen(1))
+}
+
+func TestCallRetry(t *testing.T) {
+	RegisterTestingT(t)
+
+	interceptor := retryInterceptor(CallRetryConfig{
+		Config:  retry.Config{MaxAttempts: 5, InitialInterval: time.Millisecond},
+		Methods: []string{"/test.Service/Get", "/test.ReadOnly/"},
+	})
+
+	var attempts int
+	err := interceptor(context.Background(), "/test.Service/Get", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			if attempts < 3 {
+				return status.Error(codes.Unavailable, "unavailable")
+			}
+			return nil
+		})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(attempts).To(Equal(3))
+
+	// other errors are not retried
+	attempts = 0
+	err = interceptor(context.Background(), "/test.ReadOnly/List", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			return status.Error(codes.InvalidArgument, "invalid")
+		})
+	Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	Expect(attempts).To(Equal(1))
+
+	// methods which are not listed are not retried, they may not be idempotent
+	attempts = 0
+	err = interceptor(context.Background(), "/test.Service/Create", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+	Expect(status.Code(err)).To(Equal(codes.Unavailable))
+	Expect(attempts).To(Equal(1))
+}
+
+func TestCallRetryConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	f, err := ioutil.TempFile("", "grpc-client")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("call-retry:\n  methods: [/test.Service/Get]\n  max-attempts: 5\n  initial-interval: 100ms\n")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(f.Close()).To(Succeed())
+
+	var cfg ConnectionConfig
+	Expect(config.ParseConfigFromYamlFile(f.Name(), &cfg)).To(Succeed())
+	Expect(cfg.CallRetry).To(Equal(&CallRetryConfig{
+		Config:  retry.Config{MaxAttempts: 5, InitialInterval: 100 * time.Millisecond},
+		Methods: []string{"/test.Service/Get"},
+	}))
+}
+
+func TestValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&Config{Connections: []*ConnectionConfig{{Name: "a"}}}).Validate()).ToNot(Succeed())
+	Expect((&Config{Connections: []*ConnectionConfig{
+		{Name: "a", Endpoints: []string{"x:1"}},
+		{Name: "a", Endpoints: []string{"x:2"}},
+	}}).Validate()).ToNot(Succeed())
+	Expect((&Config{Connections: []*ConnectionConfig{
+		{Name: "a", Endpoints: []string{"x:1"}, HealthCheck: &HealthCheckConfig{}},
+	}}).Validate()).ToNot(Succeed())
+	Expect((&Config{Connections: []*ConnectionConfig{
+		{Name: "a", Endpoints: []string{"x:1"}, CallRetry: &CallRetryConfig{}},
+	}}).Validate()).ToNot(Succeed())
+	Expect((&Config{Connections: []*ConnectionConfig{
+		{Name: "a", Endpoints: []string{"x:1", "x:2"}},
+	}}).Validate()).To(Succeed())
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ligato/cn-infra/utils/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// staticScheme is a scheme of targets resolved into a fixed list of endpoints
+	staticScheme = "cninfra-static"
+	// roundRobin is a name of the balancer used for connections with multiple endpoints
+	roundRobin = roundrobin.Name
+)
+
+func init() {
+	resolver.Register(&staticBuilder{})
+}
+
+// dialTarget returns the target passed to grpc.Dial for the given endpoints.
+// A single endpoint is dialed directly, multiple endpoints are encoded into
+// the target for the static resolver.
+func dialTarget(endpoints []string) string {
+	if len(endpoints) == 1 {
+		return endpoints[0]
+	}
+	return staticScheme + ":///" + strings.Join(endpoints, ",")
+}
+
+// staticBuilder builds resolvers returning endpoints listed in the target.
+type staticBuilder struct{}
+
+// Build creates resolver for the given target.
+func (*staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOption) (resolver.Resolver, error) {
+	var addrs []resolver.Address
+	for _, endpoint := range strings.Split(target.Endpoint, ",") {
+		if endpoint != "" {
+			addrs = append(addrs, resolver.Address{Addr: endpoint})
+		}
+	}
+	cc.NewAddress(addrs)
+	return staticResolver{}, nil
+}
+
+// Scheme returns the scheme handled by the builder.
+func (*staticBuilder) Scheme() string {
+	return staticScheme
+}
+
+// staticResolver never changes its addresses.
+type staticResolver struct{}
+
+// ResolveNow is no-op.
+func (staticResolver) ResolveNow(resolver.ResolveNowOption) {}
+
+// Close is no-op.
+func (staticResolver) Close() {}
+
+// retryInterceptor retries unary calls of the configured methods that failed
+// because none of the endpoints was available. Calls of other methods are not
+// retried, since the server may have received the request already.
+func retryInterceptor(cfg CallRetryConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !cfg.retried(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return retry.Do(ctx, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}, append(cfg.Options(), retry.If(isUnavailable))...)
+	}
+}
+
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/utils/handover"
 	"google.golang.org/grpc"
 )
 
@@ -47,7 +48,7 @@ func ListenAndServe(cfg *Config, srv *grpc.Server) (netListener net.Listener, er
 			return nil, err
 		}
 	default:
-		netListener, err = net.Listen(socketType, cfg.Endpoint)
+		netListener, err = handover.Listen(socketType, cfg.Endpoint)
 		if err != nil {
 			return nil, err
 		}
@@ -19,12 +19,14 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"google.golang.org/grpc/grpclog"
 
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/rpc/rest"
+	"github.com/ligato/cn-infra/utils/handover"
 	"github.com/unrolled/render"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -129,11 +131,25 @@ func (p *Plugin) AfterInit() (err error) {
 	return nil
 }
 
-// Close stops the HTTP netListener.
+// Close stops the HTTP netListener. Active RPCs are drained if the agent is being upgraded.
 func (p *Plugin) Close() error {
-	if p.grpcServer != nil {
-		p.grpcServer.Stop()
+	if p.grpcServer == nil {
+		return nil
+	}
+	if handover.Draining() {
+		stopped := make(chan struct{})
+		go func() {
+			p.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-time.After(handover.DrainTimeout):
+			p.Log.Warn("draining GRPC connections timed out")
+		}
 	}
+	p.grpcServer.Stop()
 	return nil
 }
 
@@ -148,6 +164,14 @@ func (p *Plugin) IsDisabled() bool {
 	return p.disabled
 }
 
+// ListenAddresses returns the address of the GRPC listener (for the agent boot report).
+func (p *Plugin) ListenAddresses() []string {
+	if p.disabled || p.Config == nil {
+		return nil
+	}
+	return []string{p.Config.getSocketType() + "://" + p.Config.Endpoint}
+}
+
 func (p *Plugin) getGrpcConfig() (*Config, error) {
 	var grpcCfg Config
 	found, err := p.Cfg.LoadValue(&grpcCfg)
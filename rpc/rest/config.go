@@ -22,6 +22,7 @@ import (
 	"github.com/ligato/cn-infra/config"
 	"github.com/ligato/cn-infra/infra"
 	access "github.com/ligato/cn-infra/rpc/rest/security/model/access-security"
+	"github.com/ligato/cn-infra/utils/ratelimit"
 	"github.com/namsral/flag"
 )
 
@@ -93,7 +94,7 @@ type Config struct {
 	// EnableTokenAuth enables token authorization for HTTP requests
 	EnableTokenAuth bool `json:"enable-token-auth"`
 
-	// TokenExpiration set globaly for all user tokens
+	// TokenExpiration set globaly for all user tokens (1 hour if not set)
 	TokenExpiration time.Duration `json:"token-expiration"`
 
 	// Users laoded from config file
@@ -104,6 +105,19 @@ type Config struct {
 
 	// TokenSignature is used to sign a token. Default value is used if not set.
 	TokenSignature string `json:"token-signature"`
+
+	// RateLimit limits the number of requests accepted from a single client address.
+	// Requests are not limited if not set.
+	RateLimit *ratelimit.Config `json:"rate-limit"`
+
+	// LoginRateLimit limits the number of failed login attempts for a single user name
+	// from a single client address if token authentication is enabled. Login attempts
+	// are not limited if not set.
+	LoginRateLimit *ratelimit.Config `json:"login-rate-limit"`
+
+	// MaxBodySize limits the size (in bytes) of request bodies read by handlers wrapped
+	// by ValidateBody. DefaultMaxBodySize is used if not set.
+	MaxBodySize int64 `json:"max-body-size"`
 }
 
 // DefaultConfig returns new instance of config with default endpoint
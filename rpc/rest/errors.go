@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/unrolled/render"
+)
+
+// ErrorResponse is a JSON body of REST error responses. If the error
+// belongs to an infra.ErrorClass, the class metadata are included
+// so that clients can react to the error class.
+type ErrorResponse struct {
+	Error     string         `json:"error"`
+	Code      string         `json:"code,omitempty"`
+	Plugin    string         `json:"plugin,omitempty"`
+	Retriable bool           `json:"retriable,omitempty"`
+	Severity  infra.Severity `json:"severity,omitempty"`
+	Docs      string         `json:"docs,omitempty"`
+}
+
+// NewErrorResponse builds ErrorResponse for the given error.
+func NewErrorResponse(err error) ErrorResponse {
+	resp := ErrorResponse{Error: err.Error()}
+	if class := infra.ErrorClassOf(err); class != nil {
+		resp.Code = class.Code
+		resp.Plugin = class.Plugin.String()
+		resp.Retriable = class.Retriable
+		resp.Severity = class.Severity
+		resp.Docs = class.Docs
+	}
+	return resp
+}
+
+// WriteError writes ErrorResponse for the given error. The HTTP status code
+// is taken from the error class, 500 is used for errors without the class
+// or if the class does not define it.
+func WriteError(formatter *render.Render, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if class := infra.ErrorClassOf(err); class != nil && class.HTTPStatus != 0 {
+		status = class.HTTPStatus
+	}
+	formatter.JSON(w, status, NewErrorResponse(err))
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/utils/handover"
 )
 
 // ListenAndServe starts a http server.
@@ -55,7 +56,7 @@ func ListenAndServe(config Config, handler http.Handler) (srv *http.Server, err
 		}
 	}
 
-	ln, err := net.Listen("tcp", server.Addr)
+	ln, err := handover.Listen("tcp", server.Addr)
 	if err != nil {
 		return nil, err
 	}
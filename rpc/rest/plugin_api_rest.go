@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/rpc/rest/security"
 	access "github.com/ligato/cn-infra/rpc/rest/security/model/access-security"
 	"github.com/unrolled/render"
 )
@@ -44,6 +45,19 @@ type HTTPHandlers interface {
 	GetPort() int
 }
 
+// AuthorizerRegistry is implemented by REST plugins supporting custom authorization
+// rules. Resolve it from HTTPHandlers by type assertion:
+//
+// if reg, ok := p.HTTP.(rest.AuthorizerRegistry); ok {
+//     reg.RegisterAuthorizer(authorizer)
+// }
+//
+type AuthorizerRegistry interface {
+	// RegisterAuthorizer registers custom authorization rules consulted
+	// for every request in addition to permission groups
+	RegisterAuthorizer(authorizer ...security.Authorizer)
+}
+
 // BasicHTTPAuthenticator is a delegate that implements basic HTTP authentication
 type BasicHTTPAuthenticator interface {
 	// Authenticate returns true if user is authenticated successfully, false otherwise.
@@ -15,12 +15,15 @@
 package rest
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/ligato/cn-infra/infra"
 	"github.com/ligato/cn-infra/rpc/rest/security"
 	access "github.com/ligato/cn-infra/rpc/rest/security/model/access-security"
+	"github.com/ligato/cn-infra/utils/handover"
+	"github.com/ligato/cn-infra/utils/ratelimit"
 	"github.com/ligato/cn-infra/utils/safeclose"
 	"github.com/unrolled/render"
 )
@@ -37,6 +40,9 @@ type Plugin struct {
 
 	// Access to HTTP security API
 	auth security.AuthenticatorAPI
+
+	// limits requests per client address, nil if disabled
+	limiter *ratelimit.Keyed
 }
 
 // Deps lists the dependencies of the Rest plugin.
@@ -69,6 +75,16 @@ func (p *Plugin) Init() (err error) {
 		}
 	}
 
+	if err := registerPanicMetric(); err != nil {
+		return err
+	}
+
+	if p.Config.RateLimit != nil {
+		if p.limiter, err = p.Config.RateLimit.NewKeyed(); err != nil {
+			return err
+		}
+	}
+
 	p.mx = mux.NewRouter()
 	p.formatter = render.New(render.Options{
 		IndentJSON: true,
@@ -77,11 +93,18 @@ func (p *Plugin) Init() (err error) {
 	// Enable authentication if defined by config
 	if p.EnableTokenAuth {
 		p.Log.Info("Token authentication for HTTP enabled")
+		var loginLimiter *ratelimit.Keyed
+		if p.LoginRateLimit != nil {
+			if loginLimiter, err = p.LoginRateLimit.NewKeyed(); err != nil {
+				return err
+			}
+		}
 		p.auth = security.NewAuthenticator(p.mx, &security.Settings{
-			Users:     p.Users,
-			ExpTime:   p.TokenExpiration,
-			Cost:      p.PasswordHashCost,
-			Signature: p.TokenSignature,
+			Users:        p.Users,
+			ExpTime:      p.TokenExpiration,
+			Cost:         p.PasswordHashCost,
+			Signature:    p.TokenSignature,
+			LoginLimiter: loginLimiter,
 		}, p.Log)
 	}
 
@@ -92,12 +115,7 @@ func (p *Plugin) Init() (err error) {
 func (p *Plugin) AfterInit() (err error) {
 	cfgCopy := *p.Config
 
-	var handler http.Handler = p.mx
-	if p.Authenticator != nil {
-		handler = auth(handler, p.Authenticator)
-	}
-
-	p.server, err = ListenAndServe(cfgCopy, handler)
+	p.server, err = ListenAndServe(cfgCopy, p.handler())
 	if err != nil {
 		return err
 	}
@@ -111,6 +129,21 @@ func (p *Plugin) AfterInit() (err error) {
 	return nil
 }
 
+// handler wraps the router with the limits and authentication of the server.
+func (p *Plugin) handler() http.Handler {
+	var handler http.Handler = p.mx
+	if p.Config.MaxBodySize > 0 {
+		handler = limitBodySize(handler, p.Config.MaxBodySize)
+	}
+	if p.Authenticator != nil {
+		handler = auth(handler, p.Authenticator)
+	}
+	if p.limiter != nil {
+		handler = rateLimit(handler, p.limiter, p.Config.RateLimit.RetryAfter(), p.formatter)
+	}
+	return recoverPanic(handler, p.PluginName, p.Log, p.formatter)
+}
+
 // RegisterHTTPHandler registers HTTP <handler> at the given <path>. Every request is validated if enabled.
 func (p *Plugin) RegisterHTTPHandler(path string, provider HandlerProvider, methods ...string) *mux.Route {
 	p.Log.Debugf("Registering handler: %s", path)
@@ -129,6 +162,16 @@ func (p *Plugin) RegisterPermissionGroup(group ...*access.PermissionGroup) {
 	}
 }
 
+// RegisterAuthorizer adds custom authorization rules if token authentication is enabled
+func (p *Plugin) RegisterAuthorizer(authorizer ...security.Authorizer) {
+	if !p.Config.EnableTokenAuth {
+		p.Log.Warnf("Token authentication is disabled, %d authorizer(s) will not be applied", len(authorizer))
+		return
+	}
+	p.Log.Debugf("Registering %d authorizer(s)", len(authorizer))
+	p.auth.AddAuthorizer(authorizer...)
+}
+
 // GetPort returns plugin configuration port
 func (p *Plugin) GetPort() int {
 	if p.Config != nil {
@@ -137,7 +180,27 @@ func (p *Plugin) GetPort() int {
 	return 0
 }
 
-// Close stops the HTTP server.
+// ListenAddresses returns the address of the HTTP server (for the agent boot report).
+func (p *Plugin) ListenAddresses() []string {
+	if p.Config == nil {
+		return nil
+	}
+	if p.Config.UseHTTPS() {
+		return []string{"https://" + p.Config.Endpoint}
+	}
+	return []string{"http://" + p.Config.Endpoint}
+}
+
+// Close stops the HTTP server. Active requests are drained if the agent is being upgraded.
 func (p *Plugin) Close() error {
+	if p.server != nil && handover.Draining() {
+		ctx, cancel := context.WithTimeout(context.Background(), handover.DrainTimeout)
+		defer cancel()
+		if err := p.server.Shutdown(ctx); err != nil {
+			p.Log.Warnf("draining HTTP connections failed: %v", err)
+		} else {
+			return nil
+		}
+	}
 	return safeclose.Close(p.server)
 }
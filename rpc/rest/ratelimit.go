@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/utils/ratelimit"
+	"github.com/unrolled/render"
+)
+
+// ErrRateLimited is the class of errors returned to clients which exceeded the rate limit.
+var ErrRateLimited = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "rest/rate-limited",
+	Plugin:     "http",
+	Retriable:  true,
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusTooManyRequests,
+})
+
+// rateLimit rejects requests of clients which exceeded the rate limit, the clients
+// are asked to retry after the given time (rounded up to seconds)
+func rateLimit(h http.Handler, limiter *ratelimit.Keyed, retryAfter time.Duration, formatter *render.Render) http.HandlerFunc {
+	seconds := strconv.Itoa(int((retryAfter + time.Second - 1) / time.Second))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Allow(clientAddress(r)) {
+			w.Header().Set("Retry-After", seconds)
+			WriteError(formatter, w, ErrRateLimited.Errorf("too many requests"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// clientAddress returns address of the client (without port) which sent the request
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
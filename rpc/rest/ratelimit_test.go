@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/utils/ratelimit"
+	. "github.com/onsi/gomega"
+	"github.com/unrolled/render"
+)
+
+func TestRateLimit(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := ratelimit.Config{Limit: 1, Interval: 90 * time.Second}
+	limiter, err := cfg.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	handler := rateLimit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+		limiter, cfg.RetryAfter(), render.New())
+
+	send := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+		return rec
+	}
+	Expect(send().Code).To(Equal(http.StatusOK))
+
+	rec := send()
+	Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+	Expect(rec.Header().Get("Retry-After")).To(Equal("90"))
+	var resp ErrorResponse
+	Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+	Expect(resp.Code).To(Equal(ErrRateLimited.Code))
+	Expect(resp.Retriable).To(BeTrue())
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"net/http"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/unrolled/render"
+)
+
+// ErrPluginNotReady is the class of errors returned for requests handled
+// by a plugin which is not ready yet.
+var ErrPluginNotReady = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "rest/plugin-not-ready",
+	Plugin:     "http",
+	Retriable:  true,
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusServiceUnavailable,
+})
+
+// StatusInitializing is the status reported in NotReadyResponse.
+const StatusInitializing = "initializing"
+
+// ReadinessProbe reports whether the plugin owning a handler is ready to serve
+// requests. If not, <state> describes the current state of the plugin (e.g. "init").
+type ReadinessProbe func() (ready bool, state string)
+
+// NotReadyResponse is a JSON body of responses to requests rejected because
+// the plugin owning the handler is not ready.
+type NotReadyResponse struct {
+	ErrorResponse
+	Status string `json:"status"`
+	State  string `json:"state,omitempty"`
+}
+
+// WhenReady wraps the handler provider so that requests are passed to the handler
+// only once the <plugin> is ready according to the <probe>. Until then, requests
+// are rejected with 503 Service Unavailable and NotReadyResponse of ErrPluginNotReady
+// class instead of executing the handler against half-initialized state.
+//
+// Example:
+//
+//	http.RegisterHTTPHandler("/greeting", rest.WhenReady(p.greetingHandler,
+//		p.PluginName, p.StatusCheck.ReadinessProbe(p.PluginName)), "GET")
+func WhenReady(provider HandlerProvider, plugin infra.PluginName, probe ReadinessProbe) HandlerProvider {
+	return func(formatter *render.Render) http.HandlerFunc {
+		handler := provider(formatter)
+		return func(w http.ResponseWriter, req *http.Request) {
+			ready, state := probe()
+			if !ready {
+				err := ErrPluginNotReady.Errorf("plugin %s is not ready (state: %s)", plugin, state)
+				w.Header().Set("Retry-After", "1")
+				formatter.JSON(w, http.StatusServiceUnavailable, NotReadyResponse{
+					ErrorResponse: NewErrorResponse(err),
+					Status:        StatusInitializing,
+					State:         state,
+				})
+				return
+			}
+			handler(w, req)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/unrolled/render"
+)
+
+func TestWhenReady(t *testing.T) {
+	RegisterTestingT(t)
+
+	var calls int
+	handler := func(formatter *render.Render) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			calls++
+		}
+	}
+	ready := false
+	provider := WhenReady(handler, "test", func() (bool, string) {
+		if ready {
+			return true, "ok"
+		}
+		return false, "init"
+	})
+
+	rec := serve(provider, "")
+	Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	Expect(rec.Header().Get("Retry-After")).ToNot(BeEmpty())
+	var resp NotReadyResponse
+	Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+	Expect(resp.Status).To(Equal(StatusInitializing))
+	Expect(resp.State).To(Equal("init"))
+	Expect(resp.Code).To(Equal(ErrPluginNotReady.Code))
+	Expect(resp.Retriable).To(BeTrue())
+	Expect(calls).To(BeZero())
+
+	ready = true
+	Expect(serve(provider, "").Code).To(Equal(http.StatusOK))
+	Expect(calls).To(Equal(1))
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/unrolled/render"
+)
+
+// RequestIDHeader is the header carrying ID of the request. The ID is taken
+// from the request if present, otherwise it is generated. It is always set
+// in the response, so that clients can correlate errors with the agent logs.
+const RequestIDHeader = "X-Request-ID"
+
+// ErrHandlerPanic is the class of errors returned for requests whose handler panicked.
+var ErrHandlerPanic = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "rest/handler-panic",
+	Plugin:     "http",
+	Severity:   infra.SeverityError,
+	HTTPStatus: http.StatusInternalServerError,
+})
+
+// PanicResponse is a JSON body of responses to requests whose handler panicked.
+type PanicResponse struct {
+	ErrorResponse
+	RequestID string `json:"request-id"`
+}
+
+// handlerPanics counts recovered panics of HTTP handlers, exposed in the default
+// Prometheus registry.
+var handlerPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "cninfra",
+	Subsystem: "http",
+	Name:      "handler_panics_total",
+	Help:      "Number of panics recovered in HTTP handlers.",
+}, []string{"plugin"})
+
+func registerPanicMetric() error {
+	if err := prometheus.Register(handlerPanics); err != nil {
+		if _, registered := err.(prometheus.AlreadyRegisteredError); !registered {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverPanic sets the request ID and converts panics of the handler into
+// 500 responses with PanicResponse body instead of dropping the connection.
+func recoverPanic(h http.Handler, plugin infra.PluginName, log logging.Logger, formatter *render.Render) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+			r.Header.Set(RequestIDHeader, id)
+		}
+		w.Header().Set(RequestIDHeader, id)
+		rw := &responseWriter{ResponseWriter: w}
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// deliberate abort of the response
+				panic(rec)
+			}
+			handlerPanics.WithLabelValues(plugin.String()).Inc()
+			log.WithFields(logging.Fields{"request-id": id, "method": r.Method, "path": r.URL.Path}).
+				Errorf("panic in HTTP handler: %v\n%s", rec, debug.Stack())
+			if rw.wroteHeader {
+				// the response is already (partially) sent
+				return
+			}
+			formatter.JSON(rw, http.StatusInternalServerError, PanicResponse{
+				ErrorResponse: NewErrorResponse(ErrHandlerPanic.Errorf("internal error while handling the request")),
+				RequestID:     id,
+			})
+		}()
+
+		h.ServeHTTP(rw, r)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%p", b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// responseWriter records whether the response header has been written.
+// Optional interfaces of the wrapped writer (Flusher, Hijacker, CloseNotifier,
+// Pusher) are passed through, so that e.g. websocket handlers keep working.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader records the header has been written.
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the header has been written.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes the call to the wrapped writer if it supports flushing.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack passes the call to the wrapped writer if it supports hijacking.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		// the connection is taken over by the handler, no error response can be sent
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// CloseNotify passes the call to the wrapped writer if it supports close
+// notifications, otherwise the returned channel never receives.
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return nil
+}
+
+// Push passes the call to the wrapped writer if it supports HTTP/2 server push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
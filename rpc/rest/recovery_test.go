@@ -0,0 +1,115 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/logging"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/unrolled/render"
+)
+
+// recoveredPanics returns the number of panics recovered for the server so far.
+func recoveredPanics(server string) float64 {
+	metric := &dto.Metric{}
+	Expect(handlerPanics.WithLabelValues(server).Write(metric)).To(Succeed())
+	return metric.GetCounter().GetValue()
+}
+
+func TestRecoverPanic(t *testing.T) {
+	RegisterTestingT(t)
+
+	// the counter is global, only the panics recovered by this test are checked
+	panicsBefore := recoveredPanics("http-test")
+
+	handler := recoverPanic(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/panic":
+			panic("boom")
+		case "/partial":
+			w.WriteHeader(http.StatusOK)
+			panic("boom")
+		}
+		w.Write([]byte(r.Header.Get(RequestIDHeader)))
+	}), "http-test", logging.DefaultLogger, render.New())
+
+	// request ID is propagated to the handler and the response
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler(rec, req)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(rec.Body.String()).To(Equal("req-1"))
+	Expect(rec.Header().Get(RequestIDHeader)).To(Equal("req-1"))
+
+	// panic is converted to 500 with generated request ID
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/panic", nil))
+	Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+	var resp PanicResponse
+	Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+	Expect(resp.Code).To(Equal(ErrHandlerPanic.Code))
+	Expect(resp.RequestID).ToNot(BeEmpty())
+	Expect(resp.RequestID).To(Equal(rec.Header().Get(RequestIDHeader)))
+
+	// already sent response is left as is
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/partial", nil))
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(rec.Body.Len()).To(BeZero())
+
+	Expect(recoveredPanics("http-test") - panicsBefore).To(Equal(2.0))
+}
+
+func TestRecoverPanicHijack(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := &Plugin{Config: DefaultConfig(), mx: mux.NewRouter(), formatter: render.New()}
+	p.PluginName = "http-test"
+	p.Log = logging.ForPlugin("http-test")
+	p.RegisterHTTPHandler("/hijack", func(formatter *render.Render) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				panic(err)
+			}
+			defer conn.Close()
+			buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+			buf.Flush()
+			// connection is no longer managed by the server, the panic must not write a response
+			panic("boom")
+		}
+	}, http.MethodGet)
+	server := httptest.NewServer(p.handler())
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /hijack HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+	Expect(resp.Header.Get("Upgrade")).To(Equal("test"))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net/http"
+)
+
+// Authorizer is a custom authorization rule allowing attribute-based access
+// control on top of the permission groups, e.g. restricting some URL to
+// a maintenance window. Authorizers are consulted by Validate for every request
+// of an authenticated user permitted by the permission groups (including
+// the admin). The request is denied with 403 Forbidden if any of the authorizers
+// returns an error, the error is used as the reason.
+type Authorizer func(user *User, req *http.Request) error
+
+// AddAuthorizer adds custom authorization rules.
+func (a *authenticator) AddAuthorizer(authorizer ...Authorizer) {
+	a.authMu.Lock()
+	defer a.authMu.Unlock()
+
+	a.authorizers = append(a.authorizers, authorizer...)
+}
+
+// Consults all authorizers, returns the first denial
+func (a *authenticator) authorize(user *User, req *http.Request) error {
+	a.authMu.RLock()
+	defer a.authMu.RUnlock()
+
+	for _, authorizer := range a.authorizers {
+		if err := authorizer(user, req); err != nil {
+			a.log.Debugf("request %s %s of user %s denied: %v", req.Method, req.URL.Path, user.Name, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/logging/logrus"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuthorizer(t *testing.T) {
+	RegisterTestingT(t)
+
+	a := NewAuthenticator(mux.NewRouter(), &Settings{}, logrus.DefaultLogger())
+	a.AddAuthorizer(func(user *User, req *http.Request) error {
+		if req.URL.Path == "/maintenance" {
+			return errors.New("outside of maintenance window")
+		}
+		return nil
+	})
+	token, _, err := a.(*authenticator).getTokenFor(&credentials{Username: admin, Password: "ligato123"}, "")
+	Expect(err).NotTo(HaveOccurred())
+
+	handler := a.Validate(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(AuthHeaderKey, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	Expect(serve("/status").Code).To(Equal(http.StatusOK))
+	rec := serve("/maintenance")
+	Expect(rec.Code).To(Equal(http.StatusForbidden))
+	Expect(rec.Body.String()).To(ContainSubstring("outside of maintenance window"))
+}
@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	"github.com/ligato/cn-infra/logging"
 	access "github.com/ligato/cn-infra/rpc/rest/security/model/access-security"
+	"github.com/ligato/cn-infra/utils/ratelimit"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 	"golang.org/x/crypto/bcrypt"
@@ -62,6 +64,10 @@ type AuthenticatorAPI interface {
 	// permission group enabled has access to that set of keys. PGs with duplicated names are skipped.
 	AddPermissionGroup(group ...*access.PermissionGroup)
 
+	// AddAuthorizer adds custom authorization rules consulted by Validate
+	// for every request permitted by the permission groups (see Authorizer).
+	AddAuthorizer(authorizer ...Authorizer)
+
 	// Validate serves as middleware used while registering new HTTP handler. For every request, token
 	// and permission group is validated.
 	Validate(provider http.HandlerFunc) http.HandlerFunc
@@ -79,6 +85,8 @@ type Settings struct {
 	Cost int
 	// Custom token signature. If not set, default value will be used.
 	Signature string
+	// Limits failed login attempts per user name and client address. If not set, login attempts are not limited.
+	LoginLimiter *ratelimit.Keyed
 }
 
 // Credentials struct represents simple user login input
@@ -104,6 +112,16 @@ type authenticator struct {
 
 	// Token claims
 	expTime time.Duration
+
+	// Login throttling, nil if disabled
+	loginLimiter *ratelimit.Keyed
+
+	// Sessions of issued tokens
+	sessions *sessionStore
+
+	// Custom authorization rules
+	authMu      sync.RWMutex
+	authorizers []Authorizer
 }
 
 // NewAuthenticator prepares new instance of authenticator.
@@ -114,8 +132,10 @@ func NewAuthenticator(router *mux.Router, ctx *Settings, log logging.Logger) Aut
 		formatter: render.New(render.Options{
 			IndentJSON: true,
 		}),
-		groupDb: make(map[string][]*access.PermissionGroup_Permissions),
-		expTime: ctx.ExpTime,
+		groupDb:      make(map[string][]*access.PermissionGroup_Permissions),
+		expTime:      ctx.ExpTime,
+		loginLimiter: ctx.LoginLimiter,
+		sessions:     newSessionStore(),
 	}
 
 	// Authentication store
@@ -173,24 +193,11 @@ func (a *authenticator) AddPermissionGroup(group ...*access.PermissionGroup) {
 // Validate the request
 func (a *authenticator) Validate(provider http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		// Token may be accessed via cookie, or from authentication header
-		tokenString, errCode, err := a.getTokenStringFromRequest(req)
+		token, errCode, err := a.parseToken(req)
 		if err != nil {
 			a.formatter.Text(w, errCode, err.Error())
 			return
 		}
-		// Retrieve token object from raw string
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := jwt.GetSigningMethod(token.Header["alg"].(string)).(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("error parsing token")
-			}
-			return []byte(signature), nil
-		})
-		if err != nil {
-			errStr := fmt.Sprintf("500 internal server error: %s", err)
-			a.formatter.Text(w, http.StatusInternalServerError, errStr)
-			return
-		}
 		// Validate token claims
 		if token.Claims != nil {
 			if err := token.Claims.Valid(); err != nil {
@@ -200,11 +207,18 @@ func (a *authenticator) Validate(provider http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 		// Validate token itself
-		if err := a.validateToken(token, req.URL.Path, req.Method); err != nil {
+		user, err := a.validateToken(token, req.URL.Path, req.Method)
+		if err != nil {
 			errStr := fmt.Sprintf("401 Unauthorized: %v", err)
 			a.formatter.Text(w, http.StatusUnauthorized, errStr)
 			return
 		}
+		// Consult custom authorization rules
+		if err := a.authorize(user, req); err != nil {
+			errStr := fmt.Sprintf("403 Forbidden: %v", err)
+			a.formatter.Text(w, http.StatusForbidden, errStr)
+			return
+		}
 
 		provider.ServeHTTP(w, req)
 	})
@@ -215,6 +229,7 @@ func (a *authenticator) registerSecurityHandlers() {
 	a.router.HandleFunc(login, a.loginHandler).Methods(http.MethodGet, http.MethodPost)
 	a.router.HandleFunc(authenticate, a.authenticationHandler).Methods(http.MethodPost)
 	a.router.HandleFunc(logout, a.logoutHandler).Methods(http.MethodPost)
+	a.registerSessionHandlers()
 }
 
 // Login handler shows simple page to log in
@@ -237,7 +252,7 @@ func (a *authenticator) loginHandler(w http.ResponseWriter, req *http.Request) {
 			a.formatter.Text(w, http.StatusInternalServerError, errStr)
 			return
 		}
-		token, errCode, err := a.getTokenFor(credentials)
+		token, errCode, err := a.getTokenFor(credentials, sourceIP(req))
 		if err != nil {
 			a.formatter.Text(w, errCode, err.Error())
 			return
@@ -255,7 +270,7 @@ func (a *authenticator) authenticationHandler(w http.ResponseWriter, req *http.R
 		Username: req.FormValue("name"),
 		Password: req.FormValue("password"),
 	}
-	token, errCode, err := a.getTokenFor(credentials)
+	token, errCode, err := a.getTokenFor(credentials, sourceIP(req))
 	if err != nil {
 		a.formatter.Text(w, errCode, err.Error())
 		return
@@ -285,9 +300,30 @@ func (a *authenticator) logoutHandler(w http.ResponseWriter, req *http.Request)
 	}
 
 	a.userDb.SetLogoutTime(credentials.Username)
+	a.sessions.revokeUser(credentials.Username)
 	a.log.Debugf("user %s was logged out", credentials.Username)
 }
 
+// Reads token from the request and parses it.
+func (a *authenticator) parseToken(req *http.Request) (*jwt.Token, int, error) {
+	// Token may be accessed via cookie, or from authentication header
+	tokenString, errCode, err := a.getTokenStringFromRequest(req)
+	if err != nil {
+		return nil, errCode, err
+	}
+	// Retrieve token object from raw string
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := jwt.GetSigningMethod(token.Header["alg"].(string)).(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("error parsing token")
+		}
+		return []byte(signature), nil
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("500 internal server error: %s", err)
+	}
+	return token, 0, nil
+}
+
 // Read raw token from request.
 func (a *authenticator) getTokenStringFromRequest(req *http.Request) (result string, errCode int, err error) {
 	// Try to read header, validate it if exists.
@@ -316,14 +352,30 @@ func (a *authenticator) getTokenStringFromRequest(req *http.Request) (result str
 }
 
 // Get token for credentials
-func (a *authenticator) getTokenFor(credentials *credentials) (string, int, error) {
+func (a *authenticator) getTokenFor(credentials *credentials, sourceIP string) (string, int, error) {
+	// Only failed attempts are counted, per user and client address, so that
+	// neither a successful login nor a client from another address can exhaust the limit
+	limiterKey := sourceIP + "/" + credentials.Username
+	if a.loginLimiter != nil && !a.loginLimiter.Peek(limiterKey) {
+		a.log.Warnf("too many failed login attempts for user %s from %s", credentials.Username, sourceIP)
+		return "", http.StatusTooManyRequests, fmt.Errorf("429 too many requests: login attempts limit exceeded")
+	}
 	name, errCode, err := a.validateCredentials(credentials)
 	if err != nil {
+		if a.loginLimiter != nil {
+			a.loginLimiter.Allow(limiterKey)
+		}
 		return "", errCode, err
 	}
+	session, err := a.sessions.add(name, sourceIP, a.expTime)
+	if err != nil {
+		return "", http.StatusInternalServerError, fmt.Errorf("500 internal server error: failed to create session: %v", err)
+	}
 	claims := jwt.StandardClaims{
+		Id:        session.ID,
 		Audience:  name,
-		ExpiresAt: a.expTime.Nanoseconds(),
+		IssuedAt:  session.IssuedAt.Unix(),
+		ExpiresAt: session.ExpiresAt.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(signature))
@@ -331,7 +383,7 @@ func (a *authenticator) getTokenFor(credentials *credentials) (string, int, erro
 		return "", http.StatusInternalServerError, fmt.Errorf("500 internal server error: failed to sign token: %v", err)
 	}
 	a.userDb.SetLoginTime(name)
-	a.log.Debugf("user %s was logged in", name)
+	a.log.Debugf("user %s was logged in (session %s)", name, session.ID)
 
 	return tokenString, 0, nil
 }
@@ -348,48 +400,61 @@ func (a *authenticator) validateCredentials(credentials *credentials) (string, i
 	return credentials.Username, 0, nil
 }
 
-// Validates token itself and permissions
-func (a *authenticator) validateToken(token *jwt.Token, url, method string) error {
-	var userName string
-	// Read audience from the token
-	switch v := token.Claims.(type) {
-	case jwt.MapClaims:
-		var ok bool
-		if userName, ok = v["aud"].(string); !ok {
-			return fmt.Errorf("failed to validate token claims audience")
-		}
-	case jwt.StandardClaims:
-		userName = v.Audience
-	default:
-		return fmt.Errorf("failed to validate token claims")
+// Validates token itself and permissions, returns the user the token was issued for
+func (a *authenticator) validateToken(token *jwt.Token, url, method string) (*User, error) {
+	userName, sessionID, err := tokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.sessions.valid(sessionID, userName) {
+		// Session revoked
+		token.Valid = false
+		return nil, fmt.Errorf("invalid token")
 	}
 	loggedOut, err := a.userDb.IsLoggedOut(userName)
 	if err != nil {
-		return fmt.Errorf("failed to validate token: %v", err)
+		return nil, fmt.Errorf("failed to validate token: %v", err)
 	}
 	if loggedOut {
 		// User logged out
 		token.Valid = false
-		return fmt.Errorf("invalid token")
+		return nil, fmt.Errorf("invalid token")
 	}
 	user, err := a.userDb.GetUser(userName)
 	if err != nil {
-		return fmt.Errorf("failed to validate token: %v", err)
+		return nil, fmt.Errorf("failed to validate token: %v", err)
 	}
 	// Do not check for permissions if user is admin
 	if userIsAdmin(user) {
-		return nil
+		return user, nil
 	}
 	perms := a.getPermissionsForURL(url, method)
 	for _, userPerm := range user.Permissions {
 		for _, perm := range perms {
 			if userPerm == perm {
-				return nil
+				return user, nil
 			}
 		}
 	}
 
-	return fmt.Errorf("not permitted")
+	return nil, fmt.Errorf("not permitted")
+}
+
+// Reads user name (audience) and session ID from the token claims
+func tokenClaims(token *jwt.Token) (userName, sessionID string, err error) {
+	switch v := token.Claims.(type) {
+	case jwt.MapClaims:
+		var ok bool
+		if userName, ok = v["aud"].(string); !ok {
+			return "", "", fmt.Errorf("failed to validate token claims audience")
+		}
+		sessionID, _ = v["jti"].(string)
+	case jwt.StandardClaims:
+		userName, sessionID = v.Audience, v.Id
+	default:
+		return "", "", fmt.Errorf("failed to validate token claims")
+	}
+	return userName, sessionID, nil
 }
 
 // Returns all permission groups provided URL/Method is allowed for
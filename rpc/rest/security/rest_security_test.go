@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/cn-infra/logging/logrus"
+	"github.com/ligato/cn-infra/utils/ratelimit"
+	. "github.com/onsi/gomega"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	RegisterTestingT(t)
+
+	limiter, err := ratelimit.Config{Limit: 2, Interval: time.Hour, Algorithm: ratelimit.AlgorithmSlidingWindow}.NewKeyed()
+	Expect(err).NotTo(HaveOccurred())
+	a := NewAuthenticator(mux.NewRouter(), &Settings{LoginLimiter: limiter}, logrus.DefaultLogger()).(*authenticator)
+
+	valid := &credentials{Username: admin, Password: "ligato123"}
+	invalid := &credentials{Username: admin, Password: "wrong"}
+
+	// successful logins are not counted
+	for i := 0; i < 3; i++ {
+		_, _, err := a.getTokenFor(valid, "10.0.0.1")
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	for i := 0; i < 2; i++ {
+		_, code, err := a.getTokenFor(invalid, "10.0.0.2")
+		Expect(err).To(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+	}
+	// the limit is exceeded for the address, even with valid credentials
+	_, code, err := a.getTokenFor(valid, "10.0.0.2")
+	Expect(err).To(HaveOccurred())
+	Expect(code).To(Equal(http.StatusTooManyRequests))
+
+	// the same user may still log in from another address
+	_, _, err = a.getTokenFor(valid, "10.0.0.1")
+	Expect(err).NotTo(HaveOccurred())
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Admin endpoint listing active sessions (optionally filtered by ?user=<name>)
+	// and revoking all sessions of a user (DELETE with ?user=<name>).
+	sessions = "/security/sessions"
+	// Admin endpoint revoking a single session.
+	sessionByID = "/security/sessions/{id}"
+	// URL query parameter used to select sessions of a user
+	userParam = "user"
+)
+
+// Session represents a token issued to a user.
+type Session struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user"`
+	IssuedAt  time.Time `json:"issued-at"`
+	ExpiresAt time.Time `json:"expires-at"`
+	SourceIP  string    `json:"source-ip,omitempty"`
+}
+
+// sessionStore keeps sessions of tokens issued since the agent start. Tokens are valid
+// by their signature and expiry, the store only allows to revoke them before they expire.
+// Revoked session is remembered until its token would have expired. The store is not
+// persisted, so revocations are lost when the agent restarts.
+type sessionStore struct {
+	sync.Mutex
+	sessions map[string]*Session
+	revoked  map[string]time.Time
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*Session),
+		revoked:  make(map[string]time.Time),
+	}
+}
+
+// add creates a new session for the user. Expired sessions are dropped, so that
+// sessions of abandoned tokens do not pile up.
+func (s *sessionStore) add(user, sourceIP string, ttl time.Duration) (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &Session{
+		ID:        hex.EncodeToString(id),
+		User:      user,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+		SourceIP:  sourceIP,
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.pruneExpired(now)
+	s.sessions[session.ID] = session
+
+	return session, nil
+}
+
+// valid returns false if the session was revoked or it belongs to another user.
+// Sessions unknown to the store (e.g. of tokens issued before the agent restart) are valid.
+func (s *sessionStore) valid(id, user string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, revoked := s.revoked[id]; revoked {
+		return false
+	}
+	if session, ok := s.sessions[id]; ok && session.User != user {
+		return false
+	}
+	return true
+}
+
+// list returns active sessions of the user (or of all users if empty) ordered by issue time.
+func (s *sessionStore) list(user string) []Session {
+	s.Lock()
+	defer s.Unlock()
+
+	s.pruneExpired(time.Now())
+	list := []Session{}
+	for _, session := range s.sessions {
+		if user == "" || session.User == user {
+			list = append(list, *session)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].IssuedAt.Before(list[j].IssuedAt)
+	})
+	return list
+}
+
+// pruneExpired removes sessions and revocations expired before <now>, must be called with the lock held.
+func (s *sessionStore) pruneExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	for id, expiresAt := range s.revoked {
+		if now.After(expiresAt) {
+			delete(s.revoked, id)
+		}
+	}
+}
+
+// revoke revokes the session, returns false if it does not exist.
+func (s *sessionStore) revoke(id string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return false
+	}
+	s.revokeSession(session)
+	return true
+}
+
+// revokeUser revokes all sessions of the user and returns their count.
+func (s *sessionStore) revokeUser(user string) int {
+	s.Lock()
+	defer s.Unlock()
+
+	var count int
+	for _, session := range s.sessions {
+		if session.User == user {
+			s.revokeSession(session)
+			count++
+		}
+	}
+	return count
+}
+
+// revokeSession moves the session among revoked ones, must be called with the lock held.
+func (s *sessionStore) revokeSession(session *Session) {
+	delete(s.sessions, session.ID)
+	s.revoked[session.ID] = session.ExpiresAt
+}
+
+// Register admin handlers managing sessions
+func (a *authenticator) registerSessionHandlers() {
+	a.router.HandleFunc(sessions, a.Validate(a.adminOnly(a.listSessionsHandler))).Methods(http.MethodGet)
+	a.router.HandleFunc(sessions, a.Validate(a.adminOnly(a.revokeUserSessionsHandler))).Methods(http.MethodDelete)
+	a.router.HandleFunc(sessionByID, a.Validate(a.adminOnly(a.revokeSessionHandler))).Methods(http.MethodDelete)
+}
+
+// Lists active sessions
+func (a *authenticator) listSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	a.formatter.JSON(w, http.StatusOK, a.sessions.list(req.URL.Query().Get(userParam)))
+}
+
+// Revokes all sessions of the user
+func (a *authenticator) revokeUserSessionsHandler(w http.ResponseWriter, req *http.Request) {
+	user := req.URL.Query().Get(userParam)
+	if user == "" {
+		a.formatter.Text(w, http.StatusBadRequest, "400 bad request: user not specified")
+		return
+	}
+	count := a.sessions.revokeUser(user)
+	a.log.Infof("%d sessions of user %s were revoked", count, user)
+	a.formatter.JSON(w, http.StatusOK, struct {
+		Revoked int `json:"revoked"`
+	}{count})
+}
+
+// Revokes a single session
+func (a *authenticator) revokeSessionHandler(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if !a.sessions.revoke(id) {
+		a.formatter.Text(w, http.StatusNotFound, "404 not found: session does not exist")
+		return
+	}
+	a.log.Infof("session %s was revoked", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Allows the request only for users with admin permission. Token is expected to be validated already.
+func (a *authenticator) adminOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		token, errCode, err := a.parseToken(req)
+		if err != nil {
+			a.formatter.Text(w, errCode, err.Error())
+			return
+		}
+		userName, _, err := tokenClaims(token)
+		if err != nil {
+			a.formatter.Text(w, http.StatusUnauthorized, "401 Unauthorized: "+err.Error())
+			return
+		}
+		user, err := a.userDb.GetUser(userName)
+		if err != nil || !userIsAdmin(user) {
+			a.formatter.Text(w, http.StatusForbidden, "403 forbidden: admin permission required")
+			return
+		}
+		handler(w, req)
+	}
+}
+
+// Returns IP address of the client
+func sourceIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package security
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSessionStore(t *testing.T) {
+	RegisterTestingT(t)
+
+	s := newSessionStore()
+	s1, err := s.add("alice", "10.0.0.1", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	s2, err := s.add("alice", "10.0.0.2", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	s3, err := s.add("bob", "10.0.0.3", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	expired, err := s.add("bob", "10.0.0.4", -time.Second)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(s1.ID).NotTo(Equal(s2.ID))
+	Expect(s.valid(s1.ID, "alice")).To(BeTrue())
+	Expect(s.valid(s1.ID, "bob")).To(BeFalse())
+	// expiry is checked with the token itself
+	Expect(s.valid(expired.ID, "bob")).To(BeTrue())
+
+	Expect(s.list("")).To(HaveLen(3))
+	Expect(s.list("bob")).To(ConsistOf(*s3))
+
+	Expect(s.revoke(s3.ID)).To(BeTrue())
+	Expect(s.revoke(s3.ID)).To(BeFalse())
+	Expect(s.valid(s3.ID, "bob")).To(BeFalse())
+
+	Expect(s.revokeUser("alice")).To(Equal(2))
+	Expect(s.list("")).To(BeEmpty())
+	Expect(s.valid(s1.ID, "alice")).To(BeFalse())
+	Expect(s.valid(s2.ID, "alice")).To(BeFalse())
+}
+
+func TestSessionStoreKeepsUnknownSessionsValid(t *testing.T) {
+	RegisterTestingT(t)
+
+	// session issued before the agent restart
+	issued, err := newSessionStore().add("alice", "10.0.0.1", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+
+	s := newSessionStore()
+	Expect(s.valid(issued.ID, "alice")).To(BeTrue())
+	Expect(s.revoke(issued.ID)).To(BeFalse())
+}
+
+func TestSessionStorePrunesExpiredRevocations(t *testing.T) {
+	RegisterTestingT(t)
+
+	s := newSessionStore()
+	expired, err := s.add("alice", "10.0.0.1", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(s.revoke(expired.ID)).To(BeTrue())
+	Expect(s.valid(expired.ID, "alice")).To(BeFalse())
+
+	// the token of the revoked session has expired meanwhile
+	s.revoked[expired.ID] = time.Now().Add(-time.Second)
+	_, err = s.add("alice", "10.0.0.1", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(s.revoked).To(BeEmpty())
+}
+
+func TestSessionStorePrunesExpiredOnAdd(t *testing.T) {
+	RegisterTestingT(t)
+
+	s := newSessionStore()
+	for i := 0; i < 10; i++ {
+		_, err := s.add("alice", "10.0.0.1", -time.Second)
+		Expect(err).NotTo(HaveOccurred())
+	}
+	_, err := s.add("alice", "10.0.0.1", time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(s.sessions).To(HaveLen(1))
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/unrolled/render"
+)
+
+// ErrInvalidBody is the class of errors returned for requests
+// with body rejected by the BodyValidator.
+var ErrInvalidBody = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "rest/invalid-body",
+	Plugin:     "http",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusBadRequest,
+})
+
+// ErrBodyTooLarge is the class of errors returned for requests with body
+// exceeding the limit of ValidateBody.
+var ErrBodyTooLarge = infra.RegisterErrorClass(infra.ErrorClass{
+	Code:       "rest/body-too-large",
+	Plugin:     "http",
+	Severity:   infra.SeverityWarning,
+	HTTPStatus: http.StatusRequestEntityTooLarge,
+})
+
+// DefaultMaxBodySize is the maximum size of the request body read by ValidateBody
+// if the max-body-size is not set in the plugin config.
+const DefaultMaxBodySize = 1 << 20
+
+// maxBodySizeKey is the request context key of the body size limit.
+type maxBodySizeKey struct{}
+
+// limitBodySize sets the limit of the request body size read by ValidateBody.
+func limitBodySize(next http.Handler, size int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), maxBodySizeKey{}, size)))
+	})
+}
+
+// maxBodySize returns the limit of the request body size.
+func maxBodySize(req *http.Request) int64 {
+	if size, ok := req.Context().Value(maxBodySizeKey{}).(int64); ok {
+		return size
+	}
+	return DefaultMaxBodySize
+}
+
+// BodyValidator validates body of the request before it is passed to the handler.
+type BodyValidator interface {
+	// ValidateBody returns error if the body is not valid.
+	ValidateBody(body []byte) error
+}
+
+// BodyValidatorFunc is a function implementing BodyValidator.
+type BodyValidatorFunc func(body []byte) error
+
+// ValidateBody calls the function.
+func (f BodyValidatorFunc) ValidateBody(body []byte) error {
+	return f(body)
+}
+
+// ProtoBody returns BodyValidator requiring the body to be a JSON representation
+// of the given proto message type. Unknown fields are rejected.
+func ProtoBody(msg proto.Message) BodyValidator {
+	msgType := reflect.TypeOf(msg).Elem()
+	return BodyValidatorFunc(func(body []byte) error {
+		value := reflect.New(msgType).Interface().(proto.Message)
+		return jsonpb.Unmarshal(bytes.NewReader(body), value)
+	})
+}
+
+// JSONBody returns BodyValidator requiring the body to be a JSON representation
+// of the type of <v> (e.g. a struct, which serves as the schema). Unknown fields
+// and any data following the JSON value are rejected.
+func JSONBody(v interface{}) BodyValidator {
+	valueType := reflect.TypeOf(v)
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	return BodyValidatorFunc(func(body []byte) error {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(reflect.New(valueType).Interface()); err != nil {
+			return err
+		}
+		var trailing json.RawMessage
+		if err := decoder.Decode(&trailing); err != io.EOF {
+			return fmt.Errorf("unexpected data after the JSON value")
+		}
+		return nil
+	})
+}
+
+// ValidateBody wraps the handler provider so that the request body is validated by all
+// given validators before the handler is invoked. Invalid requests are rejected with
+// 400 Bad Request and ErrorResponse of ErrInvalidBody class. Requests with body larger
+// than max-body-size (DefaultMaxBodySize by default) are rejected with 413 Request Entity
+// Too Large and ErrorResponse of ErrBodyTooLarge class.
+//
+// Example:
+//
+//	http.RegisterHTTPHandler("/greeting", rest.ValidateBody(p.greetingHandler,
+//		rest.JSONBody(Greeting{})), "POST")
+func ValidateBody(provider HandlerProvider, validators ...BodyValidator) HandlerProvider {
+	return func(formatter *render.Render) http.HandlerFunc {
+		handler := provider(formatter)
+		return func(w http.ResponseWriter, req *http.Request) {
+			var body []byte
+			if req.Body != nil {
+				limit := maxBodySize(req)
+				var err error
+				body, err = ioutil.ReadAll(http.MaxBytesReader(w, req.Body, limit))
+				req.Body.Close()
+				if err != nil && int64(len(body)) >= limit {
+					WriteError(formatter, w, ErrBodyTooLarge.Errorf("request body exceeds %d bytes", limit))
+					return
+				} else if err != nil {
+					WriteError(formatter, w, ErrInvalidBody.Errorf("reading request body failed: %v", err))
+					return
+				}
+			}
+			for _, validator := range validators {
+				if err := validator.ValidateBody(body); err != nil {
+					WriteError(formatter, w, ErrInvalidBody.Wrap(fmt.Errorf("invalid request body: %v", err)))
+					return
+				}
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			handler(w, req)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	. "github.com/onsi/gomega"
+	"github.com/unrolled/render"
+)
+
+type greeting struct {
+	Greeting string `json:"greeting"`
+}
+
+func serve(provider HandlerProvider, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	provider(render.New())(rec, req)
+	return rec
+}
+
+func TestValidateBody(t *testing.T) {
+	RegisterTestingT(t)
+
+	var received string
+	echo := func(formatter *render.Render) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			received = string(body)
+		}
+	}
+	notEmpty := BodyValidatorFunc(func(body []byte) error {
+		if strings.Contains(string(body), `""`) {
+			return errors.New("empty greeting")
+		}
+		return nil
+	})
+
+	provider := ValidateBody(echo, JSONBody(greeting{}), notEmpty)
+	rec := serve(provider, `{"greeting": "hello"}`)
+	Expect(rec.Code).To(Equal(http.StatusOK))
+	Expect(received).To(Equal(`{"greeting": "hello"}`))
+	Expect(serve(provider, "{\"greeting\": \"hello\"}\n").Code).To(Equal(http.StatusOK))
+
+	invalid := []string{`{"greeting": 1}`, `{"unknown": "x"}`, `{"greeting": ""}`, ``,
+		`{"greeting": "hello"}garbage`, `{"greeting": "hello"} {"greeting": "hi"}`, `{"greeting": "hello"}}`}
+	for _, body := range invalid {
+		rec = serve(provider, body)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest), body)
+		var resp ErrorResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Code).To(Equal(ErrInvalidBody.Code))
+	}
+
+	// body exceeding the limit is rejected
+	limited := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+		limitBodySize(provider(render.New()), 16).ServeHTTP(rec, req)
+		return rec
+	}
+	Expect(limited(`{"greeting":"hi"}`).Code).To(Equal(http.StatusRequestEntityTooLarge))
+	Expect(limited(`{"greeting":"h"}`).Code).To(Equal(http.StatusOK))
+
+	provider = ValidateBody(echo, ProtoBody(&status.PluginStatus{}))
+	Expect(serve(provider, `{"name": "etcd", "state": "OK"}`).Code).To(Equal(http.StatusOK))
+	Expect(serve(provider, `{"name": "etcd", "color": "red"}`).Code).To(Equal(http.StatusBadRequest))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ligato/cn-infra/utils/retry"
+)
+
+const (
+	// EventAgentReady is sent when all plugins reach OK state for the first time.
+	EventAgentReady = "agent-ready"
+	// EventPluginUnhealthy is sent when a plugin reports error state.
+	EventPluginUnhealthy = "plugin-unhealthy"
+	// EventResyncCompleted is sent when the resync procedure has finished.
+	EventResyncCompleted = "resync-completed"
+	// EventAll subscribes the webhook to all events.
+	EventAll = "*"
+)
+
+const (
+	// DefaultTimeout is the timeout of a single delivery attempt.
+	DefaultTimeout = 5 * time.Second
+	// DefaultQueueSize is the number of notifications waiting for delivery per webhook.
+	DefaultQueueSize = 100
+)
+
+// DefaultRetry is used for webhooks without retry configuration.
+var DefaultRetry = retry.Config{
+	MaxAttempts:     5,
+	InitialInterval: time.Second,
+	MaxInterval:     30 * time.Second,
+	Jitter:          0.2,
+}
+
+// Config holds the configuration of the webhook plugin.
+type Config struct {
+	Webhooks []*WebhookConfig `json:"webhooks"`
+}
+
+// WebhookConfig defines a single webhook.
+type WebhookConfig struct {
+	// Name identifies the webhook in logs.
+	Name string `json:"name"`
+	// URL is the endpoint receiving notifications.
+	URL string `json:"url"`
+	// Events lists events delivered to the webhook ("*" for all events).
+	Events []string `json:"events"`
+	// Secret is used to sign notifications (signature is not sent if empty).
+	Secret string `json:"secret"`
+	// Timeout of a single delivery attempt.
+	Timeout time.Duration `json:"timeout"`
+	// Retry defines how failed deliveries are retried (DefaultRetry if not set). Max attempts of DefaultRetry
+	// are used if max-attempts is not set, -1 retries until the plugin is closed.
+	Retry retry.Config `json:"retry"`
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers"`
+	// QueueSize is the maximum number of notifications waiting for delivery.
+	QueueSize int `json:"queue-size"`
+}
+
+// Validate checks the configuration and fills in default values.
+func (c *Config) Validate() error {
+	names := make(map[string]bool)
+	for i, wh := range c.Webhooks {
+		if wh == nil {
+			return fmt.Errorf("webhook #%d is empty", i)
+		}
+		if wh.Name == "" {
+			wh.Name = fmt.Sprintf("webhook-%d", i)
+		}
+		if names[wh.Name] {
+			return fmt.Errorf("duplicate webhook name: %q", wh.Name)
+		}
+		names[wh.Name] = true
+		u, err := url.Parse(wh.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %q: invalid URL %q", wh.Name, wh.URL)
+		}
+		if len(wh.Events) == 0 {
+			return fmt.Errorf("webhook %q: no events", wh.Name)
+		}
+		if wh.Timeout < 0 || wh.QueueSize < 0 {
+			return fmt.Errorf("webhook %q: timeout and queue-size must not be negative", wh.Name)
+		}
+		if wh.Timeout == 0 {
+			wh.Timeout = DefaultTimeout
+		}
+		if wh.QueueSize == 0 {
+			wh.QueueSize = DefaultQueueSize
+		}
+		if wh.Retry == (retry.Config{}) {
+			wh.Retry = DefaultRetry
+		} else if wh.Retry.MaxAttempts == 0 {
+			wh.Retry.MaxAttempts = DefaultRetry.MaxAttempts
+		}
+	}
+	return nil
+}
+
+// subscribed returns true if the webhook receives the event.
+func (wh *WebhookConfig) subscribed(event string) bool {
+	for _, e := range wh.Events {
+		if e == event || e == EventAll {
+			return true
+		}
+	}
+	return false
+}
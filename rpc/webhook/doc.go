@@ -0,0 +1,42 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook implements plugin that delivers notifications about agent
+// events to external HTTP endpoints (webhooks).
+//
+// Every configured webhook subscribes to a list of events. Notifications are
+// sent asynchronously as JSON in the body of a POST request:
+//
+//   {
+//     "event": "plugin-unhealthy",
+//     "agent": "vpp1",
+//     "time": "2018-06-01T12:00:00Z",
+//     "data": {...}
+//   }
+//
+// The name of the event is also sent in the X-Webhook-Event header. If the
+// webhook has a secret, the body is signed with HMAC-SHA256 and the signature
+// is sent in the X-Webhook-Signature header as "sha256=<hex digest>".
+// Failed deliveries (network errors, 429 and 5xx responses) are retried
+// with the configured backoff. Each webhook has a bounded queue; when the
+// queue is full, new notifications are dropped.
+//
+// Built-in events:
+//   - agent-ready: all plugins reached OK state for the first time,
+//   - plugin-unhealthy: a plugin reported ERROR (or degraded) state,
+//   - resync-completed: the resync procedure has finished.
+//
+// Other plugins can send custom events (e.g. "transaction-failed")
+// using Notify.
+package webhook
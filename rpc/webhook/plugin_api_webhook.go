@@ -0,0 +1,46 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+// Notifier allows other plugins to send notifications to webhooks.
+type Notifier interface {
+	// Notify queues the event for delivery to all webhooks subscribed to it.
+	// Data must be serializable to JSON.
+	Notify(event string, data interface{})
+}
+
+// Notification is the JSON payload sent to webhooks.
+type Notification struct {
+	Event string      `json:"event"`
+	Agent string      `json:"agent"`
+	Time  string      `json:"time"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// PluginUnhealthyData is the data of the plugin-unhealthy event.
+type PluginUnhealthyData struct {
+	Plugin    string `json:"plugin"`
+	State     string `json:"state"`
+	PrevState string `json:"prev_state"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// ResyncCompletedData is the data of the resync-completed event.
+type ResyncCompletedData struct {
+	Registrations []string `json:"registrations"`
+	DurationMs    int64    `json:"duration_ms"`
+	TimedOut      []string `json:"timed_out,omitempty"`
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ligato/cn-infra/datasync/resync"
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/infra"
+	"github.com/ligato/cn-infra/servicelabel"
+	"github.com/ligato/cn-infra/utils/eventbus"
+	"github.com/ligato/cn-infra/utils/retry"
+)
+
+const (
+	// EventHeader carries the name of the event.
+	EventHeader = "X-Webhook-Event"
+	// SignatureHeader carries the HMAC-SHA256 signature of the body.
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Plugin delivers notifications about agent events to webhooks.
+type Plugin struct {
+	Deps
+
+	config *Config
+	hooks  []*hook
+	subs   []*eventbus.Subscription
+
+	readyOnce sync.Once
+	loop      infra.EventLoop
+}
+
+// Deps lists dependencies of the webhook plugin.
+type Deps struct {
+	infra.PluginDeps
+	ServiceLabel servicelabel.ReaderAPI
+	StatusCheck  statuscheck.StatusReader // inject
+}
+
+// hook is a single webhook with its delivery queue.
+type hook struct {
+	config *WebhookConfig
+	queue  chan *Notification
+	client *http.Client
+}
+
+// Init loads the configuration, starts delivery of notifications
+// and subscribes to the built-in events.
+func (p *Plugin) Init() error {
+	if p.config == nil {
+		p.config = &Config{}
+		if _, err := p.Cfg.LoadValue(p.config); err != nil {
+			return err
+		}
+	}
+	if err := p.config.Validate(); err != nil {
+		return err
+	}
+	if len(p.config.Webhooks) == 0 {
+		p.Log.Debug("No webhooks configured")
+		return nil
+	}
+	p.loop.Log = p.Log
+	for _, wh := range p.config.Webhooks {
+		h := &hook{
+			config: wh,
+			queue:  make(chan *Notification, wh.QueueSize),
+			client: &http.Client{Timeout: wh.Timeout},
+		}
+		p.hooks = append(p.hooks, h)
+		p.loop.Run(infra.OnReceive(h.queue, func(ctx context.Context, n interface{}) {
+			p.deliver(ctx, h, n.(*Notification))
+		}))
+	}
+
+	// statuscheck and resync publish the events on the default bus
+	subscriptions := []struct {
+		topic   *eventbus.Topic
+		handler func(interface{})
+	}{
+		{statuscheck.AgentStateChanged, p.onAgentState},
+		{statuscheck.PluginStateChanged, p.onPluginState},
+		{resync.Completed, p.onResync},
+	}
+	for _, s := range subscriptions {
+		sub, err := eventbus.DefaultBus.SubscribeFunc(s.topic, s.handler)
+		if err != nil {
+			return err
+		}
+		p.subs = append(p.subs, sub)
+	}
+	return nil
+}
+
+// Close stops delivery of notifications. Notifications still queued are dropped.
+func (p *Plugin) Close() error {
+	for _, sub := range p.subs {
+		sub.Close()
+	}
+	p.loop.Stop()
+	return nil
+}
+
+// Notify queues the event for delivery to all webhooks subscribed to it.
+func (p *Plugin) Notify(event string, data interface{}) {
+	var n *Notification
+	for _, h := range p.hooks {
+		if !h.config.subscribed(event) {
+			continue
+		}
+		if n == nil {
+			n = &Notification{
+				Event: event,
+				Agent: p.ServiceLabel.GetAgentLabel(),
+				Time:  time.Now().UTC().Format(time.RFC3339),
+				Data:  data,
+			}
+		}
+		select {
+		case h.queue <- n:
+		default:
+			p.Log.Warnf("Webhook %s: queue is full, dropping %s notification", h.config.Name, event)
+		}
+	}
+}
+
+func (p *Plugin) onAgentState(event interface{}) {
+	ev := event.(statuscheck.AgentStateEvent)
+	if ev.State == status.OperationalState_OK {
+		p.checkReady()
+	}
+}
+
+// checkReady sends agent-ready once all plugins are in OK state. The agent state
+// only reflects the plugin which reported last, so it is not enough on its own.
+func (p *Plugin) checkReady() {
+	for _, stat := range p.StatusCheck.GetAllPluginStatus() {
+		if stat.State != status.OperationalState_OK {
+			return
+		}
+	}
+	p.readyOnce.Do(func() {
+		p.Notify(EventAgentReady, nil)
+	})
+}
+
+func (p *Plugin) onPluginState(event interface{}) {
+	ev := event.(statuscheck.PluginStateEvent)
+	if ev.State == status.OperationalState_OK {
+		p.checkReady()
+		return
+	}
+	unhealthy := func(s status.OperationalState) bool {
+		return s == status.OperationalState_ERROR || s == status.OperationalState_DEGRADED
+	}
+	if !unhealthy(ev.State) || unhealthy(ev.PrevState) {
+		return
+	}
+	data := &PluginUnhealthyData{
+		Plugin:    ev.Plugin,
+		State:     ev.State.String(),
+		PrevState: ev.PrevState.String(),
+	}
+	if ev.Error != nil {
+		data.Error = ev.Error.Message
+		data.ErrorCode = ev.Error.Code
+	}
+	p.Notify(EventPluginUnhealthy, data)
+}
+
+func (p *Plugin) onResync(event interface{}) {
+	ev := event.(resync.CompletedEvent)
+	p.Notify(EventResyncCompleted, &ResyncCompletedData{
+		Registrations: ev.Registrations,
+		DurationMs:    int64(ev.Duration / time.Millisecond),
+		TimedOut:      ev.TimedOut,
+	})
+}
+
+// deliver sends the notification queued for the webhook. Retrying is stopped
+// once the plugin is closed.
+func (p *Plugin) deliver(ctx context.Context, h *hook, n *Notification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		p.Log.Errorf("Webhook %s: failed to encode %s notification: %v", h.config.Name, n.Event, err)
+		return
+	}
+	opts := append(h.config.Retry.Options(), retry.OnRetry(func(attempt int, delay time.Duration, err error) {
+		p.Log.Debugf("Webhook %s: delivery attempt %d failed (retry in %v): %v",
+			h.config.Name, attempt, delay, err)
+	}))
+	err = retry.Do(ctx, func() error {
+		return p.send(ctx, h, n.Event, body)
+	}, opts...)
+	if err != nil && ctx.Err() == nil {
+		p.Log.Warnf("Webhook %s: failed to deliver %s notification: %v", h.config.Name, n.Event, err)
+	}
+}
+
+// send makes a single delivery attempt. Client errors other than 429 are not retried.
+func (p *Plugin) send(ctx context.Context, h *hook, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	req = req.WithContext(ctx)
+	for name, val := range h.config.Headers {
+		req.Header.Set(name, val)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if h.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.config.Secret, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	default:
+		return retry.Permanent(fmt.Errorf("unexpected response: %s", resp.Status))
+	}
+}
+
+// Sign returns the value of the signature header for the body.
+// Receivers can use it to verify notifications.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ligato/cn-infra/health/statuscheck"
+	"github.com/ligato/cn-infra/health/statuscheck/model/status"
+	"github.com/ligato/cn-infra/utils/eventbus"
+	"github.com/ligato/cn-infra/utils/retry"
+	. "github.com/onsi/gomega"
+)
+
+type received struct {
+	notification Notification
+	signature    string
+	event        string
+}
+
+func TestDeliveryWithRetry(t *testing.T) {
+	RegisterTestingT(t)
+
+	var attempts int32
+	recv := make(chan received, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		var n Notification
+		Expect(json.Unmarshal(body, &n)).To(Succeed())
+		Expect(req.Header.Get(SignatureHeader)).To(Equal(Sign("secret", body)))
+		recv <- received{notification: n, signature: req.Header.Get(SignatureHeader), event: req.Header.Get(EventHeader)}
+	}))
+	defer srv.Close()
+
+	p := NewPlugin(UseConf(Config{Webhooks: []*WebhookConfig{{
+		URL:    srv.URL,
+		Events: []string{EventPluginUnhealthy},
+		Secret: "secret",
+		Retry:  retry.Config{MaxAttempts: 3, InitialInterval: time.Millisecond},
+	}}}))
+	Expect(p.Init()).To(Succeed())
+	defer p.Close()
+
+	// not subscribed
+	eventbus.DefaultBus.Publish(statuscheck.AgentStateChanged, statuscheck.AgentStateEvent{
+		State: status.OperationalState_OK, PrevState: status.OperationalState_INIT,
+	})
+	eventbus.DefaultBus.Publish(statuscheck.PluginStateChanged, statuscheck.PluginStateEvent{
+		Plugin:    "etcd",
+		State:     status.OperationalState_ERROR,
+		PrevState: status.OperationalState_OK,
+		Error:     &status.ErrorDetails{Code: "etcd/unavailable", Message: "connection refused"},
+	})
+
+	var r received
+	Eventually(recv).Should(Receive(&r))
+	Expect(r.event).To(Equal(EventPluginUnhealthy))
+	Expect(r.notification.Event).To(Equal(EventPluginUnhealthy))
+	Expect(r.notification.Data).To(HaveKeyWithValue("plugin", "etcd"))
+	Expect(r.notification.Data).To(HaveKeyWithValue("error_code", "etcd/unavailable"))
+	Expect(atomic.LoadInt32(&attempts)).To(BeEquivalentTo(2))
+	Consistently(recv, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func TestInvalidConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	conf := &Config{Webhooks: []*WebhookConfig{{URL: "ftp://example.com", Events: []string{EventAll}}}}
+	Expect(conf.Validate()).NotTo(Succeed())
+
+	conf = &Config{Webhooks: []*WebhookConfig{{URL: "http://example.com", Events: []string{EventAll}}}}
+	Expect(conf.Validate()).To(Succeed())
+	Expect(conf.Webhooks[0].Retry).To(Equal(DefaultRetry))
+	Expect(conf.Webhooks[0].Name).To(Equal("webhook-0"))
+
+	conf = &Config{Webhooks: []*WebhookConfig{{URL: "http://example.com", Events: []string{EventAll},
+		Retry: retry.Config{InitialInterval: time.Millisecond}}}}
+	Expect(conf.Validate()).To(Succeed())
+	Expect(conf.Webhooks[0].Retry.MaxAttempts).To(Equal(DefaultRetry.MaxAttempts))
+}
+
+func TestAgentReadyWhenAllPluginsOK(t *testing.T) {
+	RegisterTestingT(t)
+
+	recv := make(chan received, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		recv <- received{event: req.Header.Get(EventHeader)}
+	}))
+	defer srv.Close()
+
+	// statuscheck publishes the state events on the default bus
+	sc := statuscheck.NewPlugin()
+	Expect(sc.Init()).To(Succeed())
+	sc.Register("a", nil)
+	sc.Register("b", nil)
+
+	p := NewPlugin(UseConf(Config{Webhooks: []*WebhookConfig{{
+		URL:    srv.URL,
+		Events: []string{EventAgentReady},
+	}}}), UseDeps(func(deps *Deps) {
+		deps.StatusCheck = sc
+	}))
+	Expect(p.Init()).To(Succeed())
+	defer p.Close()
+	Expect(sc.AfterInit()).To(Succeed())
+	defer sc.Close()
+
+	// the agent state is OK, but plugin b is still initializing
+	sc.ReportStateChange("a", statuscheck.OK, nil)
+	Expect(sc.GetAgentStatus().State).To(Equal(status.OperationalState_OK))
+	Consistently(recv, 50*time.Millisecond).ShouldNot(Receive())
+
+	sc.ReportStateChange("b", statuscheck.Error, nil)
+	Consistently(recv, 50*time.Millisecond).ShouldNot(Receive())
+
+	sc.ReportStateChange("b", statuscheck.OK, nil)
+	var r received
+	Eventually(recv).Should(Receive(&r))
+	Expect(r.event).To(Equal(EventAgentReady))
+
+	// sent only once
+	sc.ReportStateChange("a", statuscheck.Error, nil)
+	sc.ReportStateChange("a", statuscheck.OK, nil)
+	Consistently(recv, 50*time.Millisecond).ShouldNot(Receive())
+}
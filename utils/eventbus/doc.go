@@ -0,0 +1,38 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eventbus provides a lightweight in-process publish/subscribe bus
+// allowing plugins to exchange events (e.g. link up, resync done) without
+// channels threaded through their Deps.
+//
+// Topics are typed - every topic is declared with the type of its events and
+// publishing an event of a different type fails. Each subscription has its
+// own buffer and a policy deciding what happens when a slow consumer lets
+// the buffer fill up.
+//
+// Example:
+//
+//	// package of the publisher
+//	var ResyncDone = eventbus.NewTopic("resync-done", ResyncEvent{})
+//
+//	eventbus.DefaultBus.Publish(ResyncDone, ResyncEvent{Revision: rev})
+//
+//	// package of the subscriber
+//	sub, err := eventbus.DefaultBus.Subscribe(publisher.ResyncDone,
+//		eventbus.WithBuffer(10), eventbus.WithPolicy(eventbus.DropOldest))
+//	for ev := range sub.Events() {
+//		resync := ev.(publisher.ResyncEvent)
+//		...
+//	}
+package eventbus
@@ -0,0 +1,320 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBus is the bus shared by all plugins of the agent.
+var DefaultBus = NewBus()
+
+// DefaultBufferSize is the buffer size of subscriptions created without WithBuffer.
+const DefaultBufferSize = 100
+
+// Topic is a named stream of events of a single type.
+type Topic struct {
+	name      string
+	eventType reflect.Type
+}
+
+// NewTopic declares a topic with events of the same type as <event>.
+func NewTopic(name string, event interface{}) *Topic {
+	if event == nil {
+		panic(fmt.Sprintf("eventbus: topic %q declared without event type", name))
+	}
+	return &Topic{name: name, eventType: reflect.TypeOf(event)}
+}
+
+// String returns the name of the topic.
+func (t *Topic) String() string {
+	return t.name
+}
+
+// Policy decides what happens with events published to a subscription with a full buffer.
+type Policy int
+
+const (
+	// DropNewest drops the published event (default).
+	DropNewest Policy = iota
+	// DropOldest drops the oldest buffered event to make space for the published one.
+	DropOldest
+	// Block makes the publisher wait until there is space in the buffer
+	// (at most for the block timeout, if set).
+	Block
+	// Unsubscribe closes the subscription of the slow consumer.
+	Unsubscribe
+)
+
+// String returns the name of the policy.
+func (p Policy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	case Block:
+		return "block"
+	case Unsubscribe:
+		return "unsubscribe"
+	}
+	return fmt.Sprintf("policy(%d)", int(p))
+}
+
+// SubscribeOption customizes a subscription.
+type SubscribeOption func(*Subscription)
+
+// WithBuffer sets the number of events buffered for the subscriber.
+func WithBuffer(size int) SubscribeOption {
+	return func(s *Subscription) {
+		s.bufferSize = size
+	}
+}
+
+// WithPolicy sets the policy applied when the buffer is full.
+func WithPolicy(policy Policy) SubscribeOption {
+	return func(s *Subscription) {
+		s.policy = policy
+	}
+}
+
+// WithBlockTimeout limits how long the publisher waits with the Block policy
+// before the event is dropped (0 means waiting until the subscription is closed).
+func WithBlockTimeout(timeout time.Duration) SubscribeOption {
+	return func(s *Subscription) {
+		s.blockTimeout = timeout
+	}
+}
+
+// Bus dispatches published events to subscriptions of the topic.
+type Bus struct {
+	mu     sync.RWMutex
+	topics map[string]*topicSubs
+}
+
+type topicSubs struct {
+	eventType reflect.Type
+	subs      []*Subscription
+}
+
+// NewBus creates a new bus.
+func NewBus() *Bus {
+	return &Bus{topics: make(map[string]*topicSubs)}
+}
+
+// Publish sends the event to all current subscriptions of the topic.
+// Error is returned if the event does not match the type of the topic
+// or if the topic name is used by a topic of another type.
+func (b *Bus) Publish(topic *Topic, event interface{}) error {
+	if eventType := reflect.TypeOf(event); eventType != topic.eventType {
+		return fmt.Errorf("eventbus: event of type %v published to topic %q of type %v",
+			eventType, topic.name, topic.eventType)
+	}
+
+	b.mu.RLock()
+	ts, ok := b.topics[topic.name]
+	var subs []*Subscription
+	if ok {
+		if ts.eventType != topic.eventType {
+			b.mu.RUnlock()
+			return b.typeConflict(topic, ts)
+		}
+		subs = append(subs, ts.subs...)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.deliver(event)
+	}
+	return nil
+}
+
+// Subscribe creates a subscription receiving events published to the topic from now on.
+func (b *Bus) Subscribe(topic *Topic, opts ...SubscribeOption) (*Subscription, error) {
+	sub := &Subscription{
+		bus:        b,
+		topic:      topic,
+		bufferSize: DefaultBufferSize,
+		done:       make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(sub)
+	}
+	if sub.bufferSize < 0 {
+		return nil, fmt.Errorf("eventbus: negative buffer size %d", sub.bufferSize)
+	}
+	sub.ch = make(chan interface{}, sub.bufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ts, ok := b.topics[topic.name]
+	if !ok {
+		ts = &topicSubs{eventType: topic.eventType}
+		b.topics[topic.name] = ts
+	} else if ts.eventType != topic.eventType {
+		return nil, b.typeConflict(topic, ts)
+	}
+	ts.subs = append(ts.subs, sub)
+	return sub, nil
+}
+
+// SubscribeFunc subscribes to the topic and calls the handler for every event
+// from a dedicated goroutine until the subscription is closed.
+func (b *Bus) SubscribeFunc(topic *Topic, handler func(event interface{}), opts ...SubscribeOption) (*Subscription, error) {
+	sub, err := b.Subscribe(topic, opts...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for event := range sub.Events() {
+			handler(event)
+		}
+	}()
+	return sub, nil
+}
+
+// Subscribers returns the number of subscriptions of the topic.
+func (b *Bus) Subscribers(topic *Topic) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if ts, ok := b.topics[topic.name]; ok {
+		return len(ts.subs)
+	}
+	return 0
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ts, ok := b.topics[sub.topic.name]
+	if !ok {
+		return
+	}
+	for i, s := range ts.subs {
+		if s == sub {
+			ts.subs = append(ts.subs[:i], ts.subs[i+1:]...)
+			break
+		}
+	}
+	if len(ts.subs) == 0 {
+		delete(b.topics, sub.topic.name)
+	}
+}
+
+func (b *Bus) typeConflict(topic *Topic, ts *topicSubs) error {
+	return fmt.Errorf("eventbus: topic %q of type %v conflicts with existing topic of type %v",
+		topic.name, topic.eventType, ts.eventType)
+}
+
+// Subscription receives events published to a topic.
+type Subscription struct {
+	bus          *Bus
+	topic        *Topic
+	bufferSize   int
+	policy       Policy
+	blockTimeout time.Duration
+
+	mu        sync.Mutex
+	ch        chan interface{}
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   uint64
+}
+
+// Events returns the channel with events. The channel is closed when
+// the subscription is closed.
+func (s *Subscription) Events() <-chan interface{} {
+	return s.ch
+}
+
+// Dropped returns the number of events dropped due to the full buffer.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close cancels the subscription and closes the channel with events.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		// unblock publisher waiting with the Block policy
+		close(s.done)
+	})
+	s.mu.Lock()
+	s.closeLocked()
+	s.mu.Unlock()
+	s.bus.remove(s)
+}
+
+func (s *Subscription) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// deliver sends the event to the subscriber applying the policy if the buffer is full.
+func (s *Subscription) deliver(event interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- event:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case DropOldest:
+		select {
+		case <-s.ch:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+		select {
+		case s.ch <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	case Block:
+		var timeout <-chan time.Time
+		if s.blockTimeout > 0 {
+			timer := time.NewTimer(s.blockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case s.ch <- event:
+		case <-s.done:
+		case <-timeout:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	case Unsubscribe:
+		atomic.AddUint64(&s.dropped, 1)
+		s.closeLocked()
+		go s.bus.remove(s)
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
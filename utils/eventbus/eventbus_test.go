@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type linkEvent struct {
+	Name string
+	Up   bool
+}
+
+var linkTopic = NewTopic("link", linkEvent{})
+
+func TestPublishSubscribe(t *testing.T) {
+	RegisterTestingT(t)
+
+	bus := NewBus()
+	sub1, err := bus.Subscribe(linkTopic)
+	Expect(err).ToNot(HaveOccurred())
+	sub2, err := bus.Subscribe(linkTopic)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(bus.Subscribers(linkTopic)).To(Equal(2))
+
+	Expect(bus.Publish(linkTopic, linkEvent{Name: "eth0", Up: true})).To(Succeed())
+	Expect(<-sub1.Events()).To(Equal(linkEvent{Name: "eth0", Up: true}))
+	Expect(<-sub2.Events()).To(Equal(linkEvent{Name: "eth0", Up: true}))
+
+	// typed topics
+	Expect(bus.Publish(linkTopic, "eth0")).ToNot(Succeed())
+	_, err = bus.Subscribe(NewTopic("link", 1))
+	Expect(err).To(HaveOccurred())
+
+	sub1.Close()
+	_, open := <-sub1.Events()
+	Expect(open).To(BeFalse())
+	Expect(bus.Subscribers(linkTopic)).To(Equal(1))
+	Expect(bus.Publish(linkTopic, linkEvent{Name: "eth1"})).To(Succeed())
+	Expect(<-sub2.Events()).To(Equal(linkEvent{Name: "eth1"}))
+}
+
+func TestSlowConsumerPolicies(t *testing.T) {
+	RegisterTestingT(t)
+
+	bus := NewBus()
+	newest, _ := bus.Subscribe(linkTopic, WithBuffer(2))
+	oldest, _ := bus.Subscribe(linkTopic, WithBuffer(2), WithPolicy(DropOldest))
+	unsub, _ := bus.Subscribe(linkTopic, WithBuffer(2), WithPolicy(Unsubscribe))
+	block, _ := bus.Subscribe(linkTopic, WithBuffer(2), WithPolicy(Block), WithBlockTimeout(10*time.Millisecond))
+
+	for _, name := range []string{"a", "b", "c"} {
+		Expect(bus.Publish(linkTopic, linkEvent{Name: name})).To(Succeed())
+	}
+
+	names := func(sub *Subscription) (names []string) {
+		for len(sub.Events()) > 0 {
+			names = append(names, (<-sub.Events()).(linkEvent).Name)
+		}
+		return names
+	}
+	Expect(names(newest)).To(Equal([]string{"a", "b"}))
+	Expect(newest.Dropped()).To(BeEquivalentTo(1))
+	Expect(names(oldest)).To(Equal([]string{"b", "c"}))
+	Expect(oldest.Dropped()).To(BeEquivalentTo(1))
+	Expect(names(block)).To(Equal([]string{"a", "b"}))
+	Expect(block.Dropped()).To(BeEquivalentTo(1))
+
+	Expect(names(unsub)).To(Equal([]string{"a", "b"}))
+	_, open := <-unsub.Events()
+	Expect(open).To(BeFalse())
+	Eventually(func() int { return bus.Subscribers(linkTopic) }).Should(Equal(3))
+}
+
+func TestBlockedPublisherReleasedByClose(t *testing.T) {
+	RegisterTestingT(t)
+
+	bus := NewBus()
+	sub, _ := bus.Subscribe(linkTopic, WithBuffer(0), WithPolicy(Block))
+
+	published := make(chan struct{})
+	go func() {
+		bus.Publish(linkTopic, linkEvent{})
+		close(published)
+	}()
+	Consistently(published, 50*time.Millisecond).ShouldNot(BeClosed())
+	sub.Close()
+	Eventually(published).Should(BeClosed())
+}
+
+func TestSubscribeFunc(t *testing.T) {
+	RegisterTestingT(t)
+
+	bus := NewBus()
+	received := make(chan linkEvent, 1)
+	sub, err := bus.SubscribeFunc(linkTopic, func(event interface{}) {
+		received <- event.(linkEvent)
+	})
+	Expect(err).ToNot(HaveOccurred())
+	defer sub.Close()
+
+	Expect(bus.Publish(linkTopic, linkEvent{Name: "eth0"})).To(Succeed())
+	Eventually(received).Should(Receive(Equal(linkEvent{Name: "eth0"})))
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package handover supports zero-downtime upgrades of the agent binary.
+//
+// Listening sockets created by Listen are passed to a newly executed agent
+// binary by Upgrade (via file descriptor inheritance), so that the new
+// process accepts connections on the same sockets without closing them.
+// Once the new process is ready (reported by the statuscheck plugin), it
+// calls Ready which sends SIGTERM to the old process. The old process then
+// stops as usual, but servers drain active connections instead of dropping
+// them, because Draining returns true.
+// Inherited sockets which the new process did not request by Listen until
+// it called Ready are closed.
+//
+// Upgrade is usually triggered by a signal:
+//
+//	stop := handover.HandleSignal(syscall.SIGUSR2, logging.DefaultLogger)
+//	defer stop()
+package handover
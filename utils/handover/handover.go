@@ -0,0 +1,302 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handover
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ligato/cn-infra/logging"
+	"github.com/ligato/cn-infra/logging/logrus"
+)
+
+const (
+	// ListenersEnvVar lists listeners inherited from the old process in form
+	// "network:address" separated by ';'. The n-th listener uses file descriptor 3+n.
+	ListenersEnvVar = "CN_INFRA_HANDOVER_LISTENERS"
+	// ParentEnvVar holds PID of the old process which is notified when the new process is ready.
+	ParentEnvVar = "CN_INFRA_HANDOVER_PARENT"
+
+	// DrainTimeout limits how long servers drain active connections when the process
+	// is stopped after upgrade. It should be shorter than the agent stop timeout.
+	DrainTimeout = 3 * time.Second
+
+	// first file descriptor passed by exec.Cmd.ExtraFiles
+	firstInheritedFd = 3
+)
+
+// fileListener is implemented by listeners which can be passed to another process
+type fileListener interface {
+	net.Listener
+	File() (*os.File, error)
+	SyscallConn() (syscall.RawConn, error)
+}
+
+var (
+	mu        sync.Mutex
+	inherited map[string]net.Listener // listeners inherited from the old process
+	parent    int                     // PID of the old process, 0 if not started by Upgrade
+	parsed    bool
+	parseErr  error
+	active    []activeListener // listeners which will be passed to the new process
+
+	draining  int32
+	readyOnce sync.Once
+)
+
+type activeListener struct {
+	key      string
+	listener fileListener
+}
+
+// Listen returns listener inherited from the old process for given network and address,
+// or creates new listener if there is none. Listeners returned by Listen are passed
+// to the new process by Upgrade until they are closed.
+func Listen(network, address string) (net.Listener, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := parseInherited(); err != nil {
+		return nil, err
+	}
+	key := network + ":" + address
+	l, ok := inherited[key]
+	if ok {
+		delete(inherited, key)
+	} else {
+		var err error
+		if l, err = net.Listen(network, address); err != nil {
+			return nil, err
+		}
+	}
+	if fl, ok := l.(fileListener); ok {
+		pruneClosed()
+		active = append(active, activeListener{key: key, listener: fl})
+	}
+	return l, nil
+}
+
+// Inherited returns true if the process was started by Upgrade.
+func Inherited() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the error is returned by Listen and Ready
+	parseInherited()
+	return parent != 0
+}
+
+// Draining returns true once the new process was started by Upgrade. Servers should
+// drain active connections instead of dropping them when closed.
+func Draining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+// Upgrade starts new instance of the running binary with the same arguments, passing
+// all listeners created by Listen to it. The current process should keep running until
+// the new process calls Ready.
+func Upgrade() (*os.Process, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		keys  []string
+		files = []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	)
+	pruneClosed()
+	for _, al := range active {
+		f, err := al.listener.File()
+		if err != nil {
+			closeFiles(files[firstInheritedFd:])
+			return nil, fmt.Errorf("failed to pass listener %s: %v", al.key, err)
+		}
+		keys = append(keys, al.key)
+		files = append(files, f)
+	}
+	defer closeFiles(files[firstInheritedFd:])
+
+	env := append(filterEnv(os.Environ()),
+		ListenersEnvVar+"="+strings.Join(keys, ";"),
+		ParentEnvVar+"="+strconv.Itoa(os.Getpid()),
+	)
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   env,
+		Files: files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	atomic.StoreInt32(&draining, 1)
+	return process, nil
+}
+
+// Ready notifies the old process that the new process is ready to serve, so that
+// the old process can stop. Inherited listeners which were not requested by Listen
+// until then are closed. It does nothing if the process was not started by Upgrade.
+func Ready() (err error) {
+	readyOnce.Do(func() {
+		mu.Lock()
+		perr := parseInherited()
+		pid := parent
+		closeUnclaimed()
+		mu.Unlock()
+
+		if pid == 0 {
+			err = perr
+			return
+		}
+		err = syscall.Kill(pid, syscall.SIGTERM)
+	})
+	return err
+}
+
+// HandleSignal calls Upgrade whenever the signal is received. Returned function
+// stops handling of the signal.
+func HandleSignal(sig os.Signal, log logging.Logger) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, sig)
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				process, err := Upgrade()
+				if err != nil {
+					log.Errorf("agent upgrade failed: %v", err)
+					continue
+				}
+				log.Infof("agent upgrade started (new PID: %d), waiting for the new process to be ready", process.Pid)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigChan)
+			close(done)
+		})
+	}
+}
+
+// parseInherited reads PID of the old process and listeners passed by it,
+// must be called with the lock held
+func parseInherited() error {
+	if parsed {
+		return parseErr
+	}
+	parsed = true
+	inherited = make(map[string]net.Listener)
+	parseErr = parseHandoverEnv()
+	return parseErr
+}
+
+func parseHandoverEnv() error {
+	// do not pass the variables to processes started by the agent
+	// (e.g. a child calling Ready would signal a stale PID)
+	parentValue := os.Getenv(ParentEnvVar)
+	os.Unsetenv(ParentEnvVar)
+	value := os.Getenv(ListenersEnvVar)
+	os.Unsetenv(ListenersEnvVar)
+
+	if parentValue != "" {
+		pid, err := strconv.Atoi(parentValue)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", ParentEnvVar, err)
+		}
+		parent = pid
+	}
+	if value == "" {
+		return nil
+	}
+	for i, key := range strings.Split(value, ";") {
+		f := os.NewFile(uintptr(firstInheritedFd+i), key)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to inherit listener %s: %v", key, err)
+		}
+		inherited[key] = l
+	}
+	return nil
+}
+
+// closeUnclaimed closes and forgets inherited listeners which were not requested
+// by Listen (e.g. the port was changed by the new configuration),
+// must be called with the lock held
+func closeUnclaimed() {
+	for key, l := range inherited {
+		logrus.DefaultLogger().Warnf("Closing listener %s inherited from the old process, it is not used", key)
+		if err := l.Close(); err != nil {
+			logrus.DefaultLogger().Warnf("Closing inherited listener %s failed: %v", key, err)
+		}
+		delete(inherited, key)
+	}
+}
+
+// pruneClosed forgets listeners which were closed since they were created,
+// must be called with the lock held
+func pruneClosed() {
+	open := active[:0]
+	for _, al := range active {
+		if !isClosed(al.listener) {
+			open = append(open, al)
+		}
+	}
+	for i := len(open); i < len(active); i++ {
+		active[i] = activeListener{}
+	}
+	active = open
+}
+
+// isClosed returns true if the file descriptor of the listener was closed
+func isClosed(l fileListener) bool {
+	rc, err := l.SyscallConn()
+	if err != nil {
+		return true
+	}
+	return rc.Control(func(fd uintptr) {}) != nil
+}
+
+// filterEnv removes handover variables inherited from the previous upgrade
+func filterEnv(env []string) (filtered []string) {
+	for _, e := range env {
+		if strings.HasPrefix(e, ListenersEnvVar+"=") || strings.HasPrefix(e, ParentEnvVar+"=") {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
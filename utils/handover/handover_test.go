@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handover
+
+import (
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// resetListeners forgets the listeners created and inherited by previous tests.
+func resetListeners() {
+	mu.Lock()
+	defer mu.Unlock()
+	inherited, parent, parsed, parseErr, active = nil, 0, false, nil, nil
+	readyOnce = sync.Once{}
+}
+
+func TestListen(t *testing.T) {
+	RegisterTestingT(t)
+
+	resetListeners()
+	defer resetListeners()
+	l, err := Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	defer l.Close()
+
+	Expect(active).To(HaveLen(1))
+	Expect(active[0].key).To(Equal("tcp:127.0.0.1:0"))
+	Expect(Draining()).To(BeFalse())
+}
+
+func TestListenAfterClose(t *testing.T) {
+	RegisterTestingT(t)
+
+	resetListeners()
+	defer resetListeners()
+	l, err := Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(l.Close()).To(Succeed())
+
+	// closed listener is not passed to the new process
+	l, err = Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	defer l.Close()
+	Expect(active).To(HaveLen(1))
+	Expect(isClosed(active[0].listener)).To(BeFalse())
+
+	Expect(l.Close()).To(Succeed())
+	mu.Lock()
+	pruneClosed()
+	mu.Unlock()
+	Expect(active).To(BeEmpty())
+}
+
+func TestReadyWithoutUpgrade(t *testing.T) {
+	RegisterTestingT(t)
+
+	resetListeners()
+	defer resetListeners()
+	Expect(Inherited()).To(BeFalse())
+	Expect(Ready()).To(Succeed())
+}
+
+func TestReadyClosesUnclaimedListeners(t *testing.T) {
+	RegisterTestingT(t)
+
+	resetListeners()
+	defer resetListeners()
+	claimed, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	unclaimed, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	mu.Lock()
+	parsed = true
+	inherited = map[string]net.Listener{"tcp:claimed": claimed, "tcp:unclaimed": unclaimed}
+	mu.Unlock()
+
+	l, err := Listen("tcp", "claimed")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(l).To(BeIdenticalTo(claimed))
+	defer l.Close()
+
+	Expect(Ready()).To(Succeed())
+	Expect(inherited).To(BeEmpty())
+	Expect(isClosed(unclaimed.(fileListener))).To(BeTrue())
+	Expect(isClosed(claimed.(fileListener))).To(BeFalse())
+}
+
+func TestParentNotPassedToChildren(t *testing.T) {
+	RegisterTestingT(t)
+
+	resetListeners()
+	defer resetListeners()
+	os.Setenv(ParentEnvVar, "42")
+	defer os.Unsetenv(ParentEnvVar)
+
+	Expect(Inherited()).To(BeTrue())
+	Expect(parent).To(Equal(42))
+	_, found := os.LookupEnv(ParentEnvVar)
+	Expect(found).To(BeFalse())
+	Expect(Inherited()).To(BeTrue())
+}
+
+func TestFilterEnv(t *testing.T) {
+	RegisterTestingT(t)
+
+	env := filterEnv([]string{
+		"PATH=/bin",
+		ListenersEnvVar + "=tcp:0.0.0.0:9191",
+		ParentEnvVar + "=42",
+	})
+	Expect(env).To(Equal([]string{"PATH=/bin"}))
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Names of the supported limiter algorithms
+const (
+	// AlgorithmTokenBucket selects token bucket limiter (default)
+	AlgorithmTokenBucket = "token-bucket"
+	// AlgorithmSlidingWindow selects sliding window limiter
+	AlgorithmSlidingWindow = "sliding-window"
+)
+
+// Config allows to bind rate limit from plugin configuration files:
+//
+//	algorithm: token-bucket
+//	limit: 100
+//	interval: 1s
+//	burst: 20
+type Config struct {
+	// Algorithm is either token-bucket (default) or sliding-window.
+	Algorithm string `json:"algorithm"`
+	// Limit is the number of events allowed per interval.
+	Limit int `json:"limit"`
+	// Interval is the length of the period the limit applies to (1s by default).
+	Interval time.Duration `json:"interval"`
+	// Burst is the capacity of the token bucket (equals to limit by default).
+	Burst int `json:"burst"`
+	// IdleTimeout is used by keyed limiters to drop limits of inactive keys. It must not be
+	// shorter than the time the limit of a key still applies after its last event, i.e. the time
+	// to refill the token bucket (interval * burst / limit) or the sliding window (interval).
+	// By default it is 10m or that time, whichever is longer.
+	IdleTimeout time.Duration `json:"idle-timeout"`
+}
+
+// Validate checks whether the config defines valid limit.
+func (c Config) Validate() error {
+	if c.Limit <= 0 {
+		return fmt.Errorf("rate limit must be positive, got %d", c.Limit)
+	}
+	if c.Interval < 0 || c.Burst < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("rate limit interval, burst and idle timeout must not be negative")
+	}
+	if min := c.minIdleTimeout(); c.IdleTimeout != 0 && c.IdleTimeout < min {
+		return fmt.Errorf("rate limit idle timeout %v is shorter than the time the limit applies (%v)", c.IdleTimeout, min)
+	}
+	switch c.Algorithm {
+	case "", AlgorithmTokenBucket, AlgorithmSlidingWindow:
+		return nil
+	}
+	return fmt.Errorf("unknown rate limit algorithm %q", c.Algorithm)
+}
+
+// NewLimiter creates limiter defined by the config.
+func (c Config) NewLimiter() (Limiter, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c.newLimiter(), nil
+}
+
+// NewKeyed creates keyed limiter using limiter defined by the config for every key.
+func (c Config) NewKeyed() (*Keyed, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	idleTimeout := c.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = defaultIdleTimeout
+		if min := c.minIdleTimeout(); idleTimeout < min {
+			idleTimeout = min
+		}
+	}
+	return NewKeyed(c.newLimiter, idleTimeout), nil
+}
+
+func (c Config) newLimiter() Limiter {
+	if c.Algorithm == AlgorithmSlidingWindow {
+		return NewSlidingWindow(c.Limit, c.interval())
+	}
+	return NewTokenBucket(float64(c.Limit)/c.interval().Seconds(), c.burst())
+}
+
+// RetryAfter returns the time after which a client which exceeded the limit can try again,
+// i.e. the time to refill one token of the token bucket or the interval of the sliding window.
+func (c Config) RetryAfter() time.Duration {
+	if c.Algorithm == AlgorithmSlidingWindow {
+		return c.interval()
+	}
+	return c.interval() / time.Duration(c.Limit)
+}
+
+// interval returns the interval with the default applied.
+func (c Config) interval() time.Duration {
+	if c.Interval == 0 {
+		return time.Second
+	}
+	return c.Interval
+}
+
+// burst returns the burst with the default applied.
+func (c Config) burst() int {
+	if c.Burst == 0 {
+		return c.Limit
+	}
+	return c.Burst
+}
+
+// minIdleTimeout returns the shortest idle timeout which does not drop limits of keys still in effect.
+func (c Config) minIdleTimeout() time.Duration {
+	if c.Algorithm == AlgorithmSlidingWindow {
+		return c.interval()
+	}
+	// time to refill an empty bucket at <limit> tokens per interval
+	return c.interval() * time.Duration(c.burst()) / time.Duration(c.Limit)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit provides rate limiters (token bucket and sliding window)
+// that can be bound from plugin configuration files, and a keyed limiter
+// keeping separate limits for individual clients (e.g. per IP address or user).
+//
+// Example:
+//
+//	limiter := ratelimit.NewTokenBucket(10, 20) // 10 events per second, bursts of 20
+//	if !limiter.Allow() {
+//	    return errTooManyRequests
+//	}
+package ratelimit
@@ -0,0 +1,111 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultIdleTimeout is used to drop limiters of inactive keys if not set otherwise
+const defaultIdleTimeout = 10 * time.Minute
+
+// Keyed keeps separate limiter for every key (e.g. client address or user
+// name). Limiters of keys which were not used for the idle timeout are dropped.
+type Keyed struct {
+	mu          sync.Mutex
+	newLimiter  func() Limiter
+	idleTimeout time.Duration
+	limiters    map[string]*keyedEntry
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+type keyedEntry struct {
+	limiter  Limiter
+	lastUsed time.Time
+}
+
+// NewKeyed creates keyed limiter using <newLimiter> to create limiter for
+// every new key. Default idle timeout (10 minutes) is used if <idleTimeout>
+// is zero.
+func NewKeyed(newLimiter func() Limiter, idleTimeout time.Duration) *Keyed {
+	if idleTimeout == 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+	return &Keyed{
+		newLimiter:  newLimiter,
+		idleTimeout: idleTimeout,
+		limiters:    make(map[string]*keyedEntry),
+		lastSweep:   time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Allow reports whether an event for the given key may happen now.
+func (k *Keyed) Allow(key string) bool {
+	k.mu.Lock()
+	now := k.now()
+	k.sweep(now)
+	entry, ok := k.limiters[key]
+	if !ok {
+		entry = &keyedEntry{limiter: k.newLimiter()}
+		k.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	k.mu.Unlock()
+
+	return entry.limiter.Allow()
+}
+
+// Peek reports whether an event for the given key would be allowed now
+// without accounting it. This allows to account only some events (e.g. failed
+// login attempts) while rejecting all of them once the limit is reached.
+// Limiters which cannot report it without accounting the event (other than
+// TokenBucket and SlidingWindow) always allow it.
+func (k *Keyed) Peek(key string) bool {
+	k.mu.Lock()
+	now := k.now()
+	k.sweep(now)
+	entry, ok := k.limiters[key]
+	k.mu.Unlock()
+	if !ok {
+		return true
+	}
+	if p, ok := entry.limiter.(peeker); ok {
+		return p.peek()
+	}
+	return true
+}
+
+// Len returns number of keys with active limiter.
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.limiters)
+}
+
+// sweep drops limiters of idle keys at most once per idle timeout, must be called with the lock held
+func (k *Keyed) sweep(now time.Time) {
+	if now.Sub(k.lastSweep) < k.idleTimeout {
+		return
+	}
+	k.lastSweep = now
+	for key, entry := range k.limiters {
+		if now.Sub(entry.lastUsed) >= k.idleTimeout {
+			delete(k.limiters, key)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter decides whether an event may happen now.
+type Limiter interface {
+	// Allow reports whether an event may happen now. The event is accounted
+	// if it is allowed.
+	Allow() bool
+}
+
+// peeker is implemented by the limiters which can report whether an event
+// would be allowed without accounting it.
+type peeker interface {
+	peek() bool
+}
+
+// TokenBucket is a limiter which allows events at the given rate with bursts
+// of up to the bucket capacity. The bucket is full initially.
+type TokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens added per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucket creates token bucket refilled by <rate> tokens per second
+// with capacity of <burst> tokens. Burst lower than 1 is set to 1.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return newTokenBucket(rate, burst, time.Now)
+}
+
+func newTokenBucket(rate float64, burst int, now func() time.Time) *TokenBucket {
+	return &TokenBucket{
+		rate:     rate,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     now(),
+		now:      now,
+	}
+}
+
+// Allow takes one token from the bucket if available.
+func (tb *TokenBucket) Allow() bool {
+	return tb.AllowN(1)
+}
+
+// AllowN takes <n> tokens from the bucket if available.
+func (tb *TokenBucket) AllowN(n int) bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill()
+	if tb.tokens < float64(n) {
+		return false
+	}
+	tb.tokens -= float64(n)
+	return true
+}
+
+// Wait blocks until a token is available or the context is done.
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		tb.mu.Lock()
+		tb.refill()
+		if tb.tokens >= 1 {
+			tb.tokens--
+			tb.mu.Unlock()
+			return nil
+		}
+		if tb.rate <= 0 {
+			tb.mu.Unlock()
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		delay := time.Duration(math.Ceil((1 - tb.tokens) / tb.rate * float64(time.Second)))
+		tb.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// peek reports whether a token is available without taking it.
+func (tb *TokenBucket) peek() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refill()
+	return tb.tokens >= 1
+}
+
+// refill adds tokens accumulated since the last refill, must be called with the lock held
+func (tb *TokenBucket) refill() {
+	now := tb.now()
+	elapsed := now.Sub(tb.last)
+	tb.last = now
+	if elapsed <= 0 {
+		return
+	}
+	tb.tokens = math.Min(tb.capacity, tb.tokens+elapsed.Seconds()*tb.rate)
+}
+
+// SlidingWindow is a limiter which allows at most <limit> events within any
+// time window of the given length. The number of events in the window is
+// approximated from the counters of the current and the previous window.
+type SlidingWindow struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	start    time.Time // start of the current window
+	current  int
+	previous int
+	now      func() time.Time
+}
+
+// NewSlidingWindow creates limiter allowing <limit> events per <window>.
+// Window of one second is used if not set.
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	if window <= 0 {
+		window = time.Second
+	}
+	return newSlidingWindow(limit, window, time.Now)
+}
+
+func newSlidingWindow(limit int, window time.Duration, now func() time.Time) *SlidingWindow {
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		start:  now(),
+		now:    now,
+	}
+}
+
+// Allow reports whether the event fits into the limit of the sliding window.
+func (sw *SlidingWindow) Allow() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if !sw.fits() {
+		return false
+	}
+	sw.current++
+	return true
+}
+
+// peek reports whether the event would fit into the limit without accounting it.
+func (sw *SlidingWindow) peek() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.fits()
+}
+
+// fits reports whether another event fits into the limit, must be called with the lock held
+func (sw *SlidingWindow) fits() bool {
+	now := sw.now()
+	if elapsed := now.Sub(sw.start); elapsed >= sw.window {
+		// move to the next window, the previous one is kept only if adjacent
+		if elapsed < 2*sw.window {
+			sw.previous = sw.current
+		} else {
+			sw.previous = 0
+		}
+		sw.current = 0
+		sw.start = sw.start.Add(elapsed / sw.window * sw.window)
+	}
+
+	// weight of the previous window decreases as the current window progresses
+	weight := 1 - float64(now.Sub(sw.start))/float64(sw.window)
+	return float64(sw.previous)*weight+float64(sw.current) < float64(sw.limit)
+}
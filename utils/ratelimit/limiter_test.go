@@ -0,0 +1,207 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// fakeClock allows to move time in tests
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func TestTokenBucket(t *testing.T) {
+	RegisterTestingT(t)
+
+	clock := &fakeClock{t: time.Now()}
+	tb := newTokenBucket(2, 3, clock.now)
+
+	// burst
+	Expect(tb.Allow()).To(BeTrue())
+	Expect(tb.Allow()).To(BeTrue())
+	Expect(tb.Allow()).To(BeTrue())
+	Expect(tb.Allow()).To(BeFalse())
+
+	// refill of 2 tokens per second
+	clock.advance(500 * time.Millisecond)
+	Expect(tb.Allow()).To(BeTrue())
+	Expect(tb.Allow()).To(BeFalse())
+
+	// bucket does not overflow
+	clock.advance(time.Minute)
+	Expect(tb.AllowN(3)).To(BeTrue())
+	Expect(tb.Allow()).To(BeFalse())
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	RegisterTestingT(t)
+
+	tb := NewTokenBucket(100, 1)
+	Expect(tb.Wait(context.Background())).To(Succeed())
+	Expect(tb.Wait(context.Background())).To(Succeed())
+
+	// bucket which is never refilled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	empty := NewTokenBucket(0, 1)
+	Expect(empty.Wait(ctx)).To(Succeed())
+	Expect(empty.Wait(ctx)).To(MatchError(context.Canceled))
+}
+
+func TestSlidingWindow(t *testing.T) {
+	RegisterTestingT(t)
+
+	clock := &fakeClock{t: time.Now()}
+	sw := newSlidingWindow(4, time.Second, clock.now)
+
+	for i := 0; i < 4; i++ {
+		Expect(sw.Allow()).To(BeTrue())
+	}
+	Expect(sw.Allow()).To(BeFalse())
+
+	// half of the previous window still counts
+	clock.advance(1500 * time.Millisecond)
+	Expect(sw.Allow()).To(BeTrue())
+	Expect(sw.Allow()).To(BeTrue())
+	Expect(sw.Allow()).To(BeFalse())
+
+	// previous window is forgotten after two windows
+	clock.advance(3 * time.Second)
+	for i := 0; i < 4; i++ {
+		Expect(sw.Allow()).To(BeTrue())
+	}
+	Expect(sw.Allow()).To(BeFalse())
+}
+
+func TestKeyed(t *testing.T) {
+	RegisterTestingT(t)
+
+	clock := &fakeClock{t: time.Now()}
+	k := NewKeyed(func() Limiter { return newSlidingWindow(1, time.Hour, clock.now) }, time.Minute)
+	k.now = clock.now
+	k.lastSweep = clock.now()
+
+	Expect(k.Allow("a")).To(BeTrue())
+	Expect(k.Allow("a")).To(BeFalse())
+	Expect(k.Allow("b")).To(BeTrue())
+	Expect(k.Len()).To(Equal(2))
+
+	// idle keys are dropped
+	clock.advance(2 * time.Minute)
+	Expect(k.Allow("a")).To(BeTrue())
+	Expect(k.Len()).To(Equal(1))
+}
+
+func TestKeyedPeek(t *testing.T) {
+	RegisterTestingT(t)
+
+	clock := &fakeClock{t: time.Now()}
+	k := NewKeyed(func() Limiter { return newTokenBucket(1, 2, clock.now) }, time.Minute)
+	k.now = clock.now
+	k.lastSweep = clock.now()
+
+	// peeking neither creates the limiter nor takes a token
+	Expect(k.Peek("a")).To(BeTrue())
+	Expect(k.Len()).To(Equal(0))
+	Expect(k.Allow("a")).To(BeTrue())
+	Expect(k.Peek("a")).To(BeTrue())
+	Expect(k.Allow("a")).To(BeTrue())
+	Expect(k.Peek("a")).To(BeFalse())
+	Expect(k.Peek("b")).To(BeTrue())
+
+	clock.advance(time.Second)
+	Expect(k.Peek("a")).To(BeTrue())
+}
+
+func TestConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := Config{}.NewLimiter()
+	Expect(err).To(HaveOccurred())
+	_, err = Config{Limit: 1, Algorithm: "leaky-bucket"}.NewLimiter()
+	Expect(err).To(HaveOccurred())
+
+	l, err := Config{Limit: 10, Interval: time.Minute}.NewLimiter()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(l).To(BeAssignableToTypeOf(&TokenBucket{}))
+	Expect(l.(*TokenBucket).capacity).To(BeEquivalentTo(10))
+
+	l, err = Config{Limit: 10, Algorithm: AlgorithmSlidingWindow}.NewLimiter()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(l).To(BeAssignableToTypeOf(&SlidingWindow{}))
+}
+
+func TestConfigIdleTimeout(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, err := Config{Limit: 10}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(defaultIdleTimeout))
+
+	// limits of idle keys are not dropped before the bucket is refilled
+	k, err = Config{Limit: 1, Interval: time.Minute, Burst: 20}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(20 * time.Minute))
+
+	_, err = Config{Limit: 1, Interval: time.Minute, Burst: 20, IdleTimeout: 10 * time.Minute}.NewKeyed()
+	Expect(err).To(HaveOccurred())
+	k, err = Config{Limit: 1, Interval: time.Minute, Burst: 20, IdleTimeout: time.Hour}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(time.Hour))
+
+	// the bucket is refilled at <limit> tokens per interval
+	k, err = Config{Limit: 4, Interval: time.Minute, Burst: 20}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(defaultIdleTimeout))
+	k, err = Config{Limit: 2, Interval: time.Hour, Burst: 10}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(5 * time.Hour))
+
+	// sliding window applies for one interval
+	k, err = Config{Limit: 1, Interval: time.Hour, Burst: 20, Algorithm: AlgorithmSlidingWindow}.NewKeyed()
+	Expect(err).ToNot(HaveOccurred())
+	Expect(k.idleTimeout).To(Equal(time.Hour))
+}
+
+func TestConfigRetryAfter(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Config{Limit: 10}.RetryAfter()).To(Equal(100 * time.Millisecond))
+	Expect(Config{Limit: 2, Interval: time.Minute, Burst: 20}.RetryAfter()).To(Equal(30 * time.Second))
+	Expect(Config{Limit: 2, Interval: time.Minute, Algorithm: AlgorithmSlidingWindow}.RetryAfter()).To(Equal(time.Minute))
+}
+
+func TestConfigValidateIdleTimeout(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Config{Limit: 100, Interval: time.Second, Burst: 100, IdleTimeout: time.Minute}.Validate()).To(Succeed())
+	Expect(Config{Limit: 100, Interval: time.Second, Burst: 100, IdleTimeout: time.Second}.Validate()).To(Succeed())
+	Expect(Config{Limit: 100, Interval: time.Second, Burst: 100, IdleTimeout: time.Second / 2}.Validate()).ToNot(Succeed())
+	Expect(Config{Limit: 10, Interval: time.Minute, Algorithm: AlgorithmSlidingWindow, IdleTimeout: time.Minute}.Validate()).To(Succeed())
+	Expect(Config{Limit: 10, Interval: time.Minute, Algorithm: AlgorithmSlidingWindow, IdleTimeout: time.Second}.Validate()).ToNot(Succeed())
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff computes delay before the next attempt of the operation.
+type Backoff interface {
+	// Delay returns the delay after the given failed attempt (attempts are numbered from 1).
+	Delay(attempt int) time.Duration
+}
+
+// BackoffFunc is an adapter allowing to use ordinary function as Backoff.
+type BackoffFunc func(attempt int) time.Duration
+
+// Delay calls f(attempt).
+func (f BackoffFunc) Delay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ConstantBackoff returns Backoff that always waits the same interval.
+func ConstantBackoff(interval time.Duration) Backoff {
+	return BackoffFunc(func(int) time.Duration {
+		return interval
+	})
+}
+
+// ExponentialBackoff returns Backoff that doubles the delay after every attempt,
+// starting with <initial> and never exceeding <max> (zero max means no limit).
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return &Exponential{Initial: initial, Max: max, Multiplier: DefaultMultiplier}
+}
+
+// Exponential is Backoff that multiplies the delay by Multiplier after every attempt.
+type Exponential struct {
+	// Initial is the delay after the first attempt
+	Initial time.Duration
+	// Max limits the delay (zero means no limit)
+	Max time.Duration
+	// Multiplier is applied to the delay after every attempt (values lower than 1 are replaced by DefaultMultiplier)
+	Multiplier float64
+}
+
+// Delay implements Backoff.
+func (e *Exponential) Delay(attempt int) time.Duration {
+	multiplier := e.Multiplier
+	if multiplier < 1 {
+		multiplier = DefaultMultiplier
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(e.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if e.Max > 0 && delay > float64(e.Max) {
+		return e.Max
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// WithJitter wraps the backoff and randomizes every delay by up to +/- factor
+// (e.g. factor 0.2 randomizes the delay within 80%-120% of the original value).
+func WithJitter(backoff Backoff, factor float64) Backoff {
+	if factor <= 0 {
+		return backoff
+	}
+	if factor > 1 {
+		factor = 1
+	}
+	return &jitter{
+		backoff: backoff,
+		factor:  factor,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type jitter struct {
+	backoff Backoff
+	factor  float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Delay implements Backoff.
+func (j *jitter) Delay(attempt int) time.Duration {
+	delay := float64(j.backoff.Delay(attempt))
+
+	j.mu.Lock()
+	r := j.rand.Float64()
+	j.mu.Unlock()
+
+	// uniformly distributed within [delay - factor*delay, delay + factor*delay]
+	return time.Duration(delay - j.factor*delay + r*2*j.factor*delay)
+}
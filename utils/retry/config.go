@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import "time"
+
+// Config allows to bind retry behavior from plugin configuration files:
+//
+//   max-attempts: 5
+//   initial-interval: 100ms
+//   max-interval: 10s
+//   multiplier: 2
+//   jitter: 0.2
+type Config struct {
+	// MaxAttempts is the maximum number of attempts including the first one (DefaultMaxAttempts
+	// if 0, -1 means unlimited).
+	MaxAttempts int `json:"max-attempts"`
+	// InitialInterval is the delay after the first failed attempt.
+	InitialInterval time.Duration `json:"initial-interval"`
+	// MaxInterval limits the delay between attempts (0 means no limit).
+	MaxInterval time.Duration `json:"max-interval"`
+	// Multiplier is applied to the delay after every attempt (1 for constant backoff, 2 by default).
+	Multiplier float64 `json:"multiplier"`
+	// Jitter randomizes delays by up to +/- given factor (0 disables jitter).
+	Jitter float64 `json:"jitter"`
+}
+
+// Backoff returns backoff strategy defined by the config.
+func (c Config) Backoff() Backoff {
+	initial := c.InitialInterval
+	if initial == 0 {
+		initial = DefaultInitialInterval
+	}
+	multiplier := c.Multiplier
+	if multiplier == 0 {
+		multiplier = DefaultMultiplier
+	}
+	var backoff Backoff = &Exponential{Initial: initial, Max: c.MaxInterval, Multiplier: multiplier}
+	if multiplier == 1 {
+		backoff = ConstantBackoff(initial)
+	}
+	return WithJitter(backoff, c.Jitter)
+}
+
+// Options returns options for Do defined by the config.
+func (c Config) Options() []Option {
+	return []Option{
+		WithBackoff(c.Backoff()),
+		WithMaxAttempts(c.MaxAttempts),
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry provides utilities for retrying operations with configurable
+// backoff strategies (constant, exponential), jitter, context awareness
+// and hooks called before every retry.
+//
+// Example:
+//
+//   err := retry.Do(ctx, func() error {
+//       return connect()
+//   }, retry.WithBackoff(retry.ExponentialBackoff(time.Second, time.Minute)),
+//       retry.WithMaxAttempts(5),
+//       retry.OnRetry(func(attempt int, delay time.Duration, err error) {
+//           log.Warnf("attempt %d failed: %v, retrying in %v", attempt, err, delay)
+//       }))
+package retry
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts used when no other limit is set.
+	DefaultMaxAttempts = 3
+	// DefaultInitialInterval is the delay after the first failed attempt used by default.
+	DefaultInitialInterval = 100 * time.Millisecond
+	// DefaultMultiplier is used by exponential backoff to compute next delay.
+	DefaultMultiplier = 2.0
+)
+
+// Unlimited can be used with WithMaxAttempts to retry until the operation succeeds
+// or the context is canceled (any negative number has the same effect).
+const Unlimited = -1
+
+// Func is the operation that is retried.
+type Func func() error
+
+// permanentError marks error which should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Permanent wraps the error to signal that the operation should not be retried.
+// Do returns the original (unwrapped) error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if the error was marked by Permanent.
+func IsPermanent(err error) bool {
+	_, ok := err.(*permanentError)
+	return ok
+}
+
+type options struct {
+	backoff     Backoff
+	maxAttempts int
+	onRetry     []func(attempt int, delay time.Duration, err error)
+	retryIf     func(err error) bool
+}
+
+// Option customizes behavior of Do.
+type Option func(*options)
+
+// WithBackoff sets the backoff strategy (by default exponential backoff starting
+// at DefaultInitialInterval is used).
+func WithBackoff(backoff Backoff) Option {
+	return func(o *options) {
+		o.backoff = backoff
+	}
+}
+
+// WithMaxAttempts sets the maximum number of attempts including the first one
+// (DefaultMaxAttempts by default or if 0, Unlimited for no limit).
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		if n == 0 {
+			n = DefaultMaxAttempts
+		}
+		o.maxAttempts = n
+	}
+}
+
+// OnRetry registers hook called after every failed attempt that is going to be retried,
+// with the attempt number, delay before the next attempt and the error.
+func OnRetry(hook func(attempt int, delay time.Duration, err error)) Option {
+	return func(o *options) {
+		o.onRetry = append(o.onRetry, hook)
+	}
+}
+
+// If sets predicate deciding whether the error is retriable (all errors except
+// those marked by Permanent are retried by default).
+func If(retriable func(err error) bool) Option {
+	return func(o *options) {
+		o.retryIf = retriable
+	}
+}
+
+// Do calls the function until it succeeds, the number of attempts is exhausted,
+// the error is not retriable or the context is canceled. The last error returned by the function
+// is returned (or the context error if the context was canceled before the first attempt).
+func Do(ctx context.Context, fn Func, opts ...Option) error {
+	o := options{
+		backoff:     ExponentialBackoff(DefaultInitialInterval, 0),
+		maxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if perm, ok := err.(*permanentError); ok {
+			return perm.err
+		}
+		if o.retryIf != nil && !o.retryIf(err) {
+			return err
+		}
+		if o.maxAttempts > 0 && attempt >= o.maxAttempts {
+			return err
+		}
+
+		delay := o.backoff.Delay(attempt)
+		for _, hook := range o.onRetry {
+			hook(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDoSucceeds(t *testing.T) {
+	RegisterTestingT(t)
+
+	var attempts int
+	err := Do(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithBackoff(ConstantBackoff(time.Millisecond)))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(attempts).To(Equal(3))
+}
+
+func TestDoMaxAttempts(t *testing.T) {
+	RegisterTestingT(t)
+
+	var (
+		attempts int
+		retries  []int
+	)
+	err := Do(context.Background(), func() error {
+		attempts++
+		return errors.New("failed")
+	}, WithBackoff(ConstantBackoff(time.Millisecond)), WithMaxAttempts(4),
+		OnRetry(func(attempt int, delay time.Duration, err error) {
+			retries = append(retries, attempt)
+		}))
+	Expect(err).To(MatchError("failed"))
+	Expect(attempts).To(Equal(4))
+	Expect(retries).To(Equal([]int{1, 2, 3}))
+}
+
+func TestDoPermanent(t *testing.T) {
+	RegisterTestingT(t)
+
+	var attempts int
+	err := Do(context.Background(), func() error {
+		attempts++
+		return Permanent(errors.New("permanent"))
+	}, WithMaxAttempts(Unlimited))
+	Expect(err).To(MatchError("permanent"))
+	Expect(IsPermanent(err)).To(BeFalse())
+	Expect(attempts).To(Equal(1))
+}
+
+func TestDoContextCanceled(t *testing.T) {
+	RegisterTestingT(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int
+	err := Do(ctx, func() error {
+		attempts++
+		cancel()
+		return errors.New("failed")
+	}, WithBackoff(ConstantBackoff(time.Hour)), WithMaxAttempts(Unlimited))
+	Expect(err).To(MatchError("failed"))
+	Expect(attempts).To(Equal(1))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	RegisterTestingT(t)
+
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+	Expect(backoff.Delay(1)).To(Equal(time.Second))
+	Expect(backoff.Delay(2)).To(Equal(2 * time.Second))
+	Expect(backoff.Delay(3)).To(Equal(4 * time.Second))
+	Expect(backoff.Delay(4)).To(Equal(5 * time.Second))
+	Expect(backoff.Delay(100)).To(Equal(5 * time.Second))
+}
+
+func TestJitter(t *testing.T) {
+	RegisterTestingT(t)
+
+	backoff := WithJitter(ConstantBackoff(time.Second), 0.5)
+	for i := 1; i < 100; i++ {
+		delay := backoff.Delay(i)
+		Expect(delay).To(BeNumerically(">=", 500*time.Millisecond))
+		Expect(delay).To(BeNumerically("<=", 1500*time.Millisecond))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	backoff := Config{InitialInterval: time.Second, Multiplier: 1}.Backoff()
+	Expect(backoff.Delay(10)).To(Equal(time.Second))
+
+	backoff = Config{InitialInterval: time.Second, MaxInterval: 3 * time.Second}.Backoff()
+	Expect(backoff.Delay(2)).To(Equal(2 * time.Second))
+	Expect(backoff.Delay(3)).To(Equal(3 * time.Second))
+}
+
+func TestConfigMaxAttempts(t *testing.T) {
+	RegisterTestingT(t)
+
+	attempts := func(cfg Config) int {
+		var n int
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Do(ctx, func() error {
+			if n++; n == 10 {
+				cancel()
+			}
+			return errors.New("failed")
+		}, append(cfg.Options(), WithBackoff(ConstantBackoff(time.Millisecond)))...)
+		return n
+	}
+	Expect(attempts(Config{})).To(Equal(DefaultMaxAttempts))
+	Expect(attempts(Config{MaxAttempts: 5})).To(Equal(5))
+	Expect(attempts(Config{MaxAttempts: Unlimited})).To(Equal(10))
+}